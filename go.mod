@@ -7,6 +7,7 @@ require (
 	gitlab.com/gitlab-org/api/client-go/v2 v2.58.1
 	golang.org/x/oauth2 v0.36.0
 	golang.org/x/sync v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
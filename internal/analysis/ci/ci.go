@@ -0,0 +1,161 @@
+package ci
+
+import (
+	"fmt"
+	"strings"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// States of a check, and of the CI of a head ref as a whole
+const (
+	StateSuccess = "success"
+	StateFailed  = "failed"
+	StatePending = "pending"
+	StateSkipped = "skipped"
+	StateMissing = "missing" // A required check never reported, or no check reported at all
+)
+
+// States lists the overall CI states policies can match
+var States = []string{StateFailed, StatePending, StateMissing}
+
+// Result is the CI status of one comparison's head ref
+type Result struct {
+	Repo   string          `json:"repo,omitempty"`
+	Ref    string          `json:"ref"`
+	URL    string          `json:"url,omitempty"`
+	State  string          `json:"state"`
+	Checks []types.CICheck `json:"checks,omitempty"`
+}
+
+// Analyze marks the required checks of each comparison and derives the overall state of its head ref
+// CI is failed when a required check or one that isn't allowed to fail failed, pending while checks are still running,
+// missing when no check reported or a required check never did, and success otherwise.
+// Comparisons whose CI couldn't be fetched are left out
+func Analyze(comparisons []*types.Comparison) []Result {
+	var results []Result
+	for _, comparison := range comparisons {
+		if comparison == nil || comparison.CI == nil {
+			continue
+		}
+
+		required := requiredChecks(comparison.RepoConfig)
+		reported := map[string]bool{}
+		checks := make([]types.CICheck, 0, len(comparison.CI.Checks))
+		for _, check := range comparison.CI.Checks {
+			name := strings.ToLower(check.Name)
+			check.Required = check.Required || required[name]
+			reported[name] = true
+			checks = append(checks, check)
+		}
+		for _, name := range requiredNames(comparison.RepoConfig) {
+			if !reported[strings.ToLower(name)] {
+				checks = append(checks, types.CICheck{Name: name, State: StateMissing, Required: true})
+				reported[strings.ToLower(name)] = true
+			}
+		}
+
+		results = append(results, Result{
+			Repo:   comparison.RepoURL,
+			Ref:    comparison.CI.Ref,
+			URL:    comparison.CI.URL,
+			State:  state(checks),
+			Checks: checks,
+		})
+	}
+	return results
+}
+
+// state derives the overall CI state from its checks
+func state(checks []types.CICheck) string {
+	if len(checks) == 0 {
+		return StateMissing
+	}
+
+	pending, missing := false, false
+	for _, check := range checks {
+		switch check.State {
+		case StateFailed:
+			if !check.AllowFailure || check.Required {
+				return StateFailed
+			}
+		case StatePending:
+			pending = true
+		case StateMissing:
+			missing = true
+		}
+	}
+	switch {
+	case pending:
+		return StatePending
+	case missing:
+		return StateMissing
+	default:
+		return StateSuccess
+	}
+}
+
+// requiredNames returns the required checks of the nearest config layer that lists any
+func requiredNames(cfg *types.RepoConfig) []string {
+	for layer := cfg; layer != nil; layer = layer.Base {
+		if len(layer.RequiredChecks) > 0 {
+			return layer.RequiredChecks
+		}
+	}
+	return nil
+}
+
+// requiredChecks returns the lowercase names of the required checks, which match check names case-insensitively
+func requiredChecks(cfg *types.RepoConfig) map[string]bool {
+	required := map[string]bool{}
+	for _, name := range requiredNames(cfg) {
+		required[strings.ToLower(name)] = true
+	}
+	return required
+}
+
+// Problems returns the checks of a result that failed, were skipped or never reported
+func Problems(result Result) []types.CICheck {
+	var problems []types.CICheck
+	for _, check := range result.Checks {
+		if check.State == StateFailed || check.State == StateSkipped || check.State == StateMissing {
+			problems = append(problems, check)
+		}
+	}
+	return problems
+}
+
+// Describe names a check with its state, e.g. "unit-tests (failed, required)"
+func Describe(check types.CICheck) string {
+	details := []string{check.State}
+	if check.Required {
+		details = append(details, "required")
+	}
+	if check.AllowFailure {
+		details = append(details, "allowed to fail")
+	}
+	return fmt.Sprintf("%s (%s)", check.Name, strings.Join(details, ", "))
+}
+
+// Evidence formats the CI status of each head ref as markdown for the prompt
+func Evidence(results []Result) string {
+	if len(results) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("**CI status of the release head** (check runs, commit statuses and pipeline jobs reported for the head ref):\n\n")
+	b.WriteString("| Repository | Ref | State | Checks | Failed, skipped or missing |\n")
+	b.WriteString("|------------|-----|-------|--------|----------------------------|\n")
+	for _, result := range results {
+		var problems []string
+		for _, check := range Problems(result) {
+			problems = append(problems, Describe(check))
+		}
+		if len(problems) == 0 {
+			problems = []string{"-"}
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %s |\n", result.Repo, result.Ref, result.State, len(result.Checks), strings.Join(problems, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package ci
+
+import (
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name     string
+		checks   []types.CICheck
+		required []string
+		expected string
+		problems []string
+	}{
+		{
+			name:     "all checks passed",
+			checks:   []types.CICheck{{Name: "build", State: StateSuccess}, {Name: "lint", State: StateSkipped}},
+			expected: StateSuccess,
+			problems: []string{"lint (skipped)"},
+		},
+		{
+			name:     "failed check",
+			checks:   []types.CICheck{{Name: "build", State: StateSuccess}, {Name: "unit-tests", State: StateFailed}, {Name: "e2e", State: StatePending}},
+			expected: StateFailed,
+			problems: []string{"unit-tests (failed)"},
+		},
+		{
+			name:     "failure allowed",
+			checks:   []types.CICheck{{Name: "build", State: StateSuccess}, {Name: "flaky", State: StateFailed, AllowFailure: true}},
+			expected: StateSuccess,
+			problems: []string{"flaky (failed, allowed to fail)"},
+		},
+		{
+			name:     "checks still running",
+			checks:   []types.CICheck{{Name: "build", State: StateSuccess}, {Name: "e2e", State: StatePending}},
+			expected: StatePending,
+		},
+		{
+			name:     "no checks",
+			expected: StateMissing,
+		},
+		{
+			name:     "required check never reported",
+			checks:   []types.CICheck{{Name: "Build", State: StateSuccess}},
+			required: []string{"build", "unit-tests"},
+			expected: StateMissing,
+			problems: []string{"unit-tests (missing, required)"},
+		},
+		{
+			name:     "required check failed",
+			checks:   []types.CICheck{{Name: "Build", State: StateFailed, AllowFailure: true}},
+			required: []string{"build"},
+			expected: StateFailed,
+			problems: []string{"Build (failed, required, allowed to fail)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := &types.Comparison{
+				RepoURL:    "https://github.com/org/api",
+				CI:         &types.CIStatus{Ref: "abc123", URL: "https://github.com/org/api/commit/abc123/checks", Checks: tt.checks},
+				RepoConfig: &types.RepoConfig{RequiredChecks: tt.required},
+			}
+
+			results := Analyze([]*types.Comparison{nil, {RepoURL: "https://github.com/org/docs"}, comparison})
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %+v", results)
+			}
+			if results[0].State != tt.expected {
+				t.Errorf("State = %q, want %q", results[0].State, tt.expected)
+			}
+
+			var problems []string
+			for _, check := range Problems(results[0]) {
+				problems = append(problems, Describe(check))
+			}
+			if strings.Join(problems, "\n") != strings.Join(tt.problems, "\n") {
+				t.Errorf("Problems() = %v, want %v", problems, tt.problems)
+			}
+		})
+	}
+}
+
+func TestAnalyze_GlobalRequiredChecks(t *testing.T) {
+	checks := []types.CICheck{{Name: "build", State: StateSuccess}}
+	comparison := &types.Comparison{
+		CI:         &types.CIStatus{Checks: checks},
+		RepoConfig: &types.RepoConfig{Base: &types.RepoConfig{RequiredChecks: []string{"build"}}},
+	}
+
+	results := Analyze([]*types.Comparison{comparison})
+	if !results[0].Checks[0].Required {
+		t.Error("expected the global required checks to apply")
+	}
+	if checks[0].Required {
+		t.Error("expected the comparison's checks to be left unchanged")
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	if Evidence(nil) != "" {
+		t.Error("expected no evidence without results")
+	}
+
+	evidence := Evidence([]Result{
+		{Repo: "https://github.com/org/api", Ref: "abc123", State: StateFailed, Checks: []types.CICheck{
+			{Name: "build", State: StateSuccess},
+			{Name: "unit-tests", State: StateFailed},
+			{Name: "e2e", State: StateMissing, Required: true},
+		}},
+		{Repo: "https://gitlab.com/org/worker", Ref: "v2", State: StateSuccess, Checks: []types.CICheck{{Name: "build", State: StateSuccess}}},
+	})
+	for _, want := range []string{
+		"**CI status of the release head**",
+		"| https://github.com/org/api | abc123 | failed | 3 | unit-tests (failed), e2e (missing, required) |",
+		"| https://gitlab.com/org/worker | v2 | success | 1 | - |",
+	} {
+		if !strings.Contains(evidence, want) {
+			t.Errorf("evidence missing %q:\n%s", want, evidence)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"release-confidence-score/internal/analysis/graphql"
+	"release-confidence-score/internal/analysis/openapi"
+	"release-confidence-score/internal/analysis/protobuf"
+	"release-confidence-score/internal/git/shared"
+	"release-confidence-score/internal/git/types"
+)
+
+// maxFiles bounds how many contract files are fetched at both refs per comparison
+const maxFiles = 20
+
+// differ compares two versions of a contract file
+type differ func(filename, base, head string) ([]types.APIChange, error)
+
+// differFor returns the differ for a contract file, or nil for other files
+func differFor(filename string) differ {
+	switch {
+	case openapi.IsSpec(filename):
+		return openapi.Diff
+	case protobuf.IsSchema(filename):
+		return protobuf.Diff
+	case graphql.IsSchema(filename):
+		return graphql.Diff
+	default:
+		return nil
+	}
+}
+
+// Annotate fetches each changed OpenAPI, Swagger, protobuf or GraphQL file at the base and head refs
+// and records its contract changes. Files that can't be fetched or parsed are skipped, since the raw patch
+// is still part of the analysis
+func Annotate(ctx context.Context, source types.DocumentationSource, files []types.FileChange, baseRef, headRef string) {
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(10) // Limit concurrent API calls to avoid rate limiting
+
+	candidates := 0
+	for i := range files {
+		diff := differFor(files[i].Filename)
+		if diff == nil || files[i].Generated != "" {
+			continue
+		}
+		if candidates == maxFiles {
+			slog.Warn("Too many API contract files to compare, skipping the rest", "limit", maxFiles)
+			break
+		}
+		candidates++
+
+		file := &files[i]
+		g.Go(func() error {
+			base, head, err := shared.FetchFileVersions(gCtx, source, *file, baseRef, headRef)
+			if err != nil {
+				slog.Debug("Skipping API contract diff", "file", file.Filename, "error", err)
+				return nil
+			}
+			changes, err := diff(file.Filename, base, head)
+			if err != nil {
+				slog.Debug("Skipping API contract diff", "file", file.Filename, "error", err)
+				return nil
+			}
+			file.APIChanges = changes
+			return nil
+		})
+	}
+	_ = g.Wait()
+}
+
+// Collect returns the API contract changes of all comparisons, in file order
+func Collect(comparisons []*types.Comparison) []types.APIChange {
+	var all []types.APIChange
+	for _, comparison := range comparisons {
+		if comparison == nil {
+			continue
+		}
+		for _, file := range comparison.Files {
+			all = append(all, file.APIChanges...)
+		}
+	}
+	return all
+}
+
+// Evidence formats API contract changes as a markdown table for the prompt
+func Evidence(changes []types.APIChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("**API contract changes** (computed from the base and head versions of OpenAPI/Swagger specs, protobuf and GraphQL schemas):\n\n")
+	b.WriteString("| File | Kind | Location | Change | Breaking |\n")
+	b.WriteString("|------|------|----------|--------|----------|\n")
+	for _, change := range changes {
+		breaking := "no"
+		if change.Breaking {
+			breaking = "yes"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", change.File, change.Kind, escapePipes(change.Location), escapePipes(change.Description), breaking)
+	}
+	return b.String()
+}
+
+// escapePipes keeps schema content from breaking the evidence table
+func escapePipes(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
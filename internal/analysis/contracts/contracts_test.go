@@ -0,0 +1,88 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// mockDocumentationSource serves files from a map keyed by "ref:path"
+type mockDocumentationSource struct {
+	files map[string]string
+}
+
+func (m *mockDocumentationSource) GetDefaultBranch(ctx context.Context) (string, error) {
+	return "main", nil
+}
+
+func (m *mockDocumentationSource) FetchFileContent(ctx context.Context, path, ref string) (string, error) {
+	content, ok := m.files[ref+":"+path]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return content, nil
+}
+
+func TestAnnotate(t *testing.T) {
+	source := &mockDocumentationSource{files: map[string]string{
+		"v1:api/openapi.yaml":     "openapi: 3.0.0\npaths:\n  /users:\n    get: {}\n    delete: {}\n",
+		"v2:api/openapi.yaml":     "openapi: 3.0.0\npaths:\n  /users:\n    get: {}\n",
+		"v1:proto/shop.proto":     "syntax = \"proto3\";\nmessage Order { string id = 1; string note = 2; }\n",
+		"v2:proto/shop.proto":     "syntax = \"proto3\";\nmessage Order { string id = 1; }\n",
+		"v1:graph/schema.graphql": "type Query { orders: [String!]! }",
+		"v2:graph/schema.graphql": "type Query { orders: [String] }",
+	}}
+	files := []types.FileChange{
+		{Filename: "api/openapi.yaml", Status: "modified"},
+		{Filename: "proto/shop.proto", Status: "modified"},
+		{Filename: "graph/schema.graphql", Status: "modified"},
+		{Filename: "proto/missing.proto", Status: "modified"},
+		{Filename: "gen/shop.pb.go", Status: "modified", Generated: "generated"},
+		{Filename: "main.go", Status: "modified"},
+	}
+
+	Annotate(context.Background(), source, files, "v1", "v2")
+
+	expected := []string{
+		"endpoint_removed DELETE /users",
+		"field_removed_without_reserved Order field 2",
+		"nullability_changed Query.orders",
+	}
+	var got []string
+	for _, change := range Collect([]*types.Comparison{nil, {Files: files}}) {
+		got = append(got, change.Kind+" "+change.Location)
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Collect() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+	for _, file := range files[3:] {
+		if file.APIChanges != nil {
+			t.Errorf("expected %s to be skipped, got %+v", file.Filename, file.APIChanges)
+		}
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	if Evidence(nil) != "" {
+		t.Error("expected no evidence without changes")
+	}
+
+	evidence := Evidence([]types.APIChange{
+		{File: "openapi.yaml", Kind: "endpoint_removed", Location: "DELETE /users/{id}", Description: "Endpoint removed", Breaking: true},
+		{File: "openapi.yaml", Kind: "endpoint_added", Location: "GET /health", Description: "Endpoint added"},
+		{File: "schema.graphql", Kind: "nullability_changed", Location: "Query.orders", Description: "Became nullable: [String!]! to [String]", Breaking: true},
+	})
+	for _, want := range []string{
+		"**API contract changes**",
+		"| openapi.yaml | endpoint_removed | DELETE /users/{id} | Endpoint removed | yes |",
+		"| openapi.yaml | endpoint_added | GET /health | Endpoint added | no |",
+		"| schema.graphql | nullability_changed | Query.orders | Became nullable: [String!]! to [String] | yes |",
+	} {
+		if !strings.Contains(evidence, want) {
+			t.Errorf("evidence missing %q:\n%s", want, evidence)
+		}
+	}
+}
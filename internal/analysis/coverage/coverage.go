@@ -0,0 +1,232 @@
+package coverage
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"release-confidence-score/internal/analysis/rules"
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/truncation"
+)
+
+// riskyLines is the change size from which a medium or low risk source file without a test change is listed
+const riskyLines = 50
+
+// maxUntested bounds the untested files listed per comparison
+const maxUntested = 20
+
+// sourceExtensions are the extensions of production code that is expected to come with tests
+var sourceExtensions = map[string]bool{
+	".go": true, ".py": true, ".rb": true, ".rs": true, ".php": true, ".cs": true,
+	".js": true, ".jsx": true, ".mjs": true, ".cjs": true, ".ts": true, ".tsx": true,
+	".java": true, ".kt": true, ".scala": true, ".swift": true,
+	".c": true, ".cc": true, ".cpp": true,
+}
+
+// testDirectories hold only tests, whatever their file names
+var testDirectories = map[string]bool{
+	"test":      true,
+	"tests":     true,
+	"spec":      true,
+	"__tests__": true,
+}
+
+// Signal compares the production source changes of one comparison with its test changes
+type Signal struct {
+	Repo        string         `json:"repo,omitempty"`
+	SourceFiles int            `json:"source_files"`
+	TestFiles   int            `json:"test_files"`
+	SourceLines int            `json:"source_lines"` // Added and deleted lines in production source files
+	TestLines   int            `json:"test_lines"`   // Added and deleted lines in test files
+	Ratio       float64        `json:"ratio"`        // TestLines per SourceLines; 0 without source changes
+	Untested    []UntestedFile `json:"untested,omitempty"`
+}
+
+// UntestedFile is a risky source file changed without a matching test change
+type UntestedFile struct {
+	File  string `json:"file"`
+	Risk  string `json:"risk"`  // Risk level the file is classified as for truncation
+	Lines int    `json:"lines"` // Added and deleted lines
+}
+
+// IsTest reports whether a file is a test by the conventions of its language
+func IsTest(filename string) bool {
+	_, ok := testSubject(filename)
+	return ok
+}
+
+// testSubject returns the lowercase base name, without extension, of the source file a test covers:
+// "client" for client_test.go, test_client.py, client.spec.ts, ClientTest.java or client_spec.rb
+func testSubject(filename string) (string, bool) {
+	base := path.Base(filename)
+	name := strings.TrimSuffix(base, path.Ext(base))
+	stem := strings.ToLower(name)
+
+	switch ext := path.Ext(base); {
+	case ext == ".go":
+		if subject, ok := strings.CutSuffix(stem, "_test"); ok {
+			return subject, true
+		}
+		return "", false
+	case ext == ".py" && strings.HasPrefix(stem, "test_"):
+		return strings.TrimPrefix(stem, "test_"), true
+	case ext == ".py" && strings.HasSuffix(stem, "_test"):
+		return strings.TrimSuffix(stem, "_test"), true
+	case ext == ".rb" && (strings.HasSuffix(stem, "_spec") || strings.HasSuffix(stem, "_test")):
+		return strings.TrimSuffix(strings.TrimSuffix(stem, "_spec"), "_test"), true
+	}
+
+	// foo.spec.ts and foo.test.js
+	for _, marker := range []string{".spec", ".test"} {
+		if subject, ok := strings.CutSuffix(stem, marker); ok {
+			return subject, true
+		}
+	}
+
+	// Maven and Gradle keep tests under src/test, named FooTest, FooTests, FooIT or TestFoo
+	if strings.Contains("/"+filename, "/src/test/") {
+		return jvmSubject(name), true
+	}
+	for _, segment := range strings.Split(path.Dir(filename), "/") {
+		if testDirectories[segment] {
+			return jvmSubject(name), true
+		}
+	}
+	return "", false
+}
+
+// jvmSubject strips the affixes of JVM test class names, which are case-sensitive so that "Audit" keeps its "it"
+func jvmSubject(name string) string {
+	for _, suffix := range []string{"Tests", "Test", "IT"} {
+		if subject, ok := strings.CutSuffix(name, suffix); ok && subject != "" {
+			return strings.ToLower(subject)
+		}
+	}
+	if subject, ok := strings.CutPrefix(name, "Test"); ok && subject != "" {
+		return strings.ToLower(subject)
+	}
+	return strings.ToLower(name)
+}
+
+// isSource reports whether a file is production code that tests are expected to cover
+// Lockfile, vendored and generated files, migrations and type declarations are not
+func isSource(file types.FileChange) bool {
+	if file.Generated != "" || rules.IsMigration(file.Filename) || strings.HasSuffix(file.Filename, ".d.ts") {
+		return false
+	}
+	return sourceExtensions[path.Ext(file.Filename)] && !IsTest(file.Filename)
+}
+
+// Analyze pairs each comparison's changed source files with its changed test files
+// A source file counts as tested when a test for the same base name changed anywhere in the comparison,
+// or for Go, when any test in the same package directory changed. Comparisons without source or test
+// changes are left out
+func Analyze(comparisons []*types.Comparison) []Signal {
+	var signals []Signal
+	for _, comparison := range comparisons {
+		if comparison == nil {
+			continue
+		}
+
+		signal := Signal{Repo: comparison.RepoURL}
+		subjects := map[string]bool{}
+		goTestDirs := map[string]bool{}
+		for _, file := range comparison.Files {
+			subject, ok := testSubject(file.Filename)
+			if !ok {
+				continue
+			}
+			signal.TestFiles++
+			signal.TestLines += file.Additions + file.Deletions
+			subjects[subject] = true
+			if path.Ext(file.Filename) == ".go" {
+				goTestDirs[path.Dir(file.Filename)] = true
+			}
+		}
+
+		for _, file := range comparison.Files {
+			if !isSource(file) {
+				continue
+			}
+			lines := file.Additions + file.Deletions
+			signal.SourceFiles++
+			signal.SourceLines += lines
+
+			base := path.Base(file.Filename)
+			subject := strings.ToLower(strings.TrimSuffix(base, path.Ext(base)))
+			tested := subjects[subject] || (path.Ext(file.Filename) == ".go" && goTestDirs[path.Dir(file.Filename)])
+			if tested || file.Status == "removed" {
+				continue
+			}
+
+			risk := truncation.ClassifyFile(file.Filename, comparison.RepoConfig)
+			if risk <= truncation.RiskHigh || lines >= riskyLines {
+				signal.Untested = append(signal.Untested, UntestedFile{File: file.Filename, Risk: risk.String(), Lines: lines})
+			}
+		}
+
+		if signal.SourceFiles == 0 && signal.TestFiles == 0 {
+			continue
+		}
+		if signal.SourceLines > 0 {
+			signal.Ratio = float64(signal.TestLines) / float64(signal.SourceLines)
+		}
+		signal.Untested = riskiest(signal.Untested, comparison.RepoConfig)
+		signals = append(signals, signal)
+	}
+	return signals
+}
+
+// riskiest sorts untested files by risk level, then by change size, and keeps the first maxUntested
+func riskiest(files []UntestedFile, cfg *types.RepoConfig) []UntestedFile {
+	slices.SortStableFunc(files, func(a, b UntestedFile) int {
+		return cmp.Or(
+			cmp.Compare(truncation.ClassifyFile(a.File, cfg), truncation.ClassifyFile(b.File, cfg)),
+			cmp.Compare(b.Lines, a.Lines),
+			strings.Compare(a.File, b.File),
+		)
+	})
+	if len(files) > maxUntested {
+		files = files[:maxUntested]
+	}
+	return files
+}
+
+// Evidence formats the coverage signals as markdown for the prompt
+func Evidence(signals []Signal) string {
+	if len(signals) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("**Test coverage signals** (changed production source files paired with changed test files by naming convention):\n\n")
+	b.WriteString("| Repository | Source files | Test files | Source lines | Test lines | Test/source ratio |\n")
+	b.WriteString("|------------|--------------|------------|--------------|------------|-------------------|\n")
+	for _, signal := range signals {
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %s |\n", signal.Repo, signal.SourceFiles, signal.TestFiles, signal.SourceLines, signal.TestLines, FormatRatio(signal))
+	}
+
+	var untested []string
+	for _, signal := range signals {
+		for _, file := range signal.Untested {
+			untested = append(untested, fmt.Sprintf("- %s (%s risk, %d lines)", file.File, file.Risk, file.Lines))
+		}
+	}
+	if len(untested) > 0 {
+		b.WriteString("\nRisky source files changed without a matching test change:\n")
+		b.WriteString(strings.Join(untested, "\n"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// FormatRatio formats a signal's test-to-source line ratio, or "-" without source changes
+func FormatRatio(signal Signal) string {
+	if signal.SourceLines == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", signal.Ratio)
+}
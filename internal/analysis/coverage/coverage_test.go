@@ -0,0 +1,140 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+func TestTestSubject(t *testing.T) {
+	tests := []struct {
+		filename string
+		subject  string
+		isTest   bool
+	}{
+		{"pkg/client/client_test.go", "client", true},
+		{"pkg/client/client.go", "", false},
+		{"tests/test_billing.py", "billing", true},
+		{"app/billing_test.py", "billing", true},
+		{"app/billing.py", "", false},
+		{"web/src/Cart.spec.tsx", "cart", true},
+		{"web/src/cart.test.js", "cart", true},
+		{"web/src/__tests__/Cart.tsx", "cart", true},
+		{"src/test/java/com/acme/OrderServiceTest.java", "orderservice", true},
+		{"src/test/java/com/acme/OrderServiceIT.java", "orderservice", true},
+		{"src/test/kotlin/com/acme/TestOrderService.kt", "orderservice", true},
+		{"src/main/java/com/acme/OrderService.java", "", false},
+		{"spec/models/user_spec.rb", "user", true},
+		{"tests/audit.js", "audit", true},
+		{"app/models/audit.rb", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			subject, isTest := testSubject(tt.filename)
+			if subject != tt.subject || isTest != tt.isTest {
+				t.Errorf("testSubject(%q) = %q, %v, want %q, %v", tt.filename, subject, isTest, tt.subject, tt.isTest)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	comparisons := []*types.Comparison{
+		nil,
+		{
+			RepoURL: "https://github.com/org/api",
+			Files: []types.FileChange{
+				// Covered by a test for the same base name
+				{Filename: "src/main/java/com/acme/OrderService.java", Status: "modified", Additions: 80, Deletions: 20},
+				{Filename: "src/test/java/com/acme/OrderServiceTest.java", Status: "modified", Additions: 30},
+				// Go files are covered by any test in their package
+				{Filename: "pkg/cart/cart.go", Status: "modified", Additions: 10},
+				{Filename: "pkg/cart/helpers.go", Status: "modified", Additions: 10},
+				{Filename: "pkg/cart/cart_test.go", Status: "modified", Additions: 10},
+				// Untested: critical risk, large medium risk, and small medium risk which isn't listed
+				{Filename: "pkg/auth/token.go", Status: "modified", Additions: 5, Deletions: 1},
+				{Filename: "pkg/billing/invoice.go", Status: "modified", Additions: 60, Deletions: 10},
+				{Filename: "pkg/billing/format.go", Status: "modified", Additions: 3},
+				// Removed files count toward the source lines but are never listed as untested
+				{Filename: "pkg/legacy/legacy.go", Status: "removed", Deletions: 200},
+				// Not source: generated, migration, docs
+				{Filename: "gen/api.pb.go", Status: "modified", Additions: 500, Generated: "generated"},
+				{Filename: "db/migrations/0001_init.sql", Status: "added", Additions: 40},
+				{Filename: "README.md", Status: "modified", Additions: 5},
+			},
+		},
+		{
+			RepoURL: "https://github.com/org/docs",
+			Files:   []types.FileChange{{Filename: "docs/index.md", Status: "modified", Additions: 5}},
+		},
+	}
+
+	signals := Analyze(comparisons)
+	if len(signals) != 1 {
+		t.Fatalf("expected 1 signal, got %d: %+v", len(signals), signals)
+	}
+
+	signal := signals[0]
+	if signal.Repo != "https://github.com/org/api" || signal.SourceFiles != 7 || signal.TestFiles != 2 {
+		t.Errorf("unexpected file counts: %+v", signal)
+	}
+	if signal.SourceLines != 399 || signal.TestLines != 40 {
+		t.Errorf("SourceLines, TestLines = %d, %d, want 399, 40", signal.SourceLines, signal.TestLines)
+	}
+	if FormatRatio(signal) != "0.10" {
+		t.Errorf("FormatRatio() = %q, want 0.10", FormatRatio(signal))
+	}
+
+	var untested []string
+	for _, file := range signal.Untested {
+		untested = append(untested, file.File+" "+file.Risk)
+	}
+	expected := []string{"pkg/auth/token.go critical", "pkg/billing/invoice.go medium"}
+	if strings.Join(untested, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Untested =\n%s\nwant\n%s", strings.Join(untested, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestAnalyze_LimitsUntestedFiles(t *testing.T) {
+	comparison := &types.Comparison{}
+	for i := range maxUntested + 5 {
+		comparison.Files = append(comparison.Files, types.FileChange{
+			Filename:  "pkg/billing/file" + string(rune('a'+i)) + ".go",
+			Status:    "modified",
+			Additions: riskyLines + i,
+		})
+	}
+
+	signals := Analyze([]*types.Comparison{comparison})
+	if len(signals) != 1 || len(signals[0].Untested) != maxUntested {
+		t.Fatalf("expected %d untested files, got %+v", maxUntested, signals)
+	}
+	if signals[0].Untested[0].Lines != riskyLines+maxUntested+4 {
+		t.Errorf("expected the largest change first, got %+v", signals[0].Untested[0])
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	if Evidence(nil) != "" {
+		t.Error("expected no evidence without signals")
+	}
+
+	evidence := Evidence([]Signal{
+		{Repo: "https://github.com/org/api", SourceFiles: 3, TestFiles: 1, SourceLines: 200, TestLines: 50, Ratio: 0.25, Untested: []UntestedFile{
+			{File: "pkg/auth/token.go", Risk: "critical", Lines: 6},
+		}},
+		{Repo: "https://github.com/org/tests", TestFiles: 2, TestLines: 30},
+	})
+	for _, want := range []string{
+		"**Test coverage signals**",
+		"| https://github.com/org/api | 3 | 1 | 200 | 50 | 0.25 |",
+		"| https://github.com/org/tests | 0 | 2 | 0 | 30 | - |",
+		"- pkg/auth/token.go (critical risk, 6 lines)",
+	} {
+		if !strings.Contains(evidence, want) {
+			t.Errorf("evidence missing %q:\n%s", want, evidence)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package dependencies
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// Ecosystems of parsed dependency changes
+const (
+	EcosystemGo        = "go"
+	EcosystemNPM       = "npm"
+	EcosystemPyPI      = "pypi"
+	EcosystemMaven     = "maven"
+	EcosystemRubyGems  = "rubygems"
+	EcosystemCargo     = "cargo"
+	EcosystemPackagist = "packagist"
+)
+
+// Kinds of dependency change
+const (
+	ChangeAdded      = "added"
+	ChangeRemoved    = "removed"
+	ChangeUpgraded   = "upgraded"
+	ChangeDowngraded = "downgraded"
+	ChangeChanged    = "changed" // Versions differ but can't be ordered
+)
+
+// Version bumps, from most to least disruptive
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+)
+
+// parser extracts dependency changes from a patch of one manifest or lockfile format
+type parser func(patch string) []versionChange
+
+// versionChange is one side of a dependency's change as seen in a patch
+type versionChange struct {
+	name    string
+	version string
+	removed bool
+}
+
+// manifestParsers are matched by base name; requirements files are matched separately by prefix
+var manifestParsers = map[string]struct {
+	ecosystem string
+	parse     parser
+}{
+	"go.mod":       {EcosystemGo, parseGoMod},
+	"package.json": {EcosystemNPM, parsePackageJSON},
+	"pom.xml":      {EcosystemMaven, parsePomXML},
+	"Gemfile":      {EcosystemRubyGems, parseGemfile},
+}
+
+var requirementsFile = regexp.MustCompile(`^requirements.*\.txt$`)
+
+// versionCore captures up to three numeric components of a version, ignoring range operators and a "v" prefix
+var versionCore = regexp.MustCompile(`^[\s^~=<>!v]*(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// Parse returns the dependency changes in a manifest or lockfile patch, sorted by package name
+// Files that aren't dependency manifests or lockfiles yield nil
+func Parse(filename, patch string) []types.DependencyChange {
+	if patch == "" {
+		return nil
+	}
+
+	base := path.Base(filename)
+	var ecosystem string
+	var parse parser
+	lockfile := false
+
+	switch {
+	case requirementsFile.MatchString(base):
+		ecosystem, parse = EcosystemPyPI, parseRequirements
+	case lockfileParsers[base].ecosystem != "":
+		ecosystem, parse = lockfileParsers[base].ecosystem, lockfileParsers[base].parse
+		lockfile = true
+	case manifestParsers[base].ecosystem != "":
+		ecosystem, parse = manifestParsers[base].ecosystem, manifestParsers[base].parse
+	default:
+		return nil
+	}
+
+	changes := records(parse(patch))
+	for i := range changes {
+		changes[i].Ecosystem = ecosystem
+		changes[i].File = filename
+		changes[i].Lockfile = lockfile
+	}
+	return changes
+}
+
+// Annotate parses every file's raw patch and records its dependency changes on the file
+// It must run before patches are summarized or truncated
+func Annotate(files []types.FileChange) {
+	for i := range files {
+		files[i].Dependencies = Parse(files[i].Filename, files[i].Patch)
+	}
+}
+
+// Collect returns the dependency changes of all comparisons, ordered by ecosystem and package
+// A lockfile record is dropped when a manifest in the same repository already reports that package
+func Collect(comparisons []*types.Comparison) []types.DependencyChange {
+	var all []types.DependencyChange
+	for _, comparison := range comparisons {
+		if comparison == nil {
+			continue
+		}
+
+		direct := map[string]bool{}
+		for _, file := range comparison.Files {
+			for _, change := range file.Dependencies {
+				if !change.Lockfile {
+					direct[change.Ecosystem+" "+change.Package] = true
+				}
+			}
+		}
+
+		for _, file := range comparison.Files {
+			for _, change := range file.Dependencies {
+				if change.Lockfile && direct[change.Ecosystem+" "+change.Package] {
+					continue
+				}
+				all = append(all, change)
+			}
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Ecosystem != all[j].Ecosystem {
+			return all[i].Ecosystem < all[j].Ecosystem
+		}
+		return all[i].Package < all[j].Package
+	})
+	return all
+}
+
+// Describe formats a dependency change on one line, e.g. "lodash: 4.17.20 → 4.17.21 (patch bump)"
+func Describe(change types.DependencyChange) string {
+	switch change.Change {
+	case ChangeAdded:
+		return fmt.Sprintf("%s: added %s", change.Package, versionOrAny(change.To))
+	case ChangeRemoved:
+		return fmt.Sprintf("%s: removed %s", change.Package, versionOrAny(change.From))
+	}
+
+	description := fmt.Sprintf("%s: %s → %s", change.Package, versionOrAny(change.From), versionOrAny(change.To))
+	switch {
+	case change.Bump != "" && change.Change == ChangeDowngraded:
+		description += fmt.Sprintf(" (%s downgrade)", change.Bump)
+	case change.Bump != "":
+		description += fmt.Sprintf(" (%s bump)", change.Bump)
+	}
+	return description
+}
+
+// Evidence formats dependency changes as a markdown table for the prompt
+func Evidence(changes []types.DependencyChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("**Dependency changes** (parsed from manifests and lockfiles):\n\n")
+	b.WriteString("| Ecosystem | Package | From | To | Change | File |\n")
+	b.WriteString("|-----------|---------|------|----|--------|------|\n")
+	for _, change := range changes {
+		kind := change.Change
+		if change.Bump != "" {
+			kind = change.Bump + " " + kind
+		}
+		if change.Lockfile {
+			kind += " (lockfile)"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			change.Ecosystem, change.Package, orDash(change.From), orDash(change.To), kind, change.File)
+	}
+	return b.String()
+}
+
+// records pairs the removed and added sides of each package into dependency changes
+// Packages whose version didn't change, such as a line that was only reformatted, are dropped
+func records(sides []versionChange) []types.DependencyChange {
+	removed := map[string]string{}
+	added := map[string]string{}
+	for _, side := range sides {
+		versions := added
+		if side.removed {
+			versions = removed
+		}
+		if _, exists := versions[side.name]; !exists {
+			versions[side.name] = side.version
+		}
+	}
+
+	var changes []types.DependencyChange
+	for name, from := range removed {
+		to, stillPresent := added[name]
+		switch {
+		case !stillPresent:
+			changes = append(changes, types.DependencyChange{Package: name, From: from, Change: ChangeRemoved})
+		case to != from:
+			change, bump := compareVersions(from, to)
+			changes = append(changes, types.DependencyChange{Package: name, From: from, To: to, Change: change, Bump: bump})
+		}
+	}
+	for name, to := range added {
+		if _, exists := removed[name]; !exists {
+			changes = append(changes, types.DependencyChange{Package: name, To: to, Change: ChangeAdded})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Package < changes[j].Package
+	})
+	return changes
+}
+
+// compareVersions classifies a version change by direction and by the most significant component that changed
+// Versions without a numeric core, or with equal cores, are reported as changed without a bump
+func compareVersions(from, to string) (string, string) {
+	fromCore, ok := parseCore(from)
+	if !ok {
+		return ChangeChanged, ""
+	}
+	toCore, ok := parseCore(to)
+	if !ok {
+		return ChangeChanged, ""
+	}
+
+	for i, bump := range []string{BumpMajor, BumpMinor, BumpPatch} {
+		if fromCore[i] == toCore[i] {
+			continue
+		}
+		if toCore[i] > fromCore[i] {
+			return ChangeUpgraded, bump
+		}
+		return ChangeDowngraded, bump
+	}
+	return ChangeChanged, ""
+}
+
+// parseCore returns the major, minor and patch numbers of a version; missing components are zero
+func parseCore(version string) ([3]int, bool) {
+	var core [3]int
+	matches := versionCore.FindStringSubmatch(version)
+	if matches == nil {
+		return core, false
+	}
+	for i := range core {
+		if matches[i+1] != "" {
+			core[i], _ = strconv.Atoi(matches[i+1])
+		}
+	}
+	return core, true
+}
+
+func versionOrAny(version string) string {
+	if version == "" {
+		return "(any version)"
+	}
+	return version
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
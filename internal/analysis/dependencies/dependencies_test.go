@@ -0,0 +1,105 @@
+package dependencies
+
+import (
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		from, to       string
+		expectedChange string
+		expectedBump   string
+	}{
+		{"v1.2.3", "v2.0.0", ChangeUpgraded, BumpMajor},
+		{"1.2.3", "1.3.0", ChangeUpgraded, BumpMinor},
+		{"^4.17.20", "^4.17.21", ChangeUpgraded, BumpPatch},
+		{"~> 7.1", "~> 7.0", ChangeDowngraded, BumpMinor},
+		{">=2.28", ">=2.31", ChangeUpgraded, BumpMinor},
+		{"1.0", "1.0.0", ChangeChanged, ""},
+		{"v0.0.0-20230101-abc", "v0.0.0-20240101-def", ChangeChanged, ""},
+		{"latest", "1.0.0", ChangeChanged, ""},
+		{"1.0.0", "next", ChangeChanged, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			change, bump := compareVersions(tt.from, tt.to)
+			if change != tt.expectedChange || bump != tt.expectedBump {
+				t.Errorf("compareVersions(%q, %q) = %s, %s; want %s, %s", tt.from, tt.to, change, bump, tt.expectedChange, tt.expectedBump)
+			}
+		})
+	}
+}
+
+func TestAnnotateAndCollect(t *testing.T) {
+	files := []types.FileChange{
+		{Filename: "main.go", Patch: "+package main"},
+		{Filename: "go.mod", Patch: "@@ -1 +1 @@\n require (\n-\tgithub.com/foo/bar v1.2.0\n+\tgithub.com/foo/bar v1.3.0\n )"},
+		{Filename: "go.sum", Patch: "-github.com/foo/bar v1.2.0 h1:a=\n+github.com/foo/bar v1.3.0 h1:b=\n+github.com/foo/baz v0.1.0 h1:c="},
+	}
+	Annotate(files)
+
+	if files[0].Dependencies != nil {
+		t.Errorf("expected no dependencies for main.go, got %+v", files[0].Dependencies)
+	}
+	if len(files[1].Dependencies) != 1 || len(files[2].Dependencies) != 2 {
+		t.Fatalf("expected go.mod and go.sum to be annotated, got %+v and %+v", files[1].Dependencies, files[2].Dependencies)
+	}
+
+	npm := &types.Comparison{Files: []types.FileChange{{
+		Dependencies: []types.DependencyChange{{Ecosystem: EcosystemNPM, Package: "axios", File: "package.json", From: "0.21.1", To: "1.6.0", Change: ChangeUpgraded, Bump: BumpMajor}},
+	}}}
+	collected := Collect([]*types.Comparison{{Files: files}, nil, npm})
+
+	// The go.sum record for github.com/foo/bar is dropped because go.mod reports it directly
+	expected := []types.DependencyChange{
+		{Ecosystem: EcosystemGo, Package: "github.com/foo/bar", File: "go.mod", From: "v1.2.0", To: "v1.3.0", Change: ChangeUpgraded, Bump: BumpMinor},
+		{Ecosystem: EcosystemGo, Package: "github.com/foo/baz", File: "go.sum", To: "v0.1.0", Change: ChangeAdded, Lockfile: true},
+		{Ecosystem: EcosystemNPM, Package: "axios", File: "package.json", From: "0.21.1", To: "1.6.0", Change: ChangeUpgraded, Bump: BumpMajor},
+	}
+	assertChanges(t, collected, expected)
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		change   types.DependencyChange
+		expected string
+	}{
+		{types.DependencyChange{Package: "a", From: "1.0.0", To: "2.0.0", Change: ChangeUpgraded, Bump: BumpMajor}, "a: 1.0.0 → 2.0.0 (major bump)"},
+		{types.DependencyChange{Package: "b", From: "1.2.0", To: "1.1.0", Change: ChangeDowngraded, Bump: BumpMinor}, "b: 1.2.0 → 1.1.0 (minor downgrade)"},
+		{types.DependencyChange{Package: "c", From: "latest", To: "1.0.0", Change: ChangeChanged}, "c: latest → 1.0.0"},
+		{types.DependencyChange{Package: "d", To: "1.0", Change: ChangeAdded}, "d: added 1.0"},
+		{types.DependencyChange{Package: "e", Change: ChangeAdded}, "e: added (any version)"},
+		{types.DependencyChange{Package: "f", From: "3.1", Change: ChangeRemoved}, "f: removed 3.1"},
+	}
+
+	for _, tt := range tests {
+		if result := Describe(tt.change); result != tt.expected {
+			t.Errorf("Describe() = %q, want %q", result, tt.expected)
+		}
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	if Evidence(nil) != "" {
+		t.Error("expected no evidence without dependency changes")
+	}
+
+	evidence := Evidence([]types.DependencyChange{
+		{Ecosystem: EcosystemNPM, Package: "axios", File: "package.json", From: "^0.21.1", To: "^1.6.0", Change: ChangeUpgraded, Bump: BumpMajor},
+		{Ecosystem: EcosystemGo, Package: "golang.org/x/sync", File: "go.sum", To: "v0.8.0", Change: ChangeAdded, Lockfile: true},
+	})
+
+	for _, want := range []string{
+		"**Dependency changes**",
+		"| npm | axios | ^0.21.1 | ^1.6.0 | major upgraded | package.json |",
+		"| go | golang.org/x/sync | - | v0.8.0 | added (lockfile) | go.sum |",
+	} {
+		if !strings.Contains(evidence, want) {
+			t.Errorf("Evidence() missing %q in:\n%s", want, evidence)
+		}
+	}
+}
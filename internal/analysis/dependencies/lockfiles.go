@@ -0,0 +1,101 @@
+package dependencies
+
+import (
+	"regexp"
+	"strings"
+)
+
+// lineParser extracts package versions from the changed lines of a patch
+// Formats either put name and version on one line (entry), or declare a package on one
+// line and its version on a later one (name, then version)
+type lineParser struct {
+	entry    *regexp.Regexp  // Groups: name, version
+	name     *regexp.Regexp  // Group: name
+	version  *regexp.Regexp  // Group: version
+	sections map[string]bool // Names that open a section rather than a package
+}
+
+var (
+	jsonLockParser = lineParser{
+		name:    regexp.MustCompile(`^\s*"(?:.*node_modules/)?([^"]*)": \{`),
+		version: regexp.MustCompile(`^\s*"version": "=*([^"]+)"`),
+		sections: map[string]bool{
+			"":                     true, // The root project in package-lock.json
+			"packages":             true,
+			"dependencies":         true,
+			"devDependencies":      true,
+			"optionalDependencies": true,
+			"peerDependencies":     true,
+			"requires":             true,
+			"_meta":                true,
+			"default":              true,
+			"develop":              true,
+		},
+	}
+	tomlLockParser = lineParser{
+		name:    regexp.MustCompile(`^name = "([^"]+)"`),
+		version: regexp.MustCompile(`^version = "([^"]+)"`),
+	}
+)
+
+var lockfileParsers = map[string]struct {
+	ecosystem string
+	parse     parser
+}{
+	"go.sum": {EcosystemGo, lineParser{
+		entry: regexp.MustCompile(`^(\S+) (v[^\s/]+)(?:/go\.mod)? h1:`),
+	}.parse},
+	"package-lock.json":   {EcosystemNPM, jsonLockParser.parse},
+	"npm-shrinkwrap.json": {EcosystemNPM, jsonLockParser.parse},
+	"yarn.lock": {EcosystemNPM, lineParser{
+		name:    regexp.MustCompile(`^"?(@?[^@"\s]+)@[^:]*:\s*$`),
+		version: regexp.MustCompile(`^\s+version:? "?([^"\s]+)"?`),
+	}.parse},
+	"pnpm-lock.yaml": {EcosystemNPM, lineParser{
+		entry: regexp.MustCompile(`^\s+'?/?(@?[^@\s']+)@([^:('\s]+)`),
+	}.parse},
+	"Pipfile.lock": {EcosystemPyPI, jsonLockParser.parse},
+	"poetry.lock":  {EcosystemPyPI, tomlLockParser.parse},
+	"uv.lock":      {EcosystemPyPI, tomlLockParser.parse},
+	"Gemfile.lock": {EcosystemRubyGems, lineParser{
+		entry: regexp.MustCompile(`^ {4}([A-Za-z0-9_.\-]+) \(([^)]+)\)$`),
+	}.parse},
+	"Cargo.lock": {EcosystemCargo, tomlLockParser.parse},
+	"composer.lock": {EcosystemPackagist, lineParser{
+		name:    regexp.MustCompile(`^\s*"name": "([^"]+)"`),
+		version: regexp.MustCompile(`^\s*"version": "([^"]+)"`),
+	}.parse},
+}
+
+// parse returns the versions on the changed lines of a patch
+// Context lines still set the current package name, since a version line often changes alone
+func (p lineParser) parse(patch string) []versionChange {
+	var sides []versionChange
+	currentName := ""
+	for _, line := range strings.Split(patch, "\n") {
+		if line == "" || strings.HasPrefix(line, "@@") {
+			continue
+		}
+		sign, content := line[0], line[1:]
+		changed := sign == '+' || sign == '-'
+
+		if p.entry != nil {
+			if matches := p.entry.FindStringSubmatch(content); matches != nil && changed {
+				sides = append(sides, versionChange{name: matches[1], version: matches[2], removed: sign == '-'})
+			}
+			continue
+		}
+
+		if matches := p.name.FindStringSubmatch(content); matches != nil {
+			currentName = matches[1]
+			if p.sections[currentName] {
+				currentName = ""
+			}
+			continue
+		}
+		if matches := p.version.FindStringSubmatch(content); matches != nil && changed && currentName != "" {
+			sides = append(sides, versionChange{name: currentName, version: matches[1], removed: sign == '-'})
+		}
+	}
+	return sides
+}
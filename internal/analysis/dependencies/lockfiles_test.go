@@ -0,0 +1,126 @@
+package dependencies
+
+import (
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+func TestParseLockfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		patch    string
+		expected []types.DependencyChange
+	}{
+		{
+			name:     "go.sum upgrade, addition and removal",
+			filename: "go.sum",
+			patch: "@@ -1,6 +1,6 @@\n" +
+				"-github.com/foo/bar v1.2.0 h1:abc=\n" +
+				"-github.com/foo/bar v1.2.0/go.mod h1:def=\n" +
+				"+github.com/foo/bar v1.3.0 h1:ghi=\n" +
+				"+github.com/foo/bar v1.3.0/go.mod h1:jkl=\n" +
+				"+golang.org/x/sync v0.8.0 h1:x=\n" +
+				"-gopkg.in/old v1.0.0 h1:y=\n" +
+				" github.com/same/mod v1.0.0 h1:z=",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemGo, Package: "github.com/foo/bar", File: "go.sum", From: "v1.2.0", To: "v1.3.0", Change: ChangeUpgraded, Bump: BumpMinor, Lockfile: true},
+				{Ecosystem: EcosystemGo, Package: "golang.org/x/sync", File: "go.sum", To: "v0.8.0", Change: ChangeAdded, Lockfile: true},
+				{Ecosystem: EcosystemGo, Package: "gopkg.in/old", File: "go.sum", From: "v1.0.0", Change: ChangeRemoved, Lockfile: true},
+			},
+		},
+		{
+			name:     "package-lock.json v3",
+			filename: "web/package-lock.json",
+			patch: "@@ -1,12 +1,12 @@\n" +
+				"   \"packages\": {\n" +
+				"     \"\": {\n" +
+				"-      \"version\": \"1.0.0\",\n" +
+				"+      \"version\": \"1.1.0\",\n" +
+				"     \"node_modules/lodash\": {\n" +
+				"-      \"version\": \"4.17.20\",\n" +
+				"+      \"version\": \"4.17.21\",\n" +
+				"     \"node_modules/@babel/core/node_modules/semver\": {\n" +
+				"-      \"version\": \"6.3.0\",\n" +
+				"+      \"version\": \"5.7.2\",",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemNPM, Package: "lodash", File: "web/package-lock.json", From: "4.17.20", To: "4.17.21", Change: ChangeUpgraded, Bump: BumpPatch, Lockfile: true},
+				{Ecosystem: EcosystemNPM, Package: "semver", File: "web/package-lock.json", From: "6.3.0", To: "5.7.2", Change: ChangeDowngraded, Bump: BumpMajor, Lockfile: true},
+			},
+		},
+		{
+			name:     "yarn.lock",
+			filename: "yarn.lock",
+			patch: "@@ -1,4 +1,4 @@\n" +
+				" \"@babel/core@^7.0.0\":\n" +
+				"-  version \"7.22.0\"\n" +
+				"+  version \"7.23.2\"",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemNPM, Package: "@babel/core", File: "yarn.lock", From: "7.22.0", To: "7.23.2", Change: ChangeUpgraded, Bump: BumpMinor, Lockfile: true},
+			},
+		},
+		{
+			name:     "Gemfile.lock",
+			filename: "Gemfile.lock",
+			patch: "@@ -1,4 +1,4 @@\n" +
+				"-    rails (7.0.1)\n" +
+				"+    rails (7.0.8)\n" +
+				"       actionpack (= 7.0.8)",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemRubyGems, Package: "rails", File: "Gemfile.lock", From: "7.0.1", To: "7.0.8", Change: ChangeUpgraded, Bump: BumpPatch, Lockfile: true},
+			},
+		},
+		{
+			name:     "poetry.lock",
+			filename: "poetry.lock",
+			patch: "@@ -1,4 +1,4 @@\n" +
+				" [[package]]\n" +
+				" name = \"requests\"\n" +
+				"-version = \"2.31.0\"\n" +
+				"+version = \"2.32.3\"",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemPyPI, Package: "requests", File: "poetry.lock", From: "2.31.0", To: "2.32.3", Change: ChangeUpgraded, Bump: BumpMinor, Lockfile: true},
+			},
+		},
+		{
+			name:     "pnpm-lock.yaml",
+			filename: "pnpm-lock.yaml",
+			patch: "@@ -1,2 +1,2 @@\n" +
+				"-  /lodash@4.17.20:\n" +
+				"+  /lodash@4.17.21:",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemNPM, Package: "lodash", File: "pnpm-lock.yaml", From: "4.17.20", To: "4.17.21", Change: ChangeUpgraded, Bump: BumpPatch, Lockfile: true},
+			},
+		},
+		{
+			name:     "checksum-only change is not a version change",
+			filename: "go.sum",
+			patch:    "-github.com/foo/bar v1.2.0 h1:abc=\n+github.com/foo/bar v1.2.0 h1:xyz=",
+		},
+		{
+			name:     "unknown lockfile",
+			filename: "mix.lock",
+			patch:    "+anything",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertChanges(t, Parse(tt.filename, tt.patch), tt.expected)
+		})
+	}
+}
+
+// assertChanges compares parsed dependency changes with the expected ones, in order
+func assertChanges(t *testing.T, result, expected []types.DependencyChange) {
+	t.Helper()
+	if len(result) != len(expected) {
+		t.Fatalf("Parse() = %+v, want %+v", result, expected)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("Parse()[%d] = %+v, want %+v", i, result[i], expected[i])
+		}
+	}
+}
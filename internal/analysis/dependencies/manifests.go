@@ -0,0 +1,211 @@
+package dependencies
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	goModBlockStart = regexp.MustCompile(`^\s*(require|replace|exclude|retract|tool|godebug)\s*\($`)
+	goModRequire    = regexp.MustCompile(`^\s*(?:require\s+)?([^\s()]+)\s+(v\S+)`)
+	goModDirective  = regexp.MustCompile(`^\s*(module|go|toolchain|replace|exclude|retract|tool|godebug)\b`)
+)
+
+// parseGoMod reads require directives, both single-line and in require blocks
+// Lines in replace, exclude and retract blocks are skipped
+func parseGoMod(patch string) []versionChange {
+	var sides []versionChange
+	block := "require" // A hunk may start inside a block whose opening line isn't shown
+	for _, line := range strings.Split(patch, "\n") {
+		if line == "" || strings.HasPrefix(line, "@@") {
+			continue
+		}
+		sign, content := line[0], line[1:]
+
+		if matches := goModBlockStart.FindStringSubmatch(content); matches != nil {
+			block = matches[1]
+			continue
+		}
+		if strings.TrimSpace(content) == ")" {
+			block = ""
+			continue
+		}
+		if sign != '+' && sign != '-' {
+			continue
+		}
+
+		inRequireBlock := block == "require"
+		if !inRequireBlock && !strings.HasPrefix(strings.TrimSpace(content), "require ") {
+			continue
+		}
+		if goModDirective.MatchString(content) {
+			continue
+		}
+		if matches := goModRequire.FindStringSubmatch(content); matches != nil {
+			sides = append(sides, versionChange{name: matches[1], version: matches[2], removed: sign == '-'})
+		}
+	}
+	return sides
+}
+
+var (
+	packageJSONSection = regexp.MustCompile(`^\s*"(dependencies|devDependencies|peerDependencies|optionalDependencies)"\s*:\s*\{`)
+	packageJSONObject  = regexp.MustCompile(`^\s*"[^"]*"\s*:\s*\{`)
+	packageJSONEntry   = regexp.MustCompile(`^\s*"([^"]+)"\s*:\s*"([^"]+)"\s*,?\s*$`)
+	npmVersionRange    = regexp.MustCompile(`^(?:[\^~<>=v*x]|\d|latest|workspace:|npm:)`)
+)
+
+// packageJSONTopLevelKeys are string fields outside dependency sections that look like entries
+var packageJSONTopLevelKeys = map[string]bool{
+	"name": true, "version": true, "description": true, "main": true, "module": true, "types": true,
+	"license": true, "author": true, "homepage": true, "type": true, "node": true, "npm": true,
+}
+
+// parsePackageJSON reads entries of the dependency sections
+// When a hunk starts inside an unknown object, version-like entries are accepted unless they are well-known top-level fields
+func parsePackageJSON(patch string) []versionChange {
+	var sides []versionChange
+	section := "unknown"
+	for _, line := range strings.Split(patch, "\n") {
+		if line == "" || strings.HasPrefix(line, "@@") {
+			continue
+		}
+		sign, content := line[0], line[1:]
+
+		if matches := packageJSONSection.FindStringSubmatch(content); matches != nil {
+			section = matches[1]
+			continue
+		}
+		if packageJSONObject.MatchString(content) {
+			section = "other"
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(content), "}") {
+			section = "other"
+			continue
+		}
+		if sign != '+' && sign != '-' {
+			continue
+		}
+
+		matches := packageJSONEntry.FindStringSubmatch(content)
+		if matches == nil || section == "other" {
+			continue
+		}
+		if section == "unknown" && (packageJSONTopLevelKeys[matches[1]] || !npmVersionRange.MatchString(matches[2])) {
+			continue
+		}
+		sides = append(sides, versionChange{name: matches[1], version: matches[2], removed: sign == '-'})
+	}
+	return sides
+}
+
+var requirementsEntry = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9_.\-]*)(?:\[[^\]]*\])?\s*(?:(===|==|~=|>=|<=|!=|>|<)\s*([^\s;#,]+))?`)
+
+// parseRequirements reads pinned and constrained requirements; options, includes and comments are skipped
+// Package names are normalized as in PEP 503, so "Django" and "django" are the same package
+func parseRequirements(patch string) []versionChange {
+	var sides []versionChange
+	for _, line := range strings.Split(patch, "\n") {
+		if line == "" || (line[0] != '+' && line[0] != '-') {
+			continue
+		}
+		content := strings.TrimSpace(line[1:])
+		if content == "" || strings.HasPrefix(content, "#") || strings.HasPrefix(content, "-") {
+			continue
+		}
+
+		matches := requirementsEntry.FindStringSubmatch(content)
+		if matches == nil {
+			continue
+		}
+		name := strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(matches[1]))
+		version := matches[3]
+		if matches[2] != "" && matches[2] != "==" && matches[2] != "===" {
+			version = matches[2] + matches[3]
+		}
+		sides = append(sides, versionChange{name: name, version: version, removed: line[0] == '-'})
+	}
+	return sides
+}
+
+var (
+	pomBlockStart = regexp.MustCompile(`<(dependency|plugin|parent)>`)
+	pomBlockEnd   = regexp.MustCompile(`</(dependency|plugin|parent)>`)
+	pomGroupID    = regexp.MustCompile(`<groupId>\s*([^<\s]+)\s*</groupId>`)
+	pomArtifactID = regexp.MustCompile(`<artifactId>\s*([^<\s]+)\s*</artifactId>`)
+	pomVersion    = regexp.MustCompile(`<version>\s*([^<\s]+)\s*</version>`)
+)
+
+// parsePomXML reads versions of dependencies, plugins and the parent POM as groupId:artifactId
+// Coordinates usually appear as context lines around a changed version, so context lines update them too
+func parsePomXML(patch string) []versionChange {
+	var sides []versionChange
+	var groupID, artifactID string
+	var pending []versionChange // Versions seen before the block's artifactId
+	inBlock := true             // A hunk may start inside a block whose opening tag isn't shown
+
+	flush := func() {
+		if artifactID != "" {
+			for _, side := range pending {
+				side.name = coordinates(groupID, artifactID)
+				sides = append(sides, side)
+			}
+		}
+		pending = nil
+	}
+
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			flush()
+			groupID, artifactID, inBlock = "", "", true
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		sign, content := line[0], line[1:]
+
+		if pomBlockStart.MatchString(content) {
+			groupID, artifactID, pending, inBlock = "", "", nil, true
+		}
+		if matches := pomGroupID.FindStringSubmatch(content); matches != nil {
+			groupID = matches[1]
+		}
+		if matches := pomArtifactID.FindStringSubmatch(content); matches != nil {
+			artifactID = matches[1]
+		}
+		if matches := pomVersion.FindStringSubmatch(content); matches != nil && (sign == '+' || sign == '-') && inBlock {
+			pending = append(pending, versionChange{version: matches[1], removed: sign == '-'})
+		}
+		if pomBlockEnd.MatchString(content) {
+			flush()
+			groupID, artifactID, inBlock = "", "", false
+		}
+	}
+	flush()
+	return sides
+}
+
+func coordinates(groupID, artifactID string) string {
+	if groupID == "" {
+		return artifactID
+	}
+	return groupID + ":" + artifactID
+}
+
+var gemfileEntry = regexp.MustCompile(`^\s*gem\s+['"]([^'"]+)['"](?:\s*,\s*['"]([^'"]+)['"])?`)
+
+// parseGemfile reads gem declarations; a gem without a version constraint is recorded with an empty version
+func parseGemfile(patch string) []versionChange {
+	var sides []versionChange
+	for _, line := range strings.Split(patch, "\n") {
+		if line == "" || (line[0] != '+' && line[0] != '-') {
+			continue
+		}
+		if matches := gemfileEntry.FindStringSubmatch(line[1:]); matches != nil {
+			sides = append(sides, versionChange{name: matches[1], version: matches[2], removed: line[0] == '-'})
+		}
+	}
+	return sides
+}
@@ -0,0 +1,158 @@
+package dependencies
+
+import (
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+func TestParseManifests(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		patch    string
+		expected []types.DependencyChange
+	}{
+		{
+			name:     "go.mod require block",
+			filename: "go.mod",
+			patch: "@@ -3,8 +3,8 @@ go 1.25\n" +
+				" require (\n" +
+				"-\tgithub.com/google/go-github/v89 v89.0.0\n" +
+				"+\tgithub.com/google/go-github/v90 v90.0.0\n" +
+				"-\tgolang.org/x/oauth2 v0.30.0\n" +
+				"+\tgolang.org/x/oauth2 v0.31.0\n" +
+				"-\tgithub.com/pkg/errors v0.9.1 // indirect\n" +
+				"+\tgithub.com/pkg/errors v0.9.2 // indirect\n" +
+				" )\n" +
+				" replace (\n" +
+				"-\texample.com/a => ../a\n" +
+				"+\texample.com/a v1.0.0 => example.com/b v1.1.0\n" +
+				" )",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemGo, Package: "github.com/google/go-github/v89", File: "go.mod", From: "v89.0.0", Change: ChangeRemoved},
+				{Ecosystem: EcosystemGo, Package: "github.com/google/go-github/v90", File: "go.mod", To: "v90.0.0", Change: ChangeAdded},
+				{Ecosystem: EcosystemGo, Package: "github.com/pkg/errors", File: "go.mod", From: "v0.9.1", To: "v0.9.2", Change: ChangeUpgraded, Bump: BumpPatch},
+				{Ecosystem: EcosystemGo, Package: "golang.org/x/oauth2", File: "go.mod", From: "v0.30.0", To: "v0.31.0", Change: ChangeUpgraded, Bump: BumpMinor},
+			},
+		},
+		{
+			name:     "go.mod single-line require and go directive",
+			filename: "svc/go.mod",
+			patch:    "@@ -1,3 +1,3 @@\n-go 1.24\n+go 1.25\n-require gopkg.in/yaml.v3 v3.0.0\n+require gopkg.in/yaml.v3 v3.0.1",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemGo, Package: "gopkg.in/yaml.v3", File: "svc/go.mod", From: "v3.0.0", To: "v3.0.1", Change: ChangeUpgraded, Bump: BumpPatch},
+			},
+		},
+		{
+			name:     "package.json dependency sections",
+			filename: "web/package.json",
+			patch: "@@ -1,12 +1,12 @@\n" +
+				" {\n" +
+				"   \"name\": \"web\",\n" +
+				"-  \"version\": \"1.0.0\",\n" +
+				"+  \"version\": \"1.1.0\",\n" +
+				"   \"scripts\": {\n" +
+				"-    \"build\": \"tsc\"\n" +
+				"+    \"build\": \"tsc -p .\"\n" +
+				"   },\n" +
+				"   \"dependencies\": {\n" +
+				"-    \"axios\": \"^0.21.1\",\n" +
+				"+    \"axios\": \"^1.6.0\",\n" +
+				"+    \"zod\": \"3.22.4\"\n" +
+				"   },\n" +
+				"   \"devDependencies\": {\n" +
+				"-    \"jest\": \"~29.6.0\"\n" +
+				"+    \"jest\": \"~29.7.0\"\n" +
+				"   }",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemNPM, Package: "axios", File: "web/package.json", From: "^0.21.1", To: "^1.6.0", Change: ChangeUpgraded, Bump: BumpMajor},
+				{Ecosystem: EcosystemNPM, Package: "jest", File: "web/package.json", From: "~29.6.0", To: "~29.7.0", Change: ChangeUpgraded, Bump: BumpMinor},
+				{Ecosystem: EcosystemNPM, Package: "zod", File: "web/package.json", To: "3.22.4", Change: ChangeAdded},
+			},
+		},
+		{
+			name:     "package.json hunk starting inside dependencies",
+			filename: "package.json",
+			patch:    "@@ -10,3 +10,3 @@\n     \"express\": \"^4.18.0\",\n-    \"lodash\": \"^4.17.20\"\n+    \"lodash\": \"^4.17.21\"",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemNPM, Package: "lodash", File: "package.json", From: "^4.17.20", To: "^4.17.21", Change: ChangeUpgraded, Bump: BumpPatch},
+			},
+		},
+		{
+			name:     "requirements.txt",
+			filename: "requirements-dev.txt",
+			patch: "@@ -1,5 +1,5 @@\n" +
+				"-Django==4.2.7\n" +
+				"+django==5.0.1\n" +
+				"-requests>=2.28\n" +
+				"+requests>=2.31\n" +
+				"+celery[redis]==5.3.6  # workers\n" +
+				"+-r base.txt\n" +
+				"+# comment",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemPyPI, Package: "celery", File: "requirements-dev.txt", To: "5.3.6", Change: ChangeAdded},
+				{Ecosystem: EcosystemPyPI, Package: "django", File: "requirements-dev.txt", From: "4.2.7", To: "5.0.1", Change: ChangeUpgraded, Bump: BumpMajor},
+				{Ecosystem: EcosystemPyPI, Package: "requests", File: "requirements-dev.txt", From: ">=2.28", To: ">=2.31", Change: ChangeUpgraded, Bump: BumpMinor},
+			},
+		},
+		{
+			name:     "pom.xml dependency version",
+			filename: "pom.xml",
+			patch: "@@ -20,6 +20,6 @@\n" +
+				"         <dependency>\n" +
+				"             <groupId>com.fasterxml.jackson.core</groupId>\n" +
+				"             <artifactId>jackson-databind</artifactId>\n" +
+				"-            <version>2.15.2</version>\n" +
+				"+            <version>2.16.0</version>\n" +
+				"         </dependency>\n" +
+				"@@ -40,4 +40,4 @@\n" +
+				"             <artifactId>junit-jupiter</artifactId>\n" +
+				"-            <version>5.9.3</version>\n" +
+				"+            <version>5.10.1</version>\n" +
+				"         </dependency>",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemMaven, Package: "com.fasterxml.jackson.core:jackson-databind", File: "pom.xml", From: "2.15.2", To: "2.16.0", Change: ChangeUpgraded, Bump: BumpMinor},
+				{Ecosystem: EcosystemMaven, Package: "junit-jupiter", File: "pom.xml", From: "5.9.3", To: "5.10.1", Change: ChangeUpgraded, Bump: BumpMinor},
+			},
+		},
+		{
+			name:     "pom.xml added dependency",
+			filename: "service/pom.xml",
+			patch: "@@ -30,0 +30,5 @@\n" +
+				"+        <dependency>\n" +
+				"+            <groupId>org.postgresql</groupId>\n" +
+				"+            <artifactId>postgresql</artifactId>\n" +
+				"+            <version>42.7.1</version>\n" +
+				"+        </dependency>",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemMaven, Package: "org.postgresql:postgresql", File: "service/pom.xml", To: "42.7.1", Change: ChangeAdded},
+			},
+		},
+		{
+			name:     "Gemfile",
+			filename: "Gemfile",
+			patch: "@@ -1,4 +1,4 @@\n" +
+				"-gem 'rails', '~> 7.0.0'\n" +
+				"+gem 'rails', '~> 7.1.0'\n" +
+				"+gem \"sidekiq\"\n" +
+				"-gem 'puma', '5.6.7'",
+			expected: []types.DependencyChange{
+				{Ecosystem: EcosystemRubyGems, Package: "puma", File: "Gemfile", From: "5.6.7", Change: ChangeRemoved},
+				{Ecosystem: EcosystemRubyGems, Package: "rails", File: "Gemfile", From: "~> 7.0.0", To: "~> 7.1.0", Change: ChangeUpgraded, Bump: BumpMinor},
+				{Ecosystem: EcosystemRubyGems, Package: "sidekiq", File: "Gemfile", Change: ChangeAdded},
+			},
+		},
+		{
+			name:     "not a manifest",
+			filename: "main.go",
+			patch:    "+require x v1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertChanges(t, Parse(tt.filename, tt.patch), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package goapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"release-confidence-score/internal/git/shared"
+	"release-confidence-score/internal/git/types"
+)
+
+// maxFiles bounds how many Go files are fetched at both refs per comparison
+const maxFiles = 50
+
+// Changes to an exported identifier
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// IsSource reports whether a file is Go source that other modules can import
+// Tests, testdata, vendored code and internal packages are not part of a package's public API
+func IsSource(filename string) bool {
+	if !strings.HasSuffix(filename, ".go") || strings.HasSuffix(filename, "_test.go") {
+		return false
+	}
+	for _, segment := range strings.Split(path.Dir(filename), "/") {
+		if segment == "internal" || segment == "testdata" || segment == "vendor" {
+			return false
+		}
+	}
+	return true
+}
+
+// versions holds the exported identifiers of one changed file at the base and head refs
+type versions struct {
+	base, head map[string]symbol
+}
+
+// packageAPI holds the exported identifiers of a package's changed files at the base and head refs
+type packageAPI struct {
+	base, head map[string]symbol
+}
+
+// Annotate fetches each changed Go source file at the base and head refs and records the exported
+// identifiers added, removed or changed in its package. Identifiers are compared per package directory,
+// so one moved between two changed files of the same package is not reported. Files that can't be
+// fetched or parsed are skipped, since the raw patch is still part of the analysis
+func Annotate(ctx context.Context, source types.DocumentationSource, files []types.FileChange, baseRef, headRef string) {
+	parsed := make([]*versions, len(files))
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(10) // Limit concurrent API calls to avoid rate limiting
+
+	candidates := 0
+	for i := range files {
+		if !IsSource(files[i].Filename) || files[i].Generated != "" {
+			continue
+		}
+		if candidates == maxFiles {
+			slog.Warn("Too many Go files to compare, skipping the rest", "limit", maxFiles)
+			break
+		}
+		candidates++
+
+		g.Go(func() error {
+			file := files[i]
+			base, head, err := shared.FetchFileVersions(gCtx, source, file, baseRef, headRef)
+			if err != nil {
+				slog.Debug("Skipping Go API diff", "file", file.Filename, "error", err)
+				return nil
+			}
+			baseSymbols, baseImportable, err := parseSymbols(file.Filename, base)
+			if err != nil {
+				slog.Debug("Skipping Go API diff", "file", file.Filename, "error", err)
+				return nil
+			}
+			headSymbols, headImportable, err := parseSymbols(file.Filename, head)
+			if err != nil {
+				slog.Debug("Skipping Go API diff", "file", file.Filename, "error", err)
+				return nil
+			}
+			if !baseImportable || !headImportable {
+				return nil
+			}
+			parsed[i] = &versions{base: baseSymbols, head: headSymbols}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	packages := map[string]*packageAPI{}
+	packageFor := func(dir string) *packageAPI {
+		if packages[dir] == nil {
+			packages[dir] = &packageAPI{base: map[string]symbol{}, head: map[string]symbol{}}
+		}
+		return packages[dir]
+	}
+	for i, file := range parsed {
+		if file == nil {
+			continue
+		}
+		basePath := files[i].Filename
+		if files[i].PreviousFilename != "" {
+			basePath = files[i].PreviousFilename
+		}
+		merge(packageFor(path.Dir(basePath)).base, file.base, i)
+		merge(packageFor(path.Dir(files[i].Filename)).head, file.head, i)
+	}
+
+	dirs := make([]string, 0, len(packages))
+	for dir := range packages {
+		dirs = append(dirs, dir)
+	}
+	slices.Sort(dirs)
+
+	for _, dir := range dirs {
+		api := packages[dir]
+		for _, change := range diff(dir, api) {
+			index := api.head[change.Identifier].file
+			if change.Change == ChangeRemoved {
+				index = api.base[change.Identifier].file
+			}
+			change.File = files[index].Filename
+			files[index].GoAPI = append(files[index].GoAPI, change)
+		}
+	}
+}
+
+// merge adds a file's symbols to its package; the first declaration wins when build-constrained
+// files declare the same identifier
+func merge(into, symbols map[string]symbol, file int) {
+	for name, sym := range symbols {
+		if _, exists := into[name]; exists {
+			continue
+		}
+		sym.file = file
+		into[name] = sym
+	}
+}
+
+// diff compares a package's exported identifiers, sorted by identifier
+// Members of an added, removed or changed type are not reported separately
+func diff(dir string, api *packageAPI) []types.GoAPIChange {
+	names := map[string]bool{}
+	for name := range api.base {
+		names[name] = true
+	}
+	for name := range api.head {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	slices.Sort(sorted)
+
+	reported := func(parent string) bool {
+		before, inBase := api.base[parent]
+		after, inHead := api.head[parent]
+		return inBase != inHead || before.signature != after.signature
+	}
+
+	var changes []types.GoAPIChange
+	for _, name := range sorted {
+		before, inBase := api.base[name]
+		after, inHead := api.head[name]
+		sym := after
+		if !inHead {
+			sym = before
+		}
+		if sym.parent != "" && reported(sym.parent) {
+			continue
+		}
+
+		change := types.GoAPIChange{Package: dir, Identifier: name, Kind: sym.kind}
+		switch {
+		case !inBase:
+			change.Change = ChangeAdded
+			change.To = after.signature
+			// Every implementation outside the package lacks the new method
+			change.Breaking = after.kind == KindInterfaceMethod
+		case !inHead:
+			change.Change = ChangeRemoved
+			change.From = before.signature
+			change.Breaking = true
+		case before.signature != after.signature || before.kind != after.kind:
+			change.Change = ChangeChanged
+			change.From = before.signature
+			change.To = after.signature
+			change.Breaking = true
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// Collect returns the Go API changes of all comparisons, in file order
+func Collect(comparisons []*types.Comparison) []types.GoAPIChange {
+	var all []types.GoAPIChange
+	for _, comparison := range comparisons {
+		if comparison == nil {
+			continue
+		}
+		for _, file := range comparison.Files {
+			all = append(all, file.GoAPI...)
+		}
+	}
+	return all
+}
+
+// Signature describes the signature of a change, e.g. "func Get(string) error → func Get(context.Context, string) error"
+func Signature(change types.GoAPIChange) string {
+	switch {
+	case change.From == "":
+		return change.To
+	case change.To == "":
+		return change.From
+	default:
+		return change.From + " → " + change.To
+	}
+}
+
+// Evidence formats Go API changes as a markdown table for the prompt
+func Evidence(changes []types.GoAPIChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("**Go API changes** (exported identifiers computed by parsing the base and head versions of changed Go files; breaking changes stop code that uses them from compiling):\n\n")
+	b.WriteString("| Package | Identifier | Kind | Change | Signature | Breaking |\n")
+	b.WriteString("|---------|------------|------|--------|-----------|----------|\n")
+	for _, change := range changes {
+		breaking := "no"
+		if change.Breaking {
+			breaking = "yes"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", change.Package, change.Identifier, change.Kind, change.Change, escapePipes(Signature(change)), breaking)
+	}
+	return b.String()
+}
+
+// escapePipes keeps type unions from breaking the evidence table
+func escapePipes(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
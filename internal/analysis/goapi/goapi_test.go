@@ -0,0 +1,250 @@
+package goapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// mockDocumentationSource serves files from a map keyed by "ref:path"
+type mockDocumentationSource struct {
+	files map[string]string
+}
+
+func (m *mockDocumentationSource) GetDefaultBranch(ctx context.Context) (string, error) {
+	return "main", nil
+}
+
+func (m *mockDocumentationSource) FetchFileContent(ctx context.Context, path, ref string) (string, error) {
+	content, ok := m.files[ref+":"+path]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return content, nil
+}
+
+func TestIsSource(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected bool
+	}{
+		{"client.go", true},
+		{"pkg/client/client.go", true},
+		{"pkg/client/client_test.go", false},
+		{"internal/client/client.go", false},
+		{"pkg/internal/util.go", false},
+		{"pkg/client/testdata/fixture.go", false},
+		{"vendor/github.com/foo/bar.go", false},
+		{"pkg/client/README.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			if got := IsSource(tt.filename); got != tt.expected {
+				t.Errorf("IsSource(%q) = %v, want %v", tt.filename, got, tt.expected)
+			}
+		})
+	}
+}
+
+// describe formats a change as "change kind identifier: signature (breaking)" for compact comparisons
+func describe(changes []types.GoAPIChange) string {
+	var lines []string
+	for _, change := range changes {
+		line := change.Change + " " + change.Kind + " " + change.Identifier + ": " + Signature(change)
+		if change.Breaking {
+			line += " (breaking)"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		head     string
+		expected []string
+	}{
+		{
+			name: "function signature changed",
+			base: "package client\n\nfunc Get(url string) error { return nil }\n",
+			head: "package client\n\nfunc Get(ctx context.Context, url string) error { return nil }\n",
+			expected: []string{
+				"changed func Get: func Get(string) error → func Get(context.Context, string) error (breaking)",
+			},
+		},
+		{
+			name:     "renamed parameter is not a change",
+			base:     "package client\n\nfunc Get(url string) (resp *Response, err error) { return nil, nil }\n",
+			head:     "package client\n\nfunc Get(u string) (*Response, error) { return nil, nil }\n",
+			expected: nil,
+		},
+		{
+			name: "function added and removed",
+			base: "package client\n\nfunc Old() {}\nfunc helper() {}\n",
+			head: "package client\n\nfunc New[T any](v T) T { return v }\nfunc other() {}\n",
+			expected: []string{
+				"added func New: func New[T any](T) T",
+				"removed func Old: func Old() (breaking)",
+			},
+		},
+		{
+			name: "methods of exported types",
+			base: "package client\n\ntype Client struct{}\ntype conn struct{}\n\nfunc (c *Client) Do(r *Request) error { return nil }\nfunc (c *conn) Close() error { return nil }\n",
+			head: "package client\n\ntype Client struct{}\ntype conn struct{}\n\nfunc (c Client) Do(r *Request) error { return nil }\nfunc (c *Client) Close() error { return nil }\n",
+			expected: []string{
+				"added method Client.Close: func (*Client) Close() error",
+				"changed method Client.Do: func (*Client) Do(*Request) error → func (Client) Do(*Request) error (breaking)",
+			},
+		},
+		{
+			name: "struct fields",
+			base: "package client\n\ntype Options struct {\n\tTimeout int\n\tRetries int\n\tdebug bool\n\tLogger\n}\n",
+			head: "package client\n\ntype Options struct {\n\tTimeout time.Duration\n\tProxy string\n\t*Logger\n}\n",
+			expected: []string{
+				"changed field Options.Logger: Logger → *Logger (breaking)",
+				"added field Options.Proxy: string",
+				"removed field Options.Retries: int (breaking)",
+				"changed field Options.Timeout: int → time.Duration (breaking)",
+			},
+		},
+		{
+			name: "interface methods",
+			base: "package store\n\ntype Store interface {\n\tGet(key string) ([]byte, error)\n\tDelete(key string) error\n}\n",
+			head: "package store\n\ntype Store interface {\n\tGet(key string) ([]byte, error)\n\tList(prefix string) ([]string, error)\n\tio.Closer\n}\n",
+			expected: []string{
+				"removed interface method Store.Delete: Delete(string) error (breaking)",
+				"added interface method Store.List: List(string) ([]string, error) (breaking)",
+				"added interface method Store.io.Closer: io.Closer (breaking)",
+			},
+		},
+		{
+			name: "members of a removed type are not listed",
+			base: "package client\n\ntype Options struct {\n\tTimeout int\n}\n\nfunc (o Options) Validate() error { return nil }\n",
+			head: "package client\n",
+			expected: []string{
+				"removed type Options: type Options struct (breaking)",
+			},
+		},
+		{
+			name: "type kind changed",
+			base: "package client\n\ntype ID string\ntype Alias = int\n",
+			head: "package client\n\ntype ID int64\ntype Alias = int\n",
+			expected: []string{
+				"changed type ID: type ID string → type ID int64 (breaking)",
+			},
+		},
+		{
+			name: "constants and variables",
+			base: "package client\n\nconst (\n\tStatusOK Status = iota\n\tStatusFailed\n\tDefaultPort = 80\n)\n\nvar ErrNotFound = errors.New(\"not found\")\nvar Timeout int\n",
+			head: "package client\n\nconst (\n\tStatusOK Code = iota\n\tStatusFailed\n\tDefaultPort = 8080\n)\n\nvar Timeout time.Duration\n",
+			expected: []string{
+				"removed var ErrNotFound: var ErrNotFound (breaking)",
+				"changed const StatusFailed: const StatusFailed Status → const StatusFailed Code (breaking)",
+				"changed const StatusOK: const StatusOK Status → const StatusOK Code (breaking)",
+				"changed var Timeout: var Timeout int → var Timeout time.Duration (breaking)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, _, err := parseSymbols("client.go", tt.base)
+			if err != nil {
+				t.Fatalf("parseSymbols(base) error = %v", err)
+			}
+			head, _, err := parseSymbols("client.go", tt.head)
+			if err != nil {
+				t.Fatalf("parseSymbols(head) error = %v", err)
+			}
+
+			got := describe(diff("pkg/client", &packageAPI{base: base, head: head}))
+			if got != strings.Join(tt.expected, "\n") {
+				t.Errorf("diff() =\n%s\nwant\n%s", got, strings.Join(tt.expected, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseSymbols_PackageMain(t *testing.T) {
+	_, importable, err := parseSymbols("main.go", "package main\n\nfunc Run() {}\n")
+	if err != nil {
+		t.Fatalf("parseSymbols() error = %v", err)
+	}
+	if importable {
+		t.Error("expected package main not to be importable")
+	}
+
+	if _, _, err := parseSymbols("broken.go", "package client\n\nfunc ("); err == nil {
+		t.Error("expected an error for invalid Go source")
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	source := &mockDocumentationSource{files: map[string]string{
+		"v1:pkg/client/client.go":  "package client\n\nfunc Get(url string) error { return nil }\n\nfunc Head(url string) error { return nil }\n",
+		"v2:pkg/client/client.go":  "package client\n\nfunc Get(url string) error { return nil }\n",
+		"v2:pkg/client/head.go":    "package client\n\nfunc Head(url string) error { return nil }\n",
+		"v1:pkg/client/retry.go":   "package client\n\nfunc Retry(n int) {}\n",
+		"v1:pkg/client/options.go": "package client\n\ntype Options struct{}\n",
+		"v2:pkg/client/options.go": "package client\n\ntype Options struct{ Timeout int }\n",
+		"v1:cmd/tool/main.go":      "package main\n\nfunc Run() {}\n",
+		"v2:cmd/tool/main.go":      "package main\n",
+		"v1:pkg/broken/broken.go":  "package broken\n\nfunc Old() {}\n",
+		"v2:pkg/broken/broken.go":  "package broken\n\nfunc (",
+	}}
+	files := []types.FileChange{
+		{Filename: "pkg/client/client.go", Status: "modified"},
+		{Filename: "pkg/client/head.go", Status: "added"},
+		{Filename: "pkg/client/retry.go", Status: "removed"},
+		{Filename: "pkg/client/options.go", Status: "modified"},
+		{Filename: "cmd/tool/main.go", Status: "modified"},
+		{Filename: "pkg/broken/broken.go", Status: "modified"},
+		{Filename: "pkg/client/missing.go", Status: "modified"},
+		{Filename: "internal/util/util.go", Status: "modified"},
+	}
+
+	Annotate(context.Background(), source, files, "v1", "v2")
+
+	got := Collect([]*types.Comparison{nil, {Files: files}})
+	expected := []string{
+		"removed func Retry: func Retry(int) (breaking)",
+		"added field Options.Timeout: int",
+	}
+	if describe(got) != strings.Join(expected, "\n") {
+		t.Errorf("Collect() =\n%s\nwant\n%s", describe(got), strings.Join(expected, "\n"))
+	}
+	if got[0].File != "pkg/client/retry.go" || got[0].Package != "pkg/client" {
+		t.Errorf("expected the removal to be attributed to pkg/client/retry.go, got %+v", got[0])
+	}
+	for _, i := range []int{0, 1, 4, 5, 6, 7} {
+		if files[i].GoAPI != nil {
+			t.Errorf("expected no Go API changes for %s, got %+v", files[i].Filename, files[i].GoAPI)
+		}
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	if Evidence(nil) != "" {
+		t.Error("expected no evidence without changes")
+	}
+
+	evidence := Evidence([]types.GoAPIChange{
+		{Package: "pkg/client", File: "pkg/client/client.go", Identifier: "Get", Kind: KindFunc, Change: ChangeChanged, From: "func Get(string) error", To: "func Get(context.Context, string) error", Breaking: true},
+		{Package: "pkg/client", File: "pkg/client/options.go", Identifier: "Number", Kind: KindType, Change: ChangeAdded, To: "type Number interface{int | float64}"},
+	})
+	for _, want := range []string{
+		"**Go API changes**",
+		"| pkg/client | Get | func | changed | func Get(string) error → func Get(context.Context, string) error | yes |",
+		"| pkg/client | Number | type | added | type Number interface{int \\| float64} | no |",
+	} {
+		if !strings.Contains(evidence, want) {
+			t.Errorf("evidence missing %q:\n%s", want, evidence)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package goapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Kinds of exported identifiers
+const (
+	KindFunc            = "func"
+	KindMethod          = "method"
+	KindType            = "type"
+	KindField           = "field"
+	KindInterfaceMethod = "interface method"
+	KindConst           = "const"
+	KindVar             = "var"
+)
+
+// symbol is an exported identifier with a signature that ignores parameter names
+type symbol struct {
+	kind      string
+	signature string
+	parent    string // Type that declares a method or field; "" for package-level identifiers
+	file      int    // Index of the changed file that declares the symbol
+}
+
+// parseSymbols parses the content of a Go file and returns its exported identifiers keyed by name
+// Methods and fields are keyed as "Type.Name". The second result is false for package main, which can't be imported
+func parseSymbols(filename, content string) (map[string]symbol, bool, error) {
+	symbols := map[string]symbol{}
+	if content == "" {
+		return symbols, true, nil
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), filename, content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	if file.Name.Name == "main" {
+		return nil, false, nil
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			addFunc(symbols, decl)
+		case *ast.GenDecl:
+			addGenDecl(symbols, decl)
+		}
+	}
+	return symbols, true, nil
+}
+
+// addFunc records an exported function, or an exported method of an exported type
+func addFunc(symbols map[string]symbol, decl *ast.FuncDecl) {
+	if !decl.Name.IsExported() {
+		return
+	}
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		symbols[decl.Name.Name] = symbol{
+			kind:      KindFunc,
+			signature: "func " + decl.Name.Name + typeParams(decl.Type.TypeParams) + signature(decl.Type),
+		}
+		return
+	}
+
+	receiver := decl.Recv.List[0].Type
+	typeName := baseName(receiver)
+	if !ast.IsExported(typeName) {
+		return
+	}
+	pointer := ""
+	if _, ok := receiver.(*ast.StarExpr); ok {
+		pointer = "*"
+	}
+	symbols[typeName+"."+decl.Name.Name] = symbol{
+		kind:      KindMethod,
+		signature: fmt.Sprintf("func (%s%s) %s%s", pointer, typeName, decl.Name.Name, signature(decl.Type)),
+		parent:    typeName,
+	}
+}
+
+// addGenDecl records the exported types, constants and variables of a declaration
+func addGenDecl(symbols map[string]symbol, decl *ast.GenDecl) {
+	// Constants without a type or value repeat the previous spec's, as with iota
+	constType := ""
+	for _, spec := range decl.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			addType(symbols, spec)
+		case *ast.ValueSpec:
+			kind := KindVar
+			typ := ""
+			if spec.Type != nil {
+				typ = " " + types.ExprString(spec.Type)
+			}
+			if decl.Tok == token.CONST {
+				kind = KindConst
+				if spec.Type != nil || len(spec.Values) > 0 {
+					constType = typ
+				}
+				typ = constType
+			}
+			for _, name := range spec.Names {
+				if name.IsExported() {
+					symbols[name.Name] = symbol{kind: kind, signature: kind + " " + name.Name + typ}
+				}
+			}
+		}
+	}
+}
+
+// addType records an exported type, and the exported fields and methods of a struct or interface
+// Struct and interface signatures leave out their members, which are compared one by one
+func addType(symbols map[string]symbol, spec *ast.TypeSpec) {
+	name := spec.Name.Name
+	if !spec.Name.IsExported() {
+		return
+	}
+	header := "type " + name + typeParams(spec.TypeParams)
+
+	switch typ := spec.Type.(type) {
+	case *ast.StructType:
+		symbols[name] = symbol{kind: KindType, signature: header + " struct"}
+		for _, field := range typ.Fields.List {
+			for _, fieldName := range fieldNames(field) {
+				if ast.IsExported(fieldName) {
+					symbols[name+"."+fieldName] = symbol{kind: KindField, signature: types.ExprString(field.Type), parent: name}
+				}
+			}
+		}
+	case *ast.InterfaceType:
+		symbols[name] = symbol{kind: KindType, signature: header + " interface"}
+		for _, method := range typ.Methods.List {
+			if len(method.Names) == 0 {
+				// Embedded interfaces and type constraints add to the method set like methods do
+				embedded := types.ExprString(method.Type)
+				symbols[name+"."+embedded] = symbol{kind: KindInterfaceMethod, signature: embedded, parent: name}
+				continue
+			}
+			funcType, ok := method.Type.(*ast.FuncType)
+			if !ok {
+				continue
+			}
+			for _, methodName := range method.Names {
+				if methodName.IsExported() {
+					symbols[name+"."+methodName.Name] = symbol{kind: KindInterfaceMethod, signature: methodName.Name + signature(funcType), parent: name}
+				} else {
+					// An unexported method still stops other packages from implementing the interface
+					symbols[name+"."+methodName.Name] = symbol{kind: KindInterfaceMethod, signature: "unexported", parent: name}
+				}
+			}
+		}
+	default:
+		assign := " "
+		if spec.Assign.IsValid() {
+			assign = " = "
+		}
+		symbols[name] = symbol{kind: KindType, signature: header + assign + types.ExprString(spec.Type)}
+	}
+}
+
+// fieldNames returns a struct field's names; an embedded field is named after its type
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		return []string{baseName(field.Type)}
+	}
+	names := make([]string, 0, len(field.Names))
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	return names
+}
+
+// baseName strips pointers, package qualifiers and type arguments from a type expression
+func baseName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return baseName(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.IndexExpr:
+		return baseName(expr.X)
+	case *ast.IndexListExpr:
+		return baseName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	default:
+		return types.ExprString(expr)
+	}
+}
+
+// signature formats a function's parameter and result types without their names,
+// so renaming a parameter isn't reported as a change
+func signature(funcType *ast.FuncType) string {
+	params := "(" + strings.Join(fieldTypes(funcType.Params), ", ") + ")"
+	results := fieldTypes(funcType.Results)
+	switch len(results) {
+	case 0:
+		return params
+	case 1:
+		return params + " " + results[0]
+	default:
+		return params + " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
+// fieldTypes lists the type of every parameter or result, repeating a type shared by several names
+func fieldTypes(list *ast.FieldList) []string {
+	if list == nil {
+		return nil
+	}
+	var fieldTypes []string
+	for _, field := range list.List {
+		count := max(len(field.Names), 1)
+		for range count {
+			fieldTypes = append(fieldTypes, types.ExprString(field.Type))
+		}
+	}
+	return fieldTypes
+}
+
+// typeParams formats a type parameter list such as "[K comparable, V any]"
+func typeParams(list *ast.FieldList) string {
+	if list == nil || len(list.List) == 0 {
+		return ""
+	}
+	params := make([]string, 0, len(list.List))
+	for _, field := range list.List {
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		params = append(params, strings.Join(names, ", ")+" "+types.ExprString(field.Type))
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
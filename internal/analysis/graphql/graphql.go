@@ -0,0 +1,179 @@
+package graphql
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// Change kinds
+const (
+	KindTypeRemoved             = "type_removed"
+	KindFieldRemoved            = "field_removed"
+	KindFieldTypeChanged        = "field_type_changed"
+	KindNullabilityChanged      = "nullability_changed"
+	KindArgumentRemoved         = "argument_removed"
+	KindRequiredArgumentAdded   = "required_argument_added"
+	KindRequiredInputFieldAdded = "required_input_field_added"
+	KindEnumValueRemoved        = "enum_value_removed"
+)
+
+// IsSchema reports whether a file is a GraphQL schema
+func IsSchema(filename string) bool {
+	ext := path.Ext(filename)
+	return ext == ".graphql" || ext == ".graphqls" || ext == ".gql"
+}
+
+// Diff compares two versions of a GraphQL schema, either of which may be empty
+// Output fields break clients when they become nullable; arguments and input fields when they become required
+func Diff(filename, base, head string) ([]types.APIChange, error) {
+	if base == "" {
+		return nil, nil
+	}
+
+	before, err := parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base schema: %w", err)
+	}
+	after, err := parse(head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse head schema: %w", err)
+	}
+
+	d := &differ{filename: filename}
+	for _, name := range sortedKeys(before.types, nil) {
+		beforeType := before.types[name]
+		afterType, exists := after.types[name]
+		switch {
+		case !exists:
+			d.add(KindTypeRemoved, name, "Type removed")
+		case beforeType.kind == "enum":
+			for _, value := range sortedKeys(beforeType.values, nil) {
+				if !afterType.values[value] {
+					d.add(KindEnumValueRemoved, name+"."+value, "Enum value removed")
+				}
+			}
+		default:
+			d.compareFields(name, beforeType.kind == "input", beforeType.fields, afterType.fields)
+		}
+	}
+	return d.changes, nil
+}
+
+// differ accumulates the changes of one schema file
+type differ struct {
+	filename string
+	changes  []types.APIChange
+}
+
+// add records a breaking change
+func (d *differ) add(kind, location, format string, args ...any) {
+	d.changes = append(d.changes, types.APIChange{
+		File:        d.filename,
+		Kind:        kind,
+		Location:    location,
+		Description: fmt.Sprintf(format, args...),
+		Breaking:    true,
+	})
+}
+
+// compareFields compares the fields of an object, interface or input type
+func (d *differ) compareFields(typeName string, input bool, before, after map[string]fieldDef) {
+	for _, name := range sortedKeys(before, after) {
+		location := typeName + "." + name
+		beforeField, existed := before[name]
+		afterField, exists := after[name]
+		switch {
+		case !exists:
+			d.add(KindFieldRemoved, location, "Field removed")
+		case !existed:
+			if input && isRequired(afterField) {
+				d.add(KindRequiredInputFieldAdded, location, "New required input field of type %s", afterField.typ)
+			}
+		default:
+			d.compareTypes(location, input, beforeField, afterField)
+			if !input {
+				d.compareArguments(location, beforeField.args, afterField.args)
+			}
+		}
+	}
+}
+
+// compareArguments compares the arguments of a field
+func (d *differ) compareArguments(field string, before, after map[string]fieldDef) {
+	for _, name := range sortedKeys(before, after) {
+		location := field + "(" + name + ")"
+		beforeArgument, existed := before[name]
+		afterArgument, exists := after[name]
+		switch {
+		case !exists:
+			d.add(KindArgumentRemoved, location, "Argument removed")
+		case !existed:
+			if isRequired(afterArgument) {
+				d.add(KindRequiredArgumentAdded, location, "New required argument of type %s", afterArgument.typ)
+			}
+		default:
+			d.compareTypes(location, true, beforeArgument, afterArgument)
+		}
+	}
+}
+
+// compareTypes compares the named type and nullability of a field or argument
+func (d *differ) compareTypes(location string, input bool, before, after fieldDef) {
+	if strings.ReplaceAll(before.typ, "!", "") != strings.ReplaceAll(after.typ, "!", "") {
+		d.add(KindFieldTypeChanged, location, "Type changed from %s to %s", before.typ, after.typ)
+		return
+	}
+
+	beforeLevels, afterLevels := nullability(before.typ), nullability(after.typ)
+	for i := range beforeLevels {
+		loosened := beforeLevels[i] && !afterLevels[i]
+		tightened := !beforeLevels[i] && afterLevels[i] && !(i == 0 && after.hasDefault)
+		if !input && loosened {
+			d.add(KindNullabilityChanged, location, "Became nullable: %s to %s", before.typ, after.typ)
+			return
+		}
+		if input && tightened {
+			d.add(KindNullabilityChanged, location, "Became required: %s to %s", before.typ, after.typ)
+			return
+		}
+	}
+}
+
+// isRequired reports whether an argument or input field must be provided
+func isRequired(field fieldDef) bool {
+	return strings.HasSuffix(field.typ, "!") && !field.hasDefault
+}
+
+// nullability lists whether each level of a type reference is non-null, outermost first
+// [String!]! yields [true, true] and [String] yields [false, false]
+func nullability(typ string) []bool {
+	var levels []bool
+	for {
+		levels = append(levels, strings.HasSuffix(typ, "!"))
+		typ = strings.TrimSuffix(typ, "!")
+		if !strings.HasPrefix(typ, "[") || !strings.HasSuffix(typ, "]") {
+			return levels
+		}
+		typ = typ[1 : len(typ)-1]
+	}
+}
+
+// sortedKeys returns the union of the keys of two maps in order
+func sortedKeys[V any](a, b map[string]V) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range []map[string]V{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,193 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+const baseSchema = `# Shop API
+schema {
+  query: Query
+}
+
+directive @auth(role: String = "user") on FIELD_DEFINITION | OBJECT
+
+"""
+A customer order
+"""
+type Order implements Node @key(fields: "id") {
+  id: ID!
+  "Total in cents"
+  total: Int!
+  items: [Item!]!
+  note: String
+  status: Status!
+}
+
+type Item {
+  sku: String!
+}
+
+type Coupon {
+  code: String!
+}
+
+interface Node {
+  id: ID!
+}
+
+input OrderFilter {
+  status: Status
+  since: String
+  limit: Int = 10
+}
+
+enum Status {
+  OPEN
+  CLOSED
+  CANCELLED @deprecated(reason: "use CLOSED")
+}
+
+union SearchResult = Order | Item
+
+scalar DateTime
+
+type Query {
+  orders(filter: OrderFilter, first: Int = 20, after: String): [Order!]! @auth(role: "admin")
+  order(id: ID!): Order
+}
+`
+
+const headSchema = `schema {
+  query: Query
+}
+
+directive @auth(role: String = "user") on FIELD_DEFINITION | OBJECT
+
+type Order implements Node @key(fields: "id") {
+  id: ID!
+  total: Int
+  items: [Item]!
+  note: String!
+  status: String!
+}
+
+type Item {
+  sku: String!
+}
+
+interface Node {
+  id: ID!
+}
+
+input OrderFilter {
+  status: Status!
+  tenant: ID!
+  region: String
+  limit: Int! = 10
+}
+
+enum Status {
+  OPEN
+  CLOSED
+}
+
+union SearchResult = Order | Item
+
+scalar DateTime
+
+type Query {
+  orders(filter: OrderFilter, first: Int! = 20, tenant: ID!): [Order!]! @auth(role: "admin")
+  order(id: ID!): Order
+}
+
+extend type Query {
+  coupons: [String!]!
+}
+`
+
+// summaries returns "kind location" for each change
+func summaries(changes []types.APIChange) []string {
+	var result []string
+	for _, change := range changes {
+		result = append(result, change.Kind+" "+change.Location)
+	}
+	return result
+}
+
+func TestDiff(t *testing.T) {
+	changes, err := Diff("schema.graphql", baseSchema, headSchema)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	expected := []string{
+		"type_removed Coupon",
+		"nullability_changed Order.items",
+		"field_type_changed Order.status",
+		"nullability_changed Order.total",
+		"field_removed OrderFilter.since",
+		"nullability_changed OrderFilter.status",
+		"required_input_field_added OrderFilter.tenant",
+		"argument_removed Query.orders(after)",
+		"required_argument_added Query.orders(tenant)",
+		"enum_value_removed Status.CANCELLED",
+	}
+	if got := summaries(changes); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+
+	for _, change := range changes {
+		if !change.Breaking || change.File != "schema.graphql" || change.Description == "" {
+			t.Errorf("expected a breaking change with file and description, got %+v", change)
+		}
+		if change.Location == "Order.total" && change.Description != "Became nullable: Int! to Int" {
+			t.Errorf("unexpected description for Order.total: %q", change.Description)
+		}
+	}
+}
+
+func TestDiffEdgeCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		head     string
+		expected []string
+	}{
+		{name: "new schema", head: headSchema},
+		{name: "unchanged", base: baseSchema, head: baseSchema},
+		{
+			name: "extension fields are merged",
+			base: "type Query { a: Int }\nextend type Query { b: Int }",
+			head: "type Query { a: Int b: Int }",
+		},
+		{
+			name:     "removed schema",
+			base:     "type Query { a: Int }",
+			expected: []string{"type_removed Query"},
+		},
+		{
+			name: "tightening outputs and loosening inputs is safe",
+			base: "type Query { a(x: Int!): Int }\ninput I { y: String! }",
+			head: "type Query { a(x: Int): Int! }\ninput I { y: String }",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := Diff("schema.graphql", tt.base, tt.head)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if got := summaries(changes); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Diff() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := Diff("schema.graphql", baseSchema, `type Query { """ unterminated`); err == nil {
+		t.Error("expected an error for an unterminated block string")
+	}
+}
@@ -0,0 +1,330 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// definitionKeywords start a top-level definition
+var definitionKeywords = map[string]bool{
+	"type": true, "interface": true, "input": true, "enum": true, "union": true,
+	"scalar": true, "schema": true, "directive": true, "extend": true,
+}
+
+// schema holds the named types of a GraphQL SDL document
+type schema struct {
+	types map[string]*typeDef
+}
+
+// typeDef is an object, interface, input, enum, union or scalar type
+type typeDef struct {
+	kind   string
+	fields map[string]fieldDef // Object, interface and input fields
+	values map[string]bool     // Enum values
+}
+
+// fieldDef is a field with its type reference and arguments
+type fieldDef struct {
+	typ        string
+	hasDefault bool // Input fields only
+	args       map[string]fieldDef
+}
+
+// parser walks the tokens of an SDL document
+type parser struct {
+	tokens []string
+	pos    int
+	schema *schema
+}
+
+// parse reads the type definitions of an SDL document, merging type extensions into their types
+// Descriptions, directives and the schema definition are skipped
+func parse(content string) (*schema, error) {
+	tokens, err := tokenize(content)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, schema: &schema{types: map[string]*typeDef{}}}
+	for !p.done() {
+		token := p.next()
+		if token == "extend" {
+			token = p.next()
+		}
+
+		switch token {
+		case "type", "interface", "input":
+			p.parseFields(p.typeDef(token, p.next()))
+		case "enum":
+			p.parseEnum(p.typeDef(token, p.next()))
+		case "union", "scalar":
+			p.typeDef(token, p.next())
+			p.skipToDefinition()
+		case "schema":
+			p.skipToDefinition()
+		case "directive":
+			p.skipDirectiveDefinition()
+		default:
+			// Descriptions and anything unrecognized between definitions
+		}
+	}
+	return p.schema, nil
+}
+
+// typeDef returns the named type, creating it on first definition
+func (p *parser) typeDef(kind, name string) *typeDef {
+	def, ok := p.schema.types[name]
+	if !ok {
+		def = &typeDef{kind: kind, fields: map[string]fieldDef{}, values: map[string]bool{}}
+		p.schema.types[name] = def
+	}
+	return def
+}
+
+// parseFields reads "implements A & B @directive { fields }" for objects, interfaces and inputs
+func (p *parser) parseFields(def *typeDef) {
+	if !p.skipToBlock() {
+		return
+	}
+
+	for !p.done() {
+		token := p.next()
+		switch {
+		case token == "}":
+			return
+		case isString(token):
+		case token == "@":
+			p.skipDirective()
+		default:
+			def.fields[token] = p.parseField()
+		}
+	}
+}
+
+// parseField reads "(args): Type = default @directives" after a field name
+func (p *parser) parseField() fieldDef {
+	field := fieldDef{args: map[string]fieldDef{}}
+	if p.peek() == "(" {
+		p.next()
+		for !p.done() {
+			token := p.next()
+			if token == ")" {
+				break
+			}
+			if isString(token) {
+				continue
+			}
+			if token == "@" {
+				p.skipDirective()
+				continue
+			}
+			field.args[token] = p.parseField()
+		}
+	}
+
+	if p.peek() == ":" {
+		p.next()
+		field.typ = p.parseType()
+	}
+	if p.peek() == "=" {
+		p.next()
+		field.hasDefault = true
+		p.skipValue()
+	}
+	for p.peek() == "@" {
+		p.next()
+		p.skipDirective()
+	}
+	return field
+}
+
+// parseType reads a type reference such as [String!]!
+func (p *parser) parseType() string {
+	var b strings.Builder
+	if p.peek() == "[" {
+		p.next()
+		b.WriteString("[" + p.parseType())
+		if p.peek() == "]" {
+			p.next()
+		}
+		b.WriteString("]")
+	} else {
+		b.WriteString(p.next())
+	}
+	if p.peek() == "!" {
+		p.next()
+		b.WriteString("!")
+	}
+	return b.String()
+}
+
+// parseEnum reads the values of an enum
+func (p *parser) parseEnum(def *typeDef) {
+	if !p.skipToBlock() {
+		return
+	}
+
+	for !p.done() {
+		token := p.next()
+		switch {
+		case token == "}":
+			return
+		case isString(token):
+		case token == "@":
+			p.skipDirective()
+		default:
+			def.values[token] = true
+		}
+	}
+}
+
+// skipToBlock skips interfaces and directives up to the opening brace of a body
+// Returns false when the definition has no body
+func (p *parser) skipToBlock() bool {
+	for !p.done() {
+		switch token := p.peek(); {
+		case token == "{":
+			p.next()
+			return true
+		case definitionKeywords[token] || isString(token):
+			return false
+		case token == "@":
+			p.next()
+			p.skipDirective()
+		default:
+			p.next()
+		}
+	}
+	return false
+}
+
+// skipToDefinition skips tokens, including any block, until the next top-level definition
+func (p *parser) skipToDefinition() {
+	for !p.done() && !definitionKeywords[p.peek()] && !isString(p.peek()) {
+		switch p.next() {
+		case "{":
+			p.skipBalanced("{", "}")
+		case "@":
+			p.skipDirective()
+		}
+	}
+}
+
+// skipDirectiveDefinition skips "directive @name(args) repeatable on LOCATION | LOCATION"
+func (p *parser) skipDirectiveDefinition() {
+	if p.peek() == "@" {
+		p.next()
+	}
+	p.skipDirective()
+	for !p.done() {
+		token := p.peek()
+		if token != "on" && token != "|" && token != "repeatable" && strings.ToUpper(token) != token {
+			return
+		}
+		p.next()
+	}
+}
+
+// skipDirective skips a directive name and its arguments, after the @
+func (p *parser) skipDirective() {
+	p.next()
+	if p.peek() == "(" {
+		p.next()
+		p.skipBalanced("(", ")")
+	}
+}
+
+// skipValue skips a default value, which may be a list or an input object
+func (p *parser) skipValue() {
+	switch p.next() {
+	case "[":
+		p.skipBalanced("[", "]")
+	case "{":
+		p.skipBalanced("{", "}")
+	}
+}
+
+// skipBalanced skips past the closer matching an opener that was already consumed
+func (p *parser) skipBalanced(opener, closer string) {
+	depth := 1
+	for !p.done() && depth > 0 {
+		switch p.next() {
+		case opener:
+			depth++
+		case closer:
+			depth--
+		}
+	}
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+// isString reports whether a token is a string or block string, such as a description
+func isString(token string) bool {
+	return strings.HasPrefix(token, `"`)
+}
+
+// tokenize splits an SDL document into names, punctuation and strings, dropping comments and commas
+func tokenize(content string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(content[i:], `"""`):
+			end := strings.Index(content[i+3:], `"""`)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated block string")
+			}
+			tokens = append(tokens, content[i:i+end+6])
+			i += end + 6
+		case c == '"':
+			start := i
+			for i++; i < len(content) && content[i] != '"'; i++ {
+				if content[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(content) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			tokens = append(tokens, content[start:i])
+		case isNameByte(c):
+			start := i
+			for i < len(content) && isNameByte(content[i]) {
+				i++
+			}
+			tokens = append(tokens, content[start:i])
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// isNameByte reports whether a byte belongs to a name or number
+func isNameByte(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
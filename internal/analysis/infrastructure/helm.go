@@ -0,0 +1,86 @@
+package infrastructure
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+	"release-confidence-score/internal/git/types"
+)
+
+// valueKeys mark the Helm values that change how a chart runs; values below them are compared
+var valueKeys = map[string]bool{
+	"replicaCount": true,
+	"replicas":     true,
+	"minReplicas":  true,
+	"maxReplicas":  true,
+	"autoscaling":  true,
+	"resources":    true,
+	"image":        true,
+	"tag":          true,
+}
+
+// diffValues compares two versions of a Helm values file key by key
+// Only image, replica, autoscaling and resource values, probes and env var names are compared
+func diffValues(base, head string) ([]types.InfrastructureChange, error) {
+	var before, after map[string]any
+	if err := yaml.Unmarshal([]byte(base), &before); err != nil {
+		return nil, fmt.Errorf("failed to parse base values: %w", err)
+	}
+	if err := yaml.Unmarshal([]byte(head), &after); err != nil {
+		return nil, fmt.Errorf("failed to parse head values: %w", err)
+	}
+	return diffValueMaps("", false, before, after), nil
+}
+
+// diffValueMaps walks two value maps in parallel; relevant is set below a key in valueKeys
+func diffValueMaps(prefix string, relevant bool, before, after map[string]any) []types.InfrastructureChange {
+	var changes []types.InfrastructureChange
+	for _, key := range sortedKeys(before, after) {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+		beforeValue, afterValue := before[key], after[key]
+
+		switch {
+		case isProbe(key):
+			changes = appendBlockChange(changes, field, beforeValue, afterValue)
+		case key == "env":
+			changes = append(changes, diffValuesEnv(field, beforeValue, afterValue)...)
+		default:
+			beforeMap, beforeIsMap := beforeValue.(map[string]any)
+			afterMap, afterIsMap := afterValue.(map[string]any)
+			if beforeIsMap || afterIsMap {
+				changes = append(changes, diffValueMaps(field, relevant || valueKeys[key], beforeMap, afterMap)...)
+			} else if relevant || valueKeys[key] {
+				changes = appendScalarChange(changes, field, beforeValue, afterValue)
+			}
+		}
+	}
+	return changes
+}
+
+// diffValuesEnv compares env var names, whether the values file lists them Kubernetes style or as a map
+func diffValuesEnv(field string, before, after any) []types.InfrastructureChange {
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+	if !beforeIsMap && !afterIsMap {
+		return diffEnv(field, before, after)
+	}
+
+	var changes []types.InfrastructureChange
+	for _, name := range sortedKeys(beforeMap, afterMap) {
+		changes = appendBlockChange(changes, field+" "+name, beforeMap[name], afterMap[name])
+	}
+	return changes
+}
+
+// isProbe reports whether a key holds a container health check
+func isProbe(key string) bool {
+	for _, probe := range probes {
+		if key == probe {
+			return true
+		}
+	}
+	return false
+}
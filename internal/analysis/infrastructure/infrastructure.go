@@ -0,0 +1,169 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"release-confidence-score/internal/git/shared"
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/repoconfig"
+)
+
+// Change kinds
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// FieldObject marks a change that adds or removes a whole object
+const FieldObject = "object"
+
+// maxFiles bounds how many YAML files are fetched at both refs per comparison
+const maxFiles = 30
+
+// helmValuesFile matches values.yaml and its per-environment variants such as values-prod.yaml
+var helmValuesFile = regexp.MustCompile(`^values([._-][^/]*)?\.ya?ml$`)
+
+// ignoredPatterns are YAML files that are never Kubernetes manifests or Helm values
+var ignoredPatterns = []string{
+	".github/",
+	".gitlab-ci.yml",
+	".gitlab/",
+	".pre-commit-config.yaml",
+	".golangci.y*ml",
+	"docker-compose*.y*ml",
+	"compose.y*ml",
+	"mkdocs.y*ml",
+	"**/templates/**/*.tpl",
+}
+
+// Annotate fetches each changed YAML file at the base and head refs and records the semantic changes between them
+// Files that can't be fetched or parsed are skipped, since the raw patch is still part of the analysis
+func Annotate(ctx context.Context, source types.DocumentationSource, files []types.FileChange, baseRef, headRef string) {
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(10) // Limit concurrent API calls to avoid rate limiting
+
+	candidates := 0
+	for i := range files {
+		if !isCandidate(files[i]) {
+			continue
+		}
+		if candidates == maxFiles {
+			slog.Warn("Too many YAML files to diff semantically, skipping the rest", "limit", maxFiles)
+			break
+		}
+		candidates++
+
+		file := &files[i]
+		g.Go(func() error {
+			base, head, err := shared.FetchFileVersions(gCtx, source, *file, baseRef, headRef)
+			if err != nil {
+				slog.Debug("Skipping semantic diff", "file", file.Filename, "error", err)
+				return nil
+			}
+			changes, err := Diff(file.Filename, base, head)
+			if err != nil {
+				slog.Debug("Skipping semantic diff", "file", file.Filename, "error", err)
+				return nil
+			}
+			file.Infrastructure = changes
+			return nil
+		})
+	}
+	_ = g.Wait()
+}
+
+// isCandidate reports whether a changed file may be a Kubernetes manifest, OpenShift template or Helm values file
+func isCandidate(file types.FileChange) bool {
+	ext := path.Ext(file.Filename)
+	if ext != ".yaml" && ext != ".yml" {
+		return false
+	}
+	return file.Generated == "" && !repoconfig.MatchAny(ignoredPatterns, file.Filename)
+}
+
+// Diff compares two versions of a YAML file, either of which may be empty
+// Helm values files are compared key by key; other files are compared object by object
+func Diff(filename, base, head string) ([]types.InfrastructureChange, error) {
+	var changes []types.InfrastructureChange
+	var err error
+	if helmValuesFile.MatchString(path.Base(filename)) {
+		changes, err = diffValues(base, head)
+	} else {
+		changes, err = diffManifests(base, head)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range changes {
+		changes[i].File = filename
+	}
+	return changes, nil
+}
+
+// Collect returns the infrastructure changes of all comparisons, in file order
+func Collect(comparisons []*types.Comparison) []types.InfrastructureChange {
+	var all []types.InfrastructureChange
+	for _, comparison := range comparisons {
+		if comparison == nil {
+			continue
+		}
+		for _, file := range comparison.Files {
+			all = append(all, file.Infrastructure...)
+		}
+	}
+	return all
+}
+
+// Describe summarizes a change, e.g. "container api memory limit 2Gi→1Gi" or "readinessProbe removed"
+func Describe(change types.InfrastructureChange) string {
+	if change.Field == FieldObject {
+		return change.Change
+	}
+
+	switch {
+	case change.Change == ChangeChanged && (change.From != "" || change.To != ""):
+		return fmt.Sprintf("%s %s→%s", change.Field, valueOrNone(change.From), valueOrNone(change.To))
+	case change.Change == ChangeAdded && change.To != "":
+		return fmt.Sprintf("%s added (%s)", change.Field, change.To)
+	case change.Change == ChangeRemoved && change.From != "":
+		return fmt.Sprintf("%s removed (was %s)", change.Field, change.From)
+	default:
+		return change.Field + " " + change.Change
+	}
+}
+
+// valueOrNone shows unset values explicitly in a from→to description
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// Evidence formats infrastructure changes as a markdown table for the prompt
+func Evidence(changes []types.InfrastructureChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("**Infrastructure changes** (semantic diff of Kubernetes manifests, OpenShift templates and Helm values at the base and head refs):\n\n")
+	b.WriteString("| File | Resource | Change |\n")
+	b.WriteString("|------|----------|--------|\n")
+	for _, change := range changes {
+		resource := change.Resource
+		if resource == "" {
+			resource = "-"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", change.File, resource, strings.ReplaceAll(Describe(change), "|", "\\|"))
+	}
+	return b.String()
+}
@@ -0,0 +1,267 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// mockDocumentationSource serves files from a map keyed by "ref:path"
+type mockDocumentationSource struct {
+	files map[string]string
+}
+
+func (m *mockDocumentationSource) GetDefaultBranch(ctx context.Context) (string, error) {
+	return "main", nil
+}
+
+func (m *mockDocumentationSource) FetchFileContent(ctx context.Context, path, ref string) (string, error) {
+	content, ok := m.files[ref+":"+path]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return content, nil
+}
+
+const baseDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: api
+          image: quay.io/org/api:1.4.0
+          resources:
+            limits:
+              memory: 2Gi
+              cpu: "1"
+          readinessProbe:
+            httpGet:
+              path: /healthz
+          env:
+            - name: LOG_LEVEL
+              value: info
+            - name: DB_PASSWORD
+              value: hunter2
+        - name: sidecar
+          image: envoy:1.30
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+`
+
+const headDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: api
+          image: quay.io/org/api:1.5.0
+          resources:
+            limits:
+              memory: 1Gi
+              cpu: "1"
+          env:
+            - name: LOG_LEVEL
+              value: debug
+`
+
+// descriptions returns "resource: description" for each change
+func descriptions(changes []types.InfrastructureChange) []string {
+	var result []string
+	for _, change := range changes {
+		result = append(result, change.Resource+": "+Describe(change))
+	}
+	return result
+}
+
+func TestDiffManifests(t *testing.T) {
+	changes, err := Diff("deploy/api.yaml", baseDeployment, headDeployment)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	expected := []string{
+		"Deployment/api: replicas 3→1",
+		"Deployment/api: container api image quay.io/org/api:1.4.0→quay.io/org/api:1.5.0",
+		"Deployment/api: container api memory limit 2Gi→1Gi",
+		"Deployment/api: container api readinessProbe removed",
+		"Deployment/api: container api env DB_PASSWORD removed",
+		"Deployment/api: container api env LOG_LEVEL changed",
+		"Deployment/api: container sidecar removed",
+		"Service/api: removed",
+	}
+	if got := descriptions(changes); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+	for _, change := range changes {
+		if change.File != "deploy/api.yaml" {
+			t.Errorf("expected the file on every change, got %+v", change)
+		}
+		if strings.Contains(change.From+change.To, "hunter2") {
+			t.Errorf("env var values must not be reported: %+v", change)
+		}
+	}
+}
+
+func TestDiffOpenShiftTemplate(t *testing.T) {
+	template := func(replicas, memory string) string {
+		return `apiVersion: template.openshift.io/v1
+kind: Template
+parameters:
+  - name: IMAGE_TAG
+objects:
+  - apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: worker
+    spec:
+      replicas: ` + replicas + `
+      template:
+        spec:
+          containers:
+            - name: worker
+              image: quay.io/org/worker:${IMAGE_TAG}
+              resources:
+                requests:
+                  memory: ` + memory + `
+`
+	}
+
+	changes, err := Diff("openshift/template.yml", template("${{REPLICAS}}", "512Mi"), template("2", "256Mi"))
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	expected := []string{
+		"Deployment/worker: replicas ${{REPLICAS}}→2",
+		"Deployment/worker: container worker memory request 512Mi→256Mi",
+	}
+	if got := descriptions(changes); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Diff() = %v, want %v", got, expected)
+	}
+}
+
+func TestDiffHelmValues(t *testing.T) {
+	base := `replicaCount: 2
+image:
+  repository: quay.io/org/api
+  tag: 1.4.0
+resources:
+  limits:
+    memory: 1Gi
+livenessProbe:
+  httpGet:
+    path: /healthz
+env:
+  FEATURE_X: "true"
+ingress:
+  enabled: true
+`
+	head := `replicaCount: 4
+image:
+  repository: quay.io/org/api
+  tag: 1.5.0
+resources:
+  limits:
+    memory: 1Gi
+    cpu: 500m
+livenessProbe:
+  httpGet:
+    path: /live
+env: {}
+ingress:
+  enabled: false
+`
+
+	changes, err := Diff("charts/api/values-prod.yaml", base, head)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	expected := []string{
+		": env FEATURE_X removed",
+		": image.tag 1.4.0→1.5.0",
+		": livenessProbe changed",
+		": replicaCount 2→4",
+		": resources.limits.cpu added (500m)",
+	}
+	if got := descriptions(changes); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Diff() = %v, want %v", got, expected)
+	}
+}
+
+func TestDiffInvalidYAML(t *testing.T) {
+	if _, err := Diff("chart/templates/deployment.yaml", "", "replicas: {{ .Values.replicas }}\n  bad: indent"); err == nil {
+		t.Error("expected an error for unparseable YAML")
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	source := &mockDocumentationSource{files: map[string]string{
+		"v1:deploy/api.yaml":  baseDeployment,
+		"v2:deploy/api.yaml":  headDeployment,
+		"v2:deploy/new.yaml":  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n",
+		"v1:deploy/gone.yaml": "apiVersion: v1\nkind: Secret\nmetadata:\n  name: creds\n",
+	}}
+	files := []types.FileChange{
+		{Filename: "deploy/api.yaml", Status: "modified"},
+		{Filename: "deploy/new.yaml", Status: "added"},
+		{Filename: "deploy/gone.yaml", Status: "removed"},
+		{Filename: "deploy/missing.yaml", Status: "modified"},
+		{Filename: ".github/workflows/ci.yaml", Status: "modified"},
+		{Filename: "pnpm-lock.yaml", Status: "modified", Generated: "lockfile"},
+		{Filename: "main.go", Status: "modified"},
+	}
+
+	Annotate(context.Background(), source, files, "v1", "v2")
+
+	if len(files[0].Infrastructure) != 8 {
+		t.Errorf("expected 8 changes for deploy/api.yaml, got %+v", files[0].Infrastructure)
+	}
+	if got := descriptions(files[1].Infrastructure); len(got) != 1 || got[0] != "ConfigMap/settings: added" {
+		t.Errorf("unexpected changes for added file: %v", got)
+	}
+	if got := descriptions(files[2].Infrastructure); len(got) != 1 || got[0] != "Secret/creds: removed" {
+		t.Errorf("unexpected changes for removed file: %v", got)
+	}
+	for _, file := range files[3:] {
+		if file.Infrastructure != nil {
+			t.Errorf("expected %s to be skipped, got %+v", file.Filename, file.Infrastructure)
+		}
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	if Evidence(nil) != "" {
+		t.Error("expected no evidence without changes")
+	}
+
+	evidence := Evidence(Collect([]*types.Comparison{nil, {Files: []types.FileChange{{
+		Infrastructure: []types.InfrastructureChange{
+			{File: "deploy/api.yaml", Resource: "Deployment/api", Field: "container api memory limit", From: "2Gi", To: "1Gi", Change: ChangeChanged},
+			{File: "values.yaml", Field: "replicaCount", From: "2", Change: ChangeRemoved},
+		},
+	}}}}))
+	for _, want := range []string{
+		"**Infrastructure changes**",
+		"| deploy/api.yaml | Deployment/api | container api memory limit 2Gi→1Gi |",
+		"| values.yaml | - | replicaCount removed (was 2) |",
+	} {
+		if !strings.Contains(evidence, want) {
+			t.Errorf("evidence missing %q:\n%s", want, evidence)
+		}
+	}
+}
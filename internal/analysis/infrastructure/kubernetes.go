@@ -0,0 +1,274 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"release-confidence-score/internal/git/types"
+)
+
+// podSpecPaths locates the pod spec inside each workload kind
+var podSpecPaths = map[string][]string{
+	"Pod":              {"spec"},
+	"Deployment":       {"spec", "template", "spec"},
+	"StatefulSet":      {"spec", "template", "spec"},
+	"DaemonSet":        {"spec", "template", "spec"},
+	"ReplicaSet":       {"spec", "template", "spec"},
+	"Job":              {"spec", "template", "spec"},
+	"DeploymentConfig": {"spec", "template", "spec"},
+	"CronJob":          {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// scalarFields are top-level spec fields compared per kind
+var scalarFields = map[string][]string{
+	"Deployment":              {"replicas"},
+	"StatefulSet":             {"replicas"},
+	"ReplicaSet":              {"replicas"},
+	"DeploymentConfig":        {"replicas"},
+	"HorizontalPodAutoscaler": {"minReplicas", "maxReplicas"},
+	"PodDisruptionBudget":     {"minAvailable", "maxUnavailable"},
+}
+
+// probes are the container health checks
+var probes = []string{"livenessProbe", "readinessProbe", "startupProbe"}
+
+// object is a Kubernetes object found in a manifest
+type object struct {
+	resource string
+	kind     string
+	body     map[string]any
+}
+
+// diffManifests compares the Kubernetes objects of two versions of a manifest
+// OpenShift templates and List kinds are unwrapped into the objects they hold
+func diffManifests(base, head string) ([]types.InfrastructureChange, error) {
+	baseObjects, err := parseManifest(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base manifest: %w", err)
+	}
+	headObjects, err := parseManifest(head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse head manifest: %w", err)
+	}
+
+	var changes []types.InfrastructureChange
+	for _, resource := range sortedKeys(baseObjects, headObjects) {
+		before, after := baseObjects[resource], headObjects[resource]
+		switch {
+		case after == nil:
+			changes = append(changes, types.InfrastructureChange{Resource: resource, Field: FieldObject, Change: ChangeRemoved})
+		case before == nil:
+			changes = append(changes, types.InfrastructureChange{Resource: resource, Field: FieldObject, Change: ChangeAdded})
+		default:
+			for _, change := range diffObject(before, after) {
+				change.Resource = resource
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// parseManifest decodes every YAML document of a manifest, keyed by kind/name
+func parseManifest(content string) (map[string]*object, error) {
+	objects := map[string]*object{}
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var document any
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		collectObjects(document, objects)
+	}
+}
+
+// collectObjects adds an object, or the objects of a template or list, to objects
+func collectObjects(document any, objects map[string]*object) {
+	body, ok := document.(map[string]any)
+	if !ok {
+		return
+	}
+
+	kind, _ := body["kind"].(string)
+	switch {
+	case kind == "Template":
+		for _, item := range asList(body["objects"]) {
+			collectObjects(item, objects)
+		}
+	case strings.HasSuffix(kind, "List"):
+		for _, item := range asList(body["items"]) {
+			collectObjects(item, objects)
+		}
+	case kind != "" && body["apiVersion"] != nil:
+		name := scalar(lookup(body, "metadata", "name"))
+		resource := kind + "/" + name
+		if namespace := scalar(lookup(body, "metadata", "namespace")); namespace != "" {
+			resource = kind + "/" + namespace + "/" + name
+		}
+		objects[resource] = &object{resource: resource, kind: kind, body: body}
+	}
+}
+
+// diffObject compares the risky fields of two versions of the same object
+func diffObject(before, after *object) []types.InfrastructureChange {
+	var changes []types.InfrastructureChange
+	for _, field := range scalarFields[after.kind] {
+		changes = appendScalarChange(changes, field, lookup(before.body, "spec", field), lookup(after.body, "spec", field))
+	}
+
+	if specPath, ok := podSpecPaths[after.kind]; ok {
+		changes = append(changes, diffPodSpec(lookup(before.body, specPath...), lookup(after.body, specPath...))...)
+	}
+	return changes
+}
+
+// diffPodSpec compares the containers of two pod specs by name
+func diffPodSpec(before, after any) []types.InfrastructureChange {
+	var changes []types.InfrastructureChange
+	for _, group := range []struct{ key, label string }{{"containers", "container"}, {"initContainers", "init container"}} {
+		beforeContainers := byName(lookup(before, group.key))
+		afterContainers := byName(lookup(after, group.key))
+
+		for _, name := range sortedKeys(beforeContainers, afterContainers) {
+			label := group.label + " " + name
+			beforeContainer, existed := beforeContainers[name]
+			afterContainer, exists := afterContainers[name]
+			switch {
+			case !exists:
+				changes = append(changes, types.InfrastructureChange{Field: label, Change: ChangeRemoved})
+			case !existed:
+				changes = append(changes, types.InfrastructureChange{Field: label, Change: ChangeAdded})
+			default:
+				changes = append(changes, diffContainer(label, beforeContainer, afterContainer)...)
+			}
+		}
+	}
+	return changes
+}
+
+// diffContainer compares the image, resources, probes and environment of a container
+func diffContainer(label string, before, after any) []types.InfrastructureChange {
+	changes := appendScalarChange(nil, label+" image", lookup(before, "image"), lookup(after, "image"))
+	changes = append(changes, diffResources(label, lookup(before, "resources"), lookup(after, "resources"))...)
+	for _, probe := range probes {
+		changes = appendBlockChange(changes, label+" "+probe, lookup(before, probe), lookup(after, probe))
+	}
+	return append(changes, diffEnv(label+" env", lookup(before, "env"), lookup(after, "env"))...)
+}
+
+// diffResources compares resource limits and requests, e.g. "memory limit 2Gi→1Gi"
+func diffResources(label string, before, after any) []types.InfrastructureChange {
+	var changes []types.InfrastructureChange
+	for _, section := range []struct{ key, label string }{{"limits", "limit"}, {"requests", "request"}} {
+		beforeValues, _ := lookup(before, section.key).(map[string]any)
+		afterValues, _ := lookup(after, section.key).(map[string]any)
+		for _, resource := range sortedKeys(beforeValues, afterValues) {
+			changes = appendScalarChange(changes, fmt.Sprintf("%s %s %s", label, resource, section.label), beforeValues[resource], afterValues[resource])
+		}
+	}
+	return changes
+}
+
+// diffEnv compares environment variables by name; values are left out since they may hold secrets
+func diffEnv(label string, before, after any) []types.InfrastructureChange {
+	beforeVars, afterVars := byName(before), byName(after)
+
+	var changes []types.InfrastructureChange
+	for _, name := range sortedKeys(beforeVars, afterVars) {
+		changes = appendBlockChange(changes, label+" "+name, beforeVars[name], afterVars[name])
+	}
+	return changes
+}
+
+// appendScalarChange records a changed scalar value with its old and new value
+func appendScalarChange(changes []types.InfrastructureChange, field string, before, after any) []types.InfrastructureChange {
+	from, to := scalar(before), scalar(after)
+	switch {
+	case from == to:
+		return changes
+	case from == "":
+		return append(changes, types.InfrastructureChange{Field: field, To: to, Change: ChangeAdded})
+	case to == "":
+		return append(changes, types.InfrastructureChange{Field: field, From: from, Change: ChangeRemoved})
+	default:
+		return append(changes, types.InfrastructureChange{Field: field, From: from, To: to, Change: ChangeChanged})
+	}
+}
+
+// appendBlockChange records that a structured value was added, removed or changed, without its content
+func appendBlockChange(changes []types.InfrastructureChange, field string, before, after any) []types.InfrastructureChange {
+	switch {
+	case reflect.DeepEqual(before, after):
+		return changes
+	case before == nil:
+		return append(changes, types.InfrastructureChange{Field: field, Change: ChangeAdded})
+	case after == nil:
+		return append(changes, types.InfrastructureChange{Field: field, Change: ChangeRemoved})
+	default:
+		return append(changes, types.InfrastructureChange{Field: field, Change: ChangeChanged})
+	}
+}
+
+// lookup walks nested maps by key, returning nil when a key is missing
+func lookup(value any, keys ...string) any {
+	for _, key := range keys {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+// asList returns value as a list, or nil
+func asList(value any) []any {
+	list, _ := value.([]any)
+	return list
+}
+
+// byName indexes a list of named entries such as containers or env vars
+func byName(value any) map[string]any {
+	entries := map[string]any{}
+	for _, item := range asList(value) {
+		if name := scalar(lookup(item, "name")); name != "" {
+			entries[name] = item
+		}
+	}
+	return entries
+}
+
+// scalar formats a scalar YAML value, or returns "" for missing and structured values
+func scalar(value any) string {
+	switch value.(type) {
+	case nil, map[string]any, []any:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// sortedKeys returns the union of the keys of two maps in order
+func sortedKeys[V any](a, b map[string]V) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range []map[string]V{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
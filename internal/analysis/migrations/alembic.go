@@ -0,0 +1,180 @@
+package migrations
+
+import (
+	"regexp"
+	"strings"
+
+	"release-confidence-score/internal/git/types"
+)
+
+var (
+	upgradeFunc   = regexp.MustCompile(`(?m)^def\s+upgrade\s*\(`)
+	downgradeFunc = regexp.MustCompile(`(?m)^def\s+downgrade\s*\([^)]*\)[^:]*:`)
+	topLevelDef   = regexp.MustCompile(`(?m)^[^\s;]`)
+	opCall        = regexp.MustCompile(`\b(?:op|batch_op)\.(\w+)\s*\(`)
+	stringLiteral = regexp.MustCompile(`['"]([^'"]+)['"]`)
+	notNullable   = regexp.MustCompile(`\bnullable\s*=\s*False\b`)
+	serverDefault = regexp.MustCompile(`\bserver_default\s*=\s*[^N\s]`)
+	typeArgument  = regexp.MustCompile(`\btype_\s*=`)
+	concurrently  = regexp.MustCompile(`\bpostgresql_concurrently\s*=\s*True\b`)
+)
+
+// analyzeAlembic checks the added operations in the upgrade step of an alembic migration
+func analyzeAlembic(file types.FileChange) []Finding {
+	src := newSource(file.Patch)
+	start, end, ok := functionBody(src.text, upgradeFunc)
+	if !ok {
+		return nil
+	}
+	body := src.text[start:end]
+
+	// Indexes on tables created by the same migration lock nothing anyone is using yet
+	created := map[string]bool{}
+	for _, call := range opCalls(body) {
+		if call.name == "create_table" {
+			if table := stringArgument(call.args, 0); table != "" {
+				created[table] = true
+			}
+		}
+	}
+
+	var findings []Finding
+	for _, call := range opCalls(body) {
+		line, added := src.span(start+call.start, start+call.end)
+		if !added {
+			continue
+		}
+
+		var check Finding
+		switch call.name {
+		case "drop_table":
+			check = Finding{Check: CheckDropTable, Description: "Dropping a table destroys its data and breaks any running code that still reads it"}
+		case "drop_column":
+			check = Finding{Check: CheckDropColumn, Description: "Dropping a column destroys its data and breaks running code that still reads it"}
+		case "add_column":
+			if !notNullable.MatchString(call.args) || serverDefault.MatchString(call.args) {
+				continue
+			}
+			check = Finding{Check: CheckNotNullWithoutDefault, Description: "Non-nullable column added without a server_default fails on tables with rows and breaks inserts from code that doesn't set it"}
+		case "alter_column":
+			switch {
+			case typeArgument.MatchString(call.args):
+				check = Finding{Check: CheckColumnTypeChange, Description: "Changing a column type can rewrite the table under an exclusive lock and break code reading the old type"}
+			case notNullable.MatchString(call.args) && !serverDefault.MatchString(call.args):
+				check = Finding{Check: CheckNotNullWithoutDefault, Description: "Making a column non-nullable scans the whole table under an exclusive lock and fails if any row is null"}
+			default:
+				continue
+			}
+		case "create_index":
+			if concurrently.MatchString(call.args) || created[stringArgument(call.args, 1)] {
+				continue
+			}
+			check = Finding{Check: CheckNonConcurrentIndex, Description: "Index is built without postgresql_concurrently=True and blocks writes to the table until it completes"}
+		default:
+			continue
+		}
+
+		check.Line = line
+		check.Statement = shorten(body[call.start:call.end])
+		findings = append(findings, check)
+	}
+
+	if finding := missingDowngrade(file, src.text); finding != nil {
+		findings = append(findings, *finding)
+	}
+	return findings
+}
+
+// missingDowngrade flags a new alembic migration whose downgrade step is absent or only passes
+func missingDowngrade(file types.FileChange, text string) *Finding {
+	if file.Status != "added" {
+		return nil
+	}
+
+	start, end, ok := functionBody(text, downgradeFunc)
+	if ok {
+		for _, line := range strings.Split(text[start:end], "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && line != "pass" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, `"""`) && !strings.HasPrefix(line, "'''") {
+				return nil
+			}
+		}
+	}
+	return &Finding{
+		Check:       CheckMissingDownMigration,
+		Description: "New migration has no downgrade step; the schema change can't be rolled back automatically",
+	}
+}
+
+// functionBody returns the offsets of a top-level function's body, up to the next top-level statement
+func functionBody(text string, header *regexp.Regexp) (int, int, bool) {
+	loc := header.FindStringIndex(text)
+	if loc == nil {
+		return 0, 0, false
+	}
+	start := loc[1]
+	if newline := strings.IndexByte(text[start:], '\n'); newline >= 0 {
+		start += newline + 1
+	} else {
+		return len(text), len(text), true
+	}
+
+	end := len(text)
+	if next := topLevelDef.FindStringIndex(text[start:]); next != nil {
+		end = start + next[0]
+	}
+	return start, end, true
+}
+
+// call is one op.<name>(...) invocation in a migration
+type call struct {
+	name  string
+	args  string
+	start int
+	end   int
+}
+
+// opCalls finds alembic operation calls, extracting their arguments by balancing parentheses
+func opCalls(body string) []call {
+	var calls []call
+	for _, loc := range opCall.FindAllStringSubmatchIndex(body, -1) {
+		depth, end := 1, len(body)
+		var quote byte
+		for i := loc[1]; i < len(body); i++ {
+			c := body[i]
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+				continue
+			}
+			if c == '\'' || c == '"' {
+				quote = c
+			} else if c == '(' {
+				depth++
+			} else if c == ')' {
+				depth--
+				if depth == 0 {
+					end = i + 1
+					break
+				}
+			}
+		}
+		calls = append(calls, call{
+			name:  body[loc[2]:loc[3]],
+			args:  body[loc[1]:max(loc[1], end-1)],
+			start: loc[0],
+			end:   end,
+		})
+	}
+	return calls
+}
+
+// stringArgument returns the nth string literal in a call's arguments
+func stringArgument(args string, n int) string {
+	matches := stringLiteral.FindAllStringSubmatch(args, n+1)
+	if len(matches) <= n {
+		return ""
+	}
+	return matches[n][1]
+}
@@ -0,0 +1,152 @@
+package migrations
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"release-confidence-score/internal/analysis/rules"
+	"release-confidence-score/internal/git/types"
+)
+
+// Check names, as reported in findings
+const (
+	CheckNotNullWithoutDefault = "not_null_without_default"
+	CheckDropColumn            = "drop_column"
+	CheckDropTable             = "drop_table"
+	CheckNonConcurrentIndex    = "non_concurrent_index"
+	CheckColumnTypeChange      = "column_type_change"
+	CheckMissingDownMigration  = "missing_down_migration"
+)
+
+// checkSeverities ranks each check by how hard its failure is to recover from
+var checkSeverities = map[string]string{
+	CheckDropTable:             rules.SeverityCritical,
+	CheckDropColumn:            rules.SeverityHigh,
+	CheckNotNullWithoutDefault: rules.SeverityHigh,
+	CheckColumnTypeChange:      rules.SeverityHigh,
+	CheckNonConcurrentIndex:    rules.SeverityMedium,
+	CheckMissingDownMigration:  rules.SeverityMedium,
+}
+
+// maxStatementLength bounds the statement quoted in findings
+const maxStatementLength = 120
+
+// Finding is a dangerous schema change found in a migration
+type Finding struct {
+	Check       string `json:"check"`
+	Severity    string `json:"severity"`
+	Repo        string `json:"repo,omitempty"`
+	File        string `json:"file"`
+	Line        int    `json:"line,omitempty"`      // Line in the new file where the statement starts
+	Statement   string `json:"statement,omitempty"` // Offending statement, shortened
+	Description string `json:"description"`
+}
+
+// Analyze checks the added statements of every SQL and alembic migration in the release
+// Down migrations are skipped, since dropping what the up migration created is their purpose
+func Analyze(comparisons []*types.Comparison) []Finding {
+	var findings []Finding
+	for _, comparison := range comparisons {
+		if comparison == nil {
+			continue
+		}
+
+		filenames := map[string]bool{}
+		for _, file := range comparison.Files {
+			filenames[file.Filename] = true
+		}
+
+		for _, file := range comparison.Files {
+			if !rules.IsMigration(file.Filename) || file.Status == "removed" || isDownMigration(file.Filename) {
+				continue
+			}
+
+			var fileFindings []Finding
+			switch {
+			case strings.EqualFold(path.Ext(file.Filename), ".sql"):
+				fileFindings = analyzeSQL(file.Patch)
+			case path.Ext(file.Filename) == ".py":
+				fileFindings = analyzeAlembic(file)
+			}
+			if finding := missingDownSQL(file, filenames); finding != nil {
+				fileFindings = append(fileFindings, *finding)
+			}
+
+			for _, finding := range fileFindings {
+				finding.Repo = comparison.RepoURL
+				finding.File = file.Filename
+				finding.Severity = checkSeverities[finding.Check]
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	if len(findings) > 0 {
+		slog.Debug("Found dangerous migration statements", "count", len(findings))
+	}
+	return findings
+}
+
+// Evidence formats migration findings as markdown for the prompt
+func Evidence(findings []Finding) string {
+	if len(findings) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("**Migration checks** (static analysis of added migration statements):\n\n")
+	for _, finding := range findings {
+		fmt.Fprintf(&b, "- [%s] `%s` %s: %s", finding.Severity, finding.Check, location(finding), finding.Description)
+		if finding.Statement != "" {
+			fmt.Fprintf(&b, " (`%s`)", finding.Statement)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// location formats a finding's file and line as file:line
+func location(finding Finding) string {
+	if finding.Line == 0 {
+		return finding.File
+	}
+	return finding.File + ":" + strconv.Itoa(finding.Line)
+}
+
+var (
+	upMigration      = regexp.MustCompile(`(?i)^(.*)\.up\.sql$`)
+	downMigrationSQL = regexp.MustCompile(`(?i)(\.down\.sql$|^U\d[^/]*__)`)
+)
+
+// isDownMigration reports whether a file reverts another migration
+func isDownMigration(filename string) bool {
+	return downMigrationSQL.MatchString(path.Base(filename))
+}
+
+// missingDownSQL flags a new .up.sql migration whose .down.sql counterpart isn't part of the release
+func missingDownSQL(file types.FileChange, filenames map[string]bool) *Finding {
+	matches := upMigration.FindStringSubmatch(file.Filename)
+	if matches == nil || file.Status != "added" {
+		return nil
+	}
+	if filenames[matches[1]+".down.sql"] || filenames[matches[1]+".DOWN.SQL"] {
+		return nil
+	}
+	return &Finding{
+		Check:       CheckMissingDownMigration,
+		Description: "New up migration has no matching .down.sql; the schema change can't be rolled back automatically",
+	}
+}
+
+// shorten collapses whitespace and truncates a statement for display
+func shorten(statement string) string {
+	statement = strings.ReplaceAll(strings.Join(strings.Fields(statement), " "), "`", "")
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength] + "..."
+	}
+	return statement
+}
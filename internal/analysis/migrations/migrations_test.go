@@ -0,0 +1,234 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/analysis/rules"
+	"release-confidence-score/internal/git/types"
+)
+
+// checks returns the check names of findings, in order
+func checks(findings []Finding) []string {
+	var names []string
+	for _, finding := range findings {
+		names = append(names, finding.Check)
+	}
+	return names
+}
+
+func TestAnalyzeSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    string
+		expected []string
+	}{
+		{
+			name:     "not null column without default",
+			patch:    "+ALTER TABLE users ADD COLUMN email text NOT NULL;",
+			expected: []string{CheckNotNullWithoutDefault},
+		},
+		{
+			name:  "not null column with default",
+			patch: "+ALTER TABLE users ADD COLUMN active boolean NOT NULL DEFAULT true;",
+		},
+		{
+			name:  "nullable column",
+			patch: "+ALTER TABLE users ADD COLUMN nickname text;",
+		},
+		{
+			name:  "added constraint",
+			patch: "+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);",
+		},
+		{
+			name:     "set not null",
+			patch:    "+ALTER TABLE users ALTER COLUMN email SET NOT NULL;",
+			expected: []string{CheckNotNullWithoutDefault},
+		},
+		{
+			name:     "drop column and table",
+			patch:    "+ALTER TABLE users DROP COLUMN legacy_id;\n+DROP TABLE IF EXISTS sessions;",
+			expected: []string{CheckDropColumn, CheckDropTable},
+		},
+		{
+			name:  "drop default and constraint",
+			patch: "+ALTER TABLE users ALTER COLUMN email DROP DEFAULT, DROP CONSTRAINT users_email_key;",
+		},
+		{
+			name:     "index without concurrently",
+			patch:    "+CREATE INDEX idx_users_email ON users (email);",
+			expected: []string{CheckNonConcurrentIndex},
+		},
+		{
+			name:  "index with concurrently",
+			patch: "+CREATE UNIQUE INDEX CONCURRENTLY idx_users_email ON users (email);",
+		},
+		{
+			name:  "index on table created in the same migration",
+			patch: "+CREATE TABLE audit (id bigint, actor text);\n+CREATE INDEX idx_audit_actor ON audit (actor);",
+		},
+		{
+			name:  "mysql online index",
+			patch: "+ALTER TABLE users ADD INDEX idx_email (email), ALGORITHM=INPLACE, LOCK=NONE;",
+		},
+		{
+			name:     "column type changes",
+			patch:    "+ALTER TABLE users ALTER COLUMN id TYPE bigint;\n+ALTER TABLE orders MODIFY COLUMN total DECIMAL(12,2);",
+			expected: []string{CheckColumnTypeChange, CheckColumnTypeChange},
+		},
+		{
+			name:     "several clauses in one statement",
+			patch:    "+ALTER TABLE users\n+  ADD COLUMN tenant_id bigint NOT NULL,\n+  DROP COLUMN legacy_id;",
+			expected: []string{CheckNotNullWithoutDefault, CheckDropColumn},
+		},
+		{
+			name:  "commented out statement",
+			patch: "+-- DROP TABLE sessions;\n+/* ALTER TABLE users DROP COLUMN legacy_id; */\n+SELECT 1;",
+		},
+		{
+			name:  "semicolon in string",
+			patch: "+INSERT INTO notes VALUES ('drop table; later');",
+		},
+		{
+			name:  "unchanged context statement",
+			patch: "@@ -1,2 +1,3 @@\n DROP TABLE sessions;\n+CREATE TABLE sessions (id bigint);",
+		},
+		{
+			name:     "removed statement is ignored",
+			patch:    "@@ -1,2 +1,1 @@\n-ALTER TABLE users ADD COLUMN a text;\n+ALTER TABLE users DROP COLUMN b;",
+			expected: []string{CheckDropColumn},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checks(analyzeSQL(tt.patch))
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("analyzeSQL() checks = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSQLLineNumbers(t *testing.T) {
+	patch := "@@ -10,2 +10,4 @@\n SELECT 1;\n+\n+ALTER TABLE users\n+  DROP COLUMN legacy_id;\n@@ -40,1 +42,2 @@\n SELECT 2;\n+DROP TABLE sessions;"
+
+	findings := analyzeSQL(patch)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", findings)
+	}
+	if findings[0].Line != 12 || findings[0].Statement != "ALTER TABLE users DROP COLUMN legacy_id" {
+		t.Errorf("unexpected first finding: %+v", findings[0])
+	}
+	if findings[1].Line != 43 || findings[1].Check != CheckDropTable {
+		t.Errorf("unexpected second finding: %+v", findings[1])
+	}
+}
+
+func TestAnalyzeAlembic(t *testing.T) {
+	migration := strings.Join([]string{
+		`"""add tenant"""`,
+		`from alembic import op`,
+		`import sqlalchemy as sa`,
+		``,
+		`def upgrade():`,
+		`    op.create_table("audit", sa.Column("id", sa.BigInteger()))`,
+		`    op.create_index("ix_audit_id", "audit", ["id"])`,
+		`    op.add_column("users", sa.Column("tenant_id", sa.BigInteger(), nullable=False))`,
+		`    op.add_column("users", sa.Column("active", sa.Boolean(), nullable=False, server_default=sa.true()))`,
+		`    op.create_index("ix_users_tenant", "users", ["tenant_id"])`,
+		`    op.create_index("ix_users_email", "users", ["email"], postgresql_concurrently=True)`,
+		`    op.alter_column(`,
+		`        "users", "id",`,
+		`        type_=sa.BigInteger(),`,
+		`    )`,
+		`    op.drop_column("users", "legacy_id")`,
+		`    op.drop_table("sessions")`,
+		``,
+		`def downgrade():`,
+		`    op.drop_table("audit")`,
+	}, "\n")
+	file := types.FileChange{Filename: "alembic/versions/abc_add_tenant.py", Status: "added", Patch: "@@ -0,0 +1,20 @@\n+" + strings.ReplaceAll(migration, "\n", "\n+")}
+
+	findings := analyzeAlembic(file)
+	expected := []string{CheckNotNullWithoutDefault, CheckNonConcurrentIndex, CheckColumnTypeChange, CheckDropColumn, CheckDropTable}
+	if got := checks(findings); strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Fatalf("analyzeAlembic() checks = %v, want %v", got, expected)
+	}
+	if findings[2].Line != 12 {
+		t.Errorf("expected alter_column on line 12, got %d", findings[2].Line)
+	}
+}
+
+func TestMissingDowngrade(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		body     string
+		expected bool
+	}{
+		{"downgrade only passes", "added", "def upgrade():\n    op.drop_column('users', 'a')\n\ndef downgrade():\n    # irreversible\n    pass\n", true},
+		{"no downgrade", "added", "def upgrade():\n    pass\n", true},
+		{"real downgrade", "added", "def upgrade():\n    pass\n\ndef downgrade() -> None:\n    op.add_column('users', sa.Column('a', sa.Text()))\n", false},
+		{"modified migration", "modified", "def upgrade():\n    pass\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := types.FileChange{Status: tt.status}
+			if got := missingDowngrade(file, tt.body) != nil; got != tt.expected {
+				t.Errorf("missingDowngrade() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	comparisons := []*types.Comparison{
+		nil,
+		{
+			RepoURL: "https://github.com/org/app",
+			Files: []types.FileChange{
+				{Filename: "db/migrations/001_users.up.sql", Status: "added", Patch: "+ALTER TABLE users DROP COLUMN legacy_id;"},
+				{Filename: "db/migrations/001_users.down.sql", Status: "added", Patch: "+DROP TABLE users;"},
+				{Filename: "db/migrations/002_orders.up.sql", Status: "added", Patch: "+CREATE TABLE orders (id bigint);"},
+				{Filename: "db/migrations/000_old.up.sql", Status: "removed", Patch: "-DROP TABLE users;"},
+				{Filename: "src/schema.sql", Status: "modified", Patch: "+DROP TABLE users;"},
+			},
+		},
+	}
+
+	findings := Analyze(comparisons)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", findings)
+	}
+
+	dropped := findings[0]
+	if dropped.Check != CheckDropColumn || dropped.Severity != rules.SeverityHigh || dropped.Repo != "https://github.com/org/app" || dropped.File != "db/migrations/001_users.up.sql" || dropped.Line != 1 {
+		t.Errorf("unexpected drop column finding: %+v", dropped)
+	}
+	missing := findings[1]
+	if missing.Check != CheckMissingDownMigration || missing.Severity != rules.SeverityMedium || missing.File != "db/migrations/002_orders.up.sql" {
+		t.Errorf("unexpected missing down migration finding: %+v", missing)
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	if Evidence(nil) != "" {
+		t.Error("expected no evidence without findings")
+	}
+
+	evidence := Evidence([]Finding{
+		{Check: CheckDropTable, Severity: rules.SeverityCritical, File: "migrations/V2__drop.sql", Line: 3, Statement: "DROP TABLE sessions", Description: "Dropping a table destroys its data"},
+		{Check: CheckMissingDownMigration, Severity: rules.SeverityMedium, File: "migrations/3.up.sql", Description: "No down migration"},
+	})
+	for _, want := range []string{
+		"**Migration checks**",
+		"- [critical] `drop_table` migrations/V2__drop.sql:3: Dropping a table destroys its data (`DROP TABLE sessions`)",
+		"- [medium] `missing_down_migration` migrations/3.up.sql: No down migration\n",
+	} {
+		if !strings.Contains(evidence, want) {
+			t.Errorf("evidence missing %q:\n%s", want, evidence)
+		}
+	}
+}
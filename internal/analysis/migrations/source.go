@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// source is the new-file side of a patch as one text, remembering which lines were added
+// Hunks are separated by a lone ";" so statements never span the gap between them
+type source struct {
+	text       string
+	lineStarts []int  // Offset of each line in text
+	lineNumber []int  // Line number of each line in the new file; 0 for separators
+	added      []bool // Whether each line was added by the patch
+}
+
+// newSource rebuilds the new file's visible lines from a unified diff
+func newSource(patch string) *source {
+	src := &source{}
+	var b strings.Builder
+	next := 1
+
+	appendLine := func(text string, number int, added bool) {
+		src.lineStarts = append(src.lineStarts, b.Len())
+		src.lineNumber = append(src.lineNumber, number)
+		src.added = append(src.added, added)
+		b.WriteString(text)
+		b.WriteString("\n")
+	}
+
+	for _, line := range strings.Split(patch, "\n") {
+		if matches := hunkHeader.FindStringSubmatch(line); matches != nil {
+			next, _ = strconv.Atoi(matches[1])
+			appendLine(";", 0, false)
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		switch line[0] {
+		case '+':
+			appendLine(line[1:], next, true)
+			next++
+		case ' ':
+			appendLine(line[1:], next, false)
+			next++
+		}
+	}
+
+	src.text = b.String()
+	return src
+}
+
+// lineAt returns the index of the line containing offset
+func (s *source) lineAt(offset int) int {
+	return sort.Search(len(s.lineStarts), func(i int) bool { return s.lineStarts[i] > offset }) - 1
+}
+
+// span describes the text between two offsets: the new-file line where its content starts,
+// and whether the patch added any of its lines
+func (s *source) span(start, end int) (int, bool) {
+	// Skip leading whitespace so the reported line is where the statement's text begins
+	for start < end && (s.text[start] == ' ' || s.text[start] == '\t' || s.text[start] == '\n' || s.text[start] == '\r') {
+		start++
+	}
+	if start >= end {
+		return 0, false
+	}
+
+	first, last := s.lineAt(start), s.lineAt(end-1)
+	added := false
+	for i := first; i <= last; i++ {
+		added = added || s.added[i]
+	}
+	return s.lineNumber[first], added
+}
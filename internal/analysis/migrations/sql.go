@@ -0,0 +1,179 @@
+package migrations
+
+import (
+	"regexp"
+	"strings"
+)
+
+// statement is one SQL statement of a migration, with comments blanked out
+type statement struct {
+	text  string
+	start int // Offset in the source text
+	end   int
+}
+
+var (
+	alterTable      = regexp.MustCompile(`(?is)^\s*ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(\S+)\s+(.*)$`)
+	createTable     = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:(?:GLOBAL\s+|LOCAL\s+)?(?:TEMPORARY|TEMP|UNLOGGED)\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s(]+)`)
+	dropTable       = regexp.MustCompile(`(?is)^\s*DROP\s+TABLE\b`)
+	createIndex     = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:UNIQUE\s+)?INDEX\s+(CONCURRENTLY\b)?.*?\bON\s+(?:ONLY\s+)?([^\s(]+)`)
+	onlineIndexDDL  = regexp.MustCompile(`(?i)\b(?:ALGORITHM\s*=\s*(?:INPLACE|INSTANT)|LOCK\s*=\s*NONE|ONLINE\s*=\s*ON)\b`)
+	addColumn       = regexp.MustCompile(`(?is)^\s*ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(\S+)`)
+	addIndex        = regexp.MustCompile(`(?is)^\s*ADD\s+(?:UNIQUE\s+)?(?:INDEX|KEY)\b`)
+	dropColumn      = regexp.MustCompile(`(?is)^\s*DROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?(\S+)`)
+	setNotNull      = regexp.MustCompile(`(?is)^\s*ALTER\s+(?:COLUMN\s+)?\S+\s+SET\s+NOT\s+NULL\b`)
+	alterColumnType = regexp.MustCompile(`(?is)^\s*ALTER\s+(?:COLUMN\s+)?\S+\s+(?:SET\s+DATA\s+)?TYPE\b`)
+	modifyColumn    = regexp.MustCompile(`(?is)^\s*(?:MODIFY|CHANGE)\s+(?:COLUMN\s+)?\S+`)
+	notNull         = regexp.MustCompile(`(?i)\bNOT\s+NULL\b`)
+	defaultValue    = regexp.MustCompile(`(?i)\b(?:DEFAULT|GENERATED)\b`)
+)
+
+// constraintKeywords follow ADD or DROP in clauses that don't name a column
+var constraintKeywords = map[string]bool{
+	"constraint": true, "primary": true, "foreign": true, "unique": true, "index": true,
+	"key": true, "check": true, "partition": true, "default": true, "fulltext": true, "spatial": true,
+}
+
+// analyzeSQL checks the added statements of a plain SQL migration
+func analyzeSQL(patch string) []Finding {
+	src := newSource(patch)
+	statements := splitStatements(src.text)
+
+	// Indexes on tables created by the same migration lock nothing anyone is using yet
+	created := map[string]bool{}
+	for _, stmt := range statements {
+		if matches := createTable.FindStringSubmatch(stmt.text); matches != nil {
+			created[tableName(matches[1])] = true
+		}
+	}
+
+	var findings []Finding
+	for _, stmt := range statements {
+		line, added := src.span(stmt.start, stmt.end)
+		if !added {
+			continue
+		}
+		for _, check := range checkStatement(stmt.text, created) {
+			check.Line = line
+			check.Statement = shorten(stmt.text)
+			findings = append(findings, check)
+		}
+	}
+	return findings
+}
+
+// checkStatement returns the findings for one statement, without location
+func checkStatement(text string, created map[string]bool) []Finding {
+	if dropTable.MatchString(text) {
+		return []Finding{{Check: CheckDropTable, Description: "Dropping a table destroys its data and breaks any running code that still reads it"}}
+	}
+
+	if matches := createIndex.FindStringSubmatch(text); matches != nil {
+		if matches[1] != "" || created[tableName(matches[2])] || onlineIndexDDL.MatchString(text) {
+			return nil
+		}
+		return []Finding{{Check: CheckNonConcurrentIndex, Description: "Index is built without CONCURRENTLY and blocks writes to the table until it completes"}}
+	}
+
+	matches := alterTable.FindStringSubmatch(text)
+	if matches == nil {
+		return nil
+	}
+	table := tableName(matches[1])
+
+	var findings []Finding
+	for _, clause := range splitClauses(matches[2]) {
+		switch {
+		case addIndex.MatchString(clause):
+			if !created[table] && !onlineIndexDDL.MatchString(text) {
+				findings = append(findings, Finding{Check: CheckNonConcurrentIndex, Description: "Index is added without an online algorithm and may block writes to the table until it completes"})
+			}
+		case addColumn.MatchString(clause):
+			column := addColumn.FindStringSubmatch(clause)[1]
+			if !constraintKeywords[strings.ToLower(column)] && notNull.MatchString(clause) && !defaultValue.MatchString(clause) {
+				findings = append(findings, Finding{Check: CheckNotNullWithoutDefault, Description: "NOT NULL column added without a default fails on tables with rows and breaks inserts from code that doesn't set it"})
+			}
+		case setNotNull.MatchString(clause):
+			findings = append(findings, Finding{Check: CheckNotNullWithoutDefault, Description: "SET NOT NULL scans the whole table under an exclusive lock and fails if any row is null"})
+		case alterColumnType.MatchString(clause), modifyColumn.MatchString(clause):
+			findings = append(findings, Finding{Check: CheckColumnTypeChange, Description: "Changing a column type can rewrite the table under an exclusive lock and break code reading the old type"})
+		case dropColumn.MatchString(clause):
+			column := dropColumn.FindStringSubmatch(clause)[1]
+			if !constraintKeywords[strings.ToLower(column)] {
+				findings = append(findings, Finding{Check: CheckDropColumn, Description: "Dropping a column destroys its data and breaks running code that still reads it"})
+			}
+		}
+	}
+	return findings
+}
+
+// splitStatements splits SQL on semicolons outside quotes, blanking out comments
+func splitStatements(text string) []statement {
+	var statements []statement
+	clean := []byte(text)
+	start := 0
+	var quote byte
+
+	for i := 0; i < len(clean); i++ {
+		c := clean[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && i+1 < len(clean) && clean[i+1] == '-':
+			for ; i < len(clean) && clean[i] != '\n'; i++ {
+				clean[i] = ' '
+			}
+		case c == '/' && i+1 < len(clean) && clean[i+1] == '*':
+			for ; i < len(clean) && !(clean[i] == '*' && i+1 < len(clean) && clean[i+1] == '/'); i++ {
+				if clean[i] != '\n' {
+					clean[i] = ' '
+				}
+			}
+			if i+1 < len(clean) {
+				clean[i], clean[i+1] = ' ', ' '
+				i++
+			}
+		case c == ';':
+			statements = appendStatement(statements, clean, start, i)
+			start = i + 1
+		}
+	}
+	return appendStatement(statements, clean, start, len(clean))
+}
+
+// appendStatement adds the text between start and end unless it is blank
+func appendStatement(statements []statement, text []byte, start, end int) []statement {
+	if strings.TrimSpace(string(text[start:end])) == "" {
+		return statements
+	}
+	return append(statements, statement{text: string(text[start:end]), start: start, end: end})
+}
+
+// splitClauses splits the actions of an ALTER TABLE on commas outside parentheses
+func splitClauses(actions string) []string {
+	var clauses []string
+	depth, start := 0, 0
+	for i, c := range actions {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				clauses = append(clauses, actions[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(clauses, actions[start:])
+}
+
+// tableName normalizes a table reference for comparison
+func tableName(name string) string {
+	return strings.ToLower(strings.Trim(name, "`\"[]"))
+}
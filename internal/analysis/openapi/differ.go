@@ -0,0 +1,204 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// maxSchemaDepth bounds how deep nested schemas are compared
+const maxSchemaDepth = 10
+
+// differ compares two versions of a spec and accumulates the changes
+type differ struct {
+	base    *spec
+	head    *spec
+	changes []types.APIChange
+}
+
+// add records a change
+func (d *differ) add(kind, location string, breaking bool, format string, args ...any) {
+	d.changes = append(d.changes, types.APIChange{
+		Kind:        kind,
+		Location:    location,
+		Description: fmt.Sprintf(format, args...),
+		Breaking:    breaking,
+	})
+}
+
+// compare walks the operations of both versions in order
+func (d *differ) compare() {
+	baseOperations := map[string]operation{}
+	if d.base.root != nil {
+		baseOperations = d.base.operations()
+	}
+	headOperations := map[string]operation{}
+	if d.head.root != nil {
+		headOperations = d.head.operations()
+	}
+
+	for _, key := range sortedKeys(baseOperations, headOperations) {
+		before, existed := baseOperations[key]
+		after, exists := headOperations[key]
+		switch {
+		case !exists:
+			d.add(KindEndpointRemoved, key, true, "Endpoint removed")
+		case !existed:
+			d.add(KindEndpointAdded, key, false, "Endpoint added")
+		default:
+			d.compareOperation(key, before, after)
+		}
+	}
+}
+
+// compareOperation compares the parameters, request body and successful responses of an operation
+func (d *differ) compareOperation(key string, before, after operation) {
+	baseParameters, headParameters := d.base.parameters(before), d.head.parameters(after)
+	for _, name := range sortedKeys(baseParameters, headParameters) {
+		baseParameter, existed := baseParameters[name]
+		headParameter, exists := headParameters[name]
+		location := key + " parameter " + name
+		switch {
+		case !exists:
+			continue
+		case headParameter["required"] == true && (!existed || baseParameter["required"] != true):
+			d.add(KindRequiredParameterAdded, location, true, "New required %s parameter %v", headParameter["in"], headParameter["name"])
+		case existed:
+			d.compareSchemas(location, "", parameterSchema(baseParameter), parameterSchema(headParameter), true, 0)
+		}
+	}
+
+	baseBody, _ := d.base.requestBody(before)
+	headBody, headRequired := d.head.requestBody(after)
+	switch {
+	case baseBody == nil && headBody != nil && headRequired:
+		d.add(KindRequiredParameterAdded, key+" request body", true, "New required request body")
+	case baseBody != nil && headBody != nil:
+		d.compareSchemas(key+" request body", "", baseBody, headBody, true, 0)
+	}
+
+	baseResponses, headResponses := d.base.responses(before), d.head.responses(after)
+	for _, code := range sortedKeys(baseResponses, headResponses) {
+		baseSchema, existed := baseResponses[code]
+		headSchema, exists := headResponses[code]
+		if existed && exists {
+			d.compareSchemas(key+" response "+code, "", baseSchema, headSchema, false, 0)
+		}
+	}
+}
+
+// compareSchemas compares two schemas at a field path
+// Request schemas break clients when they require more or accept less; response schemas when they return less
+func (d *differ) compareSchemas(location, field string, baseValue, headValue any, request bool, depth int) {
+	base, head := d.base.schema(baseValue), d.head.schema(headValue)
+	if base == nil || head == nil || depth > maxSchemaDepth {
+		return
+	}
+	at := location
+	if field != "" {
+		at = location + " field " + field
+	}
+
+	baseType, headType := typeOf(base), typeOf(head)
+	if baseType != "" && headType != "" && baseType != headType {
+		d.add(KindTypeChanged, at, true, "Type changed from %s to %s", baseType, headType)
+		return
+	}
+
+	if request {
+		if removed := missing(base["enum"], head["enum"]); len(removed) > 0 && head["enum"] != nil {
+			d.add(KindEnumNarrowed, at, true, "Enum values no longer accepted: %s", strings.Join(removed, ", "))
+		}
+	}
+
+	baseProperties, _ := base["properties"].(map[string]any)
+	headProperties, _ := head["properties"].(map[string]any)
+	if request {
+		for _, name := range missing(head["required"], base["required"]) {
+			d.add(KindRequiredParameterAdded, location+" field "+join(field, name), true, "New required request field %s", name)
+		}
+	}
+	for _, name := range sortedKeys(baseProperties, headProperties) {
+		baseProperty, existed := baseProperties[name]
+		headProperty, exists := headProperties[name]
+		switch {
+		case existed && !exists && !request:
+			d.add(KindResponseFieldRemoved, location+" field "+join(field, name), true, "Response field %s removed", name)
+		case existed && exists:
+			d.compareSchemas(location, join(field, name), baseProperty, headProperty, request, depth+1)
+		}
+	}
+
+	if base["items"] != nil && head["items"] != nil {
+		d.compareSchemas(location, field+"[]", base["items"], head["items"], request, depth+1)
+	}
+}
+
+// parameterSchema returns a parameter's schema; Swagger 2 declares the type on the parameter itself
+func parameterSchema(parameter map[string]any) any {
+	if schema, ok := parameter["schema"]; ok {
+		return schema
+	}
+	return parameter
+}
+
+// typeOf formats a schema type, which OpenAPI 3.1 allows to be a list
+func typeOf(schema map[string]any) string {
+	switch value := schema["type"].(type) {
+	case string:
+		return value
+	case []any:
+		names := make([]string, 0, len(value))
+		for _, name := range value {
+			names = append(names, fmt.Sprint(name))
+		}
+		sort.Strings(names)
+		return strings.Join(names, "|")
+	default:
+		return ""
+	}
+}
+
+// missing returns the values of list a that are not in list b
+func missing(a, b any) []string {
+	aValues, _ := a.([]any)
+	bValues, _ := b.([]any)
+	present := map[string]bool{}
+	for _, value := range bValues {
+		present[fmt.Sprint(value)] = true
+	}
+
+	var result []string
+	for _, value := range aValues {
+		if name := fmt.Sprint(value); !present[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// join appends a property name to a field path
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+// sortedKeys returns the union of the keys of two maps in order
+func sortedKeys[V any](a, b map[string]V) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range []map[string]V{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
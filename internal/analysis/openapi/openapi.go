@@ -0,0 +1,57 @@
+package openapi
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// Change kinds
+const (
+	KindEndpointRemoved        = "endpoint_removed"
+	KindEndpointAdded          = "endpoint_added"
+	KindRequiredParameterAdded = "required_parameter_added"
+	KindResponseFieldRemoved   = "response_field_removed"
+	KindTypeChanged            = "type_changed"
+	KindEnumNarrowed           = "enum_narrowed"
+)
+
+// specFile matches OpenAPI and Swagger documents by name, the same way the risk patterns do
+var specFile = regexp.MustCompile(`(?i)(openapi|swagger)[^/]*\.(ya?ml|json)$`)
+
+// IsSpec reports whether a file is named like an OpenAPI or Swagger document
+func IsSpec(filename string) bool {
+	return specFile.MatchString(path.Base(filename))
+}
+
+// Diff compares two versions of a spec, either of which may be empty
+// A new spec has nothing to break, so only its removal or changes to an existing spec are reported
+func Diff(filename, base, head string) ([]types.APIChange, error) {
+	if base == "" {
+		return nil, nil
+	}
+
+	baseSpec, err := parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base spec: %w", err)
+	}
+	headSpec, err := parse(head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse head spec: %w", err)
+	}
+	if baseSpec == nil {
+		return nil, nil
+	}
+	if headSpec == nil {
+		headSpec = &spec{}
+	}
+
+	d := &differ{base: baseSpec, head: headSpec}
+	d.compare()
+	for i := range d.changes {
+		d.changes[i].File = filename
+	}
+	return d.changes, nil
+}
@@ -0,0 +1,258 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+const baseSpec = `openapi: 3.0.3
+info:
+  title: Users
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+      responses:
+        "200":
+          description: Users
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/User'
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+                role:
+                  type: string
+                  enum: [admin, member, guest]
+      responses:
+        "201":
+          description: Created
+  /users/{id}:
+    delete:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "204":
+          description: Deleted
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema:
+        type: integer
+  schemas:
+    User:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - type: object
+          properties:
+            email:
+              type: string
+            age:
+              type: integer
+    Base:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+const headSpec = `openapi: 3.0.3
+info:
+  title: Users
+  version: 2.0.0
+paths:
+  /users:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+        - name: tenant
+          in: header
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: Users
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/User'
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name, email]
+              properties:
+                name:
+                  type: string
+                email:
+                  type: string
+                role:
+                  type: string
+                  enum: [admin, member]
+      responses:
+        "201":
+          description: Created
+  /health:
+    get:
+      responses:
+        "200":
+          description: OK
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      required: true
+      schema:
+        type: integer
+  schemas:
+    User:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - type: object
+          properties:
+            age:
+              type: string
+    Base:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+// summaries returns "kind location" for each change, marking breaking ones
+func summaries(changes []types.APIChange) []string {
+	var result []string
+	for _, change := range changes {
+		summary := change.Kind + " " + change.Location
+		if change.Breaking {
+			summary += " !"
+		}
+		result = append(result, summary)
+	}
+	return result
+}
+
+func TestDiff(t *testing.T) {
+	changes, err := Diff("api/openapi.yaml", baseSpec, headSpec)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	expected := []string{
+		"endpoint_removed DELETE /users/{id} !",
+		"endpoint_added GET /health",
+		"required_parameter_added GET /users parameter header tenant !",
+		"required_parameter_added GET /users parameter query limit !",
+		"type_changed GET /users response 200 field [].age !",
+		"response_field_removed GET /users response 200 field [].email !",
+		"required_parameter_added POST /users request body field email !",
+		"enum_narrowed POST /users request body field role !",
+	}
+	got := summaries(changes)
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+	for _, change := range changes {
+		if change.File != "api/openapi.yaml" || change.Description == "" {
+			t.Errorf("expected file and description on every change, got %+v", change)
+		}
+	}
+}
+
+func TestDiffSwagger2(t *testing.T) {
+	base := `{"swagger": "2.0", "paths": {"/orders": {"post": {
+		"parameters": [
+			{"name": "status", "in": "query", "type": "string", "enum": ["open", "closed"]},
+			{"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Order"}}
+		],
+		"responses": {"200": {"schema": {"$ref": "#/definitions/Order"}}}
+	}}}, "definitions": {"Order": {"type": "object", "properties": {"id": {"type": "integer"}, "total": {"type": "number"}}}}}`
+	head := `{"swagger": "2.0", "paths": {"/orders": {"post": {
+		"parameters": [
+			{"name": "status", "in": "query", "type": "string", "enum": ["open"]},
+			{"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Order"}}
+		],
+		"responses": {"200": {"schema": {"$ref": "#/definitions/Order"}}}
+	}}}, "definitions": {"Order": {"type": "object", "properties": {"id": {"type": "string"}}}}}`
+
+	changes, err := Diff("swagger.json", base, head)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	expected := []string{
+		"enum_narrowed POST /orders parameter query status !",
+		"type_changed POST /orders request body field id !",
+		"type_changed POST /orders response 200 field id !",
+		"response_field_removed POST /orders response 200 field total !",
+	}
+	if got := summaries(changes); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestDiffEdgeCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		head     string
+		expected []string
+	}{
+		{name: "new spec", head: headSpec},
+		{name: "not a spec", base: "name: openapi-generator config\n", head: "name: changed\n"},
+		{
+			name:     "removed spec",
+			base:     "openapi: 3.0.0\npaths:\n  /a:\n    get: {}\n",
+			expected: []string{"endpoint_removed GET /a !"},
+		},
+		{
+			name: "cyclic references",
+			base: "openapi: 3.0.0\npaths:\n  /a:\n    get:\n      responses:\n        '200':\n          content:\n            application/json:\n              schema:\n                $ref: '#/components/schemas/Node'\ncomponents:\n  schemas:\n    Node:\n      type: object\n      properties:\n        child:\n          $ref: '#/components/schemas/Node'\n",
+			head: "openapi: 3.0.0\npaths:\n  /a:\n    get:\n      responses:\n        '200':\n          content:\n            application/json:\n              schema:\n                $ref: '#/components/schemas/Node'\ncomponents:\n  schemas:\n    Node:\n      type: object\n      properties:\n        child:\n          $ref: '#/components/schemas/Node'\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := Diff("openapi.yaml", tt.base, tt.head)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if got := summaries(changes); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Diff() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := Diff("openapi.yaml", baseSpec, "openapi: [unclosed"); err == nil {
+		t.Error("expected an error for an unparseable spec")
+	}
+}
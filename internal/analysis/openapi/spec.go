@@ -0,0 +1,206 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// methods are the operations a path item can hold
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// maxRefDepth bounds $ref chains, which may be cyclic
+const maxRefDepth = 10
+
+// spec is a parsed OpenAPI 3 or Swagger 2 document
+type spec struct {
+	root map[string]any
+}
+
+// operation is one method on one path, with the parameters it inherits from the path
+type operation struct {
+	pathItem map[string]any
+	body     map[string]any
+}
+
+// parse decodes a YAML or JSON spec; documents without an openapi or swagger version yield nil
+func parse(content string) (*spec, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, nil
+	}
+
+	var root map[string]any
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		return nil, err
+	}
+	if root["openapi"] == nil && root["swagger"] == nil {
+		return nil, nil
+	}
+	return &spec{root: root}, nil
+}
+
+// operations indexes every operation as "METHOD /path"
+func (s *spec) operations() map[string]operation {
+	operations := map[string]operation{}
+	paths, _ := s.resolve(s.root["paths"]).(map[string]any)
+	for path, item := range paths {
+		pathItem, _ := s.resolve(item).(map[string]any)
+		for _, method := range methods {
+			if body, ok := s.resolve(pathItem[method]).(map[string]any); ok {
+				operations[strings.ToUpper(method)+" "+path] = operation{pathItem: pathItem, body: body}
+			}
+		}
+	}
+	return operations
+}
+
+// parameters returns the non-body parameters of an operation keyed by "in name"
+// Operation parameters override path parameters with the same location and name
+func (s *spec) parameters(op operation) map[string]map[string]any {
+	parameters := map[string]map[string]any{}
+	for _, list := range []any{op.pathItem["parameters"], op.body["parameters"]} {
+		items, _ := list.([]any)
+		for _, item := range items {
+			parameter, ok := s.resolve(item).(map[string]any)
+			if !ok || parameter["in"] == "body" {
+				continue
+			}
+			parameters[fmt.Sprintf("%v %v", parameter["in"], parameter["name"])] = parameter
+		}
+	}
+	return parameters
+}
+
+// requestBody returns the request body schema of an operation and whether the body is required
+// OpenAPI 3 uses requestBody; Swagger 2 uses a parameter in the body
+func (s *spec) requestBody(op operation) (any, bool) {
+	if requestBody, ok := s.resolve(op.body["requestBody"]).(map[string]any); ok {
+		return s.mediaSchema(requestBody["content"]), requestBody["required"] == true
+	}
+
+	items, _ := op.body["parameters"].([]any)
+	for _, item := range items {
+		if parameter, ok := s.resolve(item).(map[string]any); ok && parameter["in"] == "body" {
+			return parameter["schema"], parameter["required"] == true
+		}
+	}
+	return nil, false
+}
+
+// responses returns the schema of each successful response of an operation, keyed by status code
+func (s *spec) responses(op operation) map[string]any {
+	schemas := map[string]any{}
+	responses, _ := s.resolve(op.body["responses"]).(map[string]any)
+	for code, item := range responses {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		response, _ := s.resolve(item).(map[string]any)
+		if schema, ok := response["schema"]; ok {
+			schemas[code] = schema
+		} else if schema := s.mediaSchema(response["content"]); schema != nil {
+			schemas[code] = schema
+		}
+	}
+	return schemas
+}
+
+// mediaSchema returns the JSON schema of an OpenAPI 3 content map, or the first media type's schema
+func (s *spec) mediaSchema(content any) any {
+	media, _ := content.(map[string]any)
+	if len(media) == 0 {
+		return nil
+	}
+	if json, ok := media["application/json"].(map[string]any); ok {
+		return json["schema"]
+	}
+
+	types := make([]string, 0, len(media))
+	for mediaType := range media {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+	first, _ := media[types[0]].(map[string]any)
+	return first["schema"]
+}
+
+// resolve follows local $ref pointers such as "#/components/schemas/User"
+// External references are left unresolved
+func (s *spec) resolve(value any) any {
+	for range maxRefDepth {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+		ref, ok := object["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return value
+		}
+		value = s.pointer(ref[2:])
+	}
+	return value
+}
+
+// pointer looks up a JSON pointer relative to the document root
+func (s *spec) pointer(pointer string) any {
+	var value any = s.root
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[token]
+	}
+	return value
+}
+
+// schema resolves a schema and merges its allOf parts, so properties declared through composition are compared too
+func (s *spec) schema(value any) map[string]any {
+	return s.mergeAllOf(value, 0)
+}
+
+// mergeAllOf resolves a schema and folds the properties and required fields of its allOf parts into it
+func (s *spec) mergeAllOf(value any, depth int) map[string]any {
+	schema, ok := s.resolve(value).(map[string]any)
+	if !ok {
+		return nil
+	}
+	parts, ok := schema["allOf"].([]any)
+	if !ok || depth > maxRefDepth {
+		return schema
+	}
+
+	merged := map[string]any{}
+	properties := map[string]any{}
+	var required []any
+	merge := func(part map[string]any) {
+		for key, value := range part {
+			switch key {
+			case "properties":
+				if props, ok := value.(map[string]any); ok {
+					for name, prop := range props {
+						properties[name] = prop
+					}
+				}
+			case "required":
+				if names, ok := value.([]any); ok {
+					required = append(required, names...)
+				}
+			case "allOf":
+			default:
+				merged[key] = value
+			}
+		}
+	}
+	for _, part := range parts {
+		merge(s.mergeAllOf(part, depth+1))
+	}
+	merge(schema)
+
+	merged["properties"] = properties
+	merged["required"] = required
+	return merged
+}
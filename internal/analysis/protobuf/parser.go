@@ -0,0 +1,355 @@
+package protobuf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// schema is the part of a .proto file that matters for wire compatibility
+type schema struct {
+	pkg      string
+	messages map[string]*message // Keyed by nested name, e.g. "Order.Item"
+	enums    map[string]*enum
+	rpcs     map[string]string // "Service.Method" -> "(Request) returns (Response)"
+}
+
+// message is a message type with its fields keyed by number
+type message struct {
+	fields   map[int]field
+	reserved reserved
+}
+
+// field is a message field; label is "repeated", "optional", "required" or ""
+type field struct {
+	name  string
+	typ   string
+	label string
+}
+
+// enum is an enum type with its values keyed by number
+type enum struct {
+	values   map[int]string
+	reserved reserved
+}
+
+// reserved holds the numbers and names a message or enum may not reuse
+type reserved struct {
+	ranges [][2]int
+	names  map[string]bool
+}
+
+// hasNumber reports whether a number is reserved
+func (r reserved) hasNumber(number int) bool {
+	for _, span := range r.ranges {
+		if number >= span[0] && number <= span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// parser walks the tokens of a .proto file
+type parser struct {
+	tokens []string
+	pos    int
+	schema *schema
+}
+
+// parse reads the package, messages, enums and services of a .proto file
+// Options, imports and extensions are skipped
+func parse(content string) (*schema, error) {
+	tokens, err := tokenize(content)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, schema: &schema{
+		messages: map[string]*message{},
+		enums:    map[string]*enum{},
+		rpcs:     map[string]string{},
+	}}
+	for !p.done() {
+		switch p.peek() {
+		case "package":
+			p.next()
+			p.schema.pkg = p.next()
+			p.skipStatement()
+		case "message":
+			p.next()
+			p.parseMessage("")
+		case "enum":
+			p.next()
+			p.parseEnum("")
+		case "service":
+			p.next()
+			p.parseService()
+		default:
+			p.skipStatement()
+		}
+	}
+	return p.schema, nil
+}
+
+// parseMessage reads a message body, including nested messages, enums and oneofs
+func (p *parser) parseMessage(parent string) {
+	name := qualify(parent, p.next())
+	msg := &message{fields: map[int]field{}, reserved: reserved{names: map[string]bool{}}}
+	p.schema.messages[name] = msg
+	if p.next() != "{" {
+		return
+	}
+	p.parseFields(name, msg)
+}
+
+// parseFields reads fields until the closing brace of a message or oneof
+func (p *parser) parseFields(name string, msg *message) {
+	for !p.done() {
+		switch p.peek() {
+		case "}":
+			p.next()
+			return
+		case "message":
+			p.next()
+			p.parseMessage(name)
+		case "enum":
+			p.next()
+			p.parseEnum(name)
+		case "oneof":
+			p.next()
+			p.next() // oneof name
+			if p.next() == "{" {
+				p.parseFields(name, msg)
+			}
+		case "reserved":
+			p.next()
+			p.parseReserved(&msg.reserved)
+		case "option", "extensions", "extend", "group", ";":
+			p.skipStatement()
+		default:
+			p.parseField(msg)
+		}
+	}
+}
+
+// parseField reads "[label] type name = number [options];" or "map<K, V> name = number;"
+func (p *parser) parseField(msg *message) {
+	var f field
+	if label := p.peek(); label == "repeated" || label == "optional" || label == "required" {
+		f.label = p.next()
+	}
+
+	f.typ = p.next()
+	if f.typ == "map" && p.peek() == "<" {
+		var b strings.Builder
+		b.WriteString("map")
+		for !p.done() {
+			token := p.next()
+			b.WriteString(token)
+			if token == ">" {
+				break
+			}
+			if token == "," {
+				b.WriteString(" ")
+			}
+		}
+		f.typ = b.String()
+	}
+
+	f.name = p.next()
+	if p.peek() != "=" {
+		p.skipStatement()
+		return
+	}
+	p.next()
+	number, err := strconv.Atoi(p.next())
+	p.skipStatement()
+	if err == nil {
+		msg.fields[number] = f
+	}
+}
+
+// parseEnum reads an enum body
+func (p *parser) parseEnum(parent string) {
+	name := qualify(parent, p.next())
+	e := &enum{values: map[int]string{}, reserved: reserved{names: map[string]bool{}}}
+	p.schema.enums[name] = e
+	if p.next() != "{" {
+		return
+	}
+
+	for !p.done() {
+		switch p.peek() {
+		case "}":
+			p.next()
+			return
+		case "reserved":
+			p.next()
+			p.parseReserved(&e.reserved)
+		case "option", ";":
+			p.skipStatement()
+		default:
+			value := p.next()
+			if p.peek() != "=" {
+				p.skipStatement()
+				continue
+			}
+			p.next()
+			number, err := strconv.Atoi(p.next())
+			p.skipStatement()
+			if err == nil {
+				e.values[number] = value
+			}
+		}
+	}
+}
+
+// parseReserved reads "reserved 2, 15, 9 to 11, 40 to max;" or "reserved "foo", "bar";"
+func (p *parser) parseReserved(r *reserved) {
+	for !p.done() {
+		token := p.next()
+		switch {
+		case token == ";":
+			return
+		case token == ",":
+		case strings.HasPrefix(token, `"`) || strings.HasPrefix(token, "'"):
+			r.names[strings.Trim(token, `"'`)] = true
+		default:
+			start, err := strconv.Atoi(token)
+			if err != nil {
+				continue
+			}
+			end := start
+			if p.peek() == "to" {
+				p.next()
+				if bound := p.next(); bound == "max" {
+					end = 536870911
+				} else if n, err := strconv.Atoi(bound); err == nil {
+					end = n
+				}
+			}
+			r.ranges = append(r.ranges, [2]int{start, end})
+		}
+	}
+}
+
+// parseService reads the rpc signatures of a service
+func (p *parser) parseService() {
+	service := p.next()
+	if p.next() != "{" {
+		return
+	}
+
+	for !p.done() {
+		switch p.peek() {
+		case "}":
+			p.next()
+			return
+		case "rpc":
+			p.next()
+			method := p.next()
+			var signature []string
+			for !p.done() && p.peek() != ";" && p.peek() != "{" {
+				signature = append(signature, p.next())
+			}
+			p.schema.rpcs[service+"."+method] = strings.NewReplacer("( ", "(", " )", ")").Replace(strings.Join(signature, " "))
+			p.skipStatement()
+		default:
+			p.skipStatement()
+		}
+	}
+}
+
+// skipStatement skips to the end of the current statement or block
+func (p *parser) skipStatement() {
+	depth := 0
+	for !p.done() {
+		switch p.next() {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth <= 0 {
+				return
+			}
+		case ";":
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+// qualify joins a nested type name to its parent
+func qualify(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// tokenize splits a .proto file into identifiers, numbers, strings and symbols, dropping comments
+func tokenize(content string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(content[i:], "//"):
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '"' || c == '\'':
+			start := i
+			for i++; i < len(content) && content[i] != c; i++ {
+				if content[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(content) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			tokens = append(tokens, content[start:i])
+		case isWordByte(c):
+			start := i
+			for i < len(content) && isWordByte(content[i]) {
+				i++
+			}
+			tokens = append(tokens, content[start:i])
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// isWordByte reports whether a byte belongs to an identifier, qualified name or number
+func isWordByte(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || c == '+' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
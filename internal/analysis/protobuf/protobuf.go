@@ -0,0 +1,161 @@
+package protobuf
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// Change kinds
+const (
+	KindPackageChanged              = "package_changed"
+	KindMessageRemoved              = "message_removed"
+	KindFieldRemovedWithoutReserved = "field_removed_without_reserved"
+	KindFieldNumberReused           = "field_number_reused"
+	KindFieldTypeChanged            = "field_type_changed"
+	KindEnumValueRemoved            = "enum_value_removed"
+	KindRPCRemoved                  = "rpc_removed"
+	KindRPCSignatureChanged         = "rpc_signature_changed"
+)
+
+// IsSchema reports whether a file is a protobuf definition
+func IsSchema(filename string) bool {
+	return path.Ext(filename) == ".proto"
+}
+
+// Diff compares two versions of a .proto file for wire compatibility, either of which may be empty
+// A new file has nothing to break, so only its removal or changes to an existing file are reported
+func Diff(filename, base, head string) ([]types.APIChange, error) {
+	if base == "" {
+		return nil, nil
+	}
+
+	before, err := parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base schema: %w", err)
+	}
+	after, err := parse(head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse head schema: %w", err)
+	}
+
+	var changes []types.APIChange
+	add := func(kind, location, format string, args ...any) {
+		changes = append(changes, types.APIChange{
+			File:        filename,
+			Kind:        kind,
+			Location:    location,
+			Description: fmt.Sprintf(format, args...),
+			Breaking:    true,
+		})
+	}
+
+	if head != "" && before.pkg != after.pkg {
+		add(KindPackageChanged, "package", "Package renamed from %s to %s, changing every fully qualified type and service name", orNone(before.pkg), orNone(after.pkg))
+	}
+
+	for _, name := range sortedKeys(before.messages) {
+		beforeMessage := before.messages[name]
+		afterMessage, exists := after.messages[name]
+		if !exists {
+			add(KindMessageRemoved, name, "Message removed")
+			continue
+		}
+
+		for _, number := range sortedNumbers(beforeMessage.fields, afterMessage.fields) {
+			beforeField, existed := beforeMessage.fields[number]
+			afterField, exists := afterMessage.fields[number]
+			location := fmt.Sprintf("%s field %d", name, number)
+			switch {
+			case !existed && beforeMessage.reserved.hasNumber(number):
+				add(KindFieldNumberReused, location, "Field %s reuses reserved number %d", afterField.name, number)
+			case !existed:
+				continue
+			case !exists && !afterMessage.reserved.hasNumber(number):
+				add(KindFieldRemovedWithoutReserved, location, "Field %s removed without reserving number %d", beforeField.name, number)
+			case !exists:
+				continue
+			case beforeField.typ != afterField.typ || isRepeated(beforeField) != isRepeated(afterField):
+				if beforeField.name != afterField.name {
+					add(KindFieldNumberReused, location, "Number %d reused: field %s %s replaced by %s %s", number, beforeField.name, describe(beforeField), afterField.name, describe(afterField))
+				} else {
+					add(KindFieldTypeChanged, location, "Field %s type changed from %s to %s", afterField.name, describe(beforeField), describe(afterField))
+				}
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(before.enums) {
+		beforeEnum := before.enums[name]
+		afterEnum, exists := after.enums[name]
+		if !exists {
+			continue
+		}
+		for _, number := range sortedNumbers(beforeEnum.values, afterEnum.values) {
+			value, existed := beforeEnum.values[number]
+			if _, exists := afterEnum.values[number]; existed && !exists && !afterEnum.reserved.hasNumber(number) {
+				add(KindEnumValueRemoved, fmt.Sprintf("%s value %d", name, number), "Enum value %s removed without reserving number %d", value, number)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(before.rpcs) {
+		signature, exists := after.rpcs[name]
+		switch {
+		case !exists:
+			add(KindRPCRemoved, name, "RPC removed")
+		case signature != before.rpcs[name]:
+			add(KindRPCSignatureChanged, name, "RPC signature changed from %s to %s", before.rpcs[name], signature)
+		}
+	}
+	return changes, nil
+}
+
+// isRepeated reports whether a field holds a list; optional and required don't change the wire format
+func isRepeated(f field) bool {
+	return f.label == "repeated"
+}
+
+// describe formats a field's type with its repeated label
+func describe(f field) string {
+	if isRepeated(f) {
+		return "repeated " + f.typ
+	}
+	return f.typ
+}
+
+// orNone names an empty package
+func orNone(pkg string) string {
+	if pkg == "" {
+		return "(none)"
+	}
+	return pkg
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedNumbers returns the union of the keys of two number-keyed maps in order
+func sortedNumbers[V any](a, b map[int]V) []int {
+	seen := map[int]bool{}
+	var numbers []int
+	for _, m := range []map[int]V{a, b} {
+		for number := range m {
+			if !seen[number] {
+				seen[number] = true
+				numbers = append(numbers, number)
+			}
+		}
+	}
+	sort.Ints(numbers)
+	return numbers
+}
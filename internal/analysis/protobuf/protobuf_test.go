@@ -0,0 +1,199 @@
+package protobuf
+
+import (
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+const baseProto = `syntax = "proto3";
+
+package shop.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "example.com/shop/v1;shopv1";
+
+/* Orders placed by customers */
+message Order {
+  reserved 9, 20 to 25;
+  reserved "legacy";
+
+  string id = 1;
+  int32 quantity = 2;
+  repeated string tags = 3;
+  string note = 4; // free text
+  map<string, string> labels = 5;
+  oneof payment {
+    string card = 6;
+    string voucher = 7;
+  }
+  string coupon = 8 [deprecated = true];
+
+  message Item {
+    string sku = 1;
+    int64 price = 2;
+  }
+
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_OPEN = 1;
+    STATUS_CLOSED = 2;
+  }
+}
+
+message Legacy {
+  string id = 1;
+}
+
+enum Channel {
+  CHANNEL_UNSPECIFIED = 0;
+  CHANNEL_WEB = 1;
+  CHANNEL_STORE = 2;
+}
+
+service OrderService {
+  rpc GetOrder (GetOrderRequest) returns (Order);
+  rpc WatchOrders (WatchRequest) returns (stream Order) {
+    option (google.api.http) = { get: "/v1/orders:watch" };
+  }
+  rpc DeleteOrder (DeleteOrderRequest) returns (Empty);
+}
+`
+
+const headProto = `syntax = "proto3";
+
+package shop.v2;
+
+message Order {
+  reserved 9, 20 to 25;
+  reserved "legacy", "note";
+  reserved 4;
+
+  string id = 1;
+  int64 quantity = 2;
+  string tags = 3;
+  map<string, string> labels = 5;
+  oneof payment {
+    string card = 6;
+  }
+  int32 discount = 8;
+  string legacy_ref = 21;
+
+  message Item {
+    string sku = 1;
+    int64 price = 2;
+  }
+
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_OPEN = 1;
+    reserved 2;
+  }
+}
+
+enum Channel {
+  CHANNEL_UNSPECIFIED = 0;
+  CHANNEL_WEB = 1;
+}
+
+service OrderService {
+  rpc GetOrder (GetOrderRequest) returns (Order);
+  rpc WatchOrders (WatchRequest) returns (Order);
+}
+`
+
+// summaries returns "kind location" for each change
+func summaries(changes []types.APIChange) []string {
+	var result []string
+	for _, change := range changes {
+		result = append(result, change.Kind+" "+change.Location)
+	}
+	return result
+}
+
+func TestDiff(t *testing.T) {
+	changes, err := Diff("proto/shop.proto", baseProto, headProto)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	expected := []string{
+		"package_changed package",
+		"message_removed Legacy",
+		"field_type_changed Order field 2",
+		"field_type_changed Order field 3",
+		"field_removed_without_reserved Order field 7",
+		"field_number_reused Order field 8",
+		"field_number_reused Order field 21",
+		"enum_value_removed Channel value 2",
+		"rpc_removed OrderService.DeleteOrder",
+		"rpc_signature_changed OrderService.WatchOrders",
+	}
+	if got := summaries(changes); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+
+	descriptions := map[string]string{}
+	for _, change := range changes {
+		if !change.Breaking || change.File != "proto/shop.proto" {
+			t.Errorf("expected a breaking change with its file, got %+v", change)
+		}
+		descriptions[change.Location] = change.Description
+	}
+	for location, want := range map[string]string{
+		"package":                  "Package renamed from shop.v1 to shop.v2",
+		"Order field 3":            "Field tags type changed from repeated string to string",
+		"Order field 8":            "Number 8 reused: field coupon string replaced by discount int32",
+		"Order field 21":           "Field legacy_ref reuses reserved number 21",
+		"OrderService.WatchOrders": "from (WatchRequest) returns (stream Order) to (WatchRequest) returns (Order)",
+	} {
+		if !strings.Contains(descriptions[location], want) {
+			t.Errorf("description of %s = %q, want it to contain %q", location, descriptions[location], want)
+		}
+	}
+}
+
+func TestDiffEdgeCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		head     string
+		expected []string
+	}{
+		{name: "new file", head: headProto},
+		{name: "unchanged", base: baseProto, head: baseProto},
+		{
+			name:     "removed file",
+			base:     "syntax = \"proto3\";\npackage a;\nmessage A { string id = 1; }\nservice S { rpc Get (A) returns (A); }\n",
+			expected: []string{"message_removed A", "rpc_removed S.Get"},
+		},
+		{
+			name: "renamed field keeps its number and type",
+			base: "message A { string name = 1; }",
+			head: "message A { string display_name = 1; }",
+		},
+		{
+			name: "optional label is wire compatible",
+			base: "message A { string name = 1; }",
+			head: "message A { optional string name = 1; }",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := Diff("a.proto", tt.base, tt.head)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if got := summaries(changes); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Diff() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := Diff("a.proto", baseProto, "message A { /* unterminated"); err == nil {
+		t.Error("expected an error for an unterminated comment")
+	}
+}
@@ -0,0 +1,338 @@
+package rules
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"release-confidence-score/internal/git/shared"
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/truncation"
+	"release-confidence-score/internal/repoconfig"
+)
+
+// Finding severities, matching the concern severities of the analysis schema
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+)
+
+// Rule names, as reported in findings and referenced by policies
+const (
+	RuleUntestedCommits    = "untested_commits"
+	RuleMigrations         = "migrations"
+	RuleCriticalFiles      = "critical_files"
+	RuleDiffSize           = "diff_size"
+	RuleDeletedFiles       = "deleted_files"
+	RuleDependencyChanges  = "dependency_changes"
+	RuleBreakingAPIChanges = "breaking_api_changes"
+)
+
+// Names lists every rule name
+var Names = []string{
+	RuleUntestedCommits,
+	RuleMigrations,
+	RuleCriticalFiles,
+	RuleDiffSize,
+	RuleDeletedFiles,
+	RuleDependencyChanges,
+	RuleBreakingAPIChanges,
+}
+
+// Diff size thresholds (changed lines across all comparisons)
+const (
+	largeDiffLines     = 1000
+	veryLargeDiffLines = 5000
+)
+
+// migrationPatterns identify database migration files across common frameworks
+var migrationPatterns = []string{
+	"**/migrations/**",
+	"**/migration/**",
+	"**/migrate/**",
+	"**/alembic/versions/**",
+	"**/db/changelog/**",
+	"V[0-9]*__*.sql",
+	"*.up.sql",
+	"*.down.sql",
+}
+
+// dependencyManifests are the file names of dependency manifests and lockfiles
+// Python requirements files (requirements*.txt) are matched separately
+var dependencyManifests = []string{
+	"go.mod", "go.sum",
+	"package.json", "package-lock.json", "yarn.lock", "pnpm-lock.yaml",
+	"poetry.lock", "pyproject.toml", "Pipfile", "Pipfile.lock",
+	"pom.xml", "build.gradle", "build.gradle.kts",
+	"Gemfile", "Gemfile.lock",
+	"Cargo.toml", "Cargo.lock",
+}
+
+// Finding is a single deterministic signal about the release
+type Finding struct {
+	Rule        string   `json:"rule"`
+	Severity    string   `json:"severity"`
+	Description string   `json:"description"`
+	Penalty     int      `json:"penalty"`              // Points subtracted from the rule score
+	References  []string `json:"references,omitempty"` // Files or commits the finding is about
+}
+
+// Result is the outcome of evaluating every rule against a release
+type Result struct {
+	Score    int       `json:"score"` // 100 minus the penalties of all findings, floored at 0
+	Findings []Finding `json:"findings"`
+}
+
+// rule inspects the release and returns a finding, or nil when the rule doesn't apply
+type rule func(comparisons []*types.Comparison) *Finding
+
+// rules are evaluated in order; findings are reported in the same order
+var rules = []rule{
+	untestedCommits,
+	migrationFiles,
+	criticalFiles,
+	diffSize,
+	deletedFiles,
+	dependencyChanges,
+	breakingAPIChanges,
+}
+
+// Evaluate computes the rule score and findings for a release without calling an LLM
+func Evaluate(comparisons []*types.Comparison) *Result {
+	result := &Result{Score: 100, Findings: []Finding{}}
+
+	for _, evaluate := range rules {
+		finding := evaluate(comparisons)
+		if finding == nil {
+			continue
+		}
+		result.Findings = append(result.Findings, *finding)
+		result.Score -= finding.Penalty
+	}
+	result.Score = max(result.Score, 0)
+
+	slog.Debug("Evaluated release rules", "score", result.Score, "findings", len(result.Findings))
+	return result
+}
+
+// Evidence formats the result as markdown for the user prompt
+func (r *Result) Evidence() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Rule-based score:** %d/100\n", r.Score)
+	if len(r.Findings) == 0 {
+		b.WriteString("\nNo rule findings.\n")
+		return b.String()
+	}
+
+	b.WriteString("\n")
+	for _, finding := range r.Findings {
+		fmt.Fprintf(&b, "- [%s] %s (-%d)", finding.Severity, finding.Description, finding.Penalty)
+		if len(finding.References) > 0 {
+			fmt.Fprintf(&b, ": %s", strings.Join(finding.References, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// untestedCommits flags commits whose PR/MR is still waiting for QE testing
+func untestedCommits(comparisons []*types.Comparison) *Finding {
+	var references []string
+	for _, comparison := range comparisons {
+		for _, commit := range comparison.Commits {
+			if commit.QETestingLabel == shared.LabelNeedsQETesting {
+				references = append(references, commitReference(commit))
+			}
+		}
+	}
+	if len(references) == 0 {
+		return nil
+	}
+
+	return &Finding{
+		Rule:        RuleUntestedCommits,
+		Severity:    SeverityHigh,
+		Description: fmt.Sprintf("%d %s labeled %s", len(references), plural(len(references), "commit is", "commits are"), shared.LabelNeedsQETesting),
+		Penalty:     min(10*len(references), 30),
+		References:  references,
+	}
+}
+
+// migrationFiles flags database migrations, which are hard to roll back
+func migrationFiles(comparisons []*types.Comparison) *Finding {
+	references := matchingFiles(comparisons, IsMigration)
+	if len(references) == 0 {
+		return nil
+	}
+
+	return &Finding{
+		Rule:        RuleMigrations,
+		Severity:    SeverityHigh,
+		Description: fmt.Sprintf("%d database migration %s changed", len(references), plural(len(references), "file", "files")),
+		Penalty:     min(15+5*(len(references)-1), 25),
+		References:  references,
+	}
+}
+
+// criticalFiles flags files classified as critical risk, other than migrations which have their own rule
+func criticalFiles(comparisons []*types.Comparison) *Finding {
+	var references []string
+	for _, comparison := range comparisons {
+		for _, file := range comparison.Files {
+			if !IsMigration(file.Filename) && truncation.ClassifyFile(file.Filename, comparison.RepoConfig) == truncation.RiskCritical {
+				references = append(references, file.Filename)
+			}
+		}
+	}
+	if len(references) == 0 {
+		return nil
+	}
+
+	return &Finding{
+		Rule:        RuleCriticalFiles,
+		Severity:    SeverityMedium,
+		Description: fmt.Sprintf("%d critical-risk %s changed (security, API contracts or configured critical paths)", len(references), plural(len(references), "file", "files")),
+		Penalty:     min(5*len(references), 20),
+		References:  references,
+	}
+}
+
+// diffSize flags large releases, which are harder to review and to roll back
+func diffSize(comparisons []*types.Comparison) *Finding {
+	var lines, files int
+	for _, comparison := range comparisons {
+		lines += comparison.Stats.TotalAdditions + comparison.Stats.TotalDeletions
+		files += len(comparison.Files)
+	}
+
+	description := fmt.Sprintf("Large release: %d changed lines across %d files", lines, files)
+	switch {
+	case lines >= veryLargeDiffLines:
+		return &Finding{Rule: RuleDiffSize, Severity: SeverityHigh, Description: description, Penalty: 20}
+	case lines >= largeDiffLines:
+		return &Finding{Rule: RuleDiffSize, Severity: SeverityMedium, Description: description, Penalty: 10}
+	default:
+		return nil
+	}
+}
+
+// deletedFiles flags removed files, which may still be referenced by other code or services
+func deletedFiles(comparisons []*types.Comparison) *Finding {
+	references := matchingStatus(comparisons, "removed")
+	if len(references) == 0 {
+		return nil
+	}
+
+	return &Finding{
+		Rule:        RuleDeletedFiles,
+		Severity:    SeverityLow,
+		Description: fmt.Sprintf("%d %s deleted", len(references), plural(len(references), "file", "files")),
+		Penalty:     min(2*len(references), 10),
+		References:  references,
+	}
+}
+
+// dependencyChanges flags changed dependency manifests and lockfiles
+func dependencyChanges(comparisons []*types.Comparison) *Finding {
+	references := matchingFiles(comparisons, isDependencyManifest)
+	if len(references) == 0 {
+		return nil
+	}
+
+	return &Finding{
+		Rule:        RuleDependencyChanges,
+		Severity:    SeverityMedium,
+		Description: fmt.Sprintf("%d dependency %s changed", len(references), plural(len(references), "manifest", "manifests")),
+		Penalty:     min(5*len(references), 15),
+		References:  references,
+	}
+}
+
+// breakingAPIChanges flags API contract changes that may break existing clients
+func breakingAPIChanges(comparisons []*types.Comparison) *Finding {
+	var references []string
+	for _, comparison := range comparisons {
+		for _, file := range comparison.Files {
+			for _, change := range file.APIChanges {
+				if change.Breaking {
+					references = append(references, fmt.Sprintf("%s: %s", change.Location, change.Description))
+				}
+			}
+		}
+	}
+	if len(references) == 0 {
+		return nil
+	}
+
+	return &Finding{
+		Rule:        RuleBreakingAPIChanges,
+		Severity:    SeverityHigh,
+		Description: fmt.Sprintf("%d breaking API contract %s", len(references), plural(len(references), "change", "changes")),
+		Penalty:     min(15+5*(len(references)-1), 25),
+		References:  references,
+	}
+}
+
+// IsMigration reports whether a file is a database migration
+func IsMigration(filename string) bool {
+	return repoconfig.MatchAny(migrationPatterns, filename)
+}
+
+// isDependencyManifest reports whether a file declares or locks dependencies
+func isDependencyManifest(filename string) bool {
+	base := path.Base(filename)
+	if strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt") {
+		return true
+	}
+	return slices.Contains(dependencyManifests, base)
+}
+
+// matchingFiles returns the changed files accepted by match
+func matchingFiles(comparisons []*types.Comparison, match func(filename string) bool) []string {
+	var filenames []string
+	for _, comparison := range comparisons {
+		for _, file := range comparison.Files {
+			if match(file.Filename) {
+				filenames = append(filenames, file.Filename)
+			}
+		}
+	}
+	return filenames
+}
+
+// matchingStatus returns the changed files with the given status
+func matchingStatus(comparisons []*types.Comparison, status string) []string {
+	var filenames []string
+	for _, comparison := range comparisons {
+		for _, file := range comparison.Files {
+			if file.Status == status {
+				filenames = append(filenames, file.Filename)
+			}
+		}
+	}
+	return filenames
+}
+
+// commitReference identifies a commit by short SHA and PR/MR number
+func commitReference(commit types.Commit) string {
+	reference := commit.ShortSHA
+	if commit.PRNumber > 0 {
+		reference = fmt.Sprintf("%s (#%d)", commit.ShortSHA, commit.PRNumber)
+	}
+	if commit.Message != "" {
+		reference = fmt.Sprintf("%s %q", reference, commit.Message)
+	}
+	return reference
+}
+
+// plural returns singular for a count of one and plural otherwise
+func plural(count int, singular, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
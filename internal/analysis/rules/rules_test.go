@@ -0,0 +1,230 @@
+package rules
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/shared"
+	"release-confidence-score/internal/git/types"
+)
+
+func file(filename, status string) types.FileChange {
+	return types.FileChange{Filename: filename, Status: status}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name          string
+		comparison    *types.Comparison
+		expectedScore int
+		expectedRules []string
+	}{
+		{
+			name: "clean release",
+			comparison: &types.Comparison{
+				Commits: []types.Commit{{ShortSHA: "abc1234", QETestingLabel: shared.LabelQETested}},
+				Files:   []types.FileChange{file("internal/service/handler.go", "modified")},
+				Stats:   types.ComparisonStats{TotalAdditions: 20, TotalDeletions: 5},
+			},
+			expectedScore: 100,
+			expectedRules: []string{},
+		},
+		{
+			name: "untested commits are capped",
+			comparison: &types.Comparison{
+				Commits: []types.Commit{
+					{ShortSHA: "a", QETestingLabel: shared.LabelNeedsQETesting},
+					{ShortSHA: "b", QETestingLabel: shared.LabelNeedsQETesting},
+					{ShortSHA: "c", QETestingLabel: shared.LabelNeedsQETesting},
+					{ShortSHA: "d", QETestingLabel: shared.LabelNeedsQETesting},
+				},
+			},
+			expectedScore: 70,
+			expectedRules: []string{"untested_commits"},
+		},
+		{
+			name: "migrations are not double counted as critical files",
+			comparison: &types.Comparison{
+				Files: []types.FileChange{
+					file("db/migrations/001_create_users.sql", "added"),
+					file("alembic/versions/abc_add_index.py", "added"),
+				},
+			},
+			expectedScore: 80,
+			expectedRules: []string{"migrations"},
+		},
+		{
+			name: "critical files",
+			comparison: &types.Comparison{
+				Files: []types.FileChange{file("config/auth.go", "modified"), file("openapi.yaml", "modified")},
+			},
+			expectedScore: 90,
+			expectedRules: []string{"critical_files"},
+		},
+		{
+			name: "large diff",
+			comparison: &types.Comparison{
+				Stats: types.ComparisonStats{TotalAdditions: 900, TotalDeletions: 200},
+			},
+			expectedScore: 90,
+			expectedRules: []string{"diff_size"},
+		},
+		{
+			name: "very large diff",
+			comparison: &types.Comparison{
+				Stats: types.ComparisonStats{TotalAdditions: 4000, TotalDeletions: 1000},
+			},
+			expectedScore: 80,
+			expectedRules: []string{"diff_size"},
+		},
+		{
+			name: "deleted files",
+			comparison: &types.Comparison{
+				Files: []types.FileChange{file("internal/legacy/a.go", "removed"), file("internal/legacy/b.go", "removed")},
+			},
+			expectedScore: 96,
+			expectedRules: []string{"deleted_files"},
+		},
+		{
+			name: "dependency manifests across ecosystems",
+			comparison: &types.Comparison{
+				Files: []types.FileChange{
+					file("go.mod", "modified"),
+					file("web/package-lock.json", "modified"),
+					file("requirements-dev.txt", "modified"),
+					file("Gemfile.lock", "modified"),
+				},
+			},
+			expectedScore: 85,
+			expectedRules: []string{"dependency_changes"},
+		},
+		{
+			name: "breaking API changes",
+			comparison: &types.Comparison{
+				Files: []types.FileChange{{
+					Filename: "docs/users-api.yaml",
+					Status:   "modified",
+					APIChanges: []types.APIChange{
+						{Location: "DELETE /users/{id}", Description: "Endpoint removed", Breaking: true},
+						{Location: "GET /health", Description: "Endpoint added"},
+						{Location: "GET /users parameter query limit", Description: "New required query parameter limit", Breaking: true},
+					},
+				}},
+			},
+			expectedScore: 80,
+			expectedRules: []string{"breaking_api_changes"},
+		},
+		{
+			name: "score is floored at zero",
+			comparison: &types.Comparison{
+				Commits: []types.Commit{
+					{ShortSHA: "a", QETestingLabel: shared.LabelNeedsQETesting},
+					{ShortSHA: "b", QETestingLabel: shared.LabelNeedsQETesting},
+					{ShortSHA: "c", QETestingLabel: shared.LabelNeedsQETesting},
+				},
+				Files: []types.FileChange{
+					file("db/migrations/001.sql", "added"),
+					file("db/migrations/002.sql", "added"),
+					file("db/migrations/003.sql", "added"),
+					file("config/auth.go", "modified"),
+					file("security/keys.go", "modified"),
+					file("openapi.yaml", "modified"),
+					file("schema.proto", "modified"),
+					file("old1.go", "removed"),
+					file("old2.go", "removed"),
+					file("old3.go", "removed"),
+					file("old4.go", "removed"),
+					file("old5.go", "removed"),
+					file("go.mod", "modified"),
+					file("go.sum", "modified"),
+					file("package.json", "modified"),
+				},
+				Stats: types.ComparisonStats{TotalAdditions: 6000},
+			},
+			expectedScore: 0,
+			expectedRules: []string{"untested_commits", "migrations", "critical_files", "diff_size", "deleted_files", "dependency_changes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate([]*types.Comparison{tt.comparison})
+
+			if result.Score != tt.expectedScore {
+				t.Errorf("Score = %d, want %d (findings: %+v)", result.Score, tt.expectedScore, result.Findings)
+			}
+
+			rules := []string{}
+			for _, finding := range result.Findings {
+				rules = append(rules, finding.Rule)
+			}
+			if !slices.Equal(rules, tt.expectedRules) {
+				t.Errorf("rules = %v, want %v", rules, tt.expectedRules)
+			}
+		})
+	}
+}
+
+func TestEvaluate_AcrossComparisons(t *testing.T) {
+	comparisons := []*types.Comparison{
+		{Commits: []types.Commit{{ShortSHA: "abc1234", PRNumber: 12, Message: "Add billing", QETestingLabel: shared.LabelNeedsQETesting}}},
+		{Stats: types.ComparisonStats{TotalAdditions: 600}},
+		{Stats: types.ComparisonStats{TotalAdditions: 600}},
+	}
+
+	result := Evaluate(comparisons)
+
+	if result.Score != 80 {
+		t.Errorf("Score = %d, want 80", result.Score)
+	}
+	if len(result.Findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", result.Findings)
+	}
+	if !slices.Equal(result.Findings[0].References, []string{`abc1234 (#12) "Add billing"`}) {
+		t.Errorf("References = %v, want the commit with its PR number and message", result.Findings[0].References)
+	}
+}
+
+func TestEvaluate_UsesRepositoryRiskConfig(t *testing.T) {
+	comparison := &types.Comparison{
+		Files: []types.FileChange{file("pkg/billing/total.go", "modified")},
+		RepoConfig: &types.RepoConfig{
+			RiskPatterns: types.RiskPatterns{Critical: []string{"pkg/billing/**"}},
+		},
+	}
+
+	result := Evaluate([]*types.Comparison{comparison})
+
+	if len(result.Findings) != 1 || result.Findings[0].Rule != "critical_files" {
+		t.Errorf("expected a critical_files finding for a configured critical path, got %+v", result.Findings)
+	}
+}
+
+func TestResultEvidence(t *testing.T) {
+	result := &Result{
+		Score: 75,
+		Findings: []Finding{
+			{Rule: "migrations", Severity: SeverityHigh, Description: "1 database migration file changed", Penalty: 15, References: []string{"db/migrations/001.sql"}},
+			{Rule: "diff_size", Severity: SeverityMedium, Description: "Large release: 1200 changed lines across 30 files", Penalty: 10},
+		},
+	}
+
+	evidence := result.Evidence()
+
+	expected := []string{
+		"**Rule-based score:** 75/100",
+		"- [high] 1 database migration file changed (-15): db/migrations/001.sql",
+		"- [medium] Large release: 1200 changed lines across 30 files (-10)\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(evidence, want) {
+			t.Errorf("Evidence() missing %q, got:\n%s", want, evidence)
+		}
+	}
+
+	empty := (&Result{Score: 100}).Evidence()
+	if !strings.Contains(empty, "No rule findings.") {
+		t.Errorf("Evidence() without findings = %q, want a no-findings note", empty)
+	}
+}
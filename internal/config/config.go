@@ -3,37 +3,98 @@ package config
 import (
 	"encoding/base64"
 	"fmt"
+	"net/url"
 	"os"
 	"slices"
 	"strconv"
 	"strings"
 )
 
-// valid log formats and levels
+// valid log formats, log levels, model providers, ensemble aggregation methods, report formats and analysis modes
 var (
-	validLogFormats = []string{"text", "json"}
-	validLogLevels  = []string{"debug", "info", "warn", "error"}
+	validAnalysisModes       = []string{"single", "hierarchical"}
+	validLogFormats          = []string{"text", "json"}
+	validLogLevels           = []string{"debug", "info", "warn", "error"}
+	validModelProviders      = []string{"claude", "gemini"}
+	validEnsembleAggregation = []string{"median", "min"}
+	validReportFormats       = []string{"markdown", "json"}
 )
 
 type Config struct {
+	AnalysisMode           string // "single" truncates oversized releases, "hierarchical" splits them into chunks
+	Ensemble               EnsembleConfig
 	FeedbackURL            string
-	GCPServiceAccountKey []byte // cleared after credential initialization
-	GitHubToken          string
+	GCPServiceAccountKey   []byte           // cleared after credential initialization
+	GitHubInstances        []GitHubInstance // GitHub Enterprise Server instances, besides github.com
+	GitHubToken            string           // Only ever sent to github.com
 	GitLabBaseURL          string
+	GitLabCACertFile       string           // PEM bundle trusted for the GitLab instance, in addition to the system roots
+	GitLabInstances        []GitLabInstance // Additional GitLab instances, besides GitLabBaseURL
 	GitLabSkipSSLVerify    bool
 	GitLabToken            string
 	LogFormat              string
 	LogLevel               string
 	ModelAPI               string
+	ModelContextWindow     int // 0 means use the built-in per-model table
 	ModelID                string
 	ModelMaxResponseTokens int
 	ModelProvider          string
+	ModelRepairAttempts    int // Re-prompts allowed when a response fails schema validation
 	ModelSkipSSLVerify     bool
+	ModelStructuredOutput  bool // Use provider-native structured output instead of prompt-only JSON
+	ModelTemperature       float64
 	ModelTimeoutSeconds    int
+	PerServiceAnalysis     bool   // Score each repository of a multi-repo release separately
+	PolicyFile             string // Policies that constrain the final score and decision
+	RepoConfigFile         string // Global .release-confidence.yaml applied beneath every repository's own file
+	ReportFormat           string
+	RulesOnlyFallback      bool // Report the rule-based score instead of failing when the LLM analysis fails
+	Sampling               SamplingConfig
 	ScoreThresholds        ScoreThresholds
 	SystemPromptVersion    string
 }
 
+// EnsembleConfig configures scoring the same release with several models
+type EnsembleConfig struct {
+	Models                []ModelConfig // Additional models queried alongside the primary model
+	Aggregation           string        // How member scores are combined: "median" or "min"
+	DisagreementThreshold int           // Score difference above which models are reported as disagreeing
+}
+
+// SamplingConfig configures self-consistency sampling, where each model is asked several times
+type SamplingConfig struct {
+	Count                 int     // Number of samples per model; 1 disables sampling
+	Temperature           float64 // Model temperature used while sampling
+	MinConcernOccurrences int     // Samples a concern must appear in to be reported; 0 means a majority
+	SpreadThreshold       int     // Score spread above which the assessment is flagged as low-confidence
+}
+
+// GitHubInstance holds the API settings of one GitHub Enterprise Server instance
+type GitHubInstance struct {
+	Name          string // Name used in the instance's RCS_GITHUB_<NAME>_* variables
+	BaseURL       string
+	UploadURL     string // Defaults to BaseURL
+	Token         string
+	SkipSSLVerify bool
+	CACertFile    string
+}
+
+// GitLabInstance holds the API settings of one GitLab instance
+type GitLabInstance struct {
+	Name          string // Name used in the instance's RCS_GITLAB_<NAME>_* variables; empty for the RCS_GITLAB_BASE_URL instance
+	BaseURL       string
+	Token         string
+	SkipSSLVerify bool
+	CACertFile    string
+}
+
+// ModelConfig identifies a single model endpoint
+type ModelConfig struct {
+	Provider string
+	API      string
+	ID       string
+}
+
 type ScoreThresholds struct {
 	AutoDeploy     int // Score above which auto-deploy is recommended
 	ReviewRequired int // Score below which manual review is required
@@ -49,12 +110,23 @@ func Load(isAppInterfaceMode bool) (*Config, error) {
 	gitHubToken := os.Getenv("RCS_GITHUB_TOKEN")
 	gitLabBaseURL := os.Getenv("RCS_GITLAB_BASE_URL")
 	gitLabToken := os.Getenv("RCS_GITLAB_TOKEN")
+	gitLabCACertFile := os.Getenv("RCS_GITLAB_CA_CERT_FILE")
+
+	gitHubInstances, err := parseGitHubInstances(os.Getenv("RCS_GITHUB_INSTANCES"))
+	if err != nil {
+		return nil, err
+	}
 
 	gitLabSkipSSL, err := parseBoolEnvOrDefault("RCS_GITLAB_SKIP_SSL_VERIFY", false)
 	if err != nil {
 		return nil, err
 	}
 
+	gitLabInstances, err := parseGitLabInstances(os.Getenv("RCS_GITLAB_INSTANCES"))
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse logging configuration
 	logFormat := os.Getenv("RCS_LOG_FORMAT")
 	logLevel := os.Getenv("RCS_LOG_LEVEL")
@@ -70,6 +142,11 @@ func Load(isAppInterfaceMode bool) (*Config, error) {
 		return nil, err
 	}
 
+	modelStructuredOutput, err := parseBoolEnvOrDefault("RCS_MODEL_STRUCTURED_OUTPUT", false)
+	if err != nil {
+		return nil, err
+	}
+
 	modelMaxResponseTokens, err := parseIntEnvOrDefault("RCS_MODEL_MAX_RESPONSE_TOKENS", 4096, 1, 1000000000)
 	if err != nil {
 		return nil, err
@@ -78,6 +155,60 @@ func Load(isAppInterfaceMode bool) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	modelContextWindow, err := parseIntEnvOrDefault("RCS_MODEL_CONTEXT_WINDOW_TOKENS", 0, 0, 1000000000)
+	if err != nil {
+		return nil, err
+	}
+	modelRepairAttempts, err := parseIntEnvOrDefault("RCS_MODEL_REPAIR_ATTEMPTS", 2, 0, 5)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse ensemble configuration
+	ensembleModels, err := parseEnsembleModels(os.Getenv("RCS_ENSEMBLE_MODELS"))
+	if err != nil {
+		return nil, err
+	}
+	ensembleAggregation := getEnvOrDefault("RCS_ENSEMBLE_AGGREGATION", "median")
+	ensembleThreshold, err := parseIntEnvOrDefault("RCS_ENSEMBLE_DISAGREEMENT_THRESHOLD", 15, 0, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse sampling configuration
+	samplingCount, err := parseIntEnvOrDefault("RCS_SAMPLING_COUNT", 1, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	samplingTemperature, err := parseFloatEnvOrDefault("RCS_SAMPLING_TEMPERATURE", 0.7, 0, 2)
+	if err != nil {
+		return nil, err
+	}
+	samplingMinOccurrences, err := parseIntEnvOrDefault("RCS_SAMPLING_MIN_CONCERN_OCCURRENCES", 0, 0, 10)
+	if err != nil {
+		return nil, err
+	}
+	samplingSpreadThreshold, err := parseIntEnvOrDefault("RCS_SAMPLING_SPREAD_THRESHOLD", 10, 0, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse analysis configuration
+	analysisMode := getEnvOrDefault("RCS_ANALYSIS_MODE", "single")
+	perServiceAnalysis, err := parseBoolEnvOrDefault("RCS_PER_SERVICE_ANALYSIS", false)
+	if err != nil {
+		return nil, err
+	}
+
+	repoConfigFile := os.Getenv("RCS_REPO_CONFIG_FILE")
+	policyFile := os.Getenv("RCS_POLICY_FILE")
+	rulesOnlyFallback, err := parseBoolEnvOrDefault("RCS_RULES_ONLY_FALLBACK", true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse report configuration
+	reportFormat := getEnvOrDefault("RCS_REPORT_FORMAT", "markdown")
 
 	// Parse score thresholds
 	autoDeploy, err := parseIntEnvOrDefault("RCS_SCORE_THRESHOLD_AUTO_DEPLOY", 80, 0, 100)
@@ -100,20 +231,43 @@ func Load(isAppInterfaceMode bool) (*Config, error) {
 
 	// Build config struct
 	cfg := &Config{
+		AnalysisMode: analysisMode,
+		Ensemble: EnsembleConfig{
+			Models:                ensembleModels,
+			Aggregation:           ensembleAggregation,
+			DisagreementThreshold: ensembleThreshold,
+		},
 		FeedbackURL:            feedbackURL,
 		GCPServiceAccountKey:   gcpSAKey,
+		GitHubInstances:        gitHubInstances,
 		GitHubToken:            gitHubToken,
 		GitLabBaseURL:          gitLabBaseURL,
+		GitLabCACertFile:       gitLabCACertFile,
+		GitLabInstances:        gitLabInstances,
 		GitLabSkipSSLVerify:    gitLabSkipSSL,
 		GitLabToken:            gitLabToken,
 		LogFormat:              logFormat,
 		LogLevel:               logLevel,
 		ModelAPI:               modelAPI,
+		ModelContextWindow:     modelContextWindow,
 		ModelID:                modelID,
 		ModelMaxResponseTokens: modelMaxResponseTokens,
 		ModelProvider:          modelProvider,
+		ModelRepairAttempts:    modelRepairAttempts,
 		ModelSkipSSLVerify:     modelSkipSSL,
+		ModelStructuredOutput:  modelStructuredOutput,
 		ModelTimeoutSeconds:    modelTimeoutSeconds,
+		PerServiceAnalysis:     perServiceAnalysis,
+		PolicyFile:             policyFile,
+		RepoConfigFile:         repoConfigFile,
+		ReportFormat:           reportFormat,
+		RulesOnlyFallback:      rulesOnlyFallback,
+		Sampling: SamplingConfig{
+			Count:                 samplingCount,
+			Temperature:           samplingTemperature,
+			MinConcernOccurrences: samplingMinOccurrences,
+			SpreadThreshold:       samplingSpreadThreshold,
+		},
 		ScoreThresholds: ScoreThresholds{
 			AutoDeploy:     autoDeploy,
 			ReviewRequired: reviewRequired,
@@ -129,6 +283,168 @@ func Load(isAppInterfaceMode bool) (*Config, error) {
 	return cfg, nil
 }
 
+// ForModel returns a copy of the config that targets the given model instead of the primary one
+func (c *Config) ForModel(model ModelConfig) *Config {
+	modelCfg := *c
+	modelCfg.ModelProvider = model.Provider
+	modelCfg.ModelAPI = model.API
+	modelCfg.ModelID = model.ID
+	return &modelCfg
+}
+
+// WithTemperature returns a copy of the config that samples the model at the given temperature
+func (c *Config) WithTemperature(temperature float64) *Config {
+	modelCfg := *c
+	modelCfg.ModelTemperature = temperature
+	return &modelCfg
+}
+
+// GitHubHosts returns the lowercased hostnames of the configured GitHub Enterprise Server instances
+func (c *Config) GitHubHosts() []string {
+	var hosts []string
+	for _, instance := range c.GitHubInstances {
+		hosts = append(hosts, Hostname(instance.BaseURL))
+	}
+	return hosts
+}
+
+// GitLabHosts returns every configured GitLab instance, keyed by its lowercased hostname
+// The RCS_GITLAB_BASE_URL instance is included when its base URL is set
+func (c *Config) GitLabHosts() map[string]GitLabInstance {
+	instances := c.GitLabInstances
+	if c.GitLabBaseURL != "" {
+		primary := GitLabInstance{
+			BaseURL:       c.GitLabBaseURL,
+			Token:         c.GitLabToken,
+			SkipSSLVerify: c.GitLabSkipSSLVerify,
+			CACertFile:    c.GitLabCACertFile,
+		}
+		instances = append([]GitLabInstance{primary}, instances...)
+	}
+
+	hosts := make(map[string]GitLabInstance, len(instances))
+	for _, instance := range instances {
+		if host := Hostname(instance.BaseURL); host != "" {
+			hosts[host] = instance
+		}
+	}
+	return hosts
+}
+
+// Hostname returns the lowercased hostname of rawURL, without its port, or "" if it has none
+// GitLab instances are matched on it, so every lookup must normalize hosts the same way
+func Hostname(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// parseList splits a comma-separated environment variable, dropping empty entries
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// isHTTPURL reports whether value is an absolute http or https URL
+func isHTTPURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// parseGitHubInstances parses a comma-separated list of GitHub Enterprise Server instance names
+// Each instance is read from RCS_GITHUB_<NAME>_BASE_URL, RCS_GITHUB_<NAME>_UPLOAD_URL, RCS_GITHUB_<NAME>_TOKEN,
+// RCS_GITHUB_<NAME>_SKIP_SSL_VERIFY and RCS_GITHUB_<NAME>_CA_CERT_FILE
+func parseGitHubInstances(value string) ([]GitHubInstance, error) {
+	var instances []GitHubInstance
+	for _, name := range parseList(value) {
+		prefix := "RCS_GITHUB_" + strings.ToUpper(name)
+
+		skipSSL, err := parseBoolEnvOrDefault(prefix+"_SKIP_SSL_VERIFY", false)
+		if err != nil {
+			return nil, err
+		}
+
+		instances = append(instances, GitHubInstance{
+			Name:          name,
+			BaseURL:       os.Getenv(prefix + "_BASE_URL"),
+			UploadURL:     os.Getenv(prefix + "_UPLOAD_URL"),
+			Token:         os.Getenv(prefix + "_TOKEN"),
+			SkipSSLVerify: skipSSL,
+			CACertFile:    os.Getenv(prefix + "_CA_CERT_FILE"),
+		})
+	}
+	return instances, nil
+}
+
+// parseGitLabInstances parses a comma-separated list of GitLab instance names
+// Each instance is read from RCS_GITLAB_<NAME>_BASE_URL, RCS_GITLAB_<NAME>_TOKEN,
+// RCS_GITLAB_<NAME>_SKIP_SSL_VERIFY and RCS_GITLAB_<NAME>_CA_CERT_FILE
+func parseGitLabInstances(value string) ([]GitLabInstance, error) {
+	var instances []GitLabInstance
+	for _, name := range parseList(value) {
+		prefix := "RCS_GITLAB_" + strings.ToUpper(name)
+
+		skipSSL, err := parseBoolEnvOrDefault(prefix+"_SKIP_SSL_VERIFY", false)
+		if err != nil {
+			return nil, err
+		}
+
+		instances = append(instances, GitLabInstance{
+			Name:          name,
+			BaseURL:       os.Getenv(prefix + "_BASE_URL"),
+			Token:         os.Getenv(prefix + "_TOKEN"),
+			SkipSSLVerify: skipSSL,
+			CACertFile:    os.Getenv(prefix + "_CA_CERT_FILE"),
+		})
+	}
+	return instances, nil
+}
+
+// parseEnsembleModels parses a comma-separated list of "provider" or "provider:model_id" entries
+// The API endpoint is read from RCS_<PROVIDER>_MODEL_API, and the model ID defaults to RCS_<PROVIDER>_MODEL_ID
+func parseEnsembleModels(value string) ([]ModelConfig, error) {
+	var models []ModelConfig
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		provider, modelID, _ := strings.Cut(entry, ":")
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if !slices.Contains(validModelProviders, provider) {
+			return nil, fmt.Errorf("RCS_ENSEMBLE_MODELS provider must be one of: %v; got: %s", validModelProviders, provider)
+		}
+		prefix := strings.ToUpper(provider)
+
+		if modelID == "" {
+			modelID = os.Getenv(fmt.Sprintf("RCS_%s_MODEL_ID", prefix))
+		}
+		modelAPI := os.Getenv(fmt.Sprintf("RCS_%s_MODEL_API", prefix))
+
+		if modelAPI == "" {
+			return nil, fmt.Errorf("RCS_%s_MODEL_API environment variable is required for ensemble model %s", prefix, entry)
+		}
+		if strings.TrimSpace(modelID) == "" {
+			return nil, fmt.Errorf("RCS_%s_MODEL_ID environment variable is required for ensemble model %s", prefix, entry)
+		}
+
+		models = append(models, ModelConfig{
+			Provider: provider,
+			API:      modelAPI,
+			ID:       strings.TrimSpace(modelID),
+		})
+	}
+	return models, nil
+}
+
 // getEnvOrDefault returns the environment variable value or a default if not set
 func getEnvOrDefault(key, defaultVal string) string {
 	if val, ok := os.LookupEnv(key); ok {
@@ -156,6 +472,25 @@ func parseIntEnvOrDefault(key string, defaultVal, min, max int) (int, error) {
 	return val, nil
 }
 
+// parseFloatEnvOrDefault parses a float environment variable with range validation or returns a default value if not set
+func parseFloatEnvOrDefault(key string, defaultVal, min, max float64) (float64, error) {
+	str, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultVal, nil
+	}
+
+	val, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a valid number, got: %s", key, str)
+	}
+
+	if val < min || val > max {
+		return 0, fmt.Errorf("%s must be between %g and %g, got: %g", key, min, max, val)
+	}
+
+	return val, nil
+}
+
 // parseBoolEnvOrDefault parses a boolean environment variable or returns a default value if not set
 func parseBoolEnvOrDefault(key string, defaultVal bool) (bool, error) {
 	str, ok := os.LookupEnv(key)
@@ -175,8 +510,8 @@ func parseBoolEnvOrDefault(key string, defaultVal bool) (bool, error) {
 func validateConfig(cfg *Config, isAppInterfaceMode bool, modelProviderPrefix string) error {
 
 	// Validate Git platform configuration
-	if cfg.GitHubToken == "" && cfg.GitLabToken == "" {
-		return fmt.Errorf("at least one of RCS_GITHUB_TOKEN or RCS_GITLAB_TOKEN is required")
+	if cfg.GitHubToken == "" && len(cfg.GitHubInstances) == 0 && cfg.GitLabToken == "" && len(cfg.GitLabInstances) == 0 {
+		return fmt.Errorf("at least one of RCS_GITHUB_TOKEN, RCS_GITHUB_INSTANCES, RCS_GITLAB_TOKEN or RCS_GITLAB_INSTANCES is required")
 	}
 	if isAppInterfaceMode && cfg.GitLabToken == "" {
 		return fmt.Errorf("RCS_GITLAB_TOKEN environment variable is required for app-interface mode")
@@ -184,6 +519,48 @@ func validateConfig(cfg *Config, isAppInterfaceMode bool, modelProviderPrefix st
 	if cfg.GitLabToken != "" && cfg.GitLabBaseURL == "" {
 		return fmt.Errorf("RCS_GITLAB_BASE_URL environment variable is required when RCS_GITLAB_TOKEN is provided")
 	}
+	hosts := map[string]string{Hostname(cfg.GitLabBaseURL): "RCS_GITLAB_BASE_URL"}
+	for _, instance := range cfg.GitLabInstances {
+		prefix := "RCS_GITLAB_" + strings.ToUpper(instance.Name)
+		if instance.BaseURL == "" {
+			return fmt.Errorf("%s_BASE_URL environment variable is required for GitLab instance %s", prefix, instance.Name)
+		}
+		if !isHTTPURL(instance.BaseURL) {
+			return fmt.Errorf("%s_BASE_URL must be an http(s) URL; got: %s", prefix, instance.BaseURL)
+		}
+		if instance.Token == "" {
+			return fmt.Errorf("%s_TOKEN environment variable is required for GitLab instance %s", prefix, instance.Name)
+		}
+		host := Hostname(instance.BaseURL)
+		if other, exists := hosts[host]; exists {
+			return fmt.Errorf("%s_BASE_URL host %s is already configured by %s", prefix, host, other)
+		}
+		hosts[host] = prefix + "_BASE_URL"
+	}
+	gitHubHosts := map[string]string{}
+	for _, instance := range cfg.GitHubInstances {
+		prefix := "RCS_GITHUB_" + strings.ToUpper(instance.Name)
+		if instance.BaseURL == "" {
+			return fmt.Errorf("%s_BASE_URL environment variable is required for GitHub instance %s", prefix, instance.Name)
+		}
+		if !isHTTPURL(instance.BaseURL) {
+			return fmt.Errorf("%s_BASE_URL must be an http(s) URL; got: %s", prefix, instance.BaseURL)
+		}
+		if instance.UploadURL != "" && !isHTTPURL(instance.UploadURL) {
+			return fmt.Errorf("%s_UPLOAD_URL must be an http(s) URL; got: %s", prefix, instance.UploadURL)
+		}
+		if instance.Token == "" {
+			return fmt.Errorf("%s_TOKEN environment variable is required for GitHub instance %s", prefix, instance.Name)
+		}
+		host := Hostname(instance.BaseURL)
+		if host == "github.com" {
+			return fmt.Errorf("%s_BASE_URL must not be github.com, which is served with RCS_GITHUB_TOKEN", prefix)
+		}
+		if other, exists := gitHubHosts[host]; exists {
+			return fmt.Errorf("%s_BASE_URL host %s is already configured by %s", prefix, host, other)
+		}
+		gitHubHosts[host] = prefix + "_BASE_URL"
+	}
 
 	// Validate logging configuration
 	if cfg.LogFormat != "" {
@@ -204,6 +581,28 @@ func validateConfig(cfg *Config, isAppInterfaceMode bool, modelProviderPrefix st
 	if cfg.ModelID == "" {
 		return fmt.Errorf("RCS_%s_MODEL_ID environment variable is required", modelProviderPrefix)
 	}
+
+	// Validate ensemble configuration
+	if !slices.Contains(validEnsembleAggregation, cfg.Ensemble.Aggregation) {
+		return fmt.Errorf("RCS_ENSEMBLE_AGGREGATION must be one of: %v; got: %s", validEnsembleAggregation, cfg.Ensemble.Aggregation)
+	}
+
+	// Validate sampling configuration
+	if cfg.Sampling.MinConcernOccurrences > cfg.Sampling.Count {
+		return fmt.Errorf("RCS_SAMPLING_MIN_CONCERN_OCCURRENCES (%d) must not exceed RCS_SAMPLING_COUNT (%d)",
+			cfg.Sampling.MinConcernOccurrences, cfg.Sampling.Count)
+	}
+
+	// Validate analysis configuration
+	if !slices.Contains(validAnalysisModes, cfg.AnalysisMode) {
+		return fmt.Errorf("RCS_ANALYSIS_MODE must be one of: %v; got: %s", validAnalysisModes, cfg.AnalysisMode)
+	}
+
+	// Validate report configuration
+	if !slices.Contains(validReportFormats, cfg.ReportFormat) {
+		return fmt.Errorf("RCS_REPORT_FORMAT must be one of: %v; got: %s", validReportFormats, cfg.ReportFormat)
+	}
+
 	// Validate score threshold logic
 	if cfg.ScoreThresholds.AutoDeploy < cfg.ScoreThresholds.ReviewRequired {
 		return fmt.Errorf("RCS_SCORE_THRESHOLD_AUTO_DEPLOY (%d) must be greater than or equal to RCS_SCORE_THRESHOLD_REVIEW_REQUIRED (%d)",
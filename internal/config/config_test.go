@@ -56,6 +56,45 @@ func TestLoad_WithDefaults(t *testing.T) {
 	if cfg.ModelTimeoutSeconds != 120 {
 		t.Errorf("ModelTimeoutSeconds = %v, expected 120 (default)", cfg.ModelTimeoutSeconds)
 	}
+	if cfg.ModelContextWindow != 0 {
+		t.Errorf("ModelContextWindow = %v, expected 0 (default)", cfg.ModelContextWindow)
+	}
+	if cfg.ModelStructuredOutput {
+		t.Errorf("ModelStructuredOutput = %v, expected false (default)", cfg.ModelStructuredOutput)
+	}
+	if cfg.ModelRepairAttempts != 2 {
+		t.Errorf("ModelRepairAttempts = %v, expected 2 (default)", cfg.ModelRepairAttempts)
+	}
+	if cfg.ModelTemperature != 0 {
+		t.Errorf("ModelTemperature = %v, expected 0 (default)", cfg.ModelTemperature)
+	}
+	if cfg.Sampling.Count != 1 {
+		t.Errorf("Sampling.Count = %v, expected 1 (default)", cfg.Sampling.Count)
+	}
+	if cfg.Sampling.Temperature != 0.7 {
+		t.Errorf("Sampling.Temperature = %v, expected 0.7 (default)", cfg.Sampling.Temperature)
+	}
+	if cfg.Sampling.SpreadThreshold != 10 {
+		t.Errorf("Sampling.SpreadThreshold = %v, expected 10 (default)", cfg.Sampling.SpreadThreshold)
+	}
+	if cfg.ReportFormat != "markdown" {
+		t.Errorf("ReportFormat = %v, expected markdown (default)", cfg.ReportFormat)
+	}
+	if cfg.AnalysisMode != "single" {
+		t.Errorf("AnalysisMode = %v, expected single (default)", cfg.AnalysisMode)
+	}
+	if cfg.PerServiceAnalysis {
+		t.Errorf("PerServiceAnalysis = %v, expected false (default)", cfg.PerServiceAnalysis)
+	}
+	if cfg.RepoConfigFile != "" {
+		t.Errorf("RepoConfigFile = %v, expected empty (default)", cfg.RepoConfigFile)
+	}
+	if !cfg.RulesOnlyFallback {
+		t.Errorf("RulesOnlyFallback = %v, expected true (default)", cfg.RulesOnlyFallback)
+	}
+	if cfg.PolicyFile != "" {
+		t.Errorf("PolicyFile = %v, expected empty (default)", cfg.PolicyFile)
+	}
 	if cfg.ScoreThresholds.AutoDeploy != 80 {
 		t.Errorf("AutoDeploy = %v, expected 80 (default)", cfg.ScoreThresholds.AutoDeploy)
 	}
@@ -68,6 +107,15 @@ func TestLoad_WithDefaults(t *testing.T) {
 	if cfg.GitLabSkipSSLVerify != false {
 		t.Errorf("GitLabSkipSSLVerify = %v, expected false (default)", cfg.GitLabSkipSSLVerify)
 	}
+	if cfg.GitLabInstances != nil {
+		t.Errorf("GitLabInstances = %v, expected none (default)", cfg.GitLabInstances)
+	}
+	if cfg.GitLabCACertFile != "" {
+		t.Errorf("GitLabCACertFile = %v, expected empty (default)", cfg.GitLabCACertFile)
+	}
+	if cfg.GitHubInstances != nil {
+		t.Errorf("GitHubInstances = %v, expected none (default)", cfg.GitHubInstances)
+	}
 	if cfg.ModelSkipSSLVerify != false {
 		t.Errorf("ModelSkipSSLVerify = %v, expected false (default)", cfg.ModelSkipSSLVerify)
 	}
@@ -154,7 +202,7 @@ func TestLoad_MissingBothGitTokens(t *testing.T) {
 	if err == nil {
 		t.Fatal("Expected error for missing both Git tokens, got none")
 	}
-	if err.Error() != "at least one of RCS_GITHUB_TOKEN or RCS_GITLAB_TOKEN is required" {
+	if err.Error() != "at least one of RCS_GITHUB_TOKEN, RCS_GITHUB_INSTANCES, RCS_GITLAB_TOKEN or RCS_GITLAB_INSTANCES is required" {
 		t.Errorf("Unexpected error message: %v", err)
 	}
 }
@@ -237,6 +285,36 @@ func TestLoad_InvalidTimeoutSeconds(t *testing.T) {
 	}
 }
 
+func TestLoad_InvalidContextWindowTokens(t *testing.T) {
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_MODEL_CONTEXT_WINDOW_TOKENS", "-1")
+
+	_, err := Load(false)
+	if err == nil {
+		t.Fatal("Expected error for negative context window tokens, got none")
+	}
+	if err.Error() != "RCS_MODEL_CONTEXT_WINDOW_TOKENS must be between 0 and 1000000000, got: -1" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestLoad_InvalidRepairAttempts(t *testing.T) {
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_MODEL_REPAIR_ATTEMPTS", "6")
+
+	_, err := Load(false)
+	if err == nil {
+		t.Fatal("Expected error for too many repair attempts, got none")
+	}
+	if err.Error() != "RCS_MODEL_REPAIR_ATTEMPTS must be between 0 and 5, got: 6" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
 func TestLoad_OutOfRangeScoreThreshold(t *testing.T) {
 	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
 	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
@@ -323,6 +401,542 @@ func TestLoad_DifferentModelProvider(t *testing.T) {
 	}
 }
 
+func TestLoad_EnsembleModels(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_GEMINI_MODEL_API", "https://gemini.example.com")
+	t.Setenv("RCS_GEMINI_MODEL_ID", "gemini-model")
+	t.Setenv("RCS_ENSEMBLE_MODELS", "gemini, claude:claude-other-model")
+	t.Setenv("RCS_ENSEMBLE_AGGREGATION", "min")
+	t.Setenv("RCS_ENSEMBLE_DISAGREEMENT_THRESHOLD", "20")
+
+	cfg, err := Load(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []ModelConfig{
+		{Provider: "gemini", API: "https://gemini.example.com", ID: "gemini-model"},
+		{Provider: "claude", API: "https://api.example.com", ID: "claude-other-model"},
+	}
+	if len(cfg.Ensemble.Models) != len(expected) {
+		t.Fatalf("Ensemble.Models = %v, expected %v", cfg.Ensemble.Models, expected)
+	}
+	for i, model := range expected {
+		if cfg.Ensemble.Models[i] != model {
+			t.Errorf("Ensemble.Models[%d] = %+v, expected %+v", i, cfg.Ensemble.Models[i], model)
+		}
+	}
+	if cfg.Ensemble.Aggregation != "min" {
+		t.Errorf("Ensemble.Aggregation = %v, expected min", cfg.Ensemble.Aggregation)
+	}
+	if cfg.Ensemble.DisagreementThreshold != 20 {
+		t.Errorf("Ensemble.DisagreementThreshold = %v, expected 20", cfg.Ensemble.DisagreementThreshold)
+	}
+}
+
+func TestLoad_EnsembleModelMissingAPI(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_ENSEMBLE_MODELS", "gemini:gemini-2.5-pro")
+
+	_, err := Load(false)
+	if err == nil {
+		t.Fatal("Expected error for ensemble model without API, got none")
+	}
+	if err.Error() != "RCS_GEMINI_MODEL_API environment variable is required for ensemble model gemini:gemini-2.5-pro" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestLoad_EnsembleModelUnknownProvider(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_ENSEMBLE_MODELS", "claude, openai:gpt-5")
+
+	_, err := Load(false)
+	if err == nil {
+		t.Fatal("Expected error for unknown ensemble provider, got none")
+	}
+	if err.Error() != "RCS_ENSEMBLE_MODELS provider must be one of: [claude gemini]; got: openai" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestLoad_InvalidEnsembleAggregation(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_ENSEMBLE_AGGREGATION", "mean")
+
+	_, err := Load(false)
+	if err == nil {
+		t.Fatal("Expected error for invalid ensemble aggregation, got none")
+	}
+	if err.Error() != "RCS_ENSEMBLE_AGGREGATION must be one of: [median min]; got: mean" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestLoad_SamplingConfiguration(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_SAMPLING_COUNT", "5")
+	t.Setenv("RCS_SAMPLING_TEMPERATURE", "0.9")
+	t.Setenv("RCS_SAMPLING_MIN_CONCERN_OCCURRENCES", "3")
+	t.Setenv("RCS_SAMPLING_SPREAD_THRESHOLD", "20")
+	t.Setenv("RCS_REPORT_FORMAT", "json")
+
+	cfg, err := Load(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := SamplingConfig{Count: 5, Temperature: 0.9, MinConcernOccurrences: 3, SpreadThreshold: 20}
+	if cfg.Sampling != expected {
+		t.Errorf("Sampling = %+v, expected %+v", cfg.Sampling, expected)
+	}
+	if cfg.ReportFormat != "json" {
+		t.Errorf("ReportFormat = %v, expected json", cfg.ReportFormat)
+	}
+}
+
+func TestLoad_SamplingOccurrencesExceedCount(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_SAMPLING_COUNT", "3")
+	t.Setenv("RCS_SAMPLING_MIN_CONCERN_OCCURRENCES", "4")
+
+	_, err := Load(false)
+	if err == nil {
+		t.Fatal("Expected error for min concern occurrences above sample count, got none")
+	}
+	if err.Error() != "RCS_SAMPLING_MIN_CONCERN_OCCURRENCES (4) must not exceed RCS_SAMPLING_COUNT (3)" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestLoad_InvalidSamplingTemperature(t *testing.T) {
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_SAMPLING_TEMPERATURE", "2.5")
+
+	_, err := Load(false)
+	if err == nil {
+		t.Fatal("Expected error for out of range sampling temperature, got none")
+	}
+	if err.Error() != "RCS_SAMPLING_TEMPERATURE must be between 0 and 2, got: 2.5" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestLoad_InvalidReportFormat(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_REPORT_FORMAT", "html")
+
+	_, err := Load(false)
+	if err == nil {
+		t.Fatal("Expected error for invalid report format, got none")
+	}
+	if err.Error() != "RCS_REPORT_FORMAT must be one of: [markdown json]; got: html" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestLoad_AnalysisMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectError string
+	}{
+		{name: "hierarchical", value: "hierarchical", expected: "hierarchical"},
+		{name: "single", value: "single", expected: "single"},
+		{name: "invalid", value: "chunked", expectError: "RCS_ANALYSIS_MODE must be one of: [single hierarchical]; got: chunked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+			t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+			t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+			t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+			t.Setenv("RCS_ANALYSIS_MODE", tt.value)
+
+			cfg, err := Load(false)
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("Expected error %q, got: %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if cfg.AnalysisMode != tt.expected {
+				t.Errorf("AnalysisMode = %v, expected %v", cfg.AnalysisMode, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoad_PerServiceAnalysis(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_PER_SERVICE_ANALYSIS", "true")
+
+	cfg, err := Load(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !cfg.PerServiceAnalysis {
+		t.Errorf("PerServiceAnalysis = %v, expected true", cfg.PerServiceAnalysis)
+	}
+}
+
+func TestLoad_RepoConfigFile(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_REPO_CONFIG_FILE", "/etc/rcs/global.yaml")
+
+	cfg, err := Load(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if cfg.RepoConfigFile != "/etc/rcs/global.yaml" {
+		t.Errorf("RepoConfigFile = %v, expected /etc/rcs/global.yaml", cfg.RepoConfigFile)
+	}
+}
+
+func TestLoad_RulesOnlyFallback(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_RULES_ONLY_FALLBACK", "false")
+
+	cfg, err := Load(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if cfg.RulesOnlyFallback {
+		t.Errorf("RulesOnlyFallback = %v, expected false", cfg.RulesOnlyFallback)
+	}
+}
+
+func TestLoad_PolicyFile(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_POLICY_FILE", "/etc/rcs/policies.yaml")
+
+	cfg, err := Load(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if cfg.PolicyFile != "/etc/rcs/policies.yaml" {
+		t.Errorf("PolicyFile = %v, expected /etc/rcs/policies.yaml", cfg.PolicyFile)
+	}
+}
+
+func TestLoad_GitHubInstances(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_GITHUB_INSTANCES", "example, internal")
+	t.Setenv("RCS_GITHUB_EXAMPLE_BASE_URL", "https://github.example.com")
+	t.Setenv("RCS_GITHUB_EXAMPLE_UPLOAD_URL", "https://uploads.github.example.com")
+	t.Setenv("RCS_GITHUB_EXAMPLE_TOKEN", "example-token")
+	t.Setenv("RCS_GITHUB_INTERNAL_BASE_URL", "https://GHE.internal:8443/")
+	t.Setenv("RCS_GITHUB_INTERNAL_TOKEN", "internal-token")
+	t.Setenv("RCS_GITHUB_INTERNAL_CA_CERT_FILE", "/etc/rcs/ca.pem")
+	t.Setenv("RCS_GITHUB_INTERNAL_SKIP_SSL_VERIFY", "true")
+
+	cfg, err := Load(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []GitHubInstance{
+		{Name: "example", BaseURL: "https://github.example.com", UploadURL: "https://uploads.github.example.com", Token: "example-token"},
+		{Name: "internal", BaseURL: "https://GHE.internal:8443/", Token: "internal-token", SkipSSLVerify: true, CACertFile: "/etc/rcs/ca.pem"},
+	}
+	if len(cfg.GitHubInstances) != len(expected) {
+		t.Fatalf("GitHubInstances = %+v, expected %d instances", cfg.GitHubInstances, len(expected))
+	}
+	for i, instance := range expected {
+		if cfg.GitHubInstances[i] != instance {
+			t.Errorf("GitHubInstances[%d] = %+v, expected %+v", i, cfg.GitHubInstances[i], instance)
+		}
+	}
+	if hosts := cfg.GitHubHosts(); len(hosts) != 2 || hosts[0] != "github.example.com" || hosts[1] != "ghe.internal" {
+		t.Errorf("GitHubHosts() = %v, expected [github.example.com ghe.internal]", hosts)
+	}
+}
+
+func TestLoad_GitHubInstancesWithoutPublicToken(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_GITHUB_INSTANCES", "example")
+	t.Setenv("RCS_GITHUB_EXAMPLE_BASE_URL", "https://github.example.com")
+	t.Setenv("RCS_GITHUB_EXAMPLE_TOKEN", "example-token")
+
+	cfg, err := Load(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if cfg.GitHubToken != "" || len(cfg.GitHubInstances) != 1 {
+		t.Errorf("GitHubToken, GitHubInstances = %q, %+v, expected only the example instance", cfg.GitHubToken, cfg.GitHubInstances)
+	}
+}
+
+func TestLoad_InvalidGitHubInstances(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "missing base URL",
+			env:      map[string]string{"RCS_GITHUB_EXAMPLE_TOKEN": "example-token"},
+			expected: "RCS_GITHUB_EXAMPLE_BASE_URL environment variable is required for GitHub instance example",
+		},
+		{
+			name:     "base URL without scheme",
+			env:      map[string]string{"RCS_GITHUB_EXAMPLE_BASE_URL": "github.example.com", "RCS_GITHUB_EXAMPLE_TOKEN": "example-token"},
+			expected: "RCS_GITHUB_EXAMPLE_BASE_URL must be an http(s) URL; got: github.example.com",
+		},
+		{
+			name:     "invalid upload URL",
+			env:      map[string]string{"RCS_GITHUB_EXAMPLE_BASE_URL": "https://github.example.com", "RCS_GITHUB_EXAMPLE_UPLOAD_URL": "ftp://uploads.example.com", "RCS_GITHUB_EXAMPLE_TOKEN": "example-token"},
+			expected: "RCS_GITHUB_EXAMPLE_UPLOAD_URL must be an http(s) URL; got: ftp://uploads.example.com",
+		},
+		{
+			name:     "missing token",
+			env:      map[string]string{"RCS_GITHUB_EXAMPLE_BASE_URL": "https://github.example.com"},
+			expected: "RCS_GITHUB_EXAMPLE_TOKEN environment variable is required for GitHub instance example",
+		},
+		{
+			name:     "github.com",
+			env:      map[string]string{"RCS_GITHUB_EXAMPLE_BASE_URL": "https://GitHub.com", "RCS_GITHUB_EXAMPLE_TOKEN": "example-token"},
+			expected: "RCS_GITHUB_EXAMPLE_BASE_URL must not be github.com, which is served with RCS_GITHUB_TOKEN",
+		},
+		{
+			name: "duplicate host",
+			env: map[string]string{
+				"RCS_GITHUB_INSTANCES":        "example,copy",
+				"RCS_GITHUB_EXAMPLE_BASE_URL": "https://github.example.com",
+				"RCS_GITHUB_EXAMPLE_TOKEN":    "example-token",
+				"RCS_GITHUB_COPY_BASE_URL":    "https://GitHub.example.com:443/",
+				"RCS_GITHUB_COPY_TOKEN":       "copy-token",
+			},
+			expected: "RCS_GITHUB_COPY_BASE_URL host github.example.com is already configured by RCS_GITHUB_EXAMPLE_BASE_URL",
+		},
+		{
+			name:     "invalid skip SSL",
+			env:      map[string]string{"RCS_GITHUB_EXAMPLE_BASE_URL": "https://github.example.com", "RCS_GITHUB_EXAMPLE_TOKEN": "example-token", "RCS_GITHUB_EXAMPLE_SKIP_SSL_VERIFY": "maybe"},
+			expected: "RCS_GITHUB_EXAMPLE_SKIP_SSL_VERIFY must be a valid boolean, got: maybe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+			t.Setenv("RCS_GITHUB_TOKEN", "github-token")
+			t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+			t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+			t.Setenv("RCS_GITHUB_INSTANCES", "example")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load(false)
+			if err == nil {
+				t.Fatal("Expected error, got none")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Unexpected error message: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad_GitLabInstances(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_GITLAB_TOKEN", "corp-token")
+	t.Setenv("RCS_GITLAB_BASE_URL", "https://gitlab.corp.example.com")
+	t.Setenv("RCS_GITLAB_CA_CERT_FILE", "/etc/rcs/corp-ca.pem")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_GITLAB_INSTANCES", "public, partner")
+	t.Setenv("RCS_GITLAB_PUBLIC_BASE_URL", "https://gitlab.com")
+	t.Setenv("RCS_GITLAB_PUBLIC_TOKEN", "public-token")
+	t.Setenv("RCS_GITLAB_PARTNER_BASE_URL", "https://GitLab.Partner.example.com:8443")
+	t.Setenv("RCS_GITLAB_PARTNER_TOKEN", "partner-token")
+	t.Setenv("RCS_GITLAB_PARTNER_SKIP_SSL_VERIFY", "true")
+	t.Setenv("RCS_GITLAB_PARTNER_CA_CERT_FILE", "/etc/rcs/partner-ca.pem")
+
+	cfg, err := Load(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if cfg.GitLabCACertFile != "/etc/rcs/corp-ca.pem" {
+		t.Errorf("GitLabCACertFile = %v, expected /etc/rcs/corp-ca.pem", cfg.GitLabCACertFile)
+	}
+	if len(cfg.GitLabInstances) != 2 {
+		t.Fatalf("GitLabInstances = %v, expected two instances", cfg.GitLabInstances)
+	}
+	partner := cfg.GitLabInstances[1]
+	if partner.Name != "partner" || partner.Token != "partner-token" || !partner.SkipSSLVerify || partner.CACertFile != "/etc/rcs/partner-ca.pem" {
+		t.Errorf("GitLabInstances[1] = %+v, expected the partner instance", partner)
+	}
+
+	hosts := cfg.GitLabHosts()
+	expected := map[string]string{
+		"gitlab.corp.example.com":    "corp-token",
+		"gitlab.com":                 "public-token",
+		"gitlab.partner.example.com": "partner-token",
+	}
+	if len(hosts) != len(expected) {
+		t.Errorf("GitLabHosts() = %v, expected %d hosts", hosts, len(expected))
+	}
+	for host, token := range expected {
+		if hosts[host].Token != token {
+			t.Errorf("GitLabHosts()[%s].Token = %q, expected %q", host, hosts[host].Token, token)
+		}
+	}
+	if hosts["gitlab.corp.example.com"].CACertFile != "/etc/rcs/corp-ca.pem" {
+		t.Errorf("expected the primary instance to use RCS_GITLAB_CA_CERT_FILE, got %+v", hosts["gitlab.corp.example.com"])
+	}
+}
+
+func TestLoad_GitLabInstancesWithoutPrimary(t *testing.T) {
+	t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+	t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+	t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+	t.Setenv("RCS_GITLAB_INSTANCES", "public")
+	t.Setenv("RCS_GITLAB_PUBLIC_BASE_URL", "https://gitlab.com")
+	t.Setenv("RCS_GITLAB_PUBLIC_TOKEN", "public-token")
+
+	cfg, err := Load(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if hosts := cfg.GitLabHosts(); len(hosts) != 1 || hosts["gitlab.com"].Token != "public-token" {
+		t.Errorf("GitLabHosts() = %v, expected only gitlab.com", hosts)
+	}
+}
+
+func TestLoad_InvalidGitLabInstances(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "missing base URL",
+			env:      map[string]string{"RCS_GITLAB_PUBLIC_TOKEN": "public-token"},
+			expected: "RCS_GITLAB_PUBLIC_BASE_URL environment variable is required for GitLab instance public",
+		},
+		{
+			name:     "base URL without scheme",
+			env:      map[string]string{"RCS_GITLAB_PUBLIC_BASE_URL": "gitlab.com", "RCS_GITLAB_PUBLIC_TOKEN": "public-token"},
+			expected: "RCS_GITLAB_PUBLIC_BASE_URL must be an http(s) URL; got: gitlab.com",
+		},
+		{
+			name:     "missing token",
+			env:      map[string]string{"RCS_GITLAB_PUBLIC_BASE_URL": "https://gitlab.com"},
+			expected: "RCS_GITLAB_PUBLIC_TOKEN environment variable is required for GitLab instance public",
+		},
+		{
+			name:     "host of the primary instance",
+			env:      map[string]string{"RCS_GITLAB_PUBLIC_BASE_URL": "https://GitLab.Corp.example.com", "RCS_GITLAB_PUBLIC_TOKEN": "public-token"},
+			expected: "RCS_GITLAB_PUBLIC_BASE_URL host gitlab.corp.example.com is already configured by RCS_GITLAB_BASE_URL",
+		},
+		{
+			name:     "invalid skip SSL",
+			env:      map[string]string{"RCS_GITLAB_PUBLIC_BASE_URL": "https://gitlab.com", "RCS_GITLAB_PUBLIC_TOKEN": "public-token", "RCS_GITLAB_PUBLIC_SKIP_SSL_VERIFY": "maybe"},
+			expected: "RCS_GITLAB_PUBLIC_SKIP_SSL_VERIFY must be a valid boolean, got: maybe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RCS_GOOGLE_SA_KEY_B64", "dGVzdA==")
+			t.Setenv("RCS_GITLAB_TOKEN", "corp-token")
+			t.Setenv("RCS_GITLAB_BASE_URL", "https://gitlab.corp.example.com")
+			t.Setenv("RCS_CLAUDE_MODEL_API", "https://api.example.com")
+			t.Setenv("RCS_CLAUDE_MODEL_ID", "claude-model")
+			t.Setenv("RCS_GITLAB_INSTANCES", "public")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load(false)
+			if err == nil {
+				t.Fatal("Expected error, got none")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Unexpected error message: %v", err)
+			}
+		})
+	}
+}
+
+func TestConfigWithTemperature(t *testing.T) {
+	cfg := &Config{ModelID: "claude-model"}
+
+	sampledCfg := cfg.WithTemperature(0.8)
+
+	if sampledCfg.ModelTemperature != 0.8 {
+		t.Errorf("ModelTemperature = %v, expected 0.8", sampledCfg.ModelTemperature)
+	}
+	if sampledCfg.ModelID != "claude-model" {
+		t.Errorf("ModelID = %v, expected claude-model", sampledCfg.ModelID)
+	}
+	if cfg.ModelTemperature != 0 {
+		t.Error("WithTemperature() modified the original config")
+	}
+}
+
+func TestConfigForModel(t *testing.T) {
+	cfg := &Config{ModelProvider: "claude", ModelAPI: "https://claude.example.com", ModelID: "claude-model", ModelMaxResponseTokens: 1000}
+
+	modelCfg := cfg.ForModel(ModelConfig{Provider: "gemini", API: "https://gemini.example.com", ID: "gemini-model"})
+
+	if modelCfg.ModelProvider != "gemini" || modelCfg.ModelAPI != "https://gemini.example.com" || modelCfg.ModelID != "gemini-model" {
+		t.Errorf("ForModel() = %+v, expected gemini model fields", modelCfg)
+	}
+	if modelCfg.ModelMaxResponseTokens != 1000 {
+		t.Errorf("ModelMaxResponseTokens = %v, expected 1000", modelCfg.ModelMaxResponseTokens)
+	}
+	if cfg.ModelProvider != "claude" {
+		t.Error("ForModel() modified the original config")
+	}
+}
+
 func TestGetEnvOrDefault(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -541,6 +1155,45 @@ func TestLoad_ValidLogFormats(t *testing.T) {
 	}
 }
 
+func TestParseFloatEnvOrDefault(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		set         bool
+		expected    float64
+		expectError bool
+	}{
+		{"not set returns default", "", false, 0.7, false},
+		{"valid value", "1.5", true, 1.5, false},
+		{"integer value", "1", true, 1, false},
+		{"below minimum", "-0.1", true, 0, true},
+		{"above maximum", "2.1", true, 0, true},
+		{"invalid number", "warm", true, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.set {
+				t.Setenv("TEST_FLOAT_VAR", tt.value)
+			}
+
+			result, err := parseFloatEnvOrDefault("TEST_FLOAT_VAR", 0.7, 0, 2)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("parseFloatEnvOrDefault() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestParseBoolEnvOrDefault(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,207 @@
+package generated
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"release-confidence-score/internal/analysis/dependencies"
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/repoconfig"
+)
+
+// AttributesFile is read from the base of each compared ref range to find linguist-generated files
+const AttributesFile = ".gitattributes"
+
+// Kinds of files whose patches are summarized instead of sent raw
+const (
+	KindLockfile  = "lockfile"
+	KindVendored  = "vendored"
+	KindGenerated = "generated"
+)
+
+// maxSummaryPackages bounds the package changes listed in one summary
+const maxSummaryPackages = 20
+
+// lockfiles are matched by base name
+var lockfiles = map[string]bool{
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"go.sum":              true,
+	"Gemfile.lock":        true,
+	"poetry.lock":         true,
+	"Pipfile.lock":        true,
+	"uv.lock":             true,
+	"Cargo.lock":          true,
+	"composer.lock":       true,
+}
+
+var vendoredPatterns = []string{
+	"**/vendor/",
+	"**/node_modules/",
+}
+
+var generatedPatterns = []string{
+	"*.pb.go",
+	"*.pb.gw.go",
+	"*_pb2.py",
+	"*_pb2_grpc.py",
+	"zz_generated*",
+	"*_generated.go",
+	"*.min.js",
+	"*.min.css",
+}
+
+// Attributes holds the linguist attributes declared in a repository's .gitattributes
+type Attributes struct {
+	rules []attributeRule
+}
+
+// attributeRule sets or unsets one linguist attribute for files matching a pattern
+type attributeRule struct {
+	pattern string
+	kind    string
+	set     bool
+}
+
+// ParseAttributes extracts linguist-generated and linguist-vendored rules from .gitattributes content
+// Other attributes and invalid patterns are ignored
+func ParseAttributes(content string) *Attributes {
+	attrs := &Attributes{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if err := repoconfig.ValidatePattern(fields[0]); err != nil {
+			continue
+		}
+
+		for _, attribute := range fields[1:] {
+			name, set := parseAttribute(attribute)
+			switch name {
+			case "linguist-generated":
+				attrs.rules = append(attrs.rules, attributeRule{pattern: fields[0], kind: KindGenerated, set: set})
+			case "linguist-vendored":
+				attrs.rules = append(attrs.rules, attributeRule{pattern: fields[0], kind: KindVendored, set: set})
+			}
+		}
+	}
+	return attrs
+}
+
+// parseAttribute splits a gitattributes attribute into its name and whether it is set
+// "attr" and "attr=true" set it; "-attr", "!attr" and "attr=false" unset it
+func parseAttribute(attribute string) (string, bool) {
+	if strings.HasPrefix(attribute, "-") || strings.HasPrefix(attribute, "!") {
+		return attribute[1:], false
+	}
+	name, value, found := strings.Cut(attribute, "=")
+	if !found {
+		return name, true
+	}
+	return name, value != "false"
+}
+
+// Fetch reads the repository's .gitattributes at ref through the documentation source
+// A missing file yields nil so that only the built-in patterns apply
+func Fetch(ctx context.Context, source types.DocumentationSource, repoURL, ref string) *Attributes {
+	content, err := source.FetchFileContent(ctx, AttributesFile, ref)
+	if err != nil {
+		slog.Debug("No .gitattributes file found", "repo", repoURL, "ref", ref, "error", err)
+		return nil
+	}
+	return ParseAttributes(content)
+}
+
+// Classify returns the kind of a lockfile, vendored or generated file, or "" for regular files
+// .gitattributes rules win over the built-in patterns, so a repository can also unmark a file
+func Classify(filename string, attrs *Attributes) string {
+	if attrs != nil {
+		kind, decided := attrs.classify(filename)
+		if decided {
+			return kind
+		}
+	}
+
+	switch {
+	case lockfiles[path.Base(filename)]:
+		return KindLockfile
+	case repoconfig.MatchAny(vendoredPatterns, filename):
+		return KindVendored
+	case repoconfig.MatchAny(generatedPatterns, filename):
+		return KindGenerated
+	default:
+		return ""
+	}
+}
+
+// classify applies the .gitattributes rules; later rules override earlier ones, as in git
+// The second result is false when no rule mentions the file
+func (a *Attributes) classify(filename string) (string, bool) {
+	state := map[string]bool{}
+	for _, rule := range a.rules {
+		if repoconfig.Match(rule.pattern, filename) {
+			state[rule.kind] = rule.set
+		}
+	}
+
+	if len(state) == 0 {
+		return "", false
+	}
+	if state[KindGenerated] {
+		return KindGenerated, true
+	}
+	if state[KindVendored] {
+		return KindVendored, true
+	}
+	return "", true
+}
+
+// Summarize replaces the patches of lockfile, vendored and generated files with compact summaries
+// Lockfile summaries list the file's parsed dependency changes, so dependencies.Annotate must run first
+// Line counts and comparison stats are left untouched, so the files still count toward the release size
+func Summarize(files []types.FileChange, attrs *Attributes) {
+	summarized := 0
+	for i := range files {
+		kind := Classify(files[i].Filename, attrs)
+		if kind == "" {
+			continue
+		}
+
+		files[i].Patch = summary(files[i], kind)
+		files[i].Generated = kind
+		summarized++
+	}
+
+	if summarized > 0 {
+		slog.Debug("Summarized generated, vendored and lockfile patches", "files", summarized)
+	}
+}
+
+// summary describes a file's change without its raw patch
+func summary(file types.FileChange, kind string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s file: raw patch omitted, +%d/-%d lines]", kind, file.Additions, file.Deletions)
+	if kind != KindLockfile {
+		return b.String()
+	}
+
+	if len(file.Dependencies) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("\nPackage changes:")
+	for i, change := range file.Dependencies {
+		if i == maxSummaryPackages {
+			fmt.Fprintf(&b, "\n- ... and %d more", len(file.Dependencies)-maxSummaryPackages)
+			break
+		}
+		fmt.Fprintf(&b, "\n- %s", dependencies.Describe(change))
+	}
+	return b.String()
+}
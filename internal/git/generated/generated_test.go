@@ -0,0 +1,139 @@
+package generated
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/analysis/dependencies"
+	"release-confidence-score/internal/git/types"
+)
+
+// mockDocumentationSource serves files from a map
+type mockDocumentationSource struct {
+	files map[string]string
+}
+
+func (m *mockDocumentationSource) GetDefaultBranch(ctx context.Context) (string, error) {
+	return "main", nil
+}
+
+func (m *mockDocumentationSource) FetchFileContent(ctx context.Context, path, ref string) (string, error) {
+	content, ok := m.files[path]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return content, nil
+}
+
+func TestClassify(t *testing.T) {
+	attrs := ParseAttributes(`# generated clients
+api/client/** linguist-generated=true
+docs/openapi.json linguist-generated
+*.pb.go -linguist-generated
+third_party/** linguist-vendored
+`)
+
+	tests := []struct {
+		name     string
+		filename string
+		attrs    *Attributes
+		expected string
+	}{
+		{name: "go.sum", filename: "go.sum", expected: KindLockfile},
+		{name: "nested package-lock.json", filename: "web/package-lock.json", expected: KindLockfile},
+		{name: "Gemfile.lock", filename: "Gemfile.lock", expected: KindLockfile},
+		{name: "vendor tree", filename: "vendor/github.com/foo/bar/bar.go", expected: KindVendored},
+		{name: "node_modules", filename: "web/node_modules/lodash/index.js", expected: KindVendored},
+		{name: "protobuf Go code", filename: "api/v1/service.pb.go", expected: KindGenerated},
+		{name: "Kubernetes deepcopy", filename: "pkg/apis/v1/zz_generated.deepcopy.go", expected: KindGenerated},
+		{name: "regular Go file", filename: "pkg/server/server.go", expected: ""},
+		{name: "go.mod is a manifest, not a lockfile", filename: "go.mod", expected: ""},
+		{name: "linguist-generated directory", filename: "api/client/models/user.go", attrs: attrs, expected: KindGenerated},
+		{name: "linguist-generated file", filename: "docs/openapi.json", attrs: attrs, expected: KindGenerated},
+		{name: "gitattributes can unmark generated code", filename: "api/v1/service.pb.go", attrs: attrs, expected: ""},
+		{name: "linguist-vendored", filename: "third_party/lib/lib.c", attrs: attrs, expected: KindVendored},
+		{name: "built-in patterns still apply with attributes", filename: "go.sum", attrs: attrs, expected: KindLockfile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Classify(tt.filename, tt.attrs); result != tt.expected {
+				t.Errorf("Classify(%q) = %q, want %q", tt.filename, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseAttributesLaterRulesWin(t *testing.T) {
+	attrs := ParseAttributes("gen/** linguist-generated\ngen/keep.go linguist-generated=false\n")
+
+	if kind := Classify("gen/out.go", attrs); kind != KindGenerated {
+		t.Errorf("Classify(gen/out.go) = %q, want %q", kind, KindGenerated)
+	}
+	if kind := Classify("gen/keep.go", attrs); kind != "" {
+		t.Errorf("Classify(gen/keep.go) = %q, want regular file", kind)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	source := &mockDocumentationSource{files: map[string]string{AttributesFile: "client/** linguist-generated\n"}}
+	if attrs := Fetch(context.Background(), source, "https://github.com/org/repo", "abc"); Classify("client/a.go", attrs) != KindGenerated {
+		t.Error("expected the fetched attributes to mark client/ as generated")
+	}
+
+	missing := &mockDocumentationSource{files: map[string]string{}}
+	if attrs := Fetch(context.Background(), missing, "https://github.com/org/repo", "abc"); attrs != nil {
+		t.Errorf("Fetch() = %+v, want nil for a missing file", attrs)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	files := []types.FileChange{
+		{
+			Filename:  "go.sum",
+			Additions: 2,
+			Deletions: 2,
+			Patch: "@@ -1,4 +1,4 @@\n" +
+				"-github.com/foo/bar v1.2.0 h1:abc=\n" +
+				"-github.com/foo/bar v1.2.0/go.mod h1:def=\n" +
+				"+github.com/foo/bar v1.3.0 h1:ghi=\n" +
+				"+github.com/foo/bar v1.3.0/go.mod h1:jkl=",
+		},
+		{Filename: "vendor/github.com/foo/bar/bar.go", Additions: 300, Deletions: 120, Patch: "huge vendored diff"},
+		{Filename: "main.go", Additions: 1, Deletions: 1, Patch: "-a\n+b"},
+	}
+
+	dependencies.Annotate(files)
+	Summarize(files, nil)
+
+	expectedLockfile := "[lockfile file: raw patch omitted, +2/-2 lines]\nPackage changes:\n- github.com/foo/bar: v1.2.0 → v1.3.0 (minor bump)"
+	if files[0].Patch != expectedLockfile || files[0].Generated != KindLockfile {
+		t.Errorf("lockfile = %q (%s), want %q", files[0].Patch, files[0].Generated, expectedLockfile)
+	}
+	if files[1].Patch != "[vendored file: raw patch omitted, +300/-120 lines]" || files[1].Generated != KindVendored {
+		t.Errorf("vendored file = %q (%s)", files[1].Patch, files[1].Generated)
+	}
+	if files[1].Additions != 300 || files[1].Deletions != 120 {
+		t.Error("summarized files must keep their line counts")
+	}
+	if files[2].Patch != "-a\n+b" || files[2].Generated != "" {
+		t.Error("regular files must keep their patch")
+	}
+}
+
+func TestSummarizeCapsPackageList(t *testing.T) {
+	var patch strings.Builder
+	for i := range 25 {
+		patch.WriteString("+github.com/foo/pkg" + string(rune('a'+i)) + " v1.0.0 h1:x=\n")
+	}
+	files := []types.FileChange{{Filename: "go.sum", Additions: 25, Patch: patch.String()}}
+
+	dependencies.Annotate(files)
+	Summarize(files, nil)
+
+	if strings.Count(files[0].Patch, "\n- ") != maxSummaryPackages+1 || !strings.HasSuffix(files[0].Patch, "- ... and 5 more") {
+		t.Errorf("expected %d packages and a remainder line, got %q", maxSummaryPackages, files[0].Patch)
+	}
+}
@@ -0,0 +1,89 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/go-github/v90/github"
+	"release-confidence-score/internal/analysis/ci"
+	"release-confidence-score/internal/git/types"
+)
+
+// fetchCIStatus fetches the check runs and commit statuses reported for a ref
+// Check runs come from GitHub Actions and other GitHub Apps; commit statuses from external CI systems
+func fetchCIStatus(ctx context.Context, client *github.Client, owner, repo, ref, repoURL string) (*types.CIStatus, error) {
+	checkRuns, err := fetchAllPaginated(ctx,
+		func(ctx context.Context, opts *github.ListOptions) ([]*github.CheckRun, *github.Response, error) {
+			results, resp, err := client.Checks.ListCheckRunsForRef(ctx, owner, repo, ref, &github.ListCheckRunsOptions{
+				Filter:      github.Ptr("latest"),
+				ListOptions: *opts,
+			})
+			return results.GetCheckRuns(), resp, err
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list check runs for %s: %w", ref, err)
+	}
+
+	statuses, err := fetchAllPaginated(ctx,
+		func(ctx context.Context, opts *github.ListOptions) ([]*github.RepoStatus, *github.Response, error) {
+			combined, resp, err := client.Repositories.GetCombinedStatus(ctx, owner, repo, ref, opts)
+			return combined.GetStatuses(), resp, err
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get combined status for %s: %w", ref, err)
+	}
+
+	status := &types.CIStatus{
+		Ref: ref,
+		URL: fmt.Sprintf("%s/commit/%s/checks", repoURL, ref),
+	}
+	for _, run := range checkRuns {
+		status.Checks = append(status.Checks, types.CICheck{
+			Name:  run.GetName(),
+			State: checkRunState(run.GetStatus(), run.GetConclusion()),
+			URL:   run.GetHTMLURL(),
+		})
+	}
+	for _, commitStatus := range statuses {
+		status.Checks = append(status.Checks, types.CICheck{
+			Name:  commitStatus.GetContext(),
+			State: commitStatusState(commitStatus.GetState()),
+			URL:   commitStatus.GetTargetURL(),
+		})
+	}
+
+	slog.Debug("Fetched CI status", "owner", owner, "repo", repo, "ref", ref, "check_runs", len(checkRuns), "statuses", len(statuses))
+	return status, nil
+}
+
+// checkRunState maps a check run's status and conclusion to a CI state
+func checkRunState(status, conclusion string) string {
+	if status != "completed" {
+		return ci.StatePending
+	}
+	switch conclusion {
+	case "success", "neutral":
+		return ci.StateSuccess
+	case "skipped":
+		return ci.StateSkipped
+	case "stale":
+		return ci.StatePending
+	default: // failure, cancelled, timed_out, action_required, startup_failure
+		return ci.StateFailed
+	}
+}
+
+// commitStatusState maps a commit status state to a CI state
+func commitStatusState(state string) string {
+	switch state {
+	case "success":
+		return ci.StateSuccess
+	case "pending":
+		return ci.StatePending
+	default: // failure, error
+		return ci.StateFailed
+	}
+}
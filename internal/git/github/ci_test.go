@@ -0,0 +1,92 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v90/github"
+	"release-confidence-score/internal/analysis/ci"
+)
+
+func TestCheckRunState(t *testing.T) {
+	tests := []struct {
+		status     string
+		conclusion string
+		expected   string
+	}{
+		{"completed", "success", ci.StateSuccess},
+		{"completed", "neutral", ci.StateSuccess},
+		{"completed", "skipped", ci.StateSkipped},
+		{"completed", "failure", ci.StateFailed},
+		{"completed", "cancelled", ci.StateFailed},
+		{"completed", "timed_out", ci.StateFailed},
+		{"completed", "action_required", ci.StateFailed},
+		{"completed", "stale", ci.StatePending},
+		{"in_progress", "", ci.StatePending},
+		{"queued", "", ci.StatePending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status+"/"+tt.conclusion, func(t *testing.T) {
+			if got := checkRunState(tt.status, tt.conclusion); got != tt.expected {
+				t.Errorf("checkRunState(%q, %q) = %q, want %q", tt.status, tt.conclusion, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFetchCIStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/org/api/commits/abc123/check-runs":
+			if r.URL.Query().Get("filter") != "latest" {
+				t.Errorf("expected only the latest check runs to be requested, got %q", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"total_count": 2, "check_runs": [
+				{"name": "build", "status": "completed", "conclusion": "success", "html_url": "https://github.com/org/api/runs/1"},
+				{"name": "unit-tests", "status": "completed", "conclusion": "failure", "html_url": "https://github.com/org/api/runs/2"}
+			]}`))
+		case "/repos/org/api/commits/abc123/status":
+			_, _ = w.Write([]byte(`{"state": "pending", "statuses": [
+				{"context": "ci/jenkins", "state": "pending", "target_url": "https://jenkins.example.com/job/1"}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := github.NewClient(github.WithURLs(github.Ptr(server.URL+"/"), nil))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	status, err := fetchCIStatus(context.Background(), client, "org", "api", "abc123", "https://github.com/org/api")
+	if err != nil {
+		t.Fatalf("fetchCIStatus() error = %v", err)
+	}
+	if status.Ref != "abc123" || status.URL != "https://github.com/org/api/commit/abc123/checks" {
+		t.Errorf("unexpected ref or URL: %+v", status)
+	}
+
+	var checks []string
+	for _, check := range status.Checks {
+		checks = append(checks, check.Name+" "+check.State+" "+check.URL)
+	}
+	expected := []string{
+		"build success https://github.com/org/api/runs/1",
+		"unit-tests failed https://github.com/org/api/runs/2",
+		"ci/jenkins pending https://jenkins.example.com/job/1",
+	}
+	if strings.Join(checks, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Checks =\n%s\nwant\n%s", strings.Join(checks, "\n"), strings.Join(expected, "\n"))
+	}
+
+	if _, err := fetchCIStatus(context.Background(), client, "org", "missing", "abc123", "https://github.com/org/missing"); err == nil {
+		t.Error("expected an error for a repository without CI data")
+	}
+}
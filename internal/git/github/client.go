@@ -1,10 +1,62 @@
 package github
 
 import (
+	"fmt"
+
 	"github.com/google/go-github/v90/github"
 	"release-confidence-score/internal/config"
+	httputil "release-confidence-score/internal/http"
 )
 
-func NewClient(cfg *config.Config) (*github.Client, error) {
-	return github.NewClient(github.WithAuthToken(cfg.GitHubToken))
+// publicHost is the host of github.com compare URLs, served by the public API
+const publicHost = "github.com"
+
+// NewClients creates a client for github.com, when RCS_GITHUB_TOKEN is set, and one for each GitHub Enterprise Server instance
+// Clients are keyed by the lowercased hostname, without port, of the compare URLs they serve, and each only ever receives its own instance's token
+func NewClients(cfg *config.Config) (map[string]*github.Client, error) {
+	clients := map[string]*github.Client{}
+	if cfg.GitHubToken != "" {
+		public, err := github.NewClient(github.WithAuthToken(cfg.GitHubToken))
+		if err != nil {
+			return nil, err
+		}
+		clients[publicHost] = public
+	}
+
+	for _, instance := range cfg.GitHubInstances {
+		client, err := newEnterpriseClient(instance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for %s: %w", instance.BaseURL, err)
+		}
+		clients[config.Hostname(instance.BaseURL)] = client
+	}
+	return clients, nil
+}
+
+// newEnterpriseClient creates a client for a single GitHub Enterprise Server instance
+// The REST API lives under /api/v3/ and uploads under /api/uploads/ of the instance's web URL
+func newEnterpriseClient(instance config.GitHubInstance) (*github.Client, error) {
+	uploadURL := instance.UploadURL
+	if uploadURL == "" {
+		uploadURL = instance.BaseURL
+	}
+
+	opts := []github.ClientOptionsFunc{
+		github.WithAuthToken(instance.Token),
+		github.WithEnterpriseURLs(instance.BaseURL, uploadURL),
+	}
+
+	if instance.SkipSSLVerify || instance.CACertFile != "" {
+		httpOpts := httputil.HTTPClientOptions{SkipSSLVerify: instance.SkipSSLVerify}
+		if instance.CACertFile != "" {
+			pool, err := httputil.LoadCACertPool(instance.CACertFile)
+			if err != nil {
+				return nil, err
+			}
+			httpOpts.RootCAs = pool
+		}
+		opts = append(opts, github.WithHTTPClient(httputil.NewHTTPClient(httpOpts)))
+	}
+
+	return github.NewClient(opts...)
 }
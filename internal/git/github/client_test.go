@@ -0,0 +1,250 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	githubapi "github.com/google/go-github/v90/github"
+	"release-confidence-score/internal/config"
+)
+
+func TestNewClients(t *testing.T) {
+	clients, err := NewClients(&config.Config{
+		GitHubToken: "token",
+		GitHubInstances: []config.GitHubInstance{
+			{Name: "example", BaseURL: "https://GitHub.Example.com", Token: "example-token"},
+			{Name: "internal", BaseURL: "https://ghe.internal:8443/", Token: "internal-token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClients() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"github.com":         "https://api.github.com/",
+		"github.example.com": "https://GitHub.Example.com/api/v3/",
+		"ghe.internal":       "https://ghe.internal:8443/api/v3/",
+	}
+	if len(clients) != len(expected) {
+		t.Errorf("NewClients() created %d clients, want %d", len(clients), len(expected))
+	}
+	for host, baseURL := range expected {
+		client, exists := clients[host]
+		if !exists {
+			t.Errorf("NewClients() missing client for %s", host)
+			continue
+		}
+		if client.BaseURL() != baseURL {
+			t.Errorf("client for %s has base URL %s, want %s", host, client.BaseURL(), baseURL)
+		}
+	}
+}
+
+func TestNewClients_WithoutPublicToken(t *testing.T) {
+	cfg := &config.Config{
+		GitHubInstances: []config.GitHubInstance{{Name: "example", BaseURL: "https://github.example.com", Token: "example-token"}},
+	}
+	clients, err := NewClients(cfg)
+	if err != nil {
+		t.Fatalf("NewClients() error = %v", err)
+	}
+	if _, exists := clients[publicHost]; exists || len(clients) != 1 {
+		t.Errorf("NewClients() = %v, want only the github.example.com client", clients)
+	}
+
+	_, _, _, err = NewFetcher(clients, cfg).FetchReleaseData(context.Background(), "https://github.com/org/api/compare/v1...v2")
+	if err == nil || !strings.Contains(err.Error(), "no GitHub client configured for host github.com") {
+		t.Errorf("FetchReleaseData() error = %v, want a missing client error", err)
+	}
+}
+
+func TestNewClients_UploadURL(t *testing.T) {
+	clients, err := NewClients(&config.Config{
+		GitHubInstances: []config.GitHubInstance{
+			{Name: "example", BaseURL: "https://github.example.com", UploadURL: "https://uploads.github.example.com", Token: "example-token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClients() error = %v", err)
+	}
+
+	if got := clients["github.example.com"].UploadURL(); got != "https://uploads.github.example.com/" {
+		t.Errorf("UploadURL = %s, want https://uploads.github.example.com/", got)
+	}
+}
+
+func TestNewClients_InvalidCACertFile(t *testing.T) {
+	_, err := NewClients(&config.Config{
+		GitHubInstances: []config.GitHubInstance{
+			{Name: "example", BaseURL: "https://github.example.com", Token: "example-token", CACertFile: filepath.Join(t.TempDir(), "missing.pem")},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to read CA bundle") {
+		t.Errorf("NewClients() error = %v, want a CA bundle error", err)
+	}
+}
+
+// TestFetchReleaseData_EnterpriseServer fetches a release from a GitHub Enterprise Server stand-in
+// that serves its API under /api/v3/ with a certificate from a private CA
+func TestFetchReleaseData_EnterpriseServer(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ghes-token" {
+			t.Errorf("expected the GitHub token on %s, got %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/org/api":
+			_, _ = w.Write([]byte(`{"default_branch": "main"}`))
+		case "/api/v3/repos/org/api/compare/v1.0.0...v1.1.0":
+			_, _ = w.Write([]byte(`{
+				"total_commits": 1,
+				"commits": [{"sha": "abc1234567890", "commit": {"message": "Add endpoint\n\nTested-by: QE", "author": {"name": "Alice"}}}],
+				"files": [{"filename": "main.go", "status": "modified", "additions": 2, "deletions": 1, "changes": 3, "patch": "@@ -1 +1,2 @@\n-a\n+b\n+c"}]
+			}`))
+		case "/api/v3/repos/org/api/commits/abc1234567890/pulls":
+			_, _ = w.Write([]byte(`[]`))
+		case "/api/v3/repos/org/api/contents/.release-confidence.yaml":
+			// The release head tries to drop the check the base ref requires
+			config := "required_checks: [unit-tests]\n"
+			if r.URL.Query().Get("ref") != "v1.0.0" {
+				config = "required_checks: [lint]\n"
+			}
+			_, _ = w.Write([]byte(`{"type": "file", "encoding": "base64", "content": "` + base64.StdEncoding.EncodeToString([]byte(config)) + `"}`))
+		case "/api/v3/repos/org/api/contents/.gitattributes":
+			// The release head tries to hide its own change by marking it generated
+			attributes := "*.pb.go linguist-generated\n"
+			if r.URL.Query().Get("ref") != "v1.0.0" {
+				attributes = "main.go linguist-generated\n"
+			}
+			_, _ = w.Write([]byte(`{"type": "file", "encoding": "base64", "content": "` + base64.StdEncoding.EncodeToString([]byte(attributes)) + `"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+
+	cfg := &config.Config{
+		GitHubToken:     "public-token",
+		GitHubInstances: []config.GitHubInstance{{Name: "ghes", BaseURL: server.URL, Token: "ghes-token", CACertFile: caFile}},
+	}
+	clients, err := NewClients(cfg)
+	if err != nil {
+		t.Fatalf("NewClients() error = %v", err)
+	}
+	fetcher := NewFetcher(clients, cfg)
+
+	compareURL := server.URL + "/org/api/compare/v1.0.0...v1.1.0"
+	if !fetcher.IsCompareURL(compareURL) {
+		t.Fatalf("IsCompareURL(%q) = false, want true", compareURL)
+	}
+
+	comparison, _, _, err := fetcher.FetchReleaseData(context.Background(), compareURL)
+	if err != nil {
+		t.Fatalf("FetchReleaseData() error = %v", err)
+	}
+	if comparison.RepoURL != server.URL+"/org/api" {
+		t.Errorf("RepoURL = %s, want %s/org/api", comparison.RepoURL, server.URL)
+	}
+	if len(comparison.Commits) != 1 || comparison.Commits[0].Message != "Add endpoint" || comparison.Commits[0].Body != "Tested-by: QE" {
+		t.Errorf("Commits = %+v, want the stand-in's commit", comparison.Commits)
+	}
+	if len(comparison.Files) != 1 || comparison.Files[0].Filename != "main.go" {
+		t.Errorf("Files = %+v, want the stand-in's file", comparison.Files)
+	} else if comparison.Files[0].Generated != "" || comparison.Files[0].Patch != "@@ -1 +1,2 @@\n-a\n+b\n+c" {
+		t.Errorf("Files[0] = %+v, want the raw patch under the base ref's .gitattributes", comparison.Files[0])
+	}
+	if comparison.RepoConfig == nil || len(comparison.RepoConfig.RequiredChecks) != 1 || comparison.RepoConfig.RequiredChecks[0] != "unit-tests" {
+		t.Errorf("RepoConfig = %+v, want the base ref's config", comparison.RepoConfig)
+	}
+}
+
+// TestFetchReleaseData_SendsEachHostItsOwnToken fetches releases from two GitHub Enterprise Server stand-ins
+// and checks each only ever receives its own instance's token, never the other's or the github.com one
+func TestFetchReleaseData_SendsEachHostItsOwnToken(t *testing.T) {
+	standIn := func(name, token string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer "+token {
+				t.Errorf("%s received Authorization %q on %s, want its own token", name, r.Header.Get("Authorization"), r.URL.Path)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/api/v3/repos/" + name + "/api":
+				_, _ = w.Write([]byte(`{"default_branch": "main"}`))
+			case "/api/v3/repos/" + name + "/api/compare/v1...v2":
+				_, _ = w.Write([]byte(`{
+					"total_commits": 1,
+					"commits": [{"sha": "abc1234567890", "commit": {"message": "Change ` + name + `", "author": {"name": "Alice"}}}],
+					"files": [{"filename": "main.go", "status": "modified", "additions": 1, "deletions": 1, "changes": 2, "patch": "@@ -1 +1 @@\n-a\n+b"}]
+				}`))
+			case "/api/v3/repos/" + name + "/api/commits/abc1234567890/pulls":
+				_, _ = w.Write([]byte(`[]`))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	}
+	first := standIn("first", "first-token")
+	defer first.Close()
+	second := standIn("second", "second-token")
+	defer second.Close()
+
+	// Reach the second stand-in as localhost, so the two instances have different hosts
+	secondURL := strings.Replace(second.URL, "127.0.0.1", "localhost", 1)
+
+	cfg := &config.Config{
+		GitHubToken: "public-token",
+		GitHubInstances: []config.GitHubInstance{
+			{Name: "first", BaseURL: first.URL, Token: "first-token"},
+			{Name: "second", BaseURL: secondURL, Token: "second-token"},
+		},
+	}
+	clients, err := NewClients(cfg)
+	if err != nil {
+		t.Fatalf("NewClients() error = %v", err)
+	}
+	fetcher := NewFetcher(clients, cfg)
+
+	tests := []struct {
+		compareURL string
+		expected   string
+	}{
+		{first.URL + "/first/api/compare/v1...v2", "Change first"},
+		{secondURL + "/second/api/compare/v1...v2", "Change second"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.compareURL, func(t *testing.T) {
+			comparison, _, _, err := fetcher.FetchReleaseData(context.Background(), tt.compareURL)
+			if err != nil {
+				t.Fatalf("FetchReleaseData() error = %v", err)
+			}
+			if len(comparison.Commits) != 1 || comparison.Commits[0].Message != tt.expected {
+				t.Errorf("Commits = %+v, want %q", comparison.Commits, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFetchReleaseData_UnconfiguredHost(t *testing.T) {
+	fetcher := NewFetcher(map[string]*githubapi.Client{}, &config.Config{})
+
+	_, _, _, err := fetcher.FetchReleaseData(context.Background(), "https://github.other.com/org/api/compare/v1...v2")
+	if err == nil || !strings.Contains(err.Error(), "no GitHub client configured for host github.other.com") {
+		t.Errorf("FetchReleaseData() error = %v, want a missing client error", err)
+	}
+}
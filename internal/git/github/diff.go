@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
 
 	"release-confidence-score/internal/git/shared"
 	"release-confidence-score/internal/git/types"
@@ -29,7 +28,7 @@ func fetchDiff(ctx context.Context, client *github.Client, owner, repo, base, he
 
 	// Initialize comparison with files and stats from GitHub
 	comparison := &types.Comparison{
-		RepoURL: fmt.Sprintf("https://github.com/%s/%s", owner, repo),
+		RepoURL: extractRepoURL(diffURL),
 		DiffURL: diffURL,
 		Commits: make([]types.Commit, len(allCommits)),
 		Files:   convertFiles(ghComparison.Files),
@@ -48,7 +47,9 @@ func fetchDiff(ctx context.Context, client *github.Client, owner, repo, base, he
 	}
 	g.Wait()
 
-	slog.Debug("Commit augmentation complete", "commit_entries", len(comparison.Commits))
+	comparison.PullRequests = cache.pullRequests(comparison.Commits)
+
+	slog.Debug("Commit augmentation complete", "commit_entries", len(comparison.Commits), "pull_requests", len(comparison.PullRequests))
 
 	return comparison, nil
 }
@@ -62,9 +63,10 @@ func buildCommitEntry(ctx context.Context, client *github.Client, commit *github
 		Author:   "Unknown",
 	}
 
-	// Extract commit message (first line only)
-	if msg := commit.GetCommit().GetMessage(); msg != "" {
-		entry.Message = strings.TrimSpace(strings.SplitN(msg, "\n", 2)[0])
+	// Extract commit message, keeping the subject line apart from the body and its trailers
+	if subject, body := shared.SplitCommitMessage(commit.GetCommit().GetMessage()); subject != "" {
+		entry.Message = subject
+		entry.Body = body
 	}
 
 	// Extract author name
@@ -10,26 +10,32 @@ import (
 
 	githubapi "github.com/google/go-github/v90/github"
 	"golang.org/x/sync/errgroup"
+	"release-confidence-score/internal/analysis/contracts"
+	"release-confidence-score/internal/analysis/dependencies"
+	"release-confidence-score/internal/analysis/goapi"
+	"release-confidence-score/internal/analysis/infrastructure"
 	"release-confidence-score/internal/config"
+	"release-confidence-score/internal/git/generated"
 	"release-confidence-score/internal/git/shared"
 	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/repoconfig"
 )
 
-// githubCompareRegex matches GitHub compare URLs and extracts components
+// githubCompareRegex matches GitHub compare URLs on any host and extracts components
 // Refs can be commit SHAs, tags (v1.0.0), or branches (main, feature/foo)
-var githubCompareRegex = regexp.MustCompile(`^https?://github\.com/([^/]+)/([^/]+)/compare/(.+?)\.\.\.([^?#]+)$`)
+var githubCompareRegex = regexp.MustCompile(`^https?://([^/]+)/([^/]+)/([^/]+)/compare/(.+?)\.\.\.([^?#]+)$`)
 
-// Fetcher implements the GitProvider interface for GitHub
+// Fetcher implements the GitProvider interface for GitHub and GitHub Enterprise Server
 type Fetcher struct {
-	client *githubapi.Client
-	config *config.Config
+	clients map[string]*githubapi.Client // Keyed by lowercased hostname, as created by NewClients
+	config  *config.Config
 }
 
 // NewFetcher creates a new GitHub data fetcher
-func NewFetcher(client *githubapi.Client, cfg *config.Config) *Fetcher {
+func NewFetcher(clients map[string]*githubapi.Client, cfg *config.Config) *Fetcher {
 	return &Fetcher{
-		client: client,
-		config: cfg,
+		clients: clients,
+		config:  cfg,
 	}
 }
 
@@ -38,9 +44,13 @@ func (f *Fetcher) Name() string {
 	return "GitHub"
 }
 
-// IsCompareURL checks if a URL is a valid GitHub compare URL
+// IsCompareURL checks if a URL is a valid compare URL on github.com or a configured GitHub Enterprise Server
 func (f *Fetcher) IsCompareURL(url string) bool {
-	return githubCompareRegex.MatchString(url)
+	host, _, _, _, _, err := parseCompareURL(url)
+	if err != nil {
+		return false
+	}
+	return host == publicHost || f.clients[host] != nil
 }
 
 // FetchReleaseData fetches all release data for a GitHub compare URL
@@ -50,12 +60,18 @@ func (f *Fetcher) FetchReleaseData(ctx context.Context, compareURL string) (*typ
 	slog.Debug("Fetching GitHub release data", "url", compareURL)
 
 	// Parse compare URL
-	owner, repo, baseCommit, headCommit, err := parseCompareURL(compareURL)
+	host, owner, repo, baseCommit, headCommit, err := parseCompareURL(compareURL)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to parse GitHub compare URL: %w", err)
 	}
 
-	slog.Debug("Parsed compare URL", "owner", owner, "repo", repo, "base", baseCommit, "head", headCommit)
+	slog.Debug("Parsed compare URL", "host", host, "owner", owner, "repo", repo, "base", baseCommit, "head", headCommit)
+
+	// Each GitHub instance has its own API endpoint, so pick the client for the compare URL's host
+	client, exists := f.clients[host]
+	if !exists {
+		return nil, nil, nil, fmt.Errorf("no GitHub client configured for host %s; set RCS_GITHUB_TOKEN for github.com or add the host to RCS_GITHUB_INSTANCES", host)
+	}
 
 	// Create shared cache to avoid duplicate API calls across operations
 	cache := newPRCache()
@@ -66,16 +82,19 @@ func (f *Fetcher) FetchReleaseData(ctx context.Context, compareURL string) (*typ
 	var comparison *types.Comparison
 	var userGuidance []types.UserGuidance
 	var documentation *types.Documentation
+	var repoConfig *types.RepoConfig
+	var attributes *generated.Attributes
+	var ciStatus *types.CIStatus
 
 	// Fetch diff and user guidance (sequential, as guidance depends on diff)
 	g.Go(func() error {
 		var err error
-		comparison, err = fetchDiff(gCtx, f.client, owner, repo, baseCommit, headCommit, compareURL, cache)
+		comparison, err = fetchDiff(gCtx, client, owner, repo, baseCommit, headCommit, compareURL, cache)
 		if err != nil {
 			return fmt.Errorf("failed to fetch and enrich comparison: %w", err)
 		}
 
-		userGuidance, err = fetchUserGuidance(gCtx, f.client, owner, repo, comparison, cache)
+		userGuidance, err = fetchUserGuidance(gCtx, client, owner, repo, comparison, cache)
 		if err != nil {
 			return fmt.Errorf("failed to fetch user guidance: %w", err)
 		}
@@ -84,7 +103,7 @@ func (f *Fetcher) FetchReleaseData(ctx context.Context, compareURL string) (*typ
 
 	// Fetch documentation (independent, runs in parallel)
 	g.Go(func() error {
-		docSource := newDocumentationSource(f.client, owner, repo)
+		docSource := newDocumentationSource(client, owner, repo)
 		baseRepo := types.Repository{
 			Owner: owner,
 			Name:  repo,
@@ -100,9 +119,46 @@ func (f *Fetcher) FetchReleaseData(ctx context.Context, compareURL string) (*typ
 		return nil
 	})
 
+	// Fetch the repository config as of the base ref, so a release can't relax the risk classes and checks it is judged by
+	g.Go(func() error {
+		repoConfig = repoconfig.Fetch(gCtx, newDocumentationSource(client, owner, repo), extractRepoURL(compareURL), baseCommit)
+		return nil
+	})
+
+	// Fetch .gitattributes as of the base ref to find files marked linguist-generated, so a release can't hide
+	// its own patches from the analysis by marking them generated
+	g.Go(func() error {
+		attributes = generated.Fetch(gCtx, newDocumentationSource(client, owner, repo), extractRepoURL(compareURL), baseCommit)
+		return nil
+	})
+
+	// Fetch the CI status of the release head; a failure only leaves CI out of the analysis
+	g.Go(func() error {
+		var err error
+		ciStatus, err = fetchCIStatus(gCtx, client, owner, repo, headCommit, extractRepoURL(compareURL))
+		if err != nil {
+			slog.Warn("Failed to fetch CI status", "repo", extractRepoURL(compareURL), "ref", headCommit, "error", err)
+		}
+		return nil
+	})
+
 	if err := g.Wait(); err != nil {
 		return nil, nil, nil, err
 	}
+	comparison.RepoConfig = repoConfig
+	comparison.CI = ciStatus
+
+	// Dependency changes are parsed from the raw patches before lockfile patches are summarized
+	dependencies.Annotate(comparison.Files)
+
+	// Lockfile, vendored and generated patches are summarized; their line counts still count in the stats
+	generated.Summarize(comparison.Files, attributes)
+
+	// Kubernetes manifests, Helm values, API specs and Go packages are compared at both refs, since their raw patches lose the surrounding context
+	docSource := newDocumentationSource(client, owner, repo)
+	infrastructure.Annotate(ctx, docSource, comparison.Files, baseCommit, headCommit)
+	contracts.Annotate(ctx, docSource, comparison.Files, baseCommit, headCommit)
+	goapi.Annotate(ctx, docSource, comparison.Files, baseCommit, headCommit)
 
 	slog.Debug("Release data fetched successfully",
 		"commit_entries", len(comparison.Commits),
@@ -113,16 +169,16 @@ func (f *Fetcher) FetchReleaseData(ctx context.Context, compareURL string) (*typ
 	return comparison, userGuidance, documentation, nil
 }
 
-// parseCompareURL extracts owner, repo, baseCommit, and headCommit from GitHub compare URL
-// Returns: owner, repo, base commit SHA, head commit SHA, error
-func parseCompareURL(compareURL string) (owner, repo, baseCommit, headCommit string, err error) {
+// parseCompareURL extracts host, owner, repo, baseCommit, and headCommit from GitHub compare URL
+// Returns: lowercased hostname without port, owner, repo, base commit SHA, head commit SHA, error
+func parseCompareURL(compareURL string) (host, owner, repo, baseCommit, headCommit string, err error) {
 	// Parse: https://github.com/owner/repo/compare/sha1...sha2
 	matches := githubCompareRegex.FindStringSubmatch(compareURL)
-	if len(matches) != 5 {
-		return "", "", "", "", fmt.Errorf("invalid GitHub compare URL format: %s", compareURL)
+	if len(matches) != 6 {
+		return "", "", "", "", "", fmt.Errorf("invalid GitHub compare URL format: %s", compareURL)
 	}
 
-	return matches[1], matches[2], matches[3], matches[4], nil
+	return config.Hostname(compareURL), matches[2], matches[3], matches[4], matches[5], nil
 }
 
 // extractRepoURL extracts the repository URL from a compare URL
@@ -171,3 +227,26 @@ func (c *prCache) getOrFetchPR(ctx context.Context, client *githubapi.Client, ow
 	c.mu.Unlock()
 	return pr, nil
 }
+
+// pullRequests returns the cached PRs the commits were merged through, in order of first appearance
+func (c *prCache) pullRequests(commits []types.Commit) []types.PullRequest {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var prs []types.PullRequest
+	seen := map[int64]bool{}
+	for _, commit := range commits {
+		pr := c.prs[int(commit.PRNumber)]
+		if pr == nil || seen[commit.PRNumber] {
+			continue
+		}
+		seen[commit.PRNumber] = true
+		prs = append(prs, types.PullRequest{
+			Number:      commit.PRNumber,
+			Title:       pr.GetTitle(),
+			Description: strings.TrimSpace(pr.GetBody()),
+			URL:         pr.GetHTMLURL(),
+		})
+	}
+	return prs
+}
@@ -1,9 +1,15 @@
 package github
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+
+	githubapi "github.com/google/go-github/v90/github"
+	"release-confidence-score/internal/git/types"
+)
 
 func TestIsCompareURL(t *testing.T) {
-	f := &Fetcher{}
+	f := &Fetcher{clients: map[string]*githubapi.Client{"github.example.com": {}}}
 
 	tests := []struct {
 		name string
@@ -41,6 +47,21 @@ func TestIsCompareURL(t *testing.T) {
 			url:  "https://github.com/owner/repo/compare/abc123def...v1.0.0",
 			want: true,
 		},
+		{
+			name: "configured GitHub Enterprise Server",
+			url:  "https://github.example.com/owner/repo/compare/v1.0.0...v2.0.0",
+			want: true,
+		},
+		{
+			name: "GitHub Enterprise Server URL with explicit port",
+			url:  "https://github.example.com:443/owner/repo/compare/v1.0.0...v2.0.0",
+			want: true,
+		},
+		{
+			name: "GitHub Enterprise Server host is case-insensitive",
+			url:  "https://GitHub.Example.com/owner/repo/compare/v1.0.0...v2.0.0",
+			want: true,
+		},
 
 		// Invalid URLs
 		{
@@ -48,6 +69,16 @@ func TestIsCompareURL(t *testing.T) {
 			url:  "https://gitlab.com/owner/repo/compare/v1.0.0...v2.0.0",
 			want: false,
 		},
+		{
+			name: "unconfigured GitHub Enterprise Server",
+			url:  "https://github.other.com/owner/repo/compare/v1.0.0...v2.0.0",
+			want: false,
+		},
+		{
+			name: "GitLab compare URL",
+			url:  "https://github.example.com/group/repo/-/compare/v1.0.0...v2.0.0",
+			want: false,
+		},
 		{
 			name: "missing compare path",
 			url:  "https://github.com/owner/repo/v1.0.0...v2.0.0",
@@ -84,6 +115,7 @@ func TestParseCompareURL(t *testing.T) {
 	tests := []struct {
 		name      string
 		url       string
+		wantHost  string
 		wantOwner string
 		wantRepo  string
 		wantBase  string
@@ -93,6 +125,7 @@ func TestParseCompareURL(t *testing.T) {
 		{
 			name:      "SHA refs",
 			url:       "https://github.com/owner/repo/compare/abc123...def456",
+			wantHost:  "github.com",
 			wantOwner: "owner",
 			wantRepo:  "repo",
 			wantBase:  "abc123",
@@ -101,6 +134,7 @@ func TestParseCompareURL(t *testing.T) {
 		{
 			name:      "version tags",
 			url:       "https://github.com/google/go-github/compare/v79.0.0...v80.0.0",
+			wantHost:  "github.com",
 			wantOwner: "google",
 			wantRepo:  "go-github",
 			wantBase:  "v79.0.0",
@@ -109,11 +143,30 @@ func TestParseCompareURL(t *testing.T) {
 		{
 			name:      "branch with hyphen",
 			url:       "https://github.com/org/my-repo/compare/main...feature-branch",
+			wantHost:  "github.com",
 			wantOwner: "org",
 			wantRepo:  "my-repo",
 			wantBase:  "main",
 			wantHead:  "feature-branch",
 		},
+		{
+			name:      "GitHub Enterprise Server",
+			url:       "https://GitHub.Example.com/org/repo/compare/v1.0.0...v1.1.0",
+			wantHost:  "github.example.com",
+			wantOwner: "org",
+			wantRepo:  "repo",
+			wantBase:  "v1.0.0",
+			wantHead:  "v1.1.0",
+		},
+		{
+			name:      "GitHub Enterprise Server with explicit port",
+			url:       "https://github.example.com:443/org/repo/compare/v1.0.0...v1.1.0",
+			wantHost:  "github.example.com",
+			wantOwner: "org",
+			wantRepo:  "repo",
+			wantBase:  "v1.0.0",
+			wantHead:  "v1.1.0",
+		},
 		{
 			name:    "invalid URL",
 			url:     "https://github.com/owner/repo/pulls",
@@ -123,7 +176,7 @@ func TestParseCompareURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			owner, repo, base, head, err := parseCompareURL(tt.url)
+			host, owner, repo, base, head, err := parseCompareURL(tt.url)
 
 			if tt.wantErr {
 				if err == nil {
@@ -136,6 +189,9 @@ func TestParseCompareURL(t *testing.T) {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
+			if host != tt.wantHost {
+				t.Errorf("host = %q, want %q", host, tt.wantHost)
+			}
 			if owner != tt.wantOwner {
 				t.Errorf("owner = %q, want %q", owner, tt.wantOwner)
 			}
@@ -184,3 +240,19 @@ func TestExtractRepoURL(t *testing.T) {
 		})
 	}
 }
+
+func TestPullRequests(t *testing.T) {
+	cache := newPRCache()
+	cache.prs[12] = &githubapi.PullRequest{Title: githubapi.Ptr("Add feature"), Body: githubapi.Ptr("Rollout plan\n"), HTMLURL: githubapi.Ptr("https://github.com/org/repo/pull/12")}
+	cache.prs[15] = &githubapi.PullRequest{Title: githubapi.Ptr("Fix typo")}
+
+	commits := []types.Commit{{PRNumber: 15}, {PRNumber: 0}, {PRNumber: 12}, {PRNumber: 15}, {PRNumber: 99}}
+	expected := []types.PullRequest{
+		{Number: 15, Title: "Fix typo"},
+		{Number: 12, Title: "Add feature", Description: "Rollout plan", URL: "https://github.com/org/repo/pull/12"},
+	}
+
+	if got := cache.pullRequests(commits); !reflect.DeepEqual(got, expected) {
+		t.Errorf("pullRequests() = %+v, want %+v", got, expected)
+	}
+}
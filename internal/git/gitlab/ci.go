@@ -0,0 +1,86 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"release-confidence-score/internal/analysis/ci"
+	"release-confidence-score/internal/git/types"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go/v2"
+)
+
+// fetchCIStatus fetches the jobs of the latest pipeline that ran for a ref
+// A ref without a pipeline yields a status without checks
+func fetchCIStatus(ctx context.Context, client *gitlab.Client, projectPath, ref string) (*types.CIStatus, error) {
+	commit, _, err := client.Commits.GetCommit(projectPath, ref, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", ref, err)
+	}
+
+	status := &types.CIStatus{Ref: ref}
+	pipeline := commit.LastPipeline
+	if pipeline == nil {
+		slog.Debug("No pipeline found for ref", "project", projectPath, "ref", ref)
+		return status, nil
+	}
+	status.URL = pipeline.WebURL
+
+	opts := &gitlab.ListJobsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: 100,
+			Page:    1,
+		},
+	}
+
+	var jobs []*gitlab.Job
+	for {
+		page, resp, err := client.Jobs.ListPipelineJobs(projectPath, pipeline.ID, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs of pipeline %d: %w", pipeline.ID, err)
+		}
+
+		jobs = append(jobs, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	for _, job := range jobs {
+		status.Checks = append(status.Checks, types.CICheck{
+			Name:         job.Name,
+			State:        jobState(job.Status),
+			AllowFailure: job.AllowFailure,
+			URL:          job.WebURL,
+		})
+	}
+
+	// Pipelines whose jobs aren't visible still report their own status
+	if len(status.Checks) == 0 {
+		status.Checks = append(status.Checks, types.CICheck{
+			Name:  "pipeline",
+			State: jobState(pipeline.Status),
+			URL:   pipeline.WebURL,
+		})
+	}
+
+	slog.Debug("Fetched CI status", "project", projectPath, "ref", ref, "pipeline", pipeline.ID, "jobs", len(jobs))
+	return status, nil
+}
+
+// jobState maps a GitLab job or pipeline status to a CI state
+func jobState(status string) string {
+	switch status {
+	case "success":
+		return ci.StateSuccess
+	case "failed", "canceled", "canceling":
+		return ci.StateFailed
+	case "skipped", "manual":
+		return ci.StateSkipped
+	default: // created, waiting_for_resource, preparing, pending, running, scheduled
+		return ci.StatePending
+	}
+}
@@ -0,0 +1,96 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/analysis/ci"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go/v2"
+)
+
+func TestJobState(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected string
+	}{
+		{"success", ci.StateSuccess},
+		{"failed", ci.StateFailed},
+		{"canceled", ci.StateFailed},
+		{"skipped", ci.StateSkipped},
+		{"manual", ci.StateSkipped},
+		{"running", ci.StatePending},
+		{"created", ci.StatePending},
+		{"waiting_for_resource", ci.StatePending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := jobState(tt.status); got != tt.expected {
+				t.Errorf("jobState(%q) = %q, want %q", tt.status, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFetchCIStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fapi/repository/commits/v2":
+			_, _ = w.Write([]byte(`{"id": "abc123", "last_pipeline": {"id": 42, "status": "failed", "web_url": "https://gitlab.com/group/api/-/pipelines/42"}}`))
+		case "/api/v4/projects/group%2Fapi/pipelines/42/jobs":
+			_, _ = w.Write([]byte(`[
+				{"name": "build", "status": "success", "web_url": "https://gitlab.com/group/api/-/jobs/1"},
+				{"name": "lint", "status": "failed", "allow_failure": true, "web_url": "https://gitlab.com/group/api/-/jobs/2"},
+				{"name": "deploy", "status": "manual", "web_url": "https://gitlab.com/group/api/-/jobs/3"}
+			]`))
+		case "/api/v4/projects/group%2Fdocs/repository/commits/v2":
+			_, _ = w.Write([]byte(`{"id": "def456"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	status, err := fetchCIStatus(context.Background(), client, "group/api", "v2")
+	if err != nil {
+		t.Fatalf("fetchCIStatus() error = %v", err)
+	}
+	if status.Ref != "v2" || status.URL != "https://gitlab.com/group/api/-/pipelines/42" {
+		t.Errorf("unexpected ref or URL: %+v", status)
+	}
+
+	var checks []string
+	for _, check := range status.Checks {
+		line := check.Name + " " + check.State
+		if check.AllowFailure {
+			line += " (allowed to fail)"
+		}
+		checks = append(checks, line)
+	}
+	expected := []string{"build success", "lint failed (allowed to fail)", "deploy skipped"}
+	if strings.Join(checks, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Checks =\n%s\nwant\n%s", strings.Join(checks, "\n"), strings.Join(expected, "\n"))
+	}
+
+	status, err = fetchCIStatus(context.Background(), client, "group/docs", "v2")
+	if err != nil {
+		t.Fatalf("fetchCIStatus() error = %v", err)
+	}
+	if len(status.Checks) != 0 {
+		t.Errorf("expected no checks without a pipeline, got %+v", status.Checks)
+	}
+
+	if _, err := fetchCIStatus(context.Background(), client, "group/missing", "v2"); err == nil {
+		t.Error("expected an error for an unknown project")
+	}
+}
@@ -1,18 +1,50 @@
 package gitlab
 
 import (
+	"fmt"
+
 	"gitlab.com/gitlab-org/api/client-go/v2"
 	"release-confidence-score/internal/config"
 	httputil "release-confidence-score/internal/http"
 )
 
+// NewClient creates a client for the GitLab instance at RCS_GITLAB_BASE_URL, which hosts app-interface
 func NewClient(cfg *config.Config) (*gitlab.Client, error) {
-	if cfg.GitLabSkipSSLVerify {
-		httpClient := httputil.NewHTTPClient(httputil.HTTPClientOptions{
-			SkipSSLVerify: true,
-		})
-		return gitlab.NewClient(cfg.GitLabToken, gitlab.WithBaseURL(cfg.GitLabBaseURL), gitlab.WithHTTPClient(httpClient))
+	return newInstanceClient(config.GitLabInstance{
+		BaseURL:       cfg.GitLabBaseURL,
+		Token:         cfg.GitLabToken,
+		SkipSSLVerify: cfg.GitLabSkipSSLVerify,
+		CACertFile:    cfg.GitLabCACertFile,
+	})
+}
+
+// NewClients creates a client for each configured GitLab instance, keyed by lowercased hostname
+// Each client only ever receives its own instance's token
+func NewClients(cfg *config.Config) (map[string]*gitlab.Client, error) {
+	clients := map[string]*gitlab.Client{}
+	for host, instance := range cfg.GitLabHosts() {
+		client, err := newInstanceClient(instance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for %s: %w", host, err)
+		}
+		clients[host] = client
+	}
+	return clients, nil
+}
+
+// newInstanceClient creates a client for a single GitLab instance
+func newInstanceClient(instance config.GitLabInstance) (*gitlab.Client, error) {
+	if instance.SkipSSLVerify || instance.CACertFile != "" {
+		httpOpts := httputil.HTTPClientOptions{SkipSSLVerify: instance.SkipSSLVerify}
+		if instance.CACertFile != "" {
+			pool, err := httputil.LoadCACertPool(instance.CACertFile)
+			if err != nil {
+				return nil, err
+			}
+			httpOpts.RootCAs = pool
+		}
+		return gitlab.NewClient(instance.Token, gitlab.WithBaseURL(instance.BaseURL), gitlab.WithHTTPClient(httputil.NewHTTPClient(httpOpts)))
 	}
 
-	return gitlab.NewClient(cfg.GitLabToken, gitlab.WithBaseURL(cfg.GitLabBaseURL))
+	return gitlab.NewClient(instance.Token, gitlab.WithBaseURL(instance.BaseURL))
 }
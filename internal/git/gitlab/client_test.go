@@ -0,0 +1,147 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gitlabapi "gitlab.com/gitlab-org/api/client-go/v2"
+	"release-confidence-score/internal/config"
+)
+
+func TestNewClients(t *testing.T) {
+	clients, err := NewClients(&config.Config{
+		GitLabBaseURL: "https://GitLab.Corp.Example.com",
+		GitLabToken:   "corp-token",
+		GitLabInstances: []config.GitLabInstance{
+			{Name: "public", BaseURL: "https://gitlab.com", Token: "public-token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClients() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"gitlab.corp.example.com": "https://GitLab.Corp.Example.com/api/v4/",
+		"gitlab.com":              "https://gitlab.com/api/v4/",
+	}
+	if len(clients) != len(expected) {
+		t.Errorf("NewClients() created %d clients, want %d", len(clients), len(expected))
+	}
+	for host, baseURL := range expected {
+		client, exists := clients[host]
+		if !exists {
+			t.Errorf("NewClients() missing client for %s", host)
+			continue
+		}
+		if client.BaseURL().String() != baseURL {
+			t.Errorf("client for %s has base URL %s, want %s", host, client.BaseURL(), baseURL)
+		}
+	}
+}
+
+func TestNewClients_InvalidCACertFile(t *testing.T) {
+	_, err := NewClients(&config.Config{
+		GitLabBaseURL: "https://gitlab.corp.example.com",
+		GitLabInstances: []config.GitLabInstance{
+			{Name: "public", BaseURL: "https://gitlab.com", Token: "public-token", CACertFile: filepath.Join(t.TempDir(), "missing.pem")},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to create client for gitlab.com") {
+		t.Errorf("NewClients() error = %v, want a CA bundle error for gitlab.com", err)
+	}
+}
+
+// TestFetchReleaseData_PicksInstanceByHost fetches releases from two GitLab stand-ins
+// and checks each API only sees its own compare URLs and token
+func TestFetchReleaseData_PicksInstanceByHost(t *testing.T) {
+	standIn := func(name, token string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("PRIVATE-TOKEN") != token {
+				t.Errorf("%s received token %q on %s, want %q", name, r.Header.Get("PRIVATE-TOKEN"), r.URL.Path, token)
+			}
+
+			// Only the endpoint matters here; the project segment is checked through the commit message
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/repository/compare"):
+				_, _ = w.Write([]byte(`{
+					"commits": [{"id": "abc1234567890", "short_id": "abc1234", "message": "Change ` + name + `", "author_name": "Alice"}],
+					"diffs": [{"new_path": "main.go", "old_path": "main.go", "diff": "@@ -1 +1 @@\n-a\n+b"}]
+				}`))
+			case strings.HasSuffix(r.URL.Path, "/merge_requests"):
+				_, _ = w.Write([]byte(`[]`))
+			case strings.HasSuffix(r.URL.Path, "/repository/files/.gitattributes"):
+				// The release head tries to hide its own change by marking it generated
+				attributes := "*.pb.go linguist-generated\n"
+				if r.URL.Query().Get("ref") != "v1" {
+					attributes = "main.go linguist-generated\n"
+				}
+				_, _ = w.Write([]byte(`{"encoding": "base64", "content": "` + base64.StdEncoding.EncodeToString([]byte(attributes)) + `"}`))
+			case !strings.Contains(r.URL.Path, "/repository/"):
+				_, _ = w.Write([]byte(`{"default_branch": "main"}`))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	}
+	internal := standIn("internal", "internal-token")
+	defer internal.Close()
+	public := standIn("public", "public-token")
+	defer public.Close()
+
+	// Reach the second stand-in as localhost, so the two instances have different hostnames
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(public.URL, "http://"))
+	if err != nil {
+		t.Fatalf("failed to split public server address: %v", err)
+	}
+	publicURL := "http://localhost:" + port
+
+	cfg := &config.Config{
+		GitLabBaseURL:   internal.URL,
+		GitLabToken:     "internal-token",
+		GitLabInstances: []config.GitLabInstance{{Name: "public", BaseURL: publicURL, Token: "public-token"}},
+	}
+	clients, err := NewClients(cfg)
+	if err != nil {
+		t.Fatalf("NewClients() error = %v", err)
+	}
+	fetcher := NewFetcher(clients, cfg)
+
+	tests := []struct {
+		compareURL string
+		expected   string
+	}{
+		{internal.URL + "/internal/api/-/compare/v1...v2", "Change internal"},
+		{publicURL + "/public/api/-/compare/v1...v2", "Change public"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.compareURL, func(t *testing.T) {
+			comparison, _, _, err := fetcher.FetchReleaseData(context.Background(), tt.compareURL)
+			if err != nil {
+				t.Fatalf("FetchReleaseData() error = %v", err)
+			}
+			if len(comparison.Commits) != 1 || comparison.Commits[0].Message != tt.expected {
+				t.Errorf("Commits = %+v, want %q", comparison.Commits, tt.expected)
+			}
+			if len(comparison.Files) != 1 || comparison.Files[0].Generated != "" {
+				t.Errorf("Files = %+v, want the raw patch under the base ref's .gitattributes", comparison.Files)
+			}
+		})
+	}
+}
+
+func TestFetchReleaseData_UnconfiguredHost(t *testing.T) {
+	fetcher := NewFetcher(map[string]*gitlabapi.Client{}, &config.Config{})
+
+	_, _, _, err := fetcher.FetchReleaseData(context.Background(), "https://gitlab.other.com/group/api/-/compare/v1...v2")
+	if err == nil || !strings.Contains(err.Error(), "no GitLab instance configured for host gitlab.other.com") {
+		t.Errorf("FetchReleaseData() error = %v, want a missing instance error", err)
+	}
+}
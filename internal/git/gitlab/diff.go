@@ -60,7 +60,9 @@ func fetchDiff(ctx context.Context, client *gitlab.Client, host, projectPath, ba
 	}
 	g.Wait()
 
-	slog.Debug("Commit augmentation complete", "commit_entries", len(comparison.Commits))
+	comparison.PullRequests = cache.pullRequests(comparison.Commits)
+
+	slog.Debug("Commit augmentation complete", "commit_entries", len(comparison.Commits), "merge_requests", len(comparison.PullRequests))
 
 	return comparison, nil
 }
@@ -74,9 +76,10 @@ func buildCommitEntry(ctx context.Context, client *gitlab.Client, commit *gitlab
 		Author:   "Unknown",
 	}
 
-	// Extract commit message (first line only)
-	if commit.Message != "" {
-		entry.Message = strings.TrimSpace(strings.SplitN(commit.Message, "\n", 2)[0])
+	// Extract commit message, keeping the subject line apart from the body and its trailers
+	if subject, body := shared.SplitCommitMessage(commit.Message); subject != "" {
+		entry.Message = subject
+		entry.Body = body
 	}
 
 	// Extract author name
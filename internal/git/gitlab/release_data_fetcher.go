@@ -9,9 +9,15 @@ import (
 	"strings"
 	"sync"
 
+	"release-confidence-score/internal/analysis/contracts"
+	"release-confidence-score/internal/analysis/dependencies"
+	"release-confidence-score/internal/analysis/goapi"
+	"release-confidence-score/internal/analysis/infrastructure"
 	"release-confidence-score/internal/config"
+	"release-confidence-score/internal/git/generated"
 	"release-confidence-score/internal/git/shared"
 	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/repoconfig"
 
 	"golang.org/x/sync/errgroup"
 
@@ -26,15 +32,15 @@ var gitlabCompareRegex = regexp.MustCompile(`^https?://([^/]+)/(.+)/-/compare/(.
 
 // Fetcher implements the GitProvider interface for GitLab
 type Fetcher struct {
-	client *gitlabapi.Client
-	config *config.Config
+	clients map[string]*gitlabapi.Client // Keyed by lowercased hostname, as created by NewClients
+	config  *config.Config
 }
 
 // NewFetcher creates a new GitLab data fetcher
-func NewFetcher(client *gitlabapi.Client, cfg *config.Config) *Fetcher {
+func NewFetcher(clients map[string]*gitlabapi.Client, cfg *config.Config) *Fetcher {
 	return &Fetcher{
-		client: client,
-		config: cfg,
+		clients: clients,
+		config:  cfg,
 	}
 }
 
@@ -62,6 +68,12 @@ func (f *Fetcher) FetchReleaseData(ctx context.Context, compareURL string) (*typ
 
 	slog.Debug("Parsed compare URL", "host", host, "project", projectPath, "base", baseCommit, "head", headCommit)
 
+	// Each GitLab instance has its own API endpoint and token, so pick the client for the compare URL's host
+	client, exists := f.clients[config.Hostname(compareURL)]
+	if !exists {
+		return nil, nil, nil, fmt.Errorf("no GitLab instance configured for host %s; set RCS_GITLAB_BASE_URL or add it to RCS_GITLAB_INSTANCES", host)
+	}
+
 	// URL-encode project path for API calls
 	encodedPath := urlEncodeProjectPath(projectPath)
 
@@ -74,16 +86,19 @@ func (f *Fetcher) FetchReleaseData(ctx context.Context, compareURL string) (*typ
 	var comparison *types.Comparison
 	var userGuidance []types.UserGuidance
 	var documentation *types.Documentation
+	var repoConfig *types.RepoConfig
+	var attributes *generated.Attributes
+	var ciStatus *types.CIStatus
 
 	// Fetch diff and user guidance (sequential, as guidance depends on diff)
 	g.Go(func() error {
 		var err error
-		comparison, err = fetchDiff(gCtx, f.client, host, projectPath, baseCommit, headCommit, compareURL, cache)
+		comparison, err = fetchDiff(gCtx, client, host, projectPath, baseCommit, headCommit, compareURL, cache)
 		if err != nil {
 			return fmt.Errorf("failed to fetch and enrich comparison: %w", err)
 		}
 
-		userGuidance, err = fetchUserGuidance(gCtx, f.client, encodedPath, comparison, cache)
+		userGuidance, err = fetchUserGuidance(gCtx, client, encodedPath, comparison, cache)
 		if err != nil {
 			return fmt.Errorf("failed to fetch user guidance: %w", err)
 		}
@@ -92,7 +107,7 @@ func (f *Fetcher) FetchReleaseData(ctx context.Context, compareURL string) (*typ
 
 	// Fetch documentation (independent, runs in parallel)
 	g.Go(func() error {
-		docSource := newDocumentationSource(f.client, host, projectPath)
+		docSource := newDocumentationSource(client, host, projectPath)
 		owner, name := splitProjectPath(projectPath)
 		baseRepo := types.Repository{
 			Owner: owner,
@@ -109,9 +124,46 @@ func (f *Fetcher) FetchReleaseData(ctx context.Context, compareURL string) (*typ
 		return nil
 	})
 
+	// Fetch the repository config as of the base ref, so a release can't relax the risk classes and checks it is judged by
+	g.Go(func() error {
+		repoConfig = repoconfig.Fetch(gCtx, newDocumentationSource(client, host, projectPath), fmt.Sprintf("https://%s/%s", host, projectPath), baseCommit)
+		return nil
+	})
+
+	// Fetch .gitattributes as of the base ref to find files marked linguist-generated, so a release can't hide
+	// its own patches from the analysis by marking them generated
+	g.Go(func() error {
+		attributes = generated.Fetch(gCtx, newDocumentationSource(client, host, projectPath), fmt.Sprintf("https://%s/%s", host, projectPath), baseCommit)
+		return nil
+	})
+
+	// Fetch the CI status of the release head; a failure only leaves CI out of the analysis
+	g.Go(func() error {
+		var err error
+		ciStatus, err = fetchCIStatus(gCtx, client, projectPath, headCommit)
+		if err != nil {
+			slog.Warn("Failed to fetch CI status", "repo", fmt.Sprintf("https://%s/%s", host, projectPath), "ref", headCommit, "error", err)
+		}
+		return nil
+	})
+
 	if err := g.Wait(); err != nil {
 		return nil, nil, nil, err
 	}
+	comparison.RepoConfig = repoConfig
+	comparison.CI = ciStatus
+
+	// Dependency changes are parsed from the raw patches before lockfile patches are summarized
+	dependencies.Annotate(comparison.Files)
+
+	// Lockfile, vendored and generated patches are summarized; their line counts still count in the stats
+	generated.Summarize(comparison.Files, attributes)
+
+	// Kubernetes manifests, Helm values, API specs and Go packages are compared at both refs, since their raw patches lose the surrounding context
+	docSource := newDocumentationSource(client, host, projectPath)
+	infrastructure.Annotate(ctx, docSource, comparison.Files, baseCommit, headCommit)
+	contracts.Annotate(ctx, docSource, comparison.Files, baseCommit, headCommit)
+	goapi.Annotate(ctx, docSource, comparison.Files, baseCommit, headCommit)
 
 	slog.Debug("Release data fetched successfully",
 		"commit_entries", len(comparison.Commits),
@@ -187,3 +239,26 @@ func (c *mrCache) getOrFetchMR(ctx context.Context, client *gitlabapi.Client, pr
 	c.mu.Unlock()
 	return mr, nil
 }
+
+// pullRequests returns the cached MRs the commits were merged through, in order of first appearance
+func (c *mrCache) pullRequests(commits []types.Commit) []types.PullRequest {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var mrs []types.PullRequest
+	seen := map[int64]bool{}
+	for _, commit := range commits {
+		mr := c.mergeRequests[commit.PRNumber]
+		if mr == nil || seen[commit.PRNumber] {
+			continue
+		}
+		seen[commit.PRNumber] = true
+		mrs = append(mrs, types.PullRequest{
+			Number:      commit.PRNumber,
+			Title:       mr.Title,
+			Description: strings.TrimSpace(mr.Description),
+			URL:         mr.WebURL,
+		})
+	}
+	return mrs
+}
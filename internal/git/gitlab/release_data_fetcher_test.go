@@ -1,6 +1,12 @@
 package gitlab
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+
+	gitlabapi "gitlab.com/gitlab-org/api/client-go/v2"
+	"release-confidence-score/internal/git/types"
+)
 
 func TestIsCompareURL(t *testing.T) {
 	f := &Fetcher{}
@@ -243,3 +249,19 @@ func TestUrlEncodeProjectPath(t *testing.T) {
 		})
 	}
 }
+
+func TestPullRequests(t *testing.T) {
+	cache := newMRCache()
+	cache.mergeRequests[12] = &gitlabapi.MergeRequest{BasicMergeRequest: gitlabapi.BasicMergeRequest{Title: "Add feature", Description: "Rollout plan\n", WebURL: "https://gitlab.com/org/repo/-/merge_requests/12"}}
+	cache.mergeRequests[15] = &gitlabapi.MergeRequest{BasicMergeRequest: gitlabapi.BasicMergeRequest{Title: "Fix typo"}}
+
+	commits := []types.Commit{{PRNumber: 15}, {PRNumber: 0}, {PRNumber: 12}, {PRNumber: 15}, {PRNumber: 99}}
+	expected := []types.PullRequest{
+		{Number: 15, Title: "Fix typo"},
+		{Number: 12, Title: "Add feature", Description: "Rollout plan", URL: "https://gitlab.com/org/repo/-/merge_requests/12"},
+	}
+
+	if got := cache.pullRequests(commits); !reflect.DeepEqual(got, expected) {
+		t.Errorf("pullRequests() = %+v, want %+v", got, expected)
+	}
+}
@@ -0,0 +1,11 @@
+package shared
+
+import "strings"
+
+// SplitCommitMessage splits a commit message into its subject line and the rest of the message
+// The body keeps its trailers (Signed-off-by:, Tested-by:, BREAKING CHANGE: ...); Windows line endings are normalized
+func SplitCommitMessage(message string) (subject, body string) {
+	message = strings.ReplaceAll(message, "\r\n", "\n")
+	subject, body, _ = strings.Cut(message, "\n")
+	return strings.TrimSpace(subject), strings.TrimSpace(body)
+}
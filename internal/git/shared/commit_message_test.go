@@ -0,0 +1,31 @@
+package shared
+
+import "testing"
+
+func TestSplitCommitMessage(t *testing.T) {
+	tests := []struct {
+		name            string
+		message         string
+		expectedSubject string
+		expectedBody    string
+	}{
+		{"empty", "", "", ""},
+		{"subject only", "Fix login\n", "Fix login", ""},
+		{
+			name:            "body and trailers",
+			message:         "feat: drop v1 API\n\nClients must move to v2.\n\nBREAKING CHANGE: /v1 is gone\nTested-by: QE <qe@example.com>\n",
+			expectedSubject: "feat: drop v1 API",
+			expectedBody:    "Clients must move to v2.\n\nBREAKING CHANGE: /v1 is gone\nTested-by: QE <qe@example.com>",
+		},
+		{"windows line endings", "Fix login\r\n\r\nSee #12\r\n", "Fix login", "See #12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, body := SplitCommitMessage(tt.message)
+			if subject != tt.expectedSubject || body != tt.expectedBody {
+				t.Errorf("SplitCommitMessage(%q) = %q, %q, want %q, %q", tt.message, subject, body, tt.expectedSubject, tt.expectedBody)
+			}
+		})
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
+	"release-confidence-score/internal/config"
 	httputil "release-confidence-score/internal/http"
 )
 
@@ -19,31 +22,38 @@ const maxResponseBodySize = 1 << 20
 
 // fetchExternalURL fetches content from an external URL
 func (d *DocumentationFetcher) fetchExternalURL(ctx context.Context, urlStr string) (string, error) {
-	// Determine if this is a GitLab URL for SSL verification settings
-	gitlabHost := gitlabHostname(d.config.GitLabBaseURL)
-	isGitLab := isGitLabURL(urlStr, gitlabHost)
-	skipSSLVerify := isGitLab && d.config.GitLabSkipSSLVerify
+	// Determine which GitLab instance, if any, serves this URL for SSL verification settings and authentication
+	instances := d.config.GitLabHosts()
+	instance, isGitLab := gitlabInstance(urlStr, instances)
 
-	httpClient := httputil.NewHTTPClient(httputil.HTTPClientOptions{
+	opts := httputil.HTTPClientOptions{
 		Timeout:         httpTimeout,
-		SkipSSLVerify:   skipSSLVerify,
+		SkipSSLVerify:   isGitLab && instance.SkipSSLVerify,
 		BlockPrivateIPs: true,
-		// The configured GitLab instance is a trusted destination that may
+		// Configured GitLab instances are trusted destinations that may
 		// legitimately live on a private IP; anything else comes from
 		// repo-controlled content and must not reach internal infrastructure.
 		// This is checked per dial, so a redirect away from GitLab to another
 		// private host is still blocked.
-		AllowedPrivateHost: gitlabHost,
-	})
+		AllowedPrivateHosts: slices.Collect(maps.Keys(instances)),
+	}
+	if isGitLab && instance.CACertFile != "" {
+		pool, err := httputil.LoadCACertPool(instance.CACertFile)
+		if err != nil {
+			return "", err
+		}
+		opts.RootCAs = pool
+	}
+	httpClient := httputil.NewHTTPClient(opts)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Add GitLab authentication if needed
-	if isGitLab && d.config.GitLabToken != "" {
-		req.Header.Set("PRIVATE-TOKEN", d.config.GitLabToken)
+	// Add GitLab authentication if needed, with the token of the instance that serves the URL
+	if isGitLab && instance.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", instance.Token)
 	}
 
 	resp, err := httpClient.Do(req)
@@ -69,8 +79,8 @@ func (d *DocumentationFetcher) fetchExternalURL(ctx context.Context, urlStr stri
 	return string(body), nil
 }
 
-// isGitLabURL checks if a URL's host exactly matches gitlabHost (as returned
-// by gitlabHostname). Only exact hostname matches are accepted to prevent
+// isGitLabURL checks if a URL's host exactly matches gitlabHost (a lowercase
+// hostname, as keyed by config.GitLabHosts). Only exact hostname matches are accepted to prevent
 // token leakage to attacker-controlled hosts.
 func isGitLabURL(urlStr, gitlabHost string) bool {
 	if gitlabHost == "" {
@@ -85,13 +95,14 @@ func isGitLabURL(urlStr, gitlabHost string) bool {
 	return strings.ToLower(parsedURL.Hostname()) == gitlabHost
 }
 
-// gitlabHostname returns the lowercase hostname of the configured GitLab
-// instance, or "" if unset or unparsable.
-func gitlabHostname(gitlabBaseURL string) string {
-	parsedBaseURL, err := url.Parse(gitlabBaseURL)
-	if err != nil || parsedBaseURL.Hostname() == "" {
-		return ""
+// gitlabInstance returns the configured GitLab instance whose hostname exactly
+// matches the URL's (see isGitLabURL), so each instance's token and TLS settings
+// are only ever used for its own host.
+func gitlabInstance(urlStr string, instances map[string]config.GitLabInstance) (config.GitLabInstance, bool) {
+	for host, instance := range instances {
+		if isGitLabURL(urlStr, host) {
+			return instance, true
+		}
 	}
-
-	return strings.ToLower(parsedBaseURL.Hostname())
+	return config.GitLabInstance{}, false
 }
@@ -217,29 +217,71 @@ func TestIsGitLabURL_InvalidURL(t *testing.T) {
 	}
 }
 
-func TestGitlabHostname(t *testing.T) {
+func TestGitlabInstance(t *testing.T) {
+	instances := map[string]config.GitLabInstance{
+		"gitlab.corp.example.com": {BaseURL: "https://gitlab.corp.example.com", Token: "corp-token"},
+		"gitlab.com":              {BaseURL: "https://gitlab.com", Token: "public-token"},
+	}
+
 	tests := []struct {
-		name       string
-		gitlabBase string
-		expected   string
+		name          string
+		url           string
+		expectedToken string
+		expected      bool
 	}{
-		{"plain host", "https://gitlab.corp.example.com", "gitlab.corp.example.com"},
-		{"path in base is ignored", "https://gitlab.corp.example.com/api/v4", "gitlab.corp.example.com"},
-		{"case is normalized", "https://GitLab.Corp.Example.COM", "gitlab.corp.example.com"},
-		{"empty base", "", ""},
-		{"malformed base", "://invalid-url", ""},
+		{"internal instance", "https://gitlab.corp.example.com/group/repo/-/raw/main/README.md", "corp-token", true},
+		{"public instance", "https://GitLab.com/group/repo/-/raw/main/README.md", "public-token", true},
+		{"subdomain of an instance", "https://evil.gitlab.com/doc.md", "", false},
+		{"instance host in the path", "https://example.com/gitlab.corp.example.com/doc.md", "", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := gitlabHostname(tt.gitlabBase)
-			if result != tt.expected {
-				t.Errorf("gitlabHostname(%s) = %q, expected %q", tt.gitlabBase, result, tt.expected)
+			instance, ok := gitlabInstance(tt.url, instances)
+			if ok != tt.expected || instance.Token != tt.expectedToken {
+				t.Errorf("gitlabInstance(%s) = %q, %v, expected %q, %v", tt.url, instance.Token, ok, tt.expectedToken, tt.expected)
 			}
 		})
 	}
 }
 
+func TestFetchExternalURL_UsesTokenOfEachInstance(t *testing.T) {
+	// Two GitLab instances: one reached as 127.0.0.1, the other as localhost
+	received := map[string]string{}
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			received[name] = r.Header.Get("PRIVATE-TOKEN")
+			w.Write([]byte("content"))
+		}
+	}
+	internal := httptest.NewServer(handler("internal"))
+	defer internal.Close()
+	public := httptest.NewServer(handler("public"))
+	defer public.Close()
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(public.URL, "http://"))
+	if err != nil {
+		t.Fatalf("failed to split public server address: %v", err)
+	}
+	publicURL := "http://localhost:" + port
+
+	cfg := &config.Config{
+		GitLabBaseURL:   internal.URL,
+		GitLabToken:     "internal-token",
+		GitLabInstances: []config.GitLabInstance{{Name: "public", BaseURL: publicURL, Token: "public-token"}},
+	}
+	fetcher := NewDocumentationFetcher(&mockDocumentationSource{}, types.Repository{}, cfg)
+
+	for _, urlStr := range []string{internal.URL, publicURL} {
+		if _, err := fetcher.fetchExternalURL(context.Background(), urlStr); err != nil {
+			t.Fatalf("expected no error for %s, got: %v", urlStr, err)
+		}
+	}
+	if received["internal"] != "internal-token" || received["public"] != "public-token" {
+		t.Errorf("expected each instance to receive only its own token, got: %v", received)
+	}
+}
+
 func TestFetchAdditionalDocContent_ExternalHTTPURL(t *testing.T) {
 	// Create a test server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,33 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// FetchFileVersions fetches a changed file as it was at the base ref and as it is at the head ref
+// An added file has no base version and a removed file has no head version; those are returned as ""
+func FetchFileVersions(ctx context.Context, source types.DocumentationSource, file types.FileChange, baseRef, headRef string) (string, string, error) {
+	var base, head string
+	if file.Status != "added" {
+		basePath := file.Filename
+		if file.PreviousFilename != "" {
+			basePath = file.PreviousFilename
+		}
+		content, err := source.FetchFileContent(ctx, basePath, baseRef)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to fetch %s at base: %w", basePath, err)
+		}
+		base = content
+	}
+	if file.Status != "removed" {
+		content, err := source.FetchFileContent(ctx, file.Filename, headRef)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to fetch %s at head: %w", file.Filename, err)
+		}
+		head = content
+	}
+	return base, head, nil
+}
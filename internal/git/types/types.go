@@ -7,11 +7,18 @@ import (
 // Comparison represents a git comparison between two refs, platform-agnostic
 // Combines both raw diff data (files, stats) and augmented commit metadata (SHA, PR#, QE labels)
 type Comparison struct {
-	RepoURL string          // Repository URL (e.g., "https://github.com/owner/repo")
-	DiffURL string          // Direct link to the comparison/diff
-	Commits []Commit        // Commits in this comparison with full metadata
-	Files   []FileChange    // Files changed in this comparison
-	Stats   ComparisonStats // Statistics about the comparison
+	RepoURL      string          // Repository URL (e.g., "https://github.com/owner/repo")
+	DiffURL      string          // Direct link to the comparison/diff
+	Commits      []Commit        // Commits in this comparison with full metadata
+	PullRequests []PullRequest   // PRs/MRs the commits were merged through, in order of first appearance
+	Files        []FileChange    // Files changed in this comparison
+	Stats        ComparisonStats // Statistics about the comparison
+
+	OmittedCommits      int // Commits left out of Commits to fit a chunk of a hierarchical analysis
+	OmittedPullRequests int // PRs/MRs left out of PullRequests to fit a chunk of a hierarchical analysis
+
+	RepoConfig *RepoConfig // Repository's .release-confidence.yaml layered over the global file; nil when neither exists
+	CI         *CIStatus   // CI checks reported for the head ref; nil when they couldn't be fetched
 }
 
 // ComparisonStats represents statistics about the comparison
@@ -27,11 +34,20 @@ type Commit struct {
 	SHA            string // Full commit SHA
 	ShortSHA       string // Short SHA for display
 	Message        string // Commit message (first line only)
+	Body           string // Rest of the commit message, including trailers such as Tested-by: and BREAKING CHANGE:
 	Author         string // Author name
 	PRNumber       int64  // Associated PR/MR number (0 if none)
 	QETestingLabel string // QE testing label status: "qe-tested", "needs-qe-testing", or empty
 }
 
+// PullRequest is a GitHub pull request or GitLab merge request that commits of a comparison were merged through
+type PullRequest struct {
+	Number      int64
+	Title       string
+	Description string // Often holds the rationale, testing notes and rollout plan
+	URL         string
+}
+
 // FileChange represents a file that was changed in a comparison
 type FileChange struct {
 	Filename         string
@@ -41,6 +57,71 @@ type FileChange struct {
 	Changes          int
 	Patch            string
 	PreviousFilename string // For renames
+	Generated        string // "lockfile", "vendored" or "generated" when Patch holds a summary instead of the raw diff
+
+	Dependencies   []DependencyChange     // Dependency changes parsed from the raw patch of a manifest or lockfile
+	Infrastructure []InfrastructureChange // Semantic changes between the base and head versions of a Kubernetes or Helm file
+	APIChanges     []APIChange            // Contract changes between the base and head versions of an API spec
+	GoAPI          []GoAPIChange          // Exported Go identifiers added, removed or changed in this file
+}
+
+// DependencyChange is a dependency added, removed or re-versioned in a manifest or lockfile
+type DependencyChange struct {
+	Ecosystem string `json:"ecosystem"` // go, npm, pypi, maven, rubygems, cargo or packagist
+	Package   string `json:"package"`
+	File      string `json:"file"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+	Change    string `json:"change"`             // added, removed, upgraded, downgraded or changed
+	Bump      string `json:"bump,omitempty"`     // major, minor or patch for upgrades and downgrades
+	Lockfile  bool   `json:"lockfile,omitempty"` // Parsed from a lockfile, so possibly a transitive dependency
+}
+
+// InfrastructureChange is a semantic change to a Kubernetes object, OpenShift template or Helm values file
+type InfrastructureChange struct {
+	File     string `json:"file"`
+	Resource string `json:"resource,omitempty"` // Kind/name of the changed object; empty for Helm values
+	Field    string `json:"field"`              // What changed, e.g. "container api memory limit"; "object" when the whole object was added or removed
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Change   string `json:"change"` // added, removed or changed
+}
+
+// APIChange is a change to an API contract between the base and head versions of a spec file
+type APIChange struct {
+	File        string `json:"file"`
+	Kind        string `json:"kind"`     // e.g. endpoint_removed or required_parameter_added
+	Location    string `json:"location"` // Where in the contract, e.g. "GET /users/{id} response 200 field email"
+	Description string `json:"description"`
+	Breaking    bool   `json:"breaking"` // Existing clients may fail against the new contract
+}
+
+// GoAPIChange is an exported identifier of a Go package added, removed or changed between the base and head refs
+type GoAPIChange struct {
+	Package    string `json:"package"` // Directory of the package, e.g. "pkg/client"
+	File       string `json:"file"`
+	Identifier string `json:"identifier"` // e.g. "NewClient", "Client.Do" or "Options.Timeout"
+	Kind       string `json:"kind"`       // func, method, type, field, interface method, const or var
+	Change     string `json:"change"`     // added, removed or changed
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+	Breaking   bool   `json:"breaking"` // Code that uses the identifier may no longer compile
+}
+
+// CIStatus holds the CI checks reported for a comparison's head ref
+type CIStatus struct {
+	Ref    string    // Head ref the checks ran against
+	URL    string    // Link to the checks or pipeline of the head ref
+	Checks []CICheck // GitHub check runs and commit statuses, or the jobs of the latest GitLab pipeline
+}
+
+// CICheck is a single check run, commit status or pipeline job
+type CICheck struct {
+	Name         string `json:"name"`
+	State        string `json:"state"`                   // success, failed, pending, skipped or missing
+	AllowFailure bool   `json:"allow_failure,omitempty"` // A failure doesn't fail the pipeline
+	Required     bool   `json:"required,omitempty"`      // Listed in the repository's required_checks
+	URL          string `json:"url,omitempty"`
 }
 
 // Repository represents basic repository information
@@ -69,3 +150,31 @@ type UserGuidance struct {
 	CommentURL   string    // Direct link to the comment
 	IsAuthorized bool      // Whether the author had permission to post
 }
+
+// RepoConfig customizes how a repository's changes are classified and truncated
+// Loaded from a repository's .release-confidence.yaml or the operator's global file
+type RepoConfig struct {
+	RiskPatterns        RiskPatterns        `yaml:"risk_patterns"`
+	SmallFileThresholds SmallFileThresholds `yaml:"small_file_thresholds"`
+	RequiredChecks      []string            `yaml:"required_checks"` // CI checks or jobs that must pass on the release head
+
+	Base *RepoConfig `yaml:"-"` // Config this one is layered over; consulted when this one has no opinion
+}
+
+// RiskPatterns lists glob patterns (with ** support) that assign files to a risk class
+// Patterns here take precedence over the built-in patterns
+type RiskPatterns struct {
+	Critical []string `yaml:"critical"`
+	High     []string `yaml:"high"`
+	Medium   []string `yaml:"medium"`
+	Low      []string `yaml:"low"`
+}
+
+// SmallFileThresholds overrides, per truncation level, the line count below which files are never truncated
+// Zero means the level keeps its inherited threshold
+type SmallFileThresholds struct {
+	Low      int `yaml:"low"`
+	Moderate int `yaml:"moderate"`
+	High     int `yaml:"high"`
+	Extreme  int `yaml:"extreme"`
+}
@@ -0,0 +1,251 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/budget"
+	"release-confidence-score/internal/llm/chunking"
+	llmerrors "release-confidence-score/internal/llm/errors"
+	"release-confidence-score/internal/llm/formatting"
+	"release-confidence-score/internal/llm/prompts/system"
+	"release-confidence-score/internal/llm/prompts/user"
+	"release-confidence-score/internal/llm/truncation"
+	"release-confidence-score/internal/report"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// minChunkBudgetRatio is the smallest share of the context window left for a chunk's diff before
+// documentation is truncated to make room
+const minChunkBudgetRatio = 0.2
+
+// minChunkTokens is the smallest diff budget a chunk is split down to after the provider rejects it as too large
+const minChunkTokens = 1000
+
+// fitsContextWindow reports whether the untruncated release fits the model's context window
+func (ra *ReleaseAnalyzer) fitsContextWindow(modelID string, comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) (bool, error) {
+	tokenBudget := budget.ForModel(modelID, ra.config.ModelContextWindow, ra.config.ModelMaxResponseTokens)
+
+	userPrompt, err := user.RenderUserPrompt(
+		formatting.FormatComparisons(comparisons),
+		formatEvidence(comparisons),
+		formatting.FormatDocumentations(documentation),
+		userGuidance,
+		truncation.TruncationMetadata{},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to format user prompt: %w", err)
+	}
+
+	return tokenBudget.Fits(system.GetSystemPrompt(ra.config), userPrompt), nil
+}
+
+// runHierarchical analyzes a release that doesn't fit the context window by splitting it into chunks,
+// analyzing each chunk separately and combining the chunk findings with a final aggregation call
+// If the aggregation call fails, the chunk analyses are merged locally instead
+func (ra *ReleaseAnalyzer) runHierarchical(model modelClient, comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) (*modelRun, error) {
+	docs := formatting.FormatDocumentations(documentation)
+	evidence := formatEvidence(comparisons)
+	chunkBudget, err := ra.chunkBudget(model.modelID, evidence, docs, userGuidance)
+	if err != nil {
+		return nil, err
+	}
+
+	// Documentation is repeated in every chunk prompt; truncate it when it leaves too little room for code
+	available := budget.ForModel(model.modelID, ra.config.ModelContextWindow, ra.config.ModelMaxResponseTokens).Available()
+	if float64(chunkBudget) < float64(available)*minChunkBudgetRatio {
+		slog.Info("Documentation leaves little room for chunks, truncating documentation", "chunk_budget", chunkBudget)
+		docs = formatting.FormatDocumentations(truncation.TruncateDocumentation(documentation, truncation.LevelHigh))
+		if chunkBudget, err = ra.chunkBudget(model.modelID, evidence, docs, userGuidance); err != nil {
+			return nil, err
+		}
+	}
+
+	chunks := chunking.Split(comparisons, chunkBudget)
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("no file changes to analyze")
+	}
+
+	slog.Info("Release exceeds context window, analyzing hierarchically",
+		"provider", model.provider,
+		"model_id", model.modelID,
+		"chunks", len(chunks),
+		"chunk_budget", chunkBudget)
+
+	// Chunks the provider rejects as too large are split further, so the analyzed chunks may outnumber the planned ones
+	chunks, analyses, err := ra.analyzeChunks(model, chunks, chunkBudget, docs, userGuidance)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &report.ChunkingResult{Chunks: make([]report.ChunkResult, len(chunks))}
+	for i, chunk := range chunks {
+		result.Chunks[i] = report.ChunkResult{
+			Label:    chunk.Label,
+			Files:    chunk.Files,
+			Score:    analyses[i].Score,
+			Concerns: analyses[i].RiskSummary.Concerns,
+		}
+	}
+
+	analysis, err := ra.aggregateChunks(model, chunks, analyses, evidence, docs, userGuidance)
+	if err != nil {
+		slog.Warn("Chunk aggregation failed, merging chunk analyses locally", "model_id", model.modelID, "error", err)
+
+		analysis, err = report.MergeChunks(analyses)
+		if err != nil {
+			return nil, err
+		}
+		return &modelRun{analysis: analysis, chunking: result}, nil
+	}
+
+	result.Aggregated = true
+	return &modelRun{analysis: analysis, chunking: result}, nil
+}
+
+// chunkBudget returns the tokens left for a chunk's diff once the system prompt, evidence,
+// documentation and user guidance are accounted for
+// The release's evidence is used as an upper bound for the evidence of each chunk
+func (ra *ReleaseAnalyzer) chunkBudget(modelID, evidence, docs string, userGuidance []types.UserGuidance) (int, error) {
+	tokenBudget := budget.ForModel(modelID, ra.config.ModelContextWindow, ra.config.ModelMaxResponseTokens)
+
+	// The chunk scope header is small; an empty diff with a placeholder label approximates the fixed cost
+	basePrompt, err := user.RenderChunkPrompt("", evidence, docs, userGuidance, user.ChunkContext{Index: 1, Total: 1})
+	if err != nil {
+		return 0, fmt.Errorf("failed to format chunk prompt: %w", err)
+	}
+
+	overhead := budget.EstimateTokens(system.GetSystemPrompt(ra.config)) + budget.EstimateTokens(basePrompt)
+	return max(tokenBudget.Available()-overhead, 1), nil
+}
+
+// analyzeChunks analyzes every chunk concurrently; the release can't be scored if any chunk fails
+// Returns the chunks that were analyzed, which include the parts of any chunk that had to be split further
+func (ra *ReleaseAnalyzer) analyzeChunks(model modelClient, chunks []chunking.Chunk, chunkBudget int, docs string, userGuidance []types.UserGuidance) ([]chunking.Chunk, []*report.StructuredAnalysis, error) {
+	parts := make([][]chunking.Chunk, len(chunks))
+	partAnalyses := make([][]*report.StructuredAnalysis, len(chunks))
+
+	var g errgroup.Group
+	g.SetLimit(10) // Limit concurrent LLM calls to avoid rate limiting
+	for i, chunk := range chunks {
+		g.Go(func() error {
+			var err error
+			chunkContext := user.ChunkContext{Index: i + 1, Total: len(chunks), Label: chunk.Label}
+			parts[i], partAnalyses[i], err = ra.analyzeChunk(model, chunk, chunkBudget, chunkContext, docs, userGuidance)
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+	return slices.Concat(parts...), slices.Concat(partAnalyses...), nil
+}
+
+// analyzeChunk analyzes a single chunk; when the provider rejects it as too large, it is split again
+// at half the budget and its parts are analyzed in its place, down to minChunkTokens
+func (ra *ReleaseAnalyzer) analyzeChunk(model modelClient, chunk chunking.Chunk, chunkBudget int, chunkContext user.ChunkContext, docs string, userGuidance []types.UserGuidance) ([]chunking.Chunk, []*report.StructuredAnalysis, error) {
+	chunkPrompt, err := user.RenderChunkPrompt(
+		formatting.FormatComparisons(chunk.Comparisons),
+		formatEvidence(chunk.Comparisons),
+		docs,
+		userGuidance,
+		chunkContext,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format chunk prompt: %w", err)
+	}
+
+	slog.Info("Analyzing chunk", "model_id", model.modelID, "chunk", chunkContext.Index, "total", chunkContext.Total, "label", chunk.Label)
+	response, err := model.client.Analyze(chunkPrompt)
+	if _, isContextErr := err.(*llmerrors.ContextWindowError); isContextErr && chunkBudget/2 >= minChunkTokens {
+		slog.Warn("Chunk exceeds context window, splitting it further",
+			"model_id", model.modelID,
+			"chunk", chunkContext.Index,
+			"label", chunk.Label,
+			"chunk_budget", chunkBudget/2)
+
+		var chunks []chunking.Chunk
+		var analyses []*report.StructuredAnalysis
+		for _, part := range chunking.Split(chunk.Comparisons, chunkBudget/2) {
+			partContext := chunkContext
+			partContext.Label = part.Label
+			partChunks, partAnalyses, err := ra.analyzeChunk(model, part, chunkBudget/2, partContext, docs, userGuidance)
+			if err != nil {
+				return nil, nil, err
+			}
+			chunks = append(chunks, partChunks...)
+			analyses = append(analyses, partAnalyses...)
+		}
+		return chunks, analyses, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to analyze chunk %d (%s): %w", chunkContext.Index, chunk.Label, err)
+	}
+
+	analysis, err := ra.parseWithRepair(model, chunkPrompt, response)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to analyze chunk %d (%s): %w", chunkContext.Index, chunk.Label, err)
+	}
+	return []chunking.Chunk{chunk}, []*report.StructuredAnalysis{analysis}, nil
+}
+
+// aggregateChunks asks the model to combine the chunk analyses into one release analysis
+// If the prompt exceeds the context window, it is retried once with condensed chunk analyses and
+// without documentation, which every chunk has already weighed
+func (ra *ReleaseAnalyzer) aggregateChunks(model modelClient, chunks []chunking.Chunk, analyses []*report.StructuredAnalysis, evidence, docs string, userGuidance []types.UserGuidance) (*report.StructuredAnalysis, error) {
+	findings, err := chunkFindings(chunks, analyses, false)
+	if err != nil {
+		return nil, err
+	}
+	aggregationPrompt, err := user.RenderAggregationPrompt(findings, evidence, docs, userGuidance)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Aggregating chunk analyses", "model_id", model.modelID, "chunks", len(findings))
+	response, err := model.client.Analyze(aggregationPrompt)
+	if _, isContextErr := err.(*llmerrors.ContextWindowError); isContextErr {
+		slog.Warn("Aggregation exceeds context window, retrying with condensed chunk analyses", "model_id", model.modelID)
+
+		if findings, err = chunkFindings(chunks, analyses, true); err != nil {
+			return nil, err
+		}
+		if aggregationPrompt, err = user.RenderAggregationPrompt(findings, evidence, "", userGuidance); err != nil {
+			return nil, err
+		}
+		response, err = model.client.Analyze(aggregationPrompt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate chunk analyses: %w", err)
+	}
+
+	return ra.parseWithRepair(model, aggregationPrompt, response)
+}
+
+// chunkFindings pairs each chunk with its analysis for the aggregation prompt
+// Condensed findings keep only each chunk's score, summary and concerns
+func chunkFindings(chunks []chunking.Chunk, analyses []*report.StructuredAnalysis, condensed bool) ([]user.ChunkFindings, error) {
+	findings := make([]user.ChunkFindings, len(chunks))
+	for i, chunk := range chunks {
+		var analysis any = analyses[i]
+		if condensed {
+			analysis = struct {
+				Score    int                  `json:"score"`
+				Summary  string               `json:"summary"`
+				Concerns []report.RiskConcern `json:"concerns"`
+			}{analyses[i].Score, analyses[i].Summary, analyses[i].RiskSummary.Concerns}
+		}
+
+		analysisJSON, err := json.Marshal(analysis)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal chunk analysis: %w", err)
+		}
+		findings[i] = user.ChunkFindings{Label: chunk.Label, Files: chunk.Files, Analysis: string(analysisJSON)}
+	}
+	return findings, nil
+}
@@ -0,0 +1,204 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/chunking"
+	llmerrors "release-confidence-score/internal/llm/errors"
+)
+
+// multiDirComparison returns a comparison with one large file in each of the given directories
+func multiDirComparison(dirs []string, lines int) *types.Comparison {
+	var patch strings.Builder
+	for i := 0; i < lines; i++ {
+		patch.WriteString(fmt.Sprintf("+code line %d with some padding text\n", i))
+	}
+
+	comparison := &types.Comparison{
+		RepoURL: "https://github.com/org/repo",
+		Commits: []types.Commit{{SHA: "abc123", Message: "large release"}},
+	}
+	for _, dir := range dirs {
+		comparison.Files = append(comparison.Files, types.FileChange{
+			Filename:  dir + "/main.go",
+			Status:    "modified",
+			Additions: lines,
+			Patch:     patch.String(),
+		})
+	}
+	return comparison
+}
+
+// newHierarchicalAnalyzer returns an analyzer with a context window small enough to force chunking
+// and the number of chunks the comparison will be split into
+func newHierarchicalAnalyzer(t *testing.T, llm *mockLLMClient, comparison *types.Comparison) (*ReleaseAnalyzer, int) {
+	t.Helper()
+
+	ra := newTestAnalyzer(nil, nil, llm)
+	ra.config.AnalysisMode = "hierarchical"
+	ra.config.ModelContextWindow = 12000
+	ra.config.ModelMaxResponseTokens = 1000
+
+	chunkBudget, err := ra.chunkBudget(ra.config.ModelID, formatEvidence([]*types.Comparison{comparison}), "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	chunks := chunking.Split([]*types.Comparison{comparison}, chunkBudget)
+	if len(chunks) < 2 {
+		t.Fatalf("expected comparison to be split into several chunks, got %d", len(chunks))
+	}
+	return ra, len(chunks)
+}
+
+func TestAnalyze_HierarchicalAggregatesChunks(t *testing.T) {
+	comparison := multiDirComparison([]string{"api", "billing", "web", "worker"}, 300)
+
+	llm := &mockLLMClient{}
+	ra, chunkCount := newHierarchicalAnalyzer(t, llm, comparison)
+
+	for i := 0; i < chunkCount; i++ {
+		llm.responses = append(llm.responses, validLLMResponse())
+	}
+	llm.responses = append(llm.responses, `{"score": 62, "summary": "Aggregated summary", "risk_summary": {"concerns": [{"severity": "high", "description": "Cross-chunk risk"}]}}`)
+
+	score, report, err := ra.analyze([]*types.Comparison{comparison}, []types.UserGuidance{}, []*types.Documentation{}, false)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if llm.callCount != chunkCount+1 {
+		t.Errorf("expected %d LLM calls, got %d", chunkCount+1, llm.callCount)
+	}
+	if score != 62 {
+		t.Errorf("expected aggregated score 62, got %v", score)
+	}
+	for i := 0; i < chunkCount; i++ {
+		if !strings.Contains(llm.callInputs[i], "Partial Release Scope") {
+			t.Errorf("call %d should be a chunk prompt", i+1)
+		}
+		if strings.Contains(llm.callInputs[i], "lines omitted") {
+			t.Errorf("call %d should not truncate files that fit a chunk", i+1)
+		}
+	}
+	if !strings.Contains(llm.callInputs[chunkCount], "Partial Analyses") {
+		t.Error("final call should be the aggregation prompt")
+	}
+	for _, want := range []string{"Hierarchical Analysis", "Cross-chunk risk", "`org/repo/api/main.go`", "final aggregation request"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	if strings.Contains(report, "Diff Truncation Applied") {
+		t.Error("hierarchical analysis should not report truncation")
+	}
+}
+
+func TestAnalyze_HierarchicalFallsBackWhenAggregationFails(t *testing.T) {
+	comparison := multiDirComparison([]string{"api", "billing", "web", "worker"}, 300)
+
+	llm := &mockLLMClient{}
+	ra, chunkCount := newHierarchicalAnalyzer(t, llm, comparison)
+
+	for i := 0; i < chunkCount; i++ {
+		llm.responses = append(llm.responses, validLLMResponse())
+		llm.errors = append(llm.errors, nil)
+	}
+	llm.responses = append(llm.responses, "")
+	llm.errors = append(llm.errors, errors.New("service unavailable"))
+
+	score, report, err := ra.analyze([]*types.Comparison{comparison}, []types.UserGuidance{}, []*types.Documentation{}, false)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if score != 85 {
+		t.Errorf("expected lowest chunk score 85, got %v", score)
+	}
+	if !strings.Contains(report, "aggregation request failed") {
+		t.Error("report should mention that chunk findings were merged locally")
+	}
+}
+
+func TestAnalyze_HierarchicalFailsWhenChunkFails(t *testing.T) {
+	comparison := multiDirComparison([]string{"api", "billing", "web", "worker"}, 300)
+
+	llm := &mockLLMClient{errors: []error{errors.New("service unavailable")}}
+	ra, _ := newHierarchicalAnalyzer(t, llm, comparison)
+
+	_, _, err := ra.analyze([]*types.Comparison{comparison}, []types.UserGuidance{}, []*types.Documentation{}, false)
+
+	if err == nil {
+		t.Fatal("expected error when a chunk cannot be analyzed")
+	}
+	if !strings.Contains(err.Error(), "failed to analyze chunk") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAnalyze_HierarchicalRetriesPromptsRejectedAsTooLarge(t *testing.T) {
+	comparison := multiDirComparison([]string{"api", "billing", "web", "worker"}, 300)
+
+	contextErr := &llmerrors.ContextWindowError{StatusCode: 400, Message: "prompt is too long", Provider: "Claude"}
+	var rejectedChunks, rejectedAggregations int
+	llm := &mockLLMClient{respond: func(userPrompt string) (string, error) {
+		// The provider only accepts chunks of at most 150 diff lines, and aggregations of condensed chunk analyses
+		if strings.Contains(userPrompt, "Partial Release Scope") && strings.Count(userPrompt, "+code line") > 150 {
+			rejectedChunks++
+			return "", contextErr
+		}
+		if strings.Contains(userPrompt, "Partial Analyses") {
+			if strings.Contains(userPrompt, "risk_summary") {
+				rejectedAggregations++
+				return "", contextErr
+			}
+			return `{"score": 62, "summary": "Aggregated summary"}`, nil
+		}
+		return validLLMResponse(), nil
+	}}
+	ra, _ := newHierarchicalAnalyzer(t, llm, comparison)
+
+	score, report, err := ra.analyze([]*types.Comparison{comparison}, []types.UserGuidance{}, []*types.Documentation{}, false)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rejectedChunks == 0 || rejectedAggregations != 1 {
+		t.Fatalf("expected rejected chunks and one rejected aggregation, got %d and %d", rejectedChunks, rejectedAggregations)
+	}
+	if score != 62 {
+		t.Errorf("expected the condensed aggregation's score 62, got %v", score)
+	}
+	for _, want := range []string{"`org/repo/api/main.go`", "`org/repo/billing/main.go`", "`org/repo/web/main.go`", "`org/repo/worker/main.go`", "final aggregation request"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+}
+
+func TestAnalyze_HierarchicalSmallReleaseUsesSingleCall(t *testing.T) {
+	llm := &mockLLMClient{responses: []string{validLLMResponse()}}
+
+	ra := newTestAnalyzer(nil, nil, llm)
+	ra.config.AnalysisMode = "hierarchical"
+
+	_, report, err := ra.analyze(
+		[]*types.Comparison{multiDirComparison([]string{"api"}, 5)},
+		[]types.UserGuidance{},
+		[]*types.Documentation{},
+		false,
+	)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if llm.callCount != 1 {
+		t.Errorf("expected 1 LLM call, got %d", llm.callCount)
+	}
+	if strings.Contains(report, "Hierarchical Analysis") {
+		t.Error("a release that fits the context window should not be chunked")
+	}
+}
@@ -3,9 +3,11 @@ package http
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 )
@@ -16,15 +18,18 @@ type HTTPClientOptions struct {
 	Timeout time.Duration
 	// SkipSSLVerify disables SSL certificate verification (use with caution)
 	SkipSSLVerify bool
+	// RootCAs, if set, replaces the system certificate pool used to verify
+	// servers -- e.g. one built by LoadCACertPool for an internal CA.
+	RootCAs *x509.CertPool
 	// BlockPrivateIPs rejects connections that resolve to private, loopback,
 	// link-local, or otherwise non-public addresses (including cloud metadata
 	// endpoints). Use for requests to attacker-influenced URLs to prevent SSRF.
 	BlockPrivateIPs bool
-	// AllowedPrivateHost, if set, exempts this exact hostname (case-insensitive)
-	// from the private-IP block -- e.g. a configured internal GitLab instance
+	// AllowedPrivateHosts exempts these exact hostnames (case-insensitive)
+	// from the private-IP block -- e.g. configured internal GitLab instances
 	// that may legitimately live on a private IP. The exemption is checked per
-	// dial, so a redirect away from this host to any other host is still blocked.
-	AllowedPrivateHost string
+	// dial, so a redirect away from these hosts to any other host is still blocked.
+	AllowedPrivateHosts []string
 }
 
 // NewHTTPClient creates an HTTP client with the specified options
@@ -34,20 +39,21 @@ func NewHTTPClient(opts HTTPClientOptions) *http.Client {
 	}
 
 	// Only configure custom transport if SSL verification needs to be skipped
-	// or private IPs need to be blocked
-	if opts.SkipSSLVerify || opts.BlockPrivateIPs {
+	// or customized, or private IPs need to be blocked
+	if opts.SkipSSLVerify || opts.RootCAs != nil || opts.BlockPrivateIPs {
 		transport := &http.Transport{
 			Proxy: http.ProxyFromEnvironment,
 		}
 
-		if opts.SkipSSLVerify {
+		if opts.SkipSSLVerify || opts.RootCAs != nil {
 			transport.TLSClientConfig = &tls.Config{
-				InsecureSkipVerify: true,
+				InsecureSkipVerify: opts.SkipSSLVerify,
+				RootCAs:            opts.RootCAs,
 			}
 		}
 
 		if opts.BlockPrivateIPs {
-			transport.DialContext = safeDialContext(opts.AllowedPrivateHost)
+			transport.DialContext = safeDialContext(opts.AllowedPrivateHosts)
 		}
 
 		client.Transport = transport
@@ -56,13 +62,31 @@ func NewHTTPClient(opts HTTPClientOptions) *http.Client {
 	return client
 }
 
+// LoadCACertPool returns the system certificate pool with the PEM certificates
+// of caFile added, so servers signed by an internal CA can be verified.
+func LoadCACertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle %s: %w", caFile, err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", caFile)
+	}
+	return pool, nil
+}
+
 // safeDialContext returns a DialContext that resolves addr and dials only
 // public IP addresses, rejecting private, loopback, link-local, and
-// unspecified ranges, except for an exact match on allowedHost. Validation
+// unspecified ranges, except for an exact match on one of allowedHosts. Validation
 // happens at dial time rather than URL-parse time, so it also covers HTTP
 // redirects (each hop is dialed and checked independently) and cannot be
 // bypassed by DNS rebinding between resolution and connection.
-func safeDialContext(allowedHost string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+func safeDialContext(allowedHosts []string) func(ctx context.Context, network, addr string) (net.Conn, error) {
 	return func(ctx context.Context, network, addr string) (net.Conn, error) {
 		host, port, err := net.SplitHostPort(addr)
 		if err != nil {
@@ -71,8 +95,10 @@ func safeDialContext(allowedHost string) func(ctx context.Context, network, addr
 
 		dialer := &net.Dialer{}
 
-		if allowedHost != "" && strings.EqualFold(host, allowedHost) {
-			return dialer.DialContext(ctx, network, addr)
+		for _, allowedHost := range allowedHosts {
+			if strings.EqualFold(host, allowedHost) {
+				return dialer.DialContext(ctx, network, addr)
+			}
 		}
 
 		ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
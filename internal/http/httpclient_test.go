@@ -2,9 +2,12 @@ package http
 
 import (
 	"crypto/tls"
+	"encoding/pem"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -211,7 +214,7 @@ func TestNewHTTPClient_BlockPrivateIPs_RejectsLoopback(t *testing.T) {
 	}
 }
 
-func TestNewHTTPClient_AllowedPrivateHost_BypassesBlockForExactHost(t *testing.T) {
+func TestNewHTTPClient_AllowedPrivateHosts_BypassesBlockForExactHost(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
@@ -223,8 +226,8 @@ func TestNewHTTPClient_AllowedPrivateHost_BypassesBlockForExactHost(t *testing.T
 	}
 
 	client := NewHTTPClient(HTTPClientOptions{
-		BlockPrivateIPs:    true,
-		AllowedPrivateHost: "localhost",
+		BlockPrivateIPs:     true,
+		AllowedPrivateHosts: []string{"localhost"},
 	})
 
 	resp, err := client.Get("http://localhost:" + port)
@@ -234,15 +237,15 @@ func TestNewHTTPClient_AllowedPrivateHost_BypassesBlockForExactHost(t *testing.T
 	resp.Body.Close()
 }
 
-func TestNewHTTPClient_AllowedPrivateHost_StillBlocksOtherHosts(t *testing.T) {
+func TestNewHTTPClient_AllowedPrivateHosts_StillBlocksOtherHosts(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer server.Close()
 
 	client := NewHTTPClient(HTTPClientOptions{
-		BlockPrivateIPs:    true,
-		AllowedPrivateHost: "localhost",
+		BlockPrivateIPs:     true,
+		AllowedPrivateHosts: []string{"localhost"},
 	})
 
 	// server.URL is http://127.0.0.1:PORT -- a different literal hostname than
@@ -268,3 +271,56 @@ func TestNewHTTPClient_UsesEnvironmentProxy(t *testing.T) {
 		t.Fatal("expected Proxy to be set so HTTP_PROXY/HTTPS_PROXY env vars are honored")
 	}
 }
+
+func TestLoadCACertPool_TrustsServerSignedByBundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+
+	// The test server's self-signed certificate isn't trusted by default
+	if _, err := NewHTTPClient(HTTPClientOptions{}).Get(server.URL); err == nil {
+		t.Fatal("expected an untrusted certificate error without the CA bundle")
+	}
+
+	pool, err := LoadCACertPool(caFile)
+	if err != nil {
+		t.Fatalf("LoadCACertPool() error = %v", err)
+	}
+	resp, err := NewHTTPClient(HTTPClientOptions{RootCAs: pool}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the CA bundle to be trusted, got: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestLoadCACertPool_Errors(t *testing.T) {
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		caFile   string
+		expected string
+	}{
+		{"missing file", filepath.Join(t.TempDir(), "missing.pem"), "failed to read CA bundle"},
+		{"no certificates", notPEM, "no PEM certificates found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCACertPool(tt.caFile)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("LoadCACertPool() error = %v, want %q", err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package budget
+
+import (
+	"strings"
+)
+
+// charsPerToken is a deliberately conservative characters-per-token ratio.
+// Diffs and JSON tokenize more densely than prose, so overestimating the prompt
+// size is preferable to sending a prompt the provider will reject.
+const charsPerToken = 3
+
+// usablePercent is the share of the context window the prompt may occupy.
+// The remainder absorbs estimation error and provider-side framing overhead.
+const usablePercent = 90
+
+// DefaultContextWindow is used for models that are not listed in contextWindows
+const DefaultContextWindow = 128000
+
+// contextWindows maps model ID prefixes to their context window size in tokens
+// Entries are matched in order, so more specific prefixes must come first
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"claude-", 200000},
+	{"gemini-1.5-pro", 2097152},
+	{"gemini-1.5-flash", 1048576},
+	{"gemini-2", 1048576},
+}
+
+// Budget describes how many tokens a prompt may use for a given model
+type Budget struct {
+	ContextWindow  int // Total context window of the model in tokens
+	ReservedTokens int // Tokens reserved for the model's response
+}
+
+// ForModel returns the token budget for a model
+// A positive contextWindowOverride takes precedence over the built-in table
+func ForModel(modelID string, contextWindowOverride, maxResponseTokens int) Budget {
+	window := contextWindowOverride
+	if window <= 0 {
+		window = ContextWindow(modelID)
+	}
+
+	return Budget{
+		ContextWindow:  window,
+		ReservedTokens: maxResponseTokens,
+	}
+}
+
+// ContextWindow returns the context window size in tokens for a model ID
+// Falls back to DefaultContextWindow for unknown models
+func ContextWindow(modelID string) int {
+	lower := strings.ToLower(modelID)
+	for _, entry := range contextWindows {
+		if strings.HasPrefix(lower, entry.prefix) {
+			return entry.tokens
+		}
+	}
+	return DefaultContextWindow
+}
+
+// EstimateTokens returns a conservative estimate of the number of tokens in text
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// Available returns the number of tokens the prompts may use
+func (b Budget) Available() int {
+	return b.ContextWindow*usablePercent/100 - b.ReservedTokens
+}
+
+// Fits reports whether the combined estimated size of the prompts fits the budget
+func (b Budget) Fits(prompts ...string) bool {
+	total := 0
+	for _, prompt := range prompts {
+		total += EstimateTokens(prompt)
+	}
+	return total <= b.Available()
+}
@@ -0,0 +1,102 @@
+package budget
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		modelID  string
+		expected int
+	}{
+		{"claude vertex model", "claude-sonnet-4@20250514", 200000},
+		{"claude uppercase", "Claude-Opus-4", 200000},
+		{"gemini 2.5 pro", "gemini-2.5-pro", 1048576},
+		{"gemini 1.5 pro", "gemini-1.5-pro-002", 2097152},
+		{"gemini 1.5 flash", "gemini-1.5-flash", 1048576},
+		{"unknown model", "some-other-model", DefaultContextWindow},
+		{"empty model", "", DefaultContextWindow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ContextWindow(tt.modelID)
+			if result != tt.expected {
+				t.Errorf("ContextWindow(%q) = %d, want %d", tt.modelID, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{"empty", "", 0},
+		{"single char", "a", 1},
+		{"exact multiple", "abcdef", 2},
+		{"rounds up", "abcdefg", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := EstimateTokens(tt.text)
+			if result != tt.expected {
+				t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestForModel(t *testing.T) {
+	t.Run("uses table when no override", func(t *testing.T) {
+		b := ForModel("claude-sonnet-4", 0, 4096)
+		if b.ContextWindow != 200000 {
+			t.Errorf("ContextWindow = %d, want %d", b.ContextWindow, 200000)
+		}
+		if b.ReservedTokens != 4096 {
+			t.Errorf("ReservedTokens = %d, want %d", b.ReservedTokens, 4096)
+		}
+	})
+
+	t.Run("override takes precedence", func(t *testing.T) {
+		b := ForModel("claude-sonnet-4", 50000, 4096)
+		if b.ContextWindow != 50000 {
+			t.Errorf("ContextWindow = %d, want %d", b.ContextWindow, 50000)
+		}
+	})
+}
+
+func TestBudgetFits(t *testing.T) {
+	// 1000 tokens * 90% - 100 reserved = 800 tokens available = 2400 chars
+	b := Budget{ContextWindow: 1000, ReservedTokens: 100}
+
+	if b.Available() != 800 {
+		t.Fatalf("Available() = %d, want %d", b.Available(), 800)
+	}
+
+	tests := []struct {
+		name     string
+		prompts  []string
+		expected bool
+	}{
+		{"no prompts", nil, true},
+		{"exactly at limit", []string{strings.Repeat("a", 2400)}, true},
+		{"just over limit", []string{strings.Repeat("a", 2401)}, false},
+		{"combined prompts over limit", []string{strings.Repeat("a", 1500), strings.Repeat("b", 1500)}, false},
+		{"combined prompts under limit", []string{strings.Repeat("a", 1000), strings.Repeat("b", 1000)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := b.Fits(tt.prompts...)
+			if result != tt.expected {
+				t.Errorf("Fits() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package chunking
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/budget"
+	"release-confidence-score/internal/llm/formatting"
+	"release-confidence-score/internal/llm/truncation"
+)
+
+// maxLabelGroups is the number of directory groups named in a chunk label before the rest are summarized
+const maxLabelGroups = 3
+
+// maxHeaderShare is the largest share of a chunk's budget that the repeated commit and PR/MR list may take
+// before it is shortened, so there is always room left for the diff
+const maxHeaderShare = 0.5
+
+// headerDescriptionChars is the length commit bodies and PR/MR descriptions are cut to when shortening a chunk header
+const headerDescriptionChars = 200
+
+// Chunk is a subset of a release's file changes small enough to analyze in a single LLM call
+type Chunk struct {
+	Label       string              // Human-readable scope, e.g. "org/repo: db/ (critical), api/ (high)"
+	Comparisons []*types.Comparison // Comparisons restricted to the chunk's files
+	Files       []string            // Paths of all files in the chunk, prefixed with the repository
+}
+
+// group is a set of files from one repository sharing a top-level directory and risk class
+type group struct {
+	dir   string
+	risk  truncation.FileRiskLevel
+	files []types.FileChange
+}
+
+// Split divides the files of all comparisons into chunks whose formatted diff fits maxTokens
+// Chunks never span repositories. Within a repository, files are grouped by top-level directory
+// and risk class, highest risk first, and groups are packed greedily so related files stay together.
+// A single file that exceeds the budget on its own has its patch truncated to fit.
+func Split(comparisons []*types.Comparison, maxTokens int) []Chunk {
+	var chunks []Chunk
+
+	for _, comparison := range comparisons {
+		if comparison == nil || len(comparison.Files) == 0 {
+			continue
+		}
+
+		// Commits and headers are repeated in every chunk of the repository
+		header, overhead := fitHeader(comparison, int(float64(maxTokens)*maxHeaderShare))
+		fileBudget := max(maxTokens-overhead, 1)
+
+		var current []group
+		currentTokens := 0
+
+		flush := func() {
+			if len(current) > 0 {
+				chunks = append(chunks, newChunk(header, current))
+				current = nil
+				currentTokens = 0
+			}
+		}
+
+		for _, g := range groupFiles(comparison.Files, comparison.RepoConfig) {
+			for _, file := range g.files {
+				file = fitFile(file, fileBudget)
+				tokens := fileTokens(file)
+
+				if currentTokens+tokens > fileBudget {
+					flush()
+				}
+
+				// Append to the last group if it's the same one, otherwise start a new group in the chunk
+				if n := len(current); n > 0 && current[n-1].dir == g.dir && current[n-1].risk == g.risk {
+					current[n-1].files = append(current[n-1].files, file)
+				} else {
+					current = append(current, group{dir: g.dir, risk: g.risk, files: []types.FileChange{file}})
+				}
+				currentTokens += tokens
+			}
+		}
+		flush()
+	}
+
+	slog.Debug("Split release into chunks", "chunks", len(chunks), "max_tokens", maxTokens)
+	return chunks
+}
+
+// fitHeader returns the comparison with its commit and PR/MR list shortened to take at most maxTokens,
+// and the tokens the list takes. Long bodies and descriptions are cut first, then entries are dropped
+// from the end of both lists; the first commit is always kept so the repository is still named.
+func fitHeader(comparison *types.Comparison, maxTokens int) (*types.Comparison, int) {
+	tokens := headerTokens(comparison)
+	if tokens <= maxTokens {
+		return comparison, tokens
+	}
+
+	shortened := *comparison
+	shortened.Commits = slices.Clone(comparison.Commits)
+	shortened.PullRequests = slices.Clone(comparison.PullRequests)
+	for i := range shortened.Commits {
+		shortened.Commits[i].Body, _ = truncation.TruncateDescription(shortened.Commits[i].Body, headerDescriptionChars)
+	}
+	for i := range shortened.PullRequests {
+		shortened.PullRequests[i].Description, _ = truncation.TruncateDescription(shortened.PullRequests[i].Description, headerDescriptionChars)
+	}
+	if tokens = headerTokens(&shortened); tokens <= maxTokens {
+		slog.Debug("Shortened commit and PR/MR descriptions to fit chunks", "repo", comparison.RepoURL, "tokens", tokens)
+		return &shortened, tokens
+	}
+
+	// Keep the first n entries of each list, for the largest n that fits
+	keep := func(n int) *types.Comparison {
+		kept := shortened
+		kept.Commits = shortened.Commits[:min(n, len(shortened.Commits))]
+		kept.PullRequests = shortened.PullRequests[:min(n, len(shortened.PullRequests))]
+		kept.OmittedCommits += len(shortened.Commits) - len(kept.Commits)
+		kept.OmittedPullRequests += len(shortened.PullRequests) - len(kept.PullRequests)
+		return &kept
+	}
+	longest := max(len(shortened.Commits), len(shortened.PullRequests))
+	n := sort.Search(longest, func(n int) bool { return headerTokens(keep(n+1)) > maxTokens })
+	kept := keep(max(n, 1))
+
+	tokens = headerTokens(kept)
+	slog.Info("Omitted commits and PRs/MRs to fit chunks",
+		"repo", comparison.RepoURL,
+		"omitted_commits", kept.OmittedCommits,
+		"omitted_pull_requests", kept.OmittedPullRequests,
+		"tokens", tokens)
+	return kept, tokens
+}
+
+// headerTokens estimates the tokens of a comparison's prompt without any files
+func headerTokens(comparison *types.Comparison) int {
+	return budget.EstimateTokens(formatting.FormatComparisons([]*types.Comparison{withFiles(comparison, nil)}))
+}
+
+// groupFiles groups files by top-level directory and risk class, ordered by risk then directory
+// Risk classes honor the repository's configured patterns
+func groupFiles(files []types.FileChange, repoConfig *types.RepoConfig) []group {
+	index := make(map[string]int)
+	var groups []group
+
+	for _, file := range files {
+		dir := topLevelDir(file.Filename)
+		risk := truncation.ClassifyFile(file.Filename, repoConfig)
+		key := fmt.Sprintf("%s|%d", dir, risk)
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, group{dir: dir, risk: risk})
+		}
+		groups[i].files = append(groups[i].files, file)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].risk != groups[j].risk {
+			return groups[i].risk < groups[j].risk
+		}
+		return groups[i].dir < groups[j].dir
+	})
+
+	return groups
+}
+
+// fitFile truncates a file's patch when the file alone exceeds the token budget
+// The highest-ranked hunks are kept; without separable hunks, two thirds of the kept lines come
+// from the start of the patch and one third from the end
+func fitFile(file types.FileChange, maxTokens int) types.FileChange {
+	tokens := fileTokens(file)
+	if tokens <= maxTokens || file.Patch == "" {
+		return file
+	}
+
+	lines := strings.Count(file.Patch, "\n") + 1
+	keep := max(lines*maxTokens/tokens, 2)
+	keepStart := keep * 2 / 3
+	file.Patch, _ = truncation.TruncatePatchByHunks(file.Patch, keepStart, keep-keepStart)
+
+	slog.Debug("Truncated oversized file for chunking", "file", file.Filename, "lines", lines, "kept", keep)
+	return file
+}
+
+// fileTokens estimates the tokens a file adds to the formatted diff
+func fileTokens(file types.FileChange) int {
+	return budget.EstimateTokens(file.Filename)*2 + budget.EstimateTokens(file.Patch) + 10
+}
+
+// newChunk builds a chunk for the given groups of a comparison
+func newChunk(comparison *types.Comparison, groups []group) Chunk {
+	var files []types.FileChange
+	var labels []string
+	for _, g := range groups {
+		files = append(files, g.files...)
+		labels = append(labels, fmt.Sprintf("%s (%s)", g.dir, g.risk))
+	}
+
+	if len(labels) > maxLabelGroups {
+		labels = append(labels[:maxLabelGroups], fmt.Sprintf("+%d more", len(labels)-maxLabelGroups))
+	}
+
+	repo := repoName(comparison.RepoURL)
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = repo + "/" + file.Filename
+	}
+
+	return Chunk{
+		Label:       fmt.Sprintf("%s: %s", repo, strings.Join(labels, ", ")),
+		Comparisons: []*types.Comparison{withFiles(comparison, files)},
+		Files:       paths,
+	}
+}
+
+// withFiles returns a copy of the comparison restricted to the given files, with stats recomputed
+func withFiles(comparison *types.Comparison, files []types.FileChange) *types.Comparison {
+	subset := &types.Comparison{
+		RepoURL:      comparison.RepoURL,
+		DiffURL:      comparison.DiffURL,
+		Commits:      comparison.Commits,
+		PullRequests: comparison.PullRequests,
+		Files:        files,
+		Stats:        types.ComparisonStats{TotalFiles: len(files)},
+
+		OmittedCommits:      comparison.OmittedCommits,
+		OmittedPullRequests: comparison.OmittedPullRequests,
+
+		RepoConfig: comparison.RepoConfig,
+		CI:         comparison.CI,
+	}
+	for _, file := range files {
+		subset.Stats.TotalAdditions += file.Additions
+		subset.Stats.TotalDeletions += file.Deletions
+		subset.Stats.TotalChanges += file.Changes
+	}
+	return subset
+}
+
+// topLevelDir returns the first path component followed by a slash, or "/" for files at the repository root
+func topLevelDir(filename string) string {
+	if dir, _, found := strings.Cut(filename, "/"); found {
+		return dir + "/"
+	}
+	return "/"
+}
+
+// repoName returns the repository path without scheme and host (e.g. "org/repo")
+func repoName(repoURL string) string {
+	parsed, err := url.Parse(repoURL)
+	if err != nil || parsed.Path == "" {
+		return repoURL
+	}
+	return strings.Trim(parsed.Path, "/")
+}
@@ -0,0 +1,232 @@
+package chunking
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/budget"
+	"release-confidence-score/internal/llm/formatting"
+)
+
+// patchOfLines returns a patch with the given number of added lines
+func patchOfLines(lines int) string {
+	var patch strings.Builder
+	for i := 0; i < lines; i++ {
+		patch.WriteString(fmt.Sprintf("+line %d with some padding text\n", i))
+	}
+	return patch.String()
+}
+
+func fileChange(filename string, lines int) types.FileChange {
+	return types.FileChange{Filename: filename, Status: "modified", Additions: lines, Changes: lines, Patch: patchOfLines(lines)}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name           string
+		comparisons    []*types.Comparison
+		maxTokens      int
+		expectedChunks int
+		expectedLabels []string
+	}{
+		{
+			name: "small release fits in one chunk per repository",
+			comparisons: []*types.Comparison{
+				{RepoURL: "https://github.com/org/api", Files: []types.FileChange{fileChange("main.go", 5)}},
+				{RepoURL: "https://github.com/org/web", Files: []types.FileChange{fileChange("index.js", 5)}},
+			},
+			maxTokens:      10000,
+			expectedChunks: 2,
+			expectedLabels: []string{"org/api: / (medium)", "org/web: / (medium)"},
+		},
+		{
+			name: "groups are ordered by risk",
+			comparisons: []*types.Comparison{
+				{RepoURL: "https://github.com/org/repo", Files: []types.FileChange{
+					fileChange("docs/guide.md", 5),
+					fileChange("db/migrations/001.sql", 5),
+					fileChange("deploy/app.yaml", 5),
+				}},
+			},
+			maxTokens:      10000,
+			expectedChunks: 1,
+			expectedLabels: []string{"org/repo: db/ (critical), deploy/ (high), docs/ (low)"},
+		},
+		{
+			name: "repository risk patterns change grouping",
+			comparisons: []*types.Comparison{
+				{
+					RepoURL: "https://github.com/org/repo",
+					Files: []types.FileChange{
+						fileChange("fixtures/users.json", 5),
+						fileChange("pkg/billing/total.go", 5),
+					},
+					RepoConfig: &types.RepoConfig{RiskPatterns: types.RiskPatterns{
+						Critical: []string{"pkg/billing/**"},
+						Low:      []string{"fixtures/**"},
+					}},
+				},
+			},
+			maxTokens:      10000,
+			expectedChunks: 1,
+			expectedLabels: []string{"org/repo: pkg/ (critical), fixtures/ (low)"},
+		},
+		{
+			name: "large groups are split across chunks",
+			comparisons: []*types.Comparison{
+				{RepoURL: "https://github.com/org/repo", Files: []types.FileChange{
+					fileChange("db/migrations/001.sql", 200),
+					fileChange("docs/guide.md", 200),
+				}},
+			},
+			maxTokens:      2500,
+			expectedChunks: 2,
+			expectedLabels: []string{"org/repo: db/ (critical)", "org/repo: docs/ (low)"},
+		},
+		{
+			name: "comparisons without files are skipped",
+			comparisons: []*types.Comparison{
+				nil,
+				{RepoURL: "https://github.com/org/repo"},
+			},
+			maxTokens:      10000,
+			expectedChunks: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := Split(tt.comparisons, tt.maxTokens)
+
+			if len(chunks) != tt.expectedChunks {
+				t.Fatalf("expected %d chunks, got %d", tt.expectedChunks, len(chunks))
+			}
+			for i, label := range tt.expectedLabels {
+				if chunks[i].Label != label {
+					t.Errorf("chunk %d label = %q, expected %q", i, chunks[i].Label, label)
+				}
+			}
+		})
+	}
+}
+
+func TestSplit_RespectsBudget(t *testing.T) {
+	var files []types.FileChange
+	for i := 0; i < 20; i++ {
+		files = append(files, fileChange(fmt.Sprintf("pkg%d/file.go", i%4), 50))
+	}
+	comparison := &types.Comparison{
+		RepoURL: "https://github.com/org/repo",
+		Commits: []types.Commit{{SHA: "abc123", Message: "big change"}},
+		Files:   files,
+	}
+
+	maxTokens := 2000
+	chunks := Split([]*types.Comparison{comparison}, maxTokens)
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected the release to be split, got %d chunks", len(chunks))
+	}
+
+	totalFiles := 0
+	for i, chunk := range chunks {
+		tokens := budget.EstimateTokens(formatting.FormatComparisons(chunk.Comparisons))
+		if tokens > maxTokens {
+			t.Errorf("chunk %d uses %d tokens, expected at most %d", i, tokens, maxTokens)
+		}
+		if len(chunk.Comparisons) != 1 || len(chunk.Comparisons[0].Commits) != 1 {
+			t.Errorf("chunk %d should carry its repository's commits", i)
+		}
+		if chunk.Comparisons[0].Stats.TotalFiles != len(chunk.Files) {
+			t.Errorf("chunk %d stats count %d files, expected %d", i, chunk.Comparisons[0].Stats.TotalFiles, len(chunk.Files))
+		}
+		totalFiles += len(chunk.Files)
+	}
+
+	if totalFiles != len(files) {
+		t.Errorf("expected every file in exactly one chunk, got %d of %d", totalFiles, len(files))
+	}
+}
+
+func TestSplit_TruncatesOversizedFile(t *testing.T) {
+	comparison := &types.Comparison{
+		RepoURL: "https://github.com/org/repo",
+		Files:   []types.FileChange{fileChange("api/handler.go", 2000)},
+	}
+
+	chunks := Split([]*types.Comparison{comparison}, 1000)
+
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+	if chunks[0].Files[0] != "org/repo/api/handler.go" {
+		t.Errorf("expected file path prefixed with repository, got %q", chunks[0].Files[0])
+	}
+
+	patch := chunks[0].Comparisons[0].Files[0].Patch
+	if !strings.Contains(patch, "lines omitted") {
+		t.Error("expected oversized patch to be truncated")
+	}
+	if !strings.HasPrefix(patch, "+line 0 ") {
+		t.Error("expected truncated patch to keep its beginning")
+	}
+	if comparison.Files[0].Patch != patchOfLines(2000) {
+		t.Error("expected original comparison to be left unchanged")
+	}
+}
+
+func TestNewChunk_SummarizesManyGroups(t *testing.T) {
+	comparison := &types.Comparison{RepoURL: "https://gitlab.example.com/group/service"}
+	groups := []group{
+		{dir: "a/", files: []types.FileChange{fileChange("a/x.go", 1)}},
+		{dir: "b/", files: []types.FileChange{fileChange("b/x.go", 1)}},
+		{dir: "c/", files: []types.FileChange{fileChange("c/x.go", 1)}},
+		{dir: "d/", files: []types.FileChange{fileChange("d/x.go", 1)}},
+		{dir: "e/", files: []types.FileChange{fileChange("e/x.go", 1)}},
+	}
+
+	chunk := newChunk(comparison, groups)
+
+	expected := "group/service: a/ (critical), b/ (critical), c/ (critical), +2 more"
+	if chunk.Label != expected {
+		t.Errorf("Label = %q, expected %q", chunk.Label, expected)
+	}
+	if len(chunk.Files) != 5 {
+		t.Errorf("expected 5 files, got %d", len(chunk.Files))
+	}
+}
+
+func TestSplit_ShortensOversizedCommitList(t *testing.T) {
+	comparison := &types.Comparison{
+		RepoURL: "https://github.com/org/repo",
+		Files:   []types.FileChange{fileChange("api/handler.go", 20)},
+	}
+	for i := 0; i < 200; i++ {
+		comparison.Commits = append(comparison.Commits, types.Commit{
+			ShortSHA: fmt.Sprintf("abc%04d", i),
+			Message:  fmt.Sprintf("Change number %d", i),
+			Body:     strings.Repeat("Long commit body explaining the change. ", 50),
+		})
+	}
+
+	chunks := Split([]*types.Comparison{comparison}, 2000)
+
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+	chunked := chunks[0].Comparisons[0]
+	if chunked.Files[0].Patch != patchOfLines(20) {
+		t.Error("expected file patch to be kept whole")
+	}
+	if chunked.OmittedCommits == 0 || chunked.OmittedCommits+len(chunked.Commits) != 200 {
+		t.Errorf("expected omitted and kept commits to add up to 200, got %d omitted and %d kept", chunked.OmittedCommits, len(chunked.Commits))
+	}
+	if !strings.Contains(formatting.FormatComparisons(chunks[0].Comparisons), "more commits omitted") {
+		t.Error("expected formatted chunk to note the omitted commits")
+	}
+	if len(comparison.Commits) != 200 || comparison.OmittedCommits != 0 || !strings.HasPrefix(comparison.Commits[0].Body, "Long commit body") || len(comparison.Commits[0].Body) != 2000 {
+		t.Error("expected original comparison to be left unchanged")
+	}
+}
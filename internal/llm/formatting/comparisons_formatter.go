@@ -40,15 +40,34 @@ func FormatComparisons(comparisons []*types.Comparison) string {
 
 			qeLabel := formatQELabel(commit.QETestingLabel)
 			result.WriteString(fmt.Sprintf("- %s (%s)%s\n", message, author, qeLabel))
+			result.WriteString(indent(commit.Body))
+		}
+		if comparison.OmittedCommits > 0 {
+			result.WriteString(fmt.Sprintf("- [... %d more commits omitted to fit the context window]\n", comparison.OmittedCommits))
 		}
 		result.WriteString("\n")
 
+		if len(comparison.PullRequests) > 0 {
+			result.WriteString("Pull/Merge Requests:\n")
+			for _, pr := range comparison.PullRequests {
+				result.WriteString(fmt.Sprintf("- #%d %s\n", pr.Number, pr.Title))
+				result.WriteString(indent(pr.Description))
+			}
+			if comparison.OmittedPullRequests > 0 {
+				result.WriteString(fmt.Sprintf("- [... %d more PRs/MRs omitted to fit the context window]\n", comparison.OmittedPullRequests))
+			}
+			result.WriteString("\n")
+		}
+
 		result.WriteString("Files:\n")
 		for _, file := range comparison.Files {
 			filename := file.Filename
 			if file.PreviousFilename != "" {
 				filename = fmt.Sprintf("%s (renamed from %s)", file.Filename, file.PreviousFilename)
 			}
+			if file.Generated != "" {
+				filename = fmt.Sprintf("%s [%s, patch summarized]", filename, file.Generated)
+			}
 			result.WriteString(fmt.Sprintf("- %s: %s +%d/-%d\n", filename, file.Status, file.Additions, file.Deletions))
 		}
 
@@ -83,6 +102,22 @@ func FormatComparisons(comparisons []*types.Comparison) string {
 	return result.String()
 }
 
+// indent indents every line of a commit body or PR/MR description to nest it under its list item
+func indent(text string) string {
+	if text == "" {
+		return ""
+	}
+	var result strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			result.WriteString("\n")
+			continue
+		}
+		result.WriteString("  " + line + "\n")
+	}
+	return result.String()
+}
+
 // formatQELabel returns a formatted QE label suffix for commit lines
 func formatQELabel(label string) string {
 	switch label {
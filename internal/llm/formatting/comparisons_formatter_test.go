@@ -341,6 +341,60 @@ func TestFormatComparisons(t *testing.T) {
 			t.Error("third commit should not have QE label")
 		}
 	})
+
+	t.Run("summarized generated files", func(t *testing.T) {
+		comparison := &types.Comparison{
+			RepoURL: "https://github.com/test/repo",
+			Commits: []types.Commit{{Message: "Bump deps", Author: "Alice"}},
+			Files: []types.FileChange{
+				{Filename: "go.sum", Status: "modified", Additions: 40, Deletions: 38, Patch: "[lockfile file: raw patch omitted, +40/-38 lines]", Generated: "lockfile"},
+				{Filename: "main.go", Status: "modified", Additions: 1, Deletions: 1, Patch: "diff"},
+			},
+			Stats: types.ComparisonStats{TotalFiles: 2, TotalAdditions: 41, TotalDeletions: 39},
+		}
+
+		result := FormatComparisons([]*types.Comparison{comparison})
+
+		if !strings.Contains(result, "- go.sum [lockfile, patch summarized]: modified +40/-38") {
+			t.Error("missing summarized marker on lockfile")
+		}
+		if !strings.Contains(result, "- main.go: modified +1/-1") {
+			t.Error("regular files should not be marked")
+		}
+		if !strings.Contains(result, "Total: 2 files, +41/-39 lines") {
+			t.Error("summarized files should still count in the totals")
+		}
+	})
+
+	t.Run("commit bodies and pull requests", func(t *testing.T) {
+		comparison := &types.Comparison{
+			RepoURL: "https://github.com/test/repo",
+			Commits: []types.Commit{
+				{Message: "feat: drop v1 API", Author: "Alice", Body: "Clients must move to v2.\n\nBREAKING CHANGE: /v1 is gone", PRNumber: 12},
+				{Message: "Fix typo", Author: "Bob"},
+			},
+			PullRequests: []types.PullRequest{
+				{Number: 12, Title: "Drop the v1 API", Description: "## Rollout\nDeploy after the clients."},
+			},
+		}
+
+		result := FormatComparisons([]*types.Comparison{comparison})
+
+		expected := "Commits:\n" +
+			"- feat: drop v1 API (Alice)\n" +
+			"  Clients must move to v2.\n" +
+			"\n" +
+			"  BREAKING CHANGE: /v1 is gone\n" +
+			"- Fix typo (Bob)\n" +
+			"\n" +
+			"Pull/Merge Requests:\n" +
+			"- #12 Drop the v1 API\n" +
+			"  ## Rollout\n" +
+			"  Deploy after the clients.\n"
+		if !strings.Contains(result, expected) {
+			t.Errorf("expected commit bodies and pull requests:\n%s\ngot:\n%s", expected, result)
+		}
+	})
 }
 
 func TestFormatQELabel(t *testing.T) {
@@ -0,0 +1,231 @@
+package injection
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// Kinds of instruction-like content
+const (
+	KindInstructionOverride = "instruction_override"
+	KindScoreManipulation   = "score_manipulation"
+	KindRoleOverride        = "role_override"
+	KindDelimiterSpoofing   = "delimiter_spoofing"
+)
+
+// Sources of untrusted content
+const (
+	SourceDiff          = "diff"
+	SourceCommit        = "commit"
+	SourcePullRequest   = "pull_request"
+	SourceDocumentation = "documentation"
+	SourceGuidance      = "guidance"
+)
+
+// blockTag delimits untrusted content in prompts
+const blockTag = "untrusted_input"
+
+// maxExcerptLength bounds the matched text quoted in findings
+const maxExcerptLength = 80
+
+// Score gaps above the rule-based score that are flagged as suspicious inflation
+const (
+	inflationGap             = 25
+	inflationGapWithAttempts = 10
+)
+
+// instructionPattern detects one kind of content that tries to instruct the model
+type instructionPattern struct {
+	kind  string
+	regex *regexp.Regexp
+}
+
+var instructionPatterns = []instructionPattern{
+	{KindInstructionOverride, regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override)\b[^.\n]{0,40}\b(?:previous|prior|above|earlier|preceding|system)\b[^.\n]{0,20}\b(?:instructions?|prompts?|rules|guidelines|directions)\b`)},
+	{KindScoreManipulation, regexp.MustCompile(`(?i)\b(?:set|give|assign|rate|make|return|output|report)\b[^.\n]{0,30}\b(?:confidence )?score\b[^.\n]{0,15}?\b(?:to|of|as|at|=)\s*(?:9\d|100)\b`)},
+	{KindRoleOverride, regexp.MustCompile(`(?i)\byou are now (?:an?|the|in)\b|\bnew (?:system )?instructions\s*:|\bpretend (?:to be|you are)\b`)},
+	{KindDelimiterSpoofing, regexp.MustCompile(`(?i)</?` + blockTag + `\b|<\|(?:im_start|im_end|system|assistant)\|>|\[/?INST\]`)},
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// Finding is instruction-like content found in untrusted input
+type Finding struct {
+	Kind     string `json:"kind"`
+	Source   string `json:"source"`
+	Repo     string `json:"repo,omitempty"`
+	Location string `json:"location"` // File path, commit, documentation file or guidance author
+	Line     int    `json:"line,omitempty"`
+	Excerpt  string `json:"excerpt"`
+}
+
+// Inflation flags an AI score well above the deterministic rule-based score
+type Inflation struct {
+	Score     int `json:"score"`
+	RuleScore int `json:"rule_score"`
+	Gap       int `json:"gap"`
+}
+
+// Wrap delimits untrusted content so the model can tell data from instructions
+// Delimiter tags inside the content are escaped so it can't close the block early
+func Wrap(source, content string) string {
+	escaped := strings.NewReplacer(
+		"<"+blockTag, "&lt;"+blockTag,
+		"</"+blockTag, "&lt;/"+blockTag,
+	).Replace(content)
+	return fmt.Sprintf("<%s source=%q>\n%s\n</%s>", blockTag, source, escaped, blockTag)
+}
+
+// Scan looks for instruction-like content in everything that reaches the prompt
+// Only authorized guidance is scanned because unauthorized guidance is never sent to the model
+func Scan(comparisons []*types.Comparison, docs []*types.Documentation, guidance []types.UserGuidance) []Finding {
+	var findings []Finding
+
+	for _, comparison := range comparisons {
+		if comparison == nil {
+			continue
+		}
+		for _, commit := range comparison.Commits {
+			// The body follows a blank line, so finding lines match the full commit message
+			for _, finding := range scanText(commit.Message + "\n\n" + commit.Body) {
+				finding.Source, finding.Repo, finding.Location = SourceCommit, comparison.RepoURL, "commit "+commit.ShortSHA
+				findings = append(findings, finding)
+			}
+		}
+		for _, pr := range comparison.PullRequests {
+			for _, finding := range scanText(pr.Title + "\n" + pr.Description) {
+				finding.Source, finding.Repo, finding.Location = SourcePullRequest, comparison.RepoURL, fmt.Sprintf("PR/MR #%d", pr.Number)
+				findings = append(findings, finding)
+			}
+		}
+		for _, file := range comparison.Files {
+			for _, finding := range scanPatch(file.Patch) {
+				finding.Source, finding.Repo, finding.Location = SourceDiff, comparison.RepoURL, file.Filename
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		contents := map[string]string{doc.MainDocFile: doc.MainDocContent}
+		for name, content := range doc.AdditionalDocsContent {
+			contents[name] = content
+		}
+		for _, name := range sortedKeys(contents) {
+			for _, finding := range scanText(contents[name]) {
+				finding.Source, finding.Repo, finding.Location = SourceDocumentation, doc.Repository.URL, name
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	for _, g := range guidance {
+		if !g.IsAuthorized {
+			continue
+		}
+		for _, finding := range scanText(g.Content) {
+			finding.Source, finding.Location = SourceGuidance, "guidance by @"+g.Author
+			findings = append(findings, finding)
+		}
+	}
+
+	if len(findings) > 0 {
+		slog.Warn("Detected instruction-like content in release data", "count", len(findings))
+	}
+	return findings
+}
+
+// CheckInflation returns the gap between the AI score and the rule-based score when it is large
+// enough to be suspicious; a smaller gap is enough when injection attempts were found
+func CheckInflation(score, ruleScore int, attempts []Finding) *Inflation {
+	threshold := inflationGap
+	if len(attempts) > 0 {
+		threshold = inflationGapWithAttempts
+	}
+
+	gap := score - ruleScore
+	if gap < threshold {
+		return nil
+	}
+	return &Inflation{Score: score, RuleScore: ruleScore, Gap: gap}
+}
+
+// scanText finds instruction-like content in plain text; finding lines are 1-based lines of the text
+func scanText(text string) []Finding {
+	var findings []Finding
+	for i, line := range strings.Split(text, "\n") {
+		findings = append(findings, scanLine(line, i+1)...)
+	}
+	return findings
+}
+
+// scanPatch finds instruction-like content in a unified diff, numbering lines like the new file
+// (removed lines use the old file's numbering)
+func scanPatch(patch string) []Finding {
+	var findings []Finding
+	oldLine, newLine := 0, 0
+	for _, line := range strings.Split(patch, "\n") {
+		if matches := hunkHeader.FindStringSubmatch(line); matches != nil {
+			oldLine, _ = strconv.Atoi(matches[1])
+			newLine, _ = strconv.Atoi(matches[2])
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		switch line[0] {
+		case '\\':
+			// "\ No newline at end of file" markers aren't part of either file
+		case '+':
+			findings = append(findings, scanLine(line[1:], newLine)...)
+			newLine++
+		case '-':
+			findings = append(findings, scanLine(line[1:], oldLine)...)
+			oldLine++
+		default:
+			findings = append(findings, scanLine(line[1:], newLine)...)
+			oldLine++
+			newLine++
+		}
+	}
+	return findings
+}
+
+// scanLine reports each kind of instruction-like content found in one line
+func scanLine(line string, lineNumber int) []Finding {
+	var findings []Finding
+	for _, pattern := range instructionPatterns {
+		if match := pattern.regex.FindString(line); match != "" {
+			findings = append(findings, Finding{Kind: pattern.kind, Line: lineNumber, Excerpt: excerpt(match)})
+		}
+	}
+	return findings
+}
+
+// excerpt shortens matched text for display and strips characters that would break markdown code spans
+func excerpt(match string) string {
+	match = strings.TrimSpace(strings.ReplaceAll(match, "`", "'"))
+	if len(match) > maxExcerptLength {
+		match = match[:maxExcerptLength] + "..."
+	}
+	return match
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
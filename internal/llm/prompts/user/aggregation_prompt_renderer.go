@@ -0,0 +1,68 @@
+package user
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"release-confidence-score/internal/git/types"
+)
+
+//go:embed aggregation_prompt_template.md
+var aggregationPromptTemplateText string
+
+var aggregationPromptTemplate *template.Template
+
+func init() {
+	aggregationPromptTemplate = template.Must(
+		template.New("aggregation_prompt").
+			Funcs(templateFuncs()).
+			Funcs(template.FuncMap{"findings": formatChunkFindings}).
+			Parse(aggregationPromptTemplateText),
+	)
+}
+
+// ChunkFindings is the analysis of one chunk of a release, as passed to the aggregation prompt
+type ChunkFindings struct {
+	Label    string
+	Files    []string
+	Analysis string // JSON analysis returned for the chunk
+}
+
+// AggregationPromptData holds the data for the aggregation prompt template
+type AggregationPromptData struct {
+	Chunks        []ChunkFindings
+	Documentation string
+	Evidence      string // Optional pre-computed signals for the whole release
+	UserGuidance  []string
+}
+
+// RenderAggregationPrompt formats the prompt that combines per-chunk analyses into one release analysis
+func RenderAggregationPrompt(chunks []ChunkFindings, evidence, documentation string, userGuidance []types.UserGuidance) (string, error) {
+	data := AggregationPromptData{
+		Chunks:        chunks,
+		Documentation: documentation,
+		Evidence:      evidence,
+		UserGuidance:  extractAuthorizedGuidance(userGuidance),
+	}
+
+	var buf bytes.Buffer
+	if err := aggregationPromptTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute aggregation prompt template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// formatChunkFindings lists the scope, files and analysis of one chunk, to be wrapped as untrusted input
+func formatChunkFindings(chunk ChunkFindings) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Scope: %s\nFiles:\n", chunk.Label)
+	for _, file := range chunk.Files {
+		fmt.Fprintf(&b, "- %s\n", file)
+	}
+	fmt.Fprintf(&b, "\nAnalysis:\n%s", chunk.Analysis)
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package user
+
+import (
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+func TestRenderAggregationPrompt(t *testing.T) {
+	chunks := []ChunkFindings{
+		{Label: "org/repo: db/ (critical)", Files: []string{"org/repo/db/001.sql"}, Analysis: `{"score": 40}`},
+		{Label: "org/repo: docs/ (low)", Files: []string{"org/repo/docs/a.md"}, Analysis: `{"score": 95}`},
+	}
+	guidance := []types.UserGuidance{{Content: "Focus on the migration", IsAuthorized: true}}
+
+	prompt, err := RenderAggregationPrompt(chunks, "**Rule-based score:** 85/100\n", "Service docs", guidance)
+	if err != nil {
+		t.Fatalf("RenderAggregationPrompt() error = %v", err)
+	}
+
+	expected := []string{
+		"split into 2 parts",
+		"### Part 1\n<untrusted_input source=\"partial_analysis\">\nScope: org/repo: db/ (critical)\nFiles:\n- org/repo/db/001.sql\n\nAnalysis:\n{\"score\": 40}\n</untrusted_input>",
+		"### Part 2\n<untrusted_input source=\"partial_analysis\">\nScope: org/repo: docs/ (low)",
+		"<untrusted_input source=\"guidance\">\n- Focus on the migration\n</untrusted_input>",
+		"## Pre-computed Evidence",
+		"<untrusted_input source=\"evidence\">\n**Rule-based score:** 85/100\n\n</untrusted_input>",
+		"## Documentation\n<untrusted_input source=\"documentation\">\nService docs\n</untrusted_input>",
+	}
+	for _, want := range expected {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("RenderAggregationPrompt() missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package user
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"text/template"
+)
+
+//go:embed repair_prompt_template.md
+var repairPromptTemplateText string
+
+var repairPromptTemplate *template.Template
+
+func init() {
+	repairPromptTemplate = template.Must(
+		template.New("repair_prompt").Parse(repairPromptTemplateText),
+	)
+}
+
+// RepairPromptData holds the data for the repair prompt template
+type RepairPromptData struct {
+	OriginalPrompt   string
+	PreviousResponse string
+	Problems         []string
+}
+
+// RenderRepairPrompt formats a follow-up prompt asking the model to fix a response that failed schema validation
+// The original prompt is repeated so the model can regenerate a truncated response instead of guessing
+func RenderRepairPrompt(originalPrompt, previousResponse string, problems []string) (string, error) {
+	data := RepairPromptData{
+		OriginalPrompt:   originalPrompt,
+		PreviousResponse: previousResponse,
+		Problems:         problems,
+	}
+
+	var buf bytes.Buffer
+	if err := repairPromptTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute repair prompt template: %w", err)
+	}
+
+	return buf.String(), nil
+}
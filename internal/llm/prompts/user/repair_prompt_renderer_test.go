@@ -0,0 +1,34 @@
+package user
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderRepairPrompt(t *testing.T) {
+	prompt, err := RenderRepairPrompt(
+		"Analyze these code changes",
+		`{"score": 150, "summary": "Oops"}`,
+		[]string{"score must be at most 100, got 150", `risk_summary.concerns[0].severity must be one of [critical high medium low], got "severe"`},
+	)
+	if err != nil {
+		t.Fatalf("RenderRepairPrompt() error = %v", err)
+	}
+
+	expected := []string{
+		"Analyze these code changes",
+		"## Previous Response Rejected",
+		"- score must be at most 100, got 150",
+		`got "severe"`,
+		"<previous_response>\n{\"score\": 150, \"summary\": \"Oops\"}\n</previous_response>",
+	}
+	for _, want := range expected {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("RenderRepairPrompt() missing %q", want)
+		}
+	}
+
+	if !strings.HasPrefix(prompt, "Analyze these code changes") {
+		t.Error("RenderRepairPrompt() should start with the original prompt")
+	}
+}
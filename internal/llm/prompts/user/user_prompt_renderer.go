@@ -4,9 +4,11 @@ import (
 	"bytes"
 	_ "embed"
 	"fmt"
+	"strings"
 	"text/template"
 
 	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/injection"
 	"release-confidence-score/internal/llm/truncation"
 )
 
@@ -17,23 +19,52 @@ var userPromptTemplate *template.Template
 
 func init() {
 	userPromptTemplate = template.Must(
-		template.New("user_prompt").Parse(userPromptTemplateV1),
+		template.New("user_prompt").Funcs(templateFuncs()).Parse(userPromptTemplateV1),
 	)
 }
 
+// templateFuncs returns the functions shared by the user and aggregation prompt templates
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"add":       func(a, b int) int { return a + b },
+		"bullets":   bullets,
+		"untrusted": injection.Wrap,
+	}
+}
+
 // PromptData holds the data for the user prompt template
 type PromptData struct {
+	Chunk              *ChunkContext // Optional scope of a partial analysis in hierarchical mode
 	Diff               string
 	Documentation      string
+	Evidence           string                         // Optional pre-computed signals from deterministic analyzers
 	TruncationMetadata *truncation.TruncationMetadata // Optional truncation information
 	UserGuidance       []string
 }
 
-// RenderUserPrompt formats the user prompt with diff, documentation, user guidance, and truncation metadata
-func RenderUserPrompt(diff, documentation string, userGuidance []types.UserGuidance, truncationMetadata truncation.TruncationMetadata) (string, error) {
+// ChunkContext describes which part of a split release a prompt covers
+type ChunkContext struct {
+	Index int // 1-based position of the chunk
+	Total int
+	Label string
+}
+
+// RenderUserPrompt formats the user prompt with diff, evidence, documentation, user guidance, and truncation metadata
+func RenderUserPrompt(diff, evidence, documentation string, userGuidance []types.UserGuidance, truncationMetadata truncation.TruncationMetadata) (string, error) {
+	return renderUserPrompt(diff, evidence, documentation, userGuidance, truncationMetadata, nil)
+}
+
+// RenderChunkPrompt formats the user prompt for one chunk of a release analyzed in hierarchical mode
+func RenderChunkPrompt(diff, evidence, documentation string, userGuidance []types.UserGuidance, chunk ChunkContext) (string, error) {
+	return renderUserPrompt(diff, evidence, documentation, userGuidance, truncation.TruncationMetadata{}, &chunk)
+}
+
+func renderUserPrompt(diff, evidence, documentation string, userGuidance []types.UserGuidance, truncationMetadata truncation.TruncationMetadata, chunk *ChunkContext) (string, error) {
 	data := PromptData{
+		Chunk:         chunk,
 		Diff:          diff,
 		Documentation: documentation,
+		Evidence:      evidence,
 		UserGuidance:  extractAuthorizedGuidance(userGuidance),
 	}
 
@@ -60,3 +91,12 @@ func extractAuthorizedGuidance(userGuidance []types.UserGuidance) (authorized []
 	}
 	return
 }
+
+// bullets formats items as a markdown list
+func bullets(items []string) string {
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = "- " + item
+	}
+	return strings.Join(lines, "\n")
+}
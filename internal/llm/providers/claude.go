@@ -15,19 +15,37 @@ import (
 	httputil "release-confidence-score/internal/http"
 	llmerrors "release-confidence-score/internal/llm/errors"
 	"release-confidence-score/internal/llm/prompts/system"
+	"release-confidence-score/internal/llm/schema"
 )
 
+// analysisToolName is the tool Claude is forced to call in structured output mode
+const analysisToolName = "submit_release_analysis"
+
 type ClaudeClient struct {
 	config      *config.Config
 	tokenSource oauth2.TokenSource
 }
 
 type ClaudeRequest struct {
-	AnthropicVersion string          `json:"anthropic_version"`
-	MaxTokens        int             `json:"max_tokens"`
-	Messages         []ClaudeMessage `json:"messages"`
-	System           string          `json:"system"`
-	Temperature      float64         `json:"temperature"`
+	AnthropicVersion string            `json:"anthropic_version"`
+	MaxTokens        int               `json:"max_tokens"`
+	Messages         []ClaudeMessage   `json:"messages"`
+	System           string            `json:"system"`
+	Temperature      float64           `json:"temperature"`
+	ToolChoice       *ClaudeToolChoice `json:"tool_choice,omitempty"`
+	Tools            []ClaudeTool      `json:"tools,omitempty"`
+}
+
+// ClaudeTool describes a tool the model can call; its input schema constrains the output
+type ClaudeTool struct {
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+	Name        string         `json:"name"`
+}
+
+type ClaudeToolChoice struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
 }
 
 type ClaudeMessage struct {
@@ -41,8 +59,10 @@ type ClaudeResponse struct {
 }
 
 type ClaudeContent struct {
-	Text string `json:"text"`
-	Type string `json:"type"`
+	Input json.RawMessage `json:"input,omitempty"` // Tool arguments, set on "tool_use" blocks
+	Name  string          `json:"name,omitempty"`
+	Text  string          `json:"text,omitempty"`
+	Type  string          `json:"type"`
 }
 
 type ClaudeUsage struct {
@@ -76,7 +96,17 @@ func (c *ClaudeClient) Analyze(userPrompt string) (string, error) {
 			}},
 		}},
 		MaxTokens:   cfg.ModelMaxResponseTokens,
-		Temperature: 0,
+		Temperature: cfg.ModelTemperature,
+	}
+
+	// Force a tool call so the analysis arrives as schema-conforming tool input instead of free text
+	if cfg.ModelStructuredOutput {
+		req.Tools = []ClaudeTool{{
+			Name:        analysisToolName,
+			Description: "Submit the release confidence analysis",
+			InputSchema: schema.ForProvider(),
+		}}
+		req.ToolChoice = &ClaudeToolChoice{Type: "tool", Name: analysisToolName}
 	}
 
 	jsonData, err := json.Marshal(req)
@@ -84,7 +114,7 @@ func (c *ClaudeClient) Analyze(userPrompt string) (string, error) {
 		return "", fmt.Errorf("marshal request: %w", err)
 	}
 
-	slog.Debug("Claude API request", "request", jsonData)
+	slog.Debug("Claude API request", "request_bytes", len(jsonData))
 
 	httpReq, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -133,12 +163,28 @@ func (c *ClaudeClient) Analyze(userPrompt string) (string, error) {
 		return "", fmt.Errorf("no content in response")
 	}
 
-	slog.Debug("Claude API response", "response", response)
+	slog.Debug("Claude API response", "response_bytes", len(body))
 
 	slog.Debug("Claude API token usage",
 		"input_tokens", response.Usage.InputTokens,
 		"output_tokens", response.Usage.OutputTokens,
 		"total_tokens", response.Usage.InputTokens+response.Usage.OutputTokens)
 
-	return response.Content[0].Text, nil
+	return responseText(response.Content), nil
+}
+
+// responseText returns the analysis from the response content
+// The tool input is preferred when the model called the analysis tool; otherwise the first text block is used
+func responseText(content []ClaudeContent) string {
+	for _, block := range content {
+		if block.Type == "tool_use" && block.Name == analysisToolName {
+			return string(block.Input)
+		}
+	}
+	for _, block := range content {
+		if block.Type == "text" {
+			return block.Text
+		}
+	}
+	return content[0].Text
 }
@@ -107,6 +107,111 @@ func TestClaudeAnalyze_Success(t *testing.T) {
 	}
 }
 
+func TestClaudeAnalyze_UsesConfiguredTemperature(t *testing.T) {
+	var request ClaudeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(ClaudeResponse{Content: []ClaudeContent{{Type: "text", Text: "{}"}}})
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		ModelAPI:            server.URL,
+		ModelID:             "claude-test",
+		ModelTemperature:    0.7,
+		ModelTimeoutSeconds: 30,
+		SystemPromptVersion: "v1",
+	}
+
+	if _, err := NewClaude(cfg, mockTS()).Analyze("test prompt"); err != nil {
+		t.Fatalf("Analyze() unexpected error: %v", err)
+	}
+	if request.Temperature != 0.7 {
+		t.Errorf("request temperature = %v, want 0.7", request.Temperature)
+	}
+}
+
+func TestClaudeAnalyze_StructuredOutput(t *testing.T) {
+	tests := []struct {
+		name             string
+		structuredOutput bool
+		content          []ClaudeContent
+		expected         string
+	}{
+		{
+			name:             "tool input returned",
+			structuredOutput: true,
+			content: []ClaudeContent{
+				{Type: "text", Text: "Submitting the analysis."},
+				{Type: "tool_use", Name: analysisToolName, Input: json.RawMessage(`{"score":80,"summary":"OK"}`)},
+			},
+			expected: `{"score":80,"summary":"OK"}`,
+		},
+		{
+			name:             "falls back to text when no tool was called",
+			structuredOutput: true,
+			content:          []ClaudeContent{{Type: "text", Text: `{"score": 70}`}},
+			expected:         `{"score": 70}`,
+		},
+		{
+			name:             "disabled sends no tools",
+			structuredOutput: false,
+			content:          []ClaudeContent{{Type: "text", Text: `{"score": 60}`}},
+			expected:         `{"score": 60}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request ClaudeRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Errorf("Failed to decode request: %v", err)
+				}
+				json.NewEncoder(w).Encode(ClaudeResponse{Content: tt.content})
+			}))
+			defer server.Close()
+
+			cfg := &config.Config{
+				ModelAPI:              server.URL,
+				ModelID:               "claude-test",
+				ModelStructuredOutput: tt.structuredOutput,
+				ModelTimeoutSeconds:   30,
+				SystemPromptVersion:   "v1",
+			}
+
+			result, err := NewClaude(cfg, mockTS()).Analyze("test prompt")
+			if err != nil {
+				t.Fatalf("Analyze() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Analyze() result = %q, want %q", result, tt.expected)
+			}
+
+			if !tt.structuredOutput {
+				if len(request.Tools) != 0 || request.ToolChoice != nil {
+					t.Errorf("expected no tools, got %+v / %+v", request.Tools, request.ToolChoice)
+				}
+				return
+			}
+			if len(request.Tools) != 1 || request.Tools[0].Name != analysisToolName {
+				t.Fatalf("expected the %s tool, got %+v", analysisToolName, request.Tools)
+			}
+			if request.Tools[0].InputSchema["type"] != "object" || request.Tools[0].InputSchema["properties"] == nil {
+				t.Errorf("unexpected input schema: %v", request.Tools[0].InputSchema)
+			}
+			if encoded, _ := json.Marshal(request.Tools[0].InputSchema); strings.Contains(string(encoded), `"minLength"`) {
+				t.Errorf("expected the input schema without minLength, got %s", encoded)
+			}
+			if request.ToolChoice == nil || request.ToolChoice.Type != "tool" || request.ToolChoice.Name != analysisToolName {
+				t.Errorf("expected tool_choice forcing %s, got %+v", analysisToolName, request.ToolChoice)
+			}
+		})
+	}
+}
+
 func TestClaudeAnalyze_EmptyResponse(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		response := ClaudeResponse{
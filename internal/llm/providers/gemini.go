@@ -15,6 +15,7 @@ import (
 	httputil "release-confidence-score/internal/http"
 	llmerrors "release-confidence-score/internal/llm/errors"
 	"release-confidence-score/internal/llm/prompts/system"
+	"release-confidence-score/internal/llm/schema"
 )
 
 type GeminiClient struct {
@@ -23,10 +24,22 @@ type GeminiClient struct {
 }
 
 type GeminiRequest struct {
-	MaxTokens   int             `json:"max_tokens"`
-	Messages    []GeminiMessage `json:"messages"`
-	Model       string          `json:"model"`
-	Temperature float64         `json:"temperature"`
+	MaxTokens      int                   `json:"max_tokens"`
+	Messages       []GeminiMessage       `json:"messages"`
+	Model          string                `json:"model"`
+	ResponseFormat *GeminiResponseFormat `json:"response_format,omitempty"`
+	Temperature    float64               `json:"temperature"`
+}
+
+// GeminiResponseFormat requests JSON output conforming to a schema (OpenAI-compatible API)
+type GeminiResponseFormat struct {
+	JSONSchema *GeminiJSONSchema `json:"json_schema,omitempty"`
+	Type       string            `json:"type"`
+}
+
+type GeminiJSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
 }
 
 type GeminiMessage struct {
@@ -66,7 +79,18 @@ func (g *GeminiClient) Analyze(userPrompt string) (string, error) {
 			Content: combinedPrompt,
 		}},
 		MaxTokens:   cfg.ModelMaxResponseTokens,
-		Temperature: 0,
+		Temperature: cfg.ModelTemperature,
+	}
+
+	// Constrain decoding to the analysis schema instead of relying on prompt instructions
+	if cfg.ModelStructuredOutput {
+		req.ResponseFormat = &GeminiResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &GeminiJSONSchema{
+				Name:   "release_analysis",
+				Schema: schema.ForProvider(),
+			},
+		}
 	}
 
 	jsonData, err := json.Marshal(req)
@@ -74,7 +98,7 @@ func (g *GeminiClient) Analyze(userPrompt string) (string, error) {
 		return "", fmt.Errorf("marshal request: %w", err)
 	}
 
-	slog.Debug("Gemini API request", "request", jsonData)
+	slog.Debug("Gemini API request", "request_bytes", len(jsonData))
 
 	url := cfg.ModelAPI + "/v1beta/openai/chat/completions"
 	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
@@ -129,7 +153,7 @@ func (g *GeminiClient) Analyze(userPrompt string) (string, error) {
 		return "", fmt.Errorf("no choices in response")
 	}
 
-	slog.Debug("Gemini API response", "response", response)
+	slog.Debug("Gemini API response", "response_bytes", len(body))
 
 	slog.Debug("Gemini API token usage",
 		"input_tokens", response.Usage.PromptTokens,
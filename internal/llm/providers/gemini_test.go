@@ -89,6 +89,60 @@ func TestGeminiAnalyze_Success(t *testing.T) {
 	}
 }
 
+func TestGeminiAnalyze_StructuredOutput(t *testing.T) {
+	tests := []struct {
+		name             string
+		structuredOutput bool
+	}{
+		{"enabled requests json_schema response format", true},
+		{"disabled sends no response format", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request GeminiRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Errorf("Failed to decode request: %v", err)
+				}
+				json.NewEncoder(w).Encode(GeminiResponse{
+					Choices: []GeminiChoice{{Message: GeminiMessage{Role: "assistant", Content: `{"score": 90}`}}},
+				})
+			}))
+			defer server.Close()
+
+			cfg := &config.Config{
+				ModelAPI:              server.URL,
+				ModelID:               "gemini-test",
+				ModelStructuredOutput: tt.structuredOutput,
+				ModelTimeoutSeconds:   30,
+				SystemPromptVersion:   "v1",
+			}
+
+			if _, err := NewGemini(cfg, mockTS()).Analyze("test prompt"); err != nil {
+				t.Fatalf("Analyze() unexpected error: %v", err)
+			}
+
+			if !tt.structuredOutput {
+				if request.ResponseFormat != nil {
+					t.Errorf("expected no response format, got %+v", request.ResponseFormat)
+				}
+				return
+			}
+			if request.ResponseFormat == nil || request.ResponseFormat.Type != "json_schema" {
+				t.Fatalf("expected json_schema response format, got %+v", request.ResponseFormat)
+			}
+			schema := request.ResponseFormat.JSONSchema
+			if schema == nil || schema.Name == "" || schema.Schema["properties"] == nil {
+				t.Errorf("unexpected json_schema: %+v", schema)
+			}
+			if encoded, _ := json.Marshal(schema.Schema); strings.Contains(string(encoded), `"minLength"`) {
+				t.Errorf("expected the json_schema without minLength, got %s", encoded)
+			}
+		})
+	}
+}
+
 func TestGeminiAnalyze_EmptyResponse(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		response := GeminiResponse{
@@ -0,0 +1,356 @@
+package redaction
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// Secret types used in placeholders and findings
+const (
+	TypeAWSAccessKey     = "aws_access_key"
+	TypeGCPAPIKey        = "gcp_api_key"
+	TypeGitHubToken      = "github_token"
+	TypeGitLabToken      = "gitlab_token"
+	TypeSlackToken       = "slack_token"
+	TypeJWT              = "jwt"
+	TypePrivateKey       = "private_key"
+	TypeConnectionString = "connection_string"
+	TypeHighEntropy      = "high_entropy_secret"
+)
+
+// Minimum length and Shannon entropy (bits per character) of a value assigned to a secret-like key
+const (
+	minSecretLength  = 16
+	minSecretEntropy = 3.5
+)
+
+// secretPattern detects one secret format; group selects the submatch to redact (0 for the whole match)
+type secretPattern struct {
+	secretType string
+	regex      *regexp.Regexp
+	group      int
+}
+
+// secretPatterns are checked in order; earlier, more specific formats win
+var secretPatterns = []secretPattern{
+	{TypeAWSAccessKey, regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), 0},
+	{TypeGCPAPIKey, regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}`), 0},
+	{TypeGitHubToken, regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`), 0},
+	{TypeGitLabToken, regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}`), 0},
+	{TypeSlackToken, regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9\-]{10,}`), 0},
+	{TypeJWT, regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`), 0},
+	{TypeConnectionString, regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.\-]*://[^\s:/@'"]+:([^\s@/'"]+)@`), 1},
+}
+
+// secretAssignmentRegex matches values assigned to keys whose name suggests a secret
+// The value is redacted only if it is long and random enough to be a real credential
+var secretAssignmentRegex = regexp.MustCompile(`(?i)[a-z0-9_.\-]*(?:secret|token|passw(?:or)?d|pwd|api_?key|access_?key|private_?key|credential|auth)[a-z0-9_.\-]*["']?\s*[:=]\s*["']?([^\s'"]{16,})(["']|\s*$|\s*[,;])`)
+
+var (
+	pemBeginRegex = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+	pemEndRegex   = regexp.MustCompile(`-----END [A-Z ]*PRIVATE KEY-----`)
+	hunkHeader    = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+)
+
+// Finding records a secret that was redacted
+type Finding struct {
+	Type     string `json:"type"`
+	Repo     string `json:"repo,omitempty"` // Repository URL, when the secret came from a repository
+	Location string `json:"location"`       // File path, documentation file or guidance author
+	Line     int    `json:"line"`           // Line in the new file for patches, or in the text otherwise
+	Removed  bool   `json:"removed,omitempty"`
+}
+
+// Placeholder returns the text that replaces a secret of the given type
+func Placeholder(secretType string) string {
+	return fmt.Sprintf("[REDACTED:%s]", secretType)
+}
+
+// Comparisons returns copies of the comparisons with secrets in file patches, commit messages
+// and PR/MR titles and descriptions redacted
+func Comparisons(comparisons []*types.Comparison) ([]*types.Comparison, []Finding) {
+	var findings []Finding
+	redacted := make([]*types.Comparison, len(comparisons))
+
+	for i, comparison := range comparisons {
+		if comparison == nil {
+			continue
+		}
+		comparisonCopy := *comparison
+
+		comparisonCopy.Commits = make([]types.Commit, len(comparison.Commits))
+		for j, commit := range comparison.Commits {
+			var subjectFindings, commitFindings []Finding
+			commit.Message, subjectFindings = Text(commit.Message)
+			commit.Body, commitFindings = Text(commit.Body)
+			comparisonCopy.Commits[j] = commit
+			findings = append(findings, located(subjectFindings, comparison.RepoURL, "commit "+commit.ShortSHA+" subject")...)
+			findings = append(findings, located(commitFindings, comparison.RepoURL, "commit "+commit.ShortSHA)...)
+		}
+
+		comparisonCopy.PullRequests = make([]types.PullRequest, len(comparison.PullRequests))
+		for j, pr := range comparison.PullRequests {
+			var titleFindings, prFindings []Finding
+			pr.Title, titleFindings = Text(pr.Title)
+			pr.Description, prFindings = Text(pr.Description)
+			comparisonCopy.PullRequests[j] = pr
+			findings = append(findings, located(titleFindings, comparison.RepoURL, fmt.Sprintf("PR/MR #%d title", pr.Number))...)
+			findings = append(findings, located(prFindings, comparison.RepoURL, fmt.Sprintf("PR/MR #%d", pr.Number))...)
+		}
+
+		comparisonCopy.Files = make([]types.FileChange, len(comparison.Files))
+		for j, file := range comparison.Files {
+			patch, fileFindings := Patch(file.Patch)
+			file.Patch = patch
+			comparisonCopy.Files[j] = file
+
+			for _, finding := range fileFindings {
+				finding.Repo = comparison.RepoURL
+				finding.Location = file.Filename
+				findings = append(findings, finding)
+			}
+		}
+		redacted[i] = &comparisonCopy
+	}
+
+	logFindings("diff", findings)
+	return redacted, findings
+}
+
+// Documentation returns copies of the documentation with secrets redacted
+func Documentation(docs []*types.Documentation) ([]*types.Documentation, []Finding) {
+	var findings []Finding
+	redacted := make([]*types.Documentation, len(docs))
+
+	for i, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		docCopy := *doc
+
+		var docFindings []Finding
+		docCopy.MainDocContent, docFindings = Text(doc.MainDocContent)
+		findings = append(findings, located(docFindings, doc.Repository.URL, doc.MainDocFile)...)
+
+		if doc.AdditionalDocsContent != nil {
+			docCopy.AdditionalDocsContent = make(map[string]string, len(doc.AdditionalDocsContent))
+			for name, content := range doc.AdditionalDocsContent {
+				docCopy.AdditionalDocsContent[name], docFindings = Text(content)
+				findings = append(findings, located(docFindings, doc.Repository.URL, name)...)
+			}
+		}
+		redacted[i] = &docCopy
+	}
+
+	logFindings("documentation", findings)
+	return redacted, findings
+}
+
+// UserGuidance returns copies of the guidance with secrets redacted
+func UserGuidance(guidance []types.UserGuidance) ([]types.UserGuidance, []Finding) {
+	var findings []Finding
+	redacted := make([]types.UserGuidance, len(guidance))
+
+	for i, g := range guidance {
+		var guidanceFindings []Finding
+		g.Content, guidanceFindings = Text(g.Content)
+		findings = append(findings, located(guidanceFindings, "", "guidance by @"+g.Author)...)
+		redacted[i] = g
+	}
+
+	logFindings("user guidance", findings)
+	return redacted, findings
+}
+
+// Text redacts secrets in plain text; finding lines are 1-based lines of the text
+func Text(text string) (string, []Finding) {
+	if text == "" {
+		return text, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	var findings []Finding
+	inPEM := false
+	for i, line := range lines {
+		var found []string
+		lines[i], found, inPEM = redactLine(line, inPEM)
+		for _, secretType := range found {
+			findings = append(findings, Finding{Type: secretType, Line: i + 1})
+		}
+	}
+	return strings.Join(lines, "\n"), findings
+}
+
+// Patch redacts secrets in a unified diff; finding lines follow the hunk headers, using the
+// old file's numbering for removed lines and the new file's numbering otherwise
+func Patch(patch string) (string, []Finding) {
+	if patch == "" {
+		return patch, nil
+	}
+
+	lines := strings.Split(patch, "\n")
+	var findings []Finding
+	inPEM := false
+	oldLine, newLine := 0, 0
+	for i, line := range lines {
+		if matches := hunkHeader.FindStringSubmatch(line); matches != nil {
+			oldLine, _ = strconv.Atoi(matches[1])
+			newLine, _ = strconv.Atoi(matches[2])
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		prefix, content := line[:1], line[1:]
+		redacted, found, stillInPEM := redactLine(content, inPEM)
+		lines[i], inPEM = prefix+redacted, stillInPEM
+
+		for _, secretType := range found {
+			finding := Finding{Type: secretType, Line: newLine}
+			if prefix == "-" {
+				finding.Line, finding.Removed = oldLine, true
+			}
+			findings = append(findings, finding)
+		}
+
+		switch prefix {
+		case "+":
+			newLine++
+		case "-":
+			oldLine++
+		default:
+			oldLine++
+			newLine++
+		}
+	}
+	return strings.Join(lines, "\n"), findings
+}
+
+// redactLine redacts secrets in one line and reports the types found
+// inPEM tracks whether the line is inside a private key block that started on an earlier line
+func redactLine(line string, inPEM bool) (string, []string, bool) {
+	if inPEM {
+		if pemEndRegex.MatchString(line) {
+			return line, nil, false
+		}
+		return Placeholder(TypePrivateKey), nil, true
+	}
+	if begin := pemBeginRegex.FindStringIndex(line); begin != nil {
+		header, rest := line[:begin[1]], line[begin[1]:]
+		end := pemEndRegex.FindStringIndex(rest)
+		if end == nil {
+			return header + redactKeyMaterial(rest), []string{TypePrivateKey}, true
+		}
+
+		// The whole key sits on one line, e.g. a JSON or YAML string with escaped newlines
+		trailer, found, stillInPEM := redactLine(rest[end[0]:], false)
+		return header + redactKeyMaterial(rest[:end[0]]) + trailer, append([]string{TypePrivateKey}, found...), stillInPEM
+	}
+
+	var found []string
+	for _, pattern := range secretPatterns {
+		line = replaceMatches(pattern.regex, pattern.group, line, func(string) (string, bool) {
+			found = append(found, pattern.secretType)
+			return Placeholder(pattern.secretType), true
+		})
+	}
+
+	line = replaceMatches(secretAssignmentRegex, 1, line, func(value string) (string, bool) {
+		if !looksLikeSecret(value) {
+			return "", false
+		}
+		found = append(found, TypeHighEntropy)
+		return Placeholder(TypeHighEntropy), true
+	})
+	return line, found, false
+}
+
+// redactKeyMaterial replaces the part of a private key that follows its BEGIN marker on the same line
+func redactKeyMaterial(material string) string {
+	if strings.TrimSpace(material) == "" {
+		return material
+	}
+	return Placeholder(TypePrivateKey)
+}
+
+// replaceMatches replaces the given submatch of every match of regex using replace
+// replace returns false to keep the original text
+func replaceMatches(regex *regexp.Regexp, group int, line string, replace func(value string) (string, bool)) string {
+	matches := regex.FindAllStringSubmatchIndex(line, -1)
+	if matches == nil {
+		return line
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[2*group], match[2*group+1]
+		if start < 0 || start < last {
+			continue
+		}
+
+		replacement, ok := replace(line[start:end])
+		if !ok {
+			continue
+		}
+		b.WriteString(line[last:start])
+		b.WriteString(replacement)
+		last = end
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// looksLikeSecret reports whether an assigned value is random enough to be a credential
+// Values mixing letters and digits with high entropy qualify; identifiers and placeholders don't
+func looksLikeSecret(value string) bool {
+	// URLs are left to the connection string pattern, which keeps the user and host readable
+	if len(value) < minSecretLength || strings.HasPrefix(value, "[REDACTED:") || strings.Contains(value, "://") {
+		return false
+	}
+
+	hasLetter := strings.IndexFunc(value, unicode.IsLetter) >= 0
+	hasDigit := strings.IndexFunc(value, unicode.IsDigit) >= 0
+	return hasLetter && hasDigit && shannonEntropy(value) >= minSecretEntropy
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	length := float64(len(s))
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// located sets the repository and location of findings from one text
+func located(findings []Finding, repo, location string) []Finding {
+	for i := range findings {
+		findings[i].Repo = repo
+		findings[i].Location = location
+	}
+	return findings
+}
+
+// logFindings warns about redacted secrets without logging their values
+func logFindings(source string, findings []Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	slog.Warn("Redacted secrets before LLM analysis", "source", source, "count", len(findings))
+}
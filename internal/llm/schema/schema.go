@@ -0,0 +1,53 @@
+package schema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+// Embedded JSON Schema describing report.StructuredAnalysis
+//
+//go:embed analysis_schema.json
+var analysisSchemaJSON []byte
+
+// providerUnsupportedKeywords are dropped from the schema sent to providers
+// Support for them differs between Claude tool input schemas and Gemini's response_format, and
+// "title" only repeats the tool and response format names. Local validation keeps the full
+// schema, so minLength is still enforced on every response.
+var providerUnsupportedKeywords = []string{"$schema", "title", "minLength"}
+
+// Analysis returns a fresh copy of the full JSON Schema that LLM responses are validated against
+func Analysis() map[string]any {
+	var schema map[string]any
+	if err := json.Unmarshal(analysisSchemaJSON, &schema); err != nil {
+		// Panic on JSON parse error since the file is embedded at compile time
+		panic(fmt.Sprintf("Failed to parse embedded analysis_schema.json: %v", err))
+	}
+	return schema
+}
+
+// ForProvider returns a fresh copy of the analysis schema for provider requests,
+// without the keywords providers don't accept
+func ForProvider() map[string]any {
+	schema := Analysis()
+	stripKeywords(schema)
+	return schema
+}
+
+// stripKeywords removes providerUnsupportedKeywords from a schema node and every subschema
+func stripKeywords(node map[string]any) {
+	for _, keyword := range providerUnsupportedKeywords {
+		delete(node, keyword)
+	}
+	if properties, ok := node["properties"].(map[string]any); ok {
+		for _, property := range properties {
+			if subschema, ok := property.(map[string]any); ok {
+				stripKeywords(subschema)
+			}
+		}
+	}
+	if items, ok := node["items"].(map[string]any); ok {
+		stripKeywords(items)
+	}
+}
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAnalysis(t *testing.T) {
+	schema := Analysis()
+	if schema["type"] != "object" {
+		t.Errorf("schema type = %v, want object", schema["type"])
+	}
+
+	// Local validation keeps every keyword
+	summary := schema["properties"].(map[string]any)["summary"].(map[string]any)
+	if summary["minLength"] != float64(1) {
+		t.Errorf("summary minLength = %v, want 1", summary["minLength"])
+	}
+
+	// Callers get their own copy
+	modified := Analysis()
+	delete(modified, "properties")
+	if _, ok := Analysis()["properties"]; !ok {
+		t.Error("Analysis() returned a shared schema")
+	}
+}
+
+func TestForProvider(t *testing.T) {
+	schema := ForProvider()
+
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %v", err)
+	}
+	for _, keyword := range providerUnsupportedKeywords {
+		if strings.Contains(string(encoded), `"`+keyword+`"`) {
+			t.Errorf("provider schema still contains %q: %s", keyword, encoded)
+		}
+	}
+
+	// Structural keywords are kept
+	concerns := schema["properties"].(map[string]any)["risk_summary"].(map[string]any)["properties"].(map[string]any)["concerns"].(map[string]any)
+	item := concerns["items"].(map[string]any)
+	if len(item["required"].([]any)) != 2 || item["properties"].(map[string]any)["severity"].(map[string]any)["enum"] == nil {
+		t.Errorf("unexpected concern item schema: %v", item)
+	}
+	if _, ok := Analysis()["title"]; !ok {
+		t.Error("ForProvider() modified the full schema")
+	}
+}
@@ -0,0 +1,58 @@
+package truncation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// trailerLine matches a git trailer such as "Tested-by: QE <qe@example.com>" or "BREAKING CHANGE: /v1 is gone"
+var trailerLine = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*|BREAKING CHANGE): \S`)
+
+// TruncateDescription shortens a commit body or PR/MR description to roughly maxChars characters,
+// cutting at a line boundary where possible and noting how much was dropped
+// A closing block of trailers is always kept, since Tested-by: and BREAKING CHANGE: lines carry release signals
+// Returns the text and whether it was shortened
+func TruncateDescription(text string, maxChars int) (string, bool) {
+	if len(text) <= maxChars {
+		return text, false
+	}
+
+	content, trailers := splitTrailers(text)
+	if len(content) <= maxChars {
+		return text, false
+	}
+
+	kept := content[:maxChars]
+	if newline := strings.LastIndex(kept, "\n"); newline > 0 {
+		kept = kept[:newline]
+	} else {
+		// Don't split a multi-byte character
+		for len(kept) > 0 && !utf8.RuneStart(content[len(kept)]) {
+			kept = kept[:len(kept)-1]
+		}
+	}
+
+	result := fmt.Sprintf("%s\n[... %d characters omitted]", strings.TrimRight(kept, " \t\n"), utf8.RuneCountInString(content[len(kept):]))
+	if trailers != "" {
+		result += "\n\n" + trailers
+	}
+	return result, true
+}
+
+// splitTrailers separates the last paragraph of a text when every line of it is a trailer
+func splitTrailers(text string) (content, trailers string) {
+	separator := strings.LastIndex(text, "\n\n")
+	if separator == -1 {
+		return text, ""
+	}
+
+	last := strings.TrimSpace(text[separator:])
+	for _, line := range strings.Split(last, "\n") {
+		if !trailerLine.MatchString(line) {
+			return text, ""
+		}
+	}
+	return strings.TrimRight(text[:separator], " \t\n"), last
+}
@@ -0,0 +1,100 @@
+package truncation
+
+import (
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+func TestTruncateDescription(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxChars  int
+		expected  string
+		shortened bool
+	}{
+		{
+			name:     "within budget",
+			text:     "Short description",
+			maxChars: 100,
+			expected: "Short description",
+		},
+		{
+			name:      "cut at a line boundary",
+			text:      "First line\nSecond line\nThird line",
+			maxChars:  15,
+			expected:  "First line\n[... 23 characters omitted]",
+			shortened: true,
+		},
+		{
+			name:      "trailers are kept",
+			text:      "Rationale that goes on\nand on\n\nBREAKING CHANGE: /v1 is gone\nTested-by: QE <qe@example.com>",
+			maxChars:  25,
+			expected:  "Rationale that goes on\n[... 7 characters omitted]\n\nBREAKING CHANGE: /v1 is gone\nTested-by: QE <qe@example.com>",
+			shortened: true,
+		},
+		{
+			name:     "only trailers exceed the budget",
+			text:     "Short\n\nSigned-off-by: Alice <alice@example.com>",
+			maxChars: 10,
+			expected: "Short\n\nSigned-off-by: Alice <alice@example.com>",
+		},
+		{
+			name:      "last paragraph that isn't all trailers is truncated",
+			text:      "Intro\n\nNote: this is prose\nnot a trailer",
+			maxChars:  12,
+			expected:  "Intro\n[... 34 characters omitted]",
+			shortened: true,
+		},
+		{
+			name:      "multi-byte characters are not split",
+			text:      "ééééé",
+			maxChars:  5,
+			expected:  "éé\n[... 3 characters omitted]",
+			shortened: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, shortened := TruncateDescription(tt.text, tt.maxChars)
+			if got != tt.expected || shortened != tt.shortened {
+				t.Errorf("TruncateDescription() = %q, %v, want %q, %v", got, shortened, tt.expected, tt.shortened)
+			}
+		})
+	}
+}
+
+func TestTruncateMultipleComparisons_Descriptions(t *testing.T) {
+	long := strings.Repeat("Rollout plan line\n", 100)
+	comparison := &types.Comparison{
+		Commits:      []types.Commit{{Message: "Add feature", Body: long + "\nTested-by: QE <qe@example.com>"}, {Message: "Fix typo", Body: "Short"}},
+		PullRequests: []types.PullRequest{{Number: 12, Title: "Add feature", Description: long}},
+	}
+
+	truncated, metadata := TruncateMultipleComparisons([]*types.Comparison{comparison}, LevelExtreme)
+
+	if metadata.DescriptionsTruncated != 2 || !metadata.Truncated {
+		t.Errorf("DescriptionsTruncated, Truncated = %d, %v, want 2, true", metadata.DescriptionsTruncated, metadata.Truncated)
+	}
+	body := truncated[0].Commits[0].Body
+	if len(body) > 400 || !strings.HasSuffix(body, "Tested-by: QE <qe@example.com>") {
+		t.Errorf("expected a short body that keeps its trailer, got %q", body)
+	}
+	if truncated[0].Commits[1].Body != "Short" {
+		t.Errorf("expected short bodies to be kept, got %q", truncated[0].Commits[1].Body)
+	}
+	if len(truncated[0].PullRequests[0].Description) > 400 {
+		t.Errorf("expected a short description, got %d characters", len(truncated[0].PullRequests[0].Description))
+	}
+	if comparison.Commits[0].Body == body || comparison.PullRequests[0].Description != long {
+		t.Error("expected the original comparison to be left unchanged")
+	}
+
+	_, metadata = TruncateMultipleComparisons([]*types.Comparison{comparison}, LevelLow)
+	if metadata.DescriptionsTruncated != 0 {
+		t.Errorf("expected descriptions within the low level budget to be kept, got %d truncated", metadata.DescriptionsTruncated)
+	}
+}
@@ -0,0 +1,169 @@
+package truncation
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// hunkHeaderPattern matches a unified diff hunk header and captures its line ranges
+var hunkHeaderPattern = regexp.MustCompile(`^(@@ -\d+(?:,\d+)? \+\d+(?:,\d+)? @@)`)
+
+// hunkSignal is a pattern on changed lines that marks a hunk as likely to matter for release risk
+type hunkSignal struct {
+	pattern *regexp.Regexp
+	weight  int
+}
+
+// hunkSignals are checked against every added or removed line; each matching signal adds its weight
+var hunkSignals = []hunkSignal{
+	// SQL and DDL statements
+	{
+		pattern: regexp.MustCompile(`(?i)\b(create|alter|drop|truncate|rename)\s+(table|index|column|schema|database|view|constraint)\b|\b(insert\s+into|delete\s+from|add\s+column|foreign\s+key|primary\s+key|grant|revoke)\b|\bupdate\s+\w+\s+set\b`),
+		weight:  6,
+	},
+	// Authentication and authorization identifiers
+	{
+		pattern: regexp.MustCompile(`(?i)auth|permission|privilege|token|password|secret|credential|\broles?\b|rbac|\bacl\b|jwt|session|csrf`),
+		weight:  5,
+	},
+	// Function, method and type signatures
+	{
+		pattern: regexp.MustCompile(`^\s*((export\s+)?(default\s+)?(async\s+)?function\b|func\b|def\b|class\b|fn\s+\w+|interface\s+\w+|type\s+\w+\s+(struct|interface)\b|(public|private|protected|internal|static)\s+[\w<>\[\],. ]*\w+\s*\()`),
+		weight:  4,
+	},
+	// Error handling
+	{
+		pattern: regexp.MustCompile(`\berr\s*!=\s*nil\b|\bcatch\b|\bexcept\b|\braise\b|\bthrow\b|\bpanic\(|\brecover\(|\btry\s*[:{]|fmt\.Errorf|errors\.(New|Wrap|Is|As)\b`),
+		weight:  3,
+	},
+	// Configuration keys and environment variables
+	{
+		pattern: regexp.MustCompile(`^\s*("?[A-Za-z_][\w.-]*"?\s*:\s*\S|[A-Z][A-Z0-9_]{2,}\s*=)`),
+		weight:  2,
+	},
+}
+
+// OmittedContent records what truncation dropped from a single file
+type OmittedContent struct {
+	File  string   `json:"file"`
+	Hunks []string `json:"hunks,omitempty"` // Ranges of hunks dropped whole, e.g. "@@ -10,7 +10,9 @@"; empty for head/tail truncation
+	Lines int      `json:"lines"`           // Total patch lines dropped
+}
+
+// hunk is one "@@ ... @@" section of a unified diff
+type hunk struct {
+	header string   // Line range part of the header, without the trailing function context
+	lines  []string // Header line followed by the hunk body
+	score  int
+}
+
+// TruncatePatchByHunks truncates a patch to roughly keepStart+keepEnd lines
+// Hunks are ranked by risk signals and kept whole in order of rank; the others are replaced by a
+// one-line "[hunk @@ -a,b +c,d @@ omitted: N lines]" summary. Patches that can't be cut at hunk
+// boundaries (fewer than two hunks, or no hunk small enough) fall back to head/tail truncation.
+func TruncatePatchByHunks(patch string, keepStart, keepEnd int) (string, OmittedContent) {
+	maxLines := keepStart + keepEnd
+	if truncated, omitted, ok := truncateHunks(patch, maxLines); ok {
+		return truncated, omitted
+	}
+
+	truncated := TruncatePatch(patch, keepStart, keepEnd)
+	if truncated == patch {
+		return patch, OmittedContent{}
+	}
+	return truncated, OmittedContent{Lines: countLines(patch) - keepStart - keepEnd}
+}
+
+// truncateHunks keeps the highest-ranked hunks whose combined size fits maxLines
+func truncateHunks(patch string, maxLines int) (string, OmittedContent, bool) {
+	endsWithNewline := strings.HasSuffix(patch, "\n")
+	preamble, hunks := parseHunks(strings.TrimSuffix(patch, "\n"))
+	if len(hunks) < 2 {
+		return "", OmittedContent{}, false
+	}
+
+	// Rank by score, keeping diff order for ties
+	ranked := make([]int, len(hunks))
+	for i := range ranked {
+		ranked[i] = i
+	}
+	sort.SliceStable(ranked, func(a, b int) bool {
+		return hunks[ranked[a]].score > hunks[ranked[b]].score
+	})
+
+	keep := make([]bool, len(hunks))
+	used := len(preamble)
+	for _, i := range ranked {
+		if used+len(hunks[i].lines) <= maxLines {
+			keep[i] = true
+			used += len(hunks[i].lines)
+		}
+	}
+	if !keep[ranked[0]] {
+		// The most important hunk doesn't fit whole, so hunk selection would drop what matters most
+		return "", OmittedContent{}, false
+	}
+
+	var omitted OmittedContent
+	result := append([]string{}, preamble...)
+	for i, h := range hunks {
+		if keep[i] {
+			result = append(result, h.lines...)
+			continue
+		}
+		body := len(h.lines) - 1
+		result = append(result, fmt.Sprintf("[hunk %s omitted: %d lines]", h.header, body))
+		omitted.Hunks = append(omitted.Hunks, h.header)
+		omitted.Lines += body
+	}
+
+	if len(omitted.Hunks) == 0 {
+		return patch, OmittedContent{}, true
+	}
+
+	truncated := strings.Join(result, "\n")
+	if endsWithNewline {
+		truncated += "\n"
+	}
+	return truncated, omitted, true
+}
+
+// parseHunks splits a patch into the lines before the first hunk header and its hunks
+func parseHunks(patch string) ([]string, []hunk) {
+	var preamble []string
+	var hunks []hunk
+
+	for _, line := range strings.Split(patch, "\n") {
+		if match := hunkHeaderPattern.FindStringSubmatch(line); match != nil {
+			hunks = append(hunks, hunk{header: match[1], lines: []string{line}})
+			continue
+		}
+		if len(hunks) == 0 {
+			preamble = append(preamble, line)
+			continue
+		}
+		current := &hunks[len(hunks)-1]
+		current.lines = append(current.lines, line)
+		current.score += scoreLine(line)
+	}
+
+	return preamble, hunks
+}
+
+// scoreLine returns the combined weight of the risk signals on an added or removed line
+func scoreLine(line string) int {
+	if !strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "-") {
+		return 0
+	}
+
+	content := line[1:]
+	score := 0
+	for _, signal := range hunkSignals {
+		if signal.pattern.MatchString(content) {
+			score += signal.weight
+		}
+	}
+	return score
+}
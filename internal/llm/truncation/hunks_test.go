@@ -0,0 +1,161 @@
+package truncation
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// contextHunk returns a hunk with the given header and number of unremarkable changed lines
+func contextHunk(header string, lines int) string {
+	var b strings.Builder
+	b.WriteString(header + "\n")
+	for i := 0; i < lines; i++ {
+		b.WriteString(fmt.Sprintf("+x%d++\n", i))
+	}
+	return b.String()
+}
+
+func TestScoreLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected int
+	}{
+		{name: "context line", line: " ALTER TABLE users DROP COLUMN email;", expected: 0},
+		{name: "ddl", line: "+ALTER TABLE users DROP COLUMN email;", expected: 6},
+		{name: "auth", line: "-if user.HasPermission(\"admin\") {", expected: 5},
+		{name: "go signature", line: "+func Handle(w http.ResponseWriter) {", expected: 4},
+		{name: "python signature", line: "+def handle(request):", expected: 4},
+		{name: "error handling", line: "+\tif err != nil {", expected: 3},
+		{name: "config key", line: "+  replicas: 3", expected: 2},
+		{name: "env var", line: "+MAX_CONNECTIONS=100", expected: 2},
+		{name: "combined signals", line: "+func validateToken(t string) error {", expected: 9},
+		{name: "plain code", line: "+\tcount++", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoreLine(tt.line); got != tt.expected {
+				t.Errorf("scoreLine(%q) = %d, want %d", tt.line, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseHunks(t *testing.T) {
+	patch := "diff preamble\n@@ -1,2 +1,3 @@ func main() {\n context\n+added\n@@ -10 +11,2 @@\n-removed\n+ALTER TABLE t ADD COLUMN c int;"
+
+	preamble, hunks := parseHunks(patch)
+
+	if len(preamble) != 1 || preamble[0] != "diff preamble" {
+		t.Errorf("preamble = %v, want [diff preamble]", preamble)
+	}
+	if len(hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %d", len(hunks))
+	}
+	if hunks[0].header != "@@ -1,2 +1,3 @@" || hunks[1].header != "@@ -10 +11,2 @@" {
+		t.Errorf("headers = %q, %q", hunks[0].header, hunks[1].header)
+	}
+	if len(hunks[0].lines) != 3 || len(hunks[1].lines) != 3 {
+		t.Errorf("hunk sizes = %d, %d, want 3, 3", len(hunks[0].lines), len(hunks[1].lines))
+	}
+	if hunks[0].score != 0 || hunks[1].score != 6 {
+		t.Errorf("scores = %d, %d, want 0, 6", hunks[0].score, hunks[1].score)
+	}
+}
+
+func TestTruncatePatchByHunks(t *testing.T) {
+	riskyHunk := "@@ -40,3 +40,4 @@\n context\n+ALTER TABLE users DROP COLUMN email;\n+if err != nil {\n"
+
+	t.Run("keeps highest-ranked hunks whole", func(t *testing.T) {
+		patch := contextHunk("@@ -1,10 +1,10 @@", 10) + riskyHunk + contextHunk("@@ -80,10 +80,10 @@", 10)
+
+		truncated, omitted := TruncatePatchByHunks(patch, 10, 5)
+
+		if !strings.Contains(truncated, riskyHunk) {
+			t.Errorf("expected risky hunk to be kept whole, got:\n%s", truncated)
+		}
+		if !strings.Contains(truncated, "+x0++\n") {
+			t.Error("expected the first low-ranked hunk to fill the remaining budget")
+		}
+		if !strings.Contains(truncated, "[hunk @@ -80,10 +80,10 @@ omitted: 10 lines]\n") {
+			t.Errorf("expected omitted hunk summary, got:\n%s", truncated)
+		}
+		if !strings.HasSuffix(truncated, "\n") {
+			t.Error("expected trailing newline to be preserved")
+		}
+		if len(omitted.Hunks) != 1 || omitted.Hunks[0] != "@@ -80,10 +80,10 @@" || omitted.Lines != 10 {
+			t.Errorf("omitted = %+v", omitted)
+		}
+	})
+
+	t.Run("keeps diff order", func(t *testing.T) {
+		patch := contextHunk("@@ -1,3 +1,3 @@", 3) + riskyHunk
+
+		truncated, _ := TruncatePatchByHunks(patch, 50, 20)
+
+		if truncated != patch {
+			t.Errorf("expected patch within budget to be unchanged, got:\n%s", truncated)
+		}
+	})
+
+	t.Run("falls back to head and tail without hunks", func(t *testing.T) {
+		patch := strings.Repeat("+line\n", 100)
+
+		truncated, omitted := TruncatePatchByHunks(patch, 10, 5)
+
+		if !strings.Contains(truncated, "lines omitted") {
+			t.Error("expected head/tail truncation")
+		}
+		if len(omitted.Hunks) != 0 || omitted.Lines != 86 {
+			t.Errorf("omitted = %+v, want 86 lines without hunks", omitted)
+		}
+	})
+
+	t.Run("falls back when the top hunk does not fit", func(t *testing.T) {
+		bigRiskyHunk := "@@ -1,30 +1,30 @@\n" + strings.Repeat("+DROP TABLE users;\n", 30)
+		patch := bigRiskyHunk + contextHunk("@@ -90,5 +90,5 @@", 5)
+
+		truncated, omitted := TruncatePatchByHunks(patch, 10, 5)
+
+		if strings.Contains(truncated, "[hunk") || !strings.Contains(truncated, "lines omitted") {
+			t.Errorf("expected head/tail truncation, got:\n%s", truncated)
+		}
+		if len(omitted.Hunks) != 0 {
+			t.Errorf("omitted = %+v, want no hunks", omitted)
+		}
+	})
+
+	t.Run("small patch is unchanged", func(t *testing.T) {
+		patch := "+one\n+two\n"
+
+		truncated, omitted := TruncatePatchByHunks(patch, 10, 5)
+
+		if truncated != patch || omitted.Lines != 0 {
+			t.Errorf("expected unchanged patch, got %q, %+v", truncated, omitted)
+		}
+	})
+}
+
+func TestTruncateMultipleComparisonsRecordsOmittedHunks(t *testing.T) {
+	patch := contextHunk("@@ -1,30 +1,30 @@", 30) + "@@ -50,2 +50,2 @@\n-token := old()\n+token := rotate()\n" + contextHunk("@@ -90,30 +90,30 @@", 30)
+	comparison := &types.Comparison{
+		RepoURL: "https://github.com/test/repo",
+		Files:   []types.FileChange{{Filename: "docs/guide.md", Patch: patch}},
+	}
+
+	result, metadata := TruncateMultipleComparisons([]*types.Comparison{comparison}, LevelHigh)
+
+	if !strings.Contains(result[0].Files[0].Patch, "+token := rotate()") {
+		t.Error("expected auth hunk to be kept")
+	}
+	if len(metadata.Omitted) != 1 {
+		t.Fatalf("expected omitted content for 1 file, got %+v", metadata.Omitted)
+	}
+	if metadata.Omitted[0].File != "docs/guide.md" || len(metadata.Omitted[0].Hunks) != 2 || metadata.Omitted[0].Lines != 60 {
+		t.Errorf("omitted = %+v", metadata.Omitted[0])
+	}
+}
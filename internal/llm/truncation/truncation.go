@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/repoconfig"
 )
 
 // Truncation level constants
@@ -38,28 +39,48 @@ const (
 	RiskLow
 )
 
+// String returns the lowercase name of the risk level
+func (r FileRiskLevel) String() string {
+	switch r {
+	case RiskCritical:
+		return "critical"
+	case RiskHigh:
+		return "high"
+	case RiskMedium:
+		return "medium"
+	case RiskLow:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
 // TruncationMetadata contains information about diff truncation applied during LLM analysis
 type TruncationMetadata struct {
-	Truncated          bool     // Whether truncation was applied
-	Level              string   // Truncation level: "low", "moderate", "high", "extreme"
-	FilesPreserved     int      // Number of files kept in full
-	FilesTruncated     int      // Number of files that were truncated
-	TruncatedFilesList []string // List of truncated file paths
-	TotalFiles         int      // Total number of files in the diff
+	Truncated          bool             `json:"truncated"`            // Whether truncation was applied
+	Level              string           `json:"level"`                // Truncation level: "low", "moderate", "high", "extreme"
+	FilesPreserved     int              `json:"files_preserved"`      // Number of files kept in full
+	FilesTruncated     int              `json:"files_truncated"`      // Number of files that were truncated
+	TruncatedFilesList []string         `json:"truncated_files_list"` // List of truncated file paths
+	TotalFiles         int              `json:"total_files"`          // Total number of files in the diff
+	Omitted            []OmittedContent `json:"omitted,omitempty"`    // What was dropped from each truncated file
+
+	DescriptionsTruncated int `json:"descriptions_truncated,omitempty"` // Commit bodies and PR/MR descriptions cut to the level's budget
 }
 
 // truncationConfig holds the parameters for a specific truncation level
 type truncationConfig struct {
-	keepStart int
-	keepEnd   int
+	keepStart        int
+	keepEnd          int
+	descriptionChars int // Characters kept of each commit body and PR/MR description, not counting trailers
 }
 
 // Truncation level configurations
 var truncationLevels = map[string]truncationConfig{
-	LevelLow:      {keepStart: 50, keepEnd: 20},
-	LevelModerate: {keepStart: 20, keepEnd: 10},
-	LevelHigh:     {keepStart: 10, keepEnd: 5},
-	LevelExtreme:  {keepStart: 5, keepEnd: 3},
+	LevelLow:      {keepStart: 50, keepEnd: 20, descriptionChars: 4000},
+	LevelModerate: {keepStart: 20, keepEnd: 10, descriptionChars: 2000},
+	LevelHigh:     {keepStart: 10, keepEnd: 5, descriptionChars: 800},
+	LevelExtreme:  {keepStart: 5, keepEnd: 3, descriptionChars: 300},
 }
 
 // Embedded risk patterns JSON file
@@ -108,7 +129,7 @@ func TruncateMultipleComparisons(comparisons []*types.Comparison, level string)
 		truncated[i], metadataList[i] = truncateComparison(comparison, normalizedLevel)
 	}
 
-	combinedMetadata := combineMetadata(metadataList, normalizedLevel)
+	combinedMetadata := CombineMetadata(metadataList, normalizedLevel)
 	return truncated, combinedMetadata
 }
 
@@ -121,13 +142,19 @@ func truncateComparison(comparison *types.Comparison, level string) (*types.Comp
 
 	keepStart, keepEnd := getTruncationParams(level)
 
-	// Create a copy of the comparison (Files are copied deeply since we modify Patch)
+	// Create a copy of the comparison (Files, Commits and PullRequests are copied deeply since we modify them)
 	truncated := &types.Comparison{
-		RepoURL: comparison.RepoURL,
-		Commits: comparison.Commits,
-		Files:   make([]types.FileChange, len(comparison.Files)),
-		Stats:   comparison.Stats,
+		RepoURL:      comparison.RepoURL,
+		Commits:      make([]types.Commit, len(comparison.Commits)),
+		PullRequests: make([]types.PullRequest, len(comparison.PullRequests)),
+		Files:        make([]types.FileChange, len(comparison.Files)),
+		Stats:        comparison.Stats,
+
+		RepoConfig: comparison.RepoConfig,
+		CI:         comparison.CI,
 	}
+	copy(truncated.Commits, comparison.Commits)
+	copy(truncated.PullRequests, comparison.PullRequests)
 	copy(truncated.Files, comparison.Files)
 
 	// Initialize truncation metadata
@@ -140,7 +167,7 @@ func truncateComparison(comparison *types.Comparison, level string) (*types.Comp
 		TruncatedFilesList: []string{},
 	}
 
-	smallFileThreshold := getSmallFileThreshold(level)
+	smallFileThreshold := resolveSmallFileThreshold(level, comparison.RepoConfig)
 
 	// Process each file
 	for i := range truncated.Files {
@@ -159,7 +186,7 @@ func truncateComparison(comparison *types.Comparison, level string) (*types.Comp
 		}
 
 		// Determine if this file should be truncated based on risk level
-		fileRisk := classifyFileRisk(file.Filename)
+		fileRisk := ClassifyFile(file.Filename, comparison.RepoConfig)
 		if !shouldTruncateFile(fileRisk, level) {
 			// Preserve this file completely
 			metadata.FilesPreserved++
@@ -168,23 +195,47 @@ func truncateComparison(comparison *types.Comparison, level string) (*types.Comp
 
 		// Truncate the patch
 		originalPatch := file.Patch
-		truncatedPatch := truncatePatch(originalPatch, keepStart, keepEnd)
+		truncatedPatch, omitted := TruncatePatchByHunks(originalPatch, keepStart, keepEnd)
 
 		if truncatedPatch != originalPatch {
 			file.Patch = truncatedPatch
 			metadata.Truncated = true
 			metadata.FilesTruncated++
 			metadata.TruncatedFilesList = append(metadata.TruncatedFilesList, file.Filename)
+
+			omitted.File = file.Filename
+			metadata.Omitted = append(metadata.Omitted, omitted)
 		} else {
 			metadata.FilesPreserved++
 		}
 	}
 
+	// Shorten long commit bodies and PR/MR descriptions
+	descriptionChars := getDescriptionChars(level)
+	for i := range truncated.Commits {
+		body, shortened := TruncateDescription(truncated.Commits[i].Body, descriptionChars)
+		if shortened {
+			truncated.Commits[i].Body = body
+			metadata.DescriptionsTruncated++
+		}
+	}
+	for i := range truncated.PullRequests {
+		description, shortened := TruncateDescription(truncated.PullRequests[i].Description, descriptionChars)
+		if shortened {
+			truncated.PullRequests[i].Description = description
+			metadata.DescriptionsTruncated++
+		}
+	}
+	if metadata.DescriptionsTruncated > 0 {
+		metadata.Truncated = true
+	}
+
 	slog.Debug("Truncated comparison",
 		"level", level,
 		"total_files", metadata.TotalFiles,
 		"preserved", metadata.FilesPreserved,
-		"truncated", metadata.FilesTruncated)
+		"truncated", metadata.FilesTruncated,
+		"descriptions_truncated", metadata.DescriptionsTruncated)
 
 	return truncated, metadata
 }
@@ -201,6 +252,17 @@ func getTruncationParams(level string) (keepStart int, keepEnd int) {
 	return config.keepStart, config.keepEnd
 }
 
+// getDescriptionChars returns the characters kept of each commit body and PR/MR description
+// Falls back to low level if the level is unknown
+// Expects level to already be normalized (lowercased)
+func getDescriptionChars(level string) int {
+	config, exists := truncationLevels[level]
+	if !exists {
+		config = truncationLevels[LevelLow]
+	}
+	return config.descriptionChars
+}
+
 // getSmallFileThreshold returns the threshold (in lines) below which files are never truncated
 // The threshold decreases as truncation becomes more aggressive
 // Expects level to already be normalized (lowercased)
@@ -219,6 +281,29 @@ func getSmallFileThreshold(level string) int {
 	}
 }
 
+// resolveSmallFileThreshold returns the small-file threshold for level, honoring repository overrides
+// The nearest config layer with a non-zero threshold for the level wins
+func resolveSmallFileThreshold(level string, cfg *types.RepoConfig) int {
+	for layer := cfg; layer != nil; layer = layer.Base {
+		thresholds := layer.SmallFileThresholds
+		var override int
+		switch level {
+		case LevelLow:
+			override = thresholds.Low
+		case LevelModerate:
+			override = thresholds.Moderate
+		case LevelHigh:
+			override = thresholds.High
+		case LevelExtreme:
+			override = thresholds.Extreme
+		}
+		if override > 0 {
+			return override
+		}
+	}
+	return getSmallFileThreshold(level)
+}
+
 // countLines returns the number of lines in the given text
 func countLines(text string) int {
 	if text == "" {
@@ -228,8 +313,8 @@ func countLines(text string) int {
 	return strings.Count(text, "\n") + 1
 }
 
-// classifyFileRisk determines the risk level of a file based on its filename
-func classifyFileRisk(filename string) FileRiskLevel {
+// ClassifyFileRisk determines the risk level of a file based on its filename
+func ClassifyFileRisk(filename string) FileRiskLevel {
 	lower := strings.ToLower(filename)
 
 	// Check patterns in order of risk level (highest to lowest)
@@ -243,6 +328,26 @@ func classifyFileRisk(filename string) FileRiskLevel {
 	return RiskMedium
 }
 
+// ClassifyFile determines the risk level of a file, consulting repository-configured patterns
+// before the built-in ones. Config layers are checked nearest first, so a repository's
+// .release-confidence.yaml overrides the operator's global file
+func ClassifyFile(filename string, cfg *types.RepoConfig) FileRiskLevel {
+	for layer := cfg; layer != nil; layer = layer.Base {
+		patterns := map[FileRiskLevel][]string{
+			RiskCritical: layer.RiskPatterns.Critical,
+			RiskHigh:     layer.RiskPatterns.High,
+			RiskMedium:   layer.RiskPatterns.Medium,
+			RiskLow:      layer.RiskPatterns.Low,
+		}
+		for _, risk := range []FileRiskLevel{RiskCritical, RiskHigh, RiskMedium, RiskLow} {
+			if repoconfig.MatchAny(patterns[risk], filename) {
+				return risk
+			}
+		}
+	}
+	return ClassifyFileRisk(filename)
+}
+
 // matchesAnyPattern checks if the filename matches any of the given glob patterns
 func matchesAnyPattern(filename string, patterns []string) bool {
 	// Split filename once before looping through patterns for efficiency
@@ -299,9 +404,9 @@ func shouldTruncateFile(risk FileRiskLevel, level string) bool {
 	return false
 }
 
-// truncatePatch truncates a patch to keep only the first keepStart and last keepEnd lines
+// TruncatePatch truncates a patch to keep only the first keepStart and last keepEnd lines
 // Returns the original patch if it's shorter than keepStart + keepEnd lines
-func truncatePatch(patch string, keepStart, keepEnd int) string {
+func TruncatePatch(patch string, keepStart, keepEnd int) string {
 	if patch == "" {
 		return patch
 	}
@@ -342,8 +447,8 @@ func truncatePatch(patch string, keepStart, keepEnd int) string {
 	return result.String()
 }
 
-// combineMetadata combines multiple truncation metadata objects into one
-func combineMetadata(metadataList []*TruncationMetadata, level string) TruncationMetadata {
+// CombineMetadata combines multiple truncation metadata objects into one
+func CombineMetadata(metadataList []*TruncationMetadata, level string) TruncationMetadata {
 	combined := TruncationMetadata{
 		Level:              level,
 		Truncated:          false,
@@ -364,6 +469,8 @@ func combineMetadata(metadataList []*TruncationMetadata, level string) Truncatio
 		combined.FilesPreserved += metadata.FilesPreserved
 		combined.FilesTruncated += metadata.FilesTruncated
 		combined.TruncatedFilesList = append(combined.TruncatedFilesList, metadata.TruncatedFilesList...)
+		combined.Omitted = append(combined.Omitted, metadata.Omitted...)
+		combined.DescriptionsTruncated += metadata.DescriptionsTruncated
 	}
 
 	return combined
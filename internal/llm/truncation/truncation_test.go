@@ -202,9 +202,49 @@ func TestClassifyFileRisk(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := classifyFileRisk(tt.filename)
+			result := ClassifyFileRisk(tt.filename)
 			if result != tt.expected {
-				t.Errorf("classifyFileRisk(%q) = %v, want %v", tt.filename, result, tt.expected)
+				t.Errorf("ClassifyFileRisk(%q) = %v, want %v", tt.filename, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClassifyFile(t *testing.T) {
+	global := &types.RepoConfig{
+		RiskPatterns: types.RiskPatterns{
+			Critical: []string{"pkg/billing/**"},
+			High:     []string{"fixtures/**"},
+		},
+	}
+	repo := &types.RepoConfig{
+		RiskPatterns: types.RiskPatterns{
+			Low:    []string{"fixtures/**"},
+			Medium: []string{"docs/runbooks/**"},
+		},
+		Base: global,
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		cfg      *types.RepoConfig
+		expected FileRiskLevel
+	}{
+		{"no config uses built-in patterns", "docs/README.md", nil, RiskLow},
+		{"custom critical pattern", "pkg/billing/invoice/total.go", global, RiskCritical},
+		{"global pattern applies beneath repository config", "pkg/billing/total.go", repo, RiskCritical},
+		{"repository pattern overrides global pattern", "fixtures/users.json", repo, RiskLow},
+		{"custom pattern overrides built-in pattern", "docs/runbooks/rollback.md", repo, RiskMedium},
+		{"unmatched file falls back to built-in patterns", "db/migrations/001.sql", repo, RiskCritical},
+		{"unmatched file defaults to medium", "cmd/main.go", repo, RiskMedium},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ClassifyFile(tt.filename, tt.cfg)
+			if result != tt.expected {
+				t.Errorf("ClassifyFile(%q) = %v, want %v", tt.filename, result, tt.expected)
 			}
 		})
 	}
@@ -296,7 +336,7 @@ func TestTruncatePatch(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := truncatePatch(tt.patch, tt.keepStart, tt.keepEnd)
+			result := TruncatePatch(tt.patch, tt.keepStart, tt.keepEnd)
 
 			if tt.expectOmit {
 				if !strings.Contains(result, "lines omitted") {
@@ -358,6 +398,33 @@ func TestGetSmallFileThreshold(t *testing.T) {
 	}
 }
 
+func TestResolveSmallFileThreshold(t *testing.T) {
+	global := &types.RepoConfig{SmallFileThresholds: types.SmallFileThresholds{High: 80, Extreme: 40}}
+	repo := &types.RepoConfig{SmallFileThresholds: types.SmallFileThresholds{High: 120}, Base: global}
+
+	tests := []struct {
+		name     string
+		level    string
+		cfg      *types.RepoConfig
+		expected int
+	}{
+		{"no config uses default", LevelHigh, nil, SmallFileThresholdHigh},
+		{"global override", LevelHigh, global, 80},
+		{"repository override wins over global", LevelHigh, repo, 120},
+		{"level unset in repository inherits global", LevelExtreme, repo, 40},
+		{"level unset everywhere uses default", LevelModerate, repo, SmallFileThresholdModerate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := resolveSmallFileThreshold(tt.level, tt.cfg)
+			if result != tt.expected {
+				t.Errorf("resolveSmallFileThreshold(%s) = %d, want %d", tt.level, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestTruncateMultipleComparisons(t *testing.T) {
 	// Create test comparison with files
 	comparison := &types.Comparison{
@@ -404,6 +471,35 @@ func TestTruncateMultipleComparisons(t *testing.T) {
 		}
 	})
 
+	t.Run("repository config preserves files", func(t *testing.T) {
+		configured := *comparison
+		configured.RepoConfig = &types.RepoConfig{
+			RiskPatterns: types.RiskPatterns{Critical: []string{"readme.md"}},
+		}
+
+		result, metadata := TruncateMultipleComparisons([]*types.Comparison{&configured}, LevelHigh)
+
+		if metadata.Truncated {
+			t.Errorf("Expected critical README.md to be preserved, truncated: %v", metadata.TruncatedFilesList)
+		}
+		if result[0].RepoConfig != configured.RepoConfig {
+			t.Error("Expected truncated comparison to keep its repository config")
+		}
+	})
+
+	t.Run("repository threshold protects files", func(t *testing.T) {
+		configured := *comparison
+		configured.RepoConfig = &types.RepoConfig{
+			SmallFileThresholds: types.SmallFileThresholds{High: 500},
+		}
+
+		_, metadata := TruncateMultipleComparisons([]*types.Comparison{&configured}, LevelHigh)
+
+		if metadata.Truncated {
+			t.Errorf("Expected files below the configured threshold to be preserved, truncated: %v", metadata.TruncatedFilesList)
+		}
+	})
+
 	t.Run("nil comparison", func(t *testing.T) {
 		result, _ := TruncateMultipleComparisons([]*types.Comparison{nil}, LevelLow)
 		if len(result) != 1 || result[0] != nil {
@@ -420,6 +516,7 @@ func TestCombineMetadata(t *testing.T) {
 		FilesPreserved:     7,
 		FilesTruncated:     3,
 		TruncatedFilesList: []string{"file1.go", "file2.go"},
+		Omitted:            []OmittedContent{{File: "file1.go", Lines: 40}, {File: "file2.go", Hunks: []string{"@@ -1,5 +1,5 @@"}, Lines: 5}},
 	}
 
 	metadata2 := &TruncationMetadata{
@@ -432,7 +529,7 @@ func TestCombineMetadata(t *testing.T) {
 	}
 
 	t.Run("combines multiple metadata", func(t *testing.T) {
-		result := combineMetadata([]*TruncationMetadata{metadata1, metadata2}, LevelModerate)
+		result := CombineMetadata([]*TruncationMetadata{metadata1, metadata2}, LevelModerate)
 
 		if !result.Truncated {
 			t.Error("Expected combined metadata to show truncation")
@@ -453,10 +550,14 @@ func TestCombineMetadata(t *testing.T) {
 		if len(result.TruncatedFilesList) != 2 {
 			t.Errorf("Expected 2 truncated files in list, got %d", len(result.TruncatedFilesList))
 		}
+
+		if len(result.Omitted) != 2 {
+			t.Errorf("Expected omitted content for 2 files, got %d", len(result.Omitted))
+		}
 	})
 
 	t.Run("handles nil metadata", func(t *testing.T) {
-		result := combineMetadata([]*TruncationMetadata{nil, metadata1, nil}, LevelModerate)
+		result := CombineMetadata([]*TruncationMetadata{nil, metadata1, nil}, LevelModerate)
 
 		if result.TotalFiles != metadata1.TotalFiles {
 			t.Error("Nil metadata should be skipped")
@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/truncation"
+	"release-confidence-score/internal/report"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// analyzePerService scores each comparison of a multi-repo release separately, in parallel, with only
+// the documentation and user guidance that belong to its repository
+// The merged analysis uses the lowest service score so the riskiest service drives the recommendation
+func (ra *ReleaseAnalyzer) analyzePerService(comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) (*modelRun, []report.ServiceResult, error) {
+	slog.Info("Analyzing release per service", "services", len(comparisons))
+
+	services := make([]report.ServiceResult, len(comparisons))
+	runs := make([]*modelRun, len(comparisons))
+
+	// Each service sends one call per ensemble model and sample at once, so fewer services run together
+	// when there are more of them, keeping concurrent LLM calls around 10 to avoid rate limiting
+	callsPerService := (len(ra.ensembleClients) + 1) * max(ra.config.Sampling.Count, 1)
+	var g errgroup.Group
+	g.SetLimit(max(10/callsPerService, 1))
+	for i, comparison := range comparisons {
+		g.Go(func() error {
+			name := serviceName(comparison.RepoURL)
+			serviceDocs := serviceDocumentation(comparison.RepoURL, documentation)
+			serviceGuidance := serviceUserGuidance(comparison.RepoURL, comparisons, userGuidance)
+
+			slog.Debug("Analyzing service",
+				"service", name,
+				"files", len(comparison.Files),
+				"documentation", len(serviceDocs),
+				"user_guidance", len(serviceGuidance))
+
+			run, ensemble, sampling, err := ra.scoreRelease([]*types.Comparison{comparison}, serviceDocs, serviceGuidance)
+			if err != nil {
+				return fmt.Errorf("failed to analyze service %s: %w", name, err)
+			}
+
+			runs[i] = run
+			services[i] = report.ServiceResult{
+				Service:    name,
+				RepoURL:    comparison.RepoURL,
+				Files:      len(comparison.Files),
+				Analysis:   run.analysis,
+				Truncation: run.truncation,
+				Chunking:   run.chunking,
+				Ensemble:   ensemble,
+				Sampling:   sampling,
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	// Riskiest service first
+	sort.SliceStable(services, func(i, j int) bool {
+		return services[i].Analysis.Score < services[j].Analysis.Score
+	})
+
+	merged, err := report.MergeServices(services)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to merge service analyses: %w", err)
+	}
+
+	return &modelRun{analysis: merged, truncation: combineTruncation(runs, comparisons)}, services, nil
+}
+
+// combineTruncation sums the truncation metadata of the per-service runs, counting every file
+// of untruncated services as preserved
+// The level is the most aggressive one applied; returns nil when no run was truncated
+func combineTruncation(runs []*modelRun, comparisons []*types.Comparison) *truncation.TruncationMetadata {
+	truncations := make([]*truncation.TruncationMetadata, len(runs))
+	for i, run := range runs {
+		truncations[i] = run.truncation
+	}
+
+	mostAggressive := mostAggressiveTruncation(truncations)
+	if mostAggressive == nil {
+		return nil
+	}
+
+	for i, metadata := range truncations {
+		if metadata == nil {
+			files := len(comparisons[i].Files)
+			truncations[i] = &truncation.TruncationMetadata{TotalFiles: files, FilesPreserved: files}
+		}
+	}
+
+	combined := truncation.CombineMetadata(truncations, mostAggressive.Level)
+	return &combined
+}
+
+// serviceDocumentation returns the documentation fetched from the given repository
+func serviceDocumentation(repoURL string, documentation []*types.Documentation) []*types.Documentation {
+	var docs []*types.Documentation
+	for _, doc := range documentation {
+		if sameRepository(doc.Repository.URL, repoURL) {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// serviceUserGuidance returns the guidance posted in the given repository plus guidance that
+// wasn't posted in any of the release's repositories (e.g. on the app-interface merge request)
+func serviceUserGuidance(repoURL string, comparisons []*types.Comparison, userGuidance []types.UserGuidance) []types.UserGuidance {
+	var guidance []types.UserGuidance
+	for _, g := range userGuidance {
+		owner := slices.IndexFunc(comparisons, func(c *types.Comparison) bool {
+			return postedIn(g.CommentURL, c.RepoURL)
+		})
+		if owner == -1 || postedIn(g.CommentURL, repoURL) {
+			guidance = append(guidance, g)
+		}
+	}
+	return guidance
+}
+
+// postedIn reports whether a comment URL belongs to the given repository
+func postedIn(commentURL, repoURL string) bool {
+	prefix := strings.ToLower(strings.TrimSuffix(repoURL, "/")) + "/"
+	return strings.HasPrefix(strings.ToLower(commentURL), prefix)
+}
+
+// sameRepository reports whether two repository URLs refer to the same repository
+func sameRepository(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "/"), strings.TrimSuffix(b, "/"))
+}
+
+// serviceName returns the repository path without scheme and host (e.g. "org/repo")
+func serviceName(repoURL string) string {
+	parsed, err := url.Parse(repoURL)
+	if err != nil || parsed.Path == "" {
+		return repoURL
+	}
+	return strings.Trim(parsed.Path, "/")
+}
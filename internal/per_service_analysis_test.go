@@ -0,0 +1,252 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/truncation"
+)
+
+func TestAnalyze_PerService(t *testing.T) {
+	commits := []types.Commit{{SHA: "abc123", Message: "change"}}
+	comparisons := []*types.Comparison{
+		{RepoURL: "https://github.com/org/api", Commits: commits, Files: []types.FileChange{{Filename: "handler.go", Patch: "+api change"}}},
+		{RepoURL: "https://gitlab.example.com/group/worker", Commits: commits, Files: []types.FileChange{{Filename: "job.py", Patch: "+worker change"}}},
+	}
+	documentation := []*types.Documentation{
+		{MainDocFile: "README.md", MainDocContent: "API service docs", Repository: types.Repository{URL: "https://github.com/org/api"}},
+		{MainDocFile: "README.md", MainDocContent: "Worker service docs", Repository: types.Repository{URL: "https://gitlab.example.com/group/worker"}},
+	}
+	guidance := []types.UserGuidance{
+		{Content: "API guidance", IsAuthorized: true, CommentURL: "https://github.com/org/api/pull/1#issuecomment-1"},
+		{Content: "Release-wide guidance", IsAuthorized: true, CommentURL: "https://gitlab.example.com/service/app-interface/-/merge_requests/9#note_1"},
+	}
+
+	llm := &mockLLMClient{
+		respond: func(userPrompt string) (string, error) {
+			if strings.Contains(userPrompt, "+api change") {
+				return `{"score": 90, "summary": "API is safe", "risk_summary": {"concerns": [{"severity": "low", "description": "New log line"}]}}`, nil
+			}
+			return `{"score": 45, "summary": "Worker is risky", "risk_summary": {"concerns": [{"severity": "critical", "description": "Job drops queue"}]}}`, nil
+		},
+	}
+
+	ra := newTestAnalyzer(nil, nil, llm)
+	ra.config.PerServiceAnalysis = true
+
+	score, report, err := ra.analyze(comparisons, guidance, documentation, false)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if llm.callCount != 2 {
+		t.Fatalf("expected one LLM call per service, got %d", llm.callCount)
+	}
+	if score != 45 {
+		t.Errorf("expected lowest service score 45, got %v", score)
+	}
+
+	for _, prompt := range llm.callInputs {
+		isAPI := strings.Contains(prompt, "+api change")
+		if isAPI == strings.Contains(prompt, "+worker change") {
+			t.Error("each prompt should contain exactly one service's diff")
+		}
+		if strings.Contains(prompt, "API service docs") != isAPI || strings.Contains(prompt, "Worker service docs") == isAPI {
+			t.Error("each prompt should only contain its own service's documentation")
+		}
+		if strings.Contains(prompt, "API guidance") != isAPI {
+			t.Error("repository guidance should only reach its own service")
+		}
+		if !strings.Contains(prompt, "Release-wide guidance") {
+			t.Error("guidance from outside the release's repositories should reach every service")
+		}
+	}
+
+	for _, want := range []string{
+		"Score by Service",
+		"| [group/worker](https://gitlab.example.com/group/worker) | 45/100 | 1 | [critical] Job drops queue |",
+		"| [org/api](https://github.com/org/api) | 90/100 | 1 | [low] New log line |",
+		"Service Analyses",
+		"### org/api — 90/100",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	if strings.Index(report, "group/worker") > strings.Index(report, "org/api") {
+		t.Error("riskiest service should be listed first")
+	}
+}
+
+// concurrencyLLMClient answers every call after a short delay and records how many calls overlapped
+type concurrencyLLMClient struct {
+	mu            sync.Mutex
+	inFlight      int
+	maxInFlight   int
+	totalRequests int
+}
+
+func (c *concurrencyLLMClient) Analyze(userPrompt string) (string, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.totalRequests++
+	c.maxInFlight = max(c.maxInFlight, c.inFlight)
+	c.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return validLLMResponse(), nil
+}
+
+func TestAnalyze_PerServiceLimitsConcurrentCalls(t *testing.T) {
+	var comparisons []*types.Comparison
+	for i := range 12 {
+		comparisons = append(comparisons, &types.Comparison{
+			RepoURL: fmt.Sprintf("https://github.com/org/service-%d", i),
+			Commits: []types.Commit{{SHA: "abc123", Message: "change"}},
+			Files:   []types.FileChange{{Filename: "main.go", Patch: "+change"}},
+		})
+	}
+
+	llm := &concurrencyLLMClient{}
+	ra := newTestAnalyzer(nil, nil, nil)
+	ra.llmClient = llm
+	ra.config.PerServiceAnalysis = true
+	ra.config.Sampling.Count = 3
+
+	if _, _, err := ra.analyze(comparisons, nil, nil, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if llm.totalRequests != 36 {
+		t.Errorf("expected 3 samples for each of 12 services, got %d calls", llm.totalRequests)
+	}
+	if llm.maxInFlight > 10 {
+		t.Errorf("expected at most 10 concurrent LLM calls, got %d", llm.maxInFlight)
+	}
+}
+
+func TestAnalyze_PerServiceSingleRepository(t *testing.T) {
+	llm := &mockLLMClient{responses: []string{validLLMResponse()}}
+
+	ra := newTestAnalyzer(nil, nil, llm)
+	ra.config.PerServiceAnalysis = true
+
+	_, report, err := ra.analyze(
+		[]*types.Comparison{{RepoURL: "https://github.com/org/api"}},
+		[]types.UserGuidance{},
+		[]*types.Documentation{},
+		false,
+	)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(report, "Score by Service") {
+		t.Error("a single repository should not get a per-service breakdown")
+	}
+}
+
+func TestAnalyze_PerServiceFailsWhenServiceFails(t *testing.T) {
+	llm := &mockLLMClient{
+		respond: func(userPrompt string) (string, error) {
+			if strings.Contains(userPrompt, "org/worker") {
+				return "", errors.New("service unavailable")
+			}
+			return validLLMResponse(), nil
+		},
+	}
+
+	ra := newTestAnalyzer(nil, nil, llm)
+	ra.config.PerServiceAnalysis = true
+
+	_, _, err := ra.analyze(
+		[]*types.Comparison{
+			{RepoURL: "https://github.com/org/api", Commits: []types.Commit{{SHA: "abc123", Message: "change"}}},
+			{RepoURL: "https://github.com/org/worker", Commits: []types.Commit{{SHA: "def456", Message: "change"}}},
+		},
+		[]types.UserGuidance{},
+		[]*types.Documentation{},
+		false,
+	)
+
+	if err == nil {
+		t.Fatal("expected error when a service cannot be analyzed")
+	}
+	if !strings.Contains(err.Error(), "failed to analyze service org/worker") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestServiceUserGuidance(t *testing.T) {
+	comparisons := []*types.Comparison{
+		{RepoURL: "https://github.com/org/api"},
+		{RepoURL: "https://github.com/org/api-gateway"},
+	}
+	guidance := []types.UserGuidance{
+		{Content: "api", CommentURL: "https://github.com/org/api/pull/1#issuecomment-1"},
+		{Content: "gateway", CommentURL: "https://github.com/org/api-gateway/pull/2#issuecomment-2"},
+		{Content: "shared", CommentURL: "https://gitlab.example.com/service/app-interface/-/merge_requests/3#note_3"},
+	}
+
+	tests := []struct {
+		name     string
+		repoURL  string
+		expected []string
+	}{
+		{name: "repository prefix does not match sibling repository", repoURL: "https://github.com/org/api", expected: []string{"api", "shared"}},
+		{name: "sibling repository", repoURL: "https://github.com/org/api-gateway/", expected: []string{"gateway", "shared"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := serviceUserGuidance(tt.repoURL, comparisons, guidance)
+
+			var contents []string
+			for _, g := range result {
+				contents = append(contents, g.Content)
+			}
+			if strings.Join(contents, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("guidance = %v, expected %v", contents, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCombineTruncation(t *testing.T) {
+	comparisons := []*types.Comparison{
+		{Files: make([]types.FileChange, 4)},
+		{Files: make([]types.FileChange, 10)},
+		{Files: make([]types.FileChange, 6)},
+	}
+
+	t.Run("no truncation", func(t *testing.T) {
+		if result := combineTruncation([]*modelRun{{}, {}, {}}, comparisons); result != nil {
+			t.Errorf("expected nil, got %+v", result)
+		}
+	})
+
+	t.Run("sums files across services", func(t *testing.T) {
+		runs := []*modelRun{
+			{},
+			{truncation: &truncation.TruncationMetadata{Truncated: true, Level: "high", TotalFiles: 10, FilesPreserved: 7, FilesTruncated: 3}},
+			{truncation: &truncation.TruncationMetadata{Truncated: true, Level: "low", TotalFiles: 6, FilesPreserved: 5, FilesTruncated: 1}},
+		}
+
+		result := combineTruncation(runs, comparisons)
+
+		if result.Level != "high" {
+			t.Errorf("Level = %s, expected high", result.Level)
+		}
+		if result.TotalFiles != 20 || result.FilesPreserved != 16 || result.FilesTruncated != 4 {
+			t.Errorf("unexpected totals: %+v", result)
+		}
+	})
+}
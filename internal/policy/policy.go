@@ -0,0 +1,294 @@
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"release-confidence-score/internal/analysis/ci"
+	"release-confidence-score/internal/analysis/rules"
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/repoconfig"
+)
+
+// Release decisions, from least to most restrictive
+// The values match the decisions of the JSON report
+const (
+	DecisionRecommended    = "recommended"
+	DecisionReviewRequired = "review_required"
+	DecisionNotRecommended = "not_recommended"
+)
+
+var decisionOrder = []string{DecisionRecommended, DecisionReviewRequired, DecisionNotRecommended}
+
+// severityOrder lists concern severities from least to most severe
+var severityOrder = []string{"low", "medium", "high", "critical"}
+
+// maxReasonItems limits how many matching items are quoted in a policy's reason
+const maxReasonItems = 3
+
+// Set is a list of policies loaded from a policy file
+type Set struct {
+	Policies []Policy `yaml:"policies"`
+}
+
+// Policy constrains the release decision when all of its conditions hold
+type Policy struct {
+	Name        string    `yaml:"name"`
+	Description string    `yaml:"description"`
+	When        Condition `yaml:"when"`
+	Then        Effect    `yaml:"then"`
+}
+
+// Condition describes when a policy fires; every field that is set must match
+type Condition struct {
+	CommitLabel     string   `yaml:"commit_label"`     // A commit's PR/MR carries this QE label
+	ConcernSeverity string   `yaml:"concern_severity"` // The analysis has a concern at this severity or above
+	Files           []string `yaml:"files"`            // A changed file matches one of these globs
+	Rules           []string `yaml:"rules"`            // One of these rule-based checks reported a finding
+	CIStatus        string   `yaml:"ci_status"`        // CI on a release head is failed, pending or missing
+}
+
+// Effect constrains the outcome of a release when its policy fires
+type Effect struct {
+	MaxDecision string `yaml:"max_decision"` // Least restrictive decision allowed
+	MaxScore    *int   `yaml:"max_score"`    // Highest confidence score allowed
+}
+
+// Concern is a risk reported by the analysis, as seen by policies
+type Concern struct {
+	Severity    string
+	Description string
+}
+
+// Input is the release data policies are evaluated against
+type Input struct {
+	Comparisons []*types.Comparison
+	Concerns    []Concern
+	Rules       *rules.Result // nil when rules were not evaluated
+	CI          []ci.Result   // CI status of each head ref whose checks could be fetched
+}
+
+// Outcome records a policy that fired and why
+type Outcome struct {
+	Policy      string `json:"policy"`
+	Description string `json:"description,omitempty"`
+	Reason      string `json:"reason"`
+	MaxDecision string `json:"max_decision,omitempty"`
+	MaxScore    *int   `json:"max_score,omitempty"`
+}
+
+// Parse decodes and validates a policy file
+func Parse(data []byte) (*Set, error) {
+	var set Set
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&set); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+
+	for i, policy := range set.Policies {
+		if err := policy.validate(); err != nil {
+			return nil, fmt.Errorf("invalid policy #%d (%s): %w", i+1, policy.Name, err)
+		}
+	}
+	return &set, nil
+}
+
+// LoadFile reads and validates the policy file at path
+// Returns nil without error when path is empty
+func LoadFile(path string) (*Set, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file %s: %w", path, err)
+	}
+
+	set, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return set, nil
+}
+
+// Evaluate returns the outcome of every policy whose conditions hold, in file order
+func (s *Set) Evaluate(input Input) []Outcome {
+	if s == nil {
+		return nil
+	}
+
+	var outcomes []Outcome
+	for _, policy := range s.Policies {
+		reasons, matched := policy.When.match(input)
+		if !matched {
+			continue
+		}
+		outcomes = append(outcomes, Outcome{
+			Policy:      policy.Name,
+			Description: policy.Description,
+			Reason:      strings.Join(reasons, "; "),
+			MaxDecision: policy.Then.MaxDecision,
+			MaxScore:    policy.Then.MaxScore,
+		})
+	}
+	return outcomes
+}
+
+// Apply constrains a score and decision by the outcomes of fired policies
+// The score only goes down and the decision only becomes more restrictive
+// Thresholds are not known here, so callers must still tighten the decision to what the capped score calls for
+func Apply(score int, decision string, outcomes []Outcome) (int, string) {
+	for _, outcome := range outcomes {
+		if outcome.MaxScore != nil {
+			score = min(score, *outcome.MaxScore)
+		}
+		if outcome.MaxDecision != "" {
+			decision = Stricter(decision, outcome.MaxDecision)
+		}
+	}
+	return score, decision
+}
+
+// Stricter returns the more restrictive of two decisions
+func Stricter(a, b string) string {
+	if slices.Index(decisionOrder, b) > slices.Index(decisionOrder, a) {
+		return b
+	}
+	return a
+}
+
+// validate checks that a policy has a name, at least one condition and at least one valid effect
+func (p Policy) validate() error {
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+
+	when := p.When
+	if when.CommitLabel == "" && when.ConcernSeverity == "" && len(when.Files) == 0 && len(when.Rules) == 0 && when.CIStatus == "" {
+		return errors.New("at least one condition is required under 'when'")
+	}
+	if when.ConcernSeverity != "" && !slices.Contains(severityOrder, when.ConcernSeverity) {
+		return fmt.Errorf("concern_severity must be one of: %v; got: %s", severityOrder, when.ConcernSeverity)
+	}
+	for _, pattern := range when.Files {
+		if err := repoconfig.ValidatePattern(pattern); err != nil {
+			return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+	}
+	for _, rule := range when.Rules {
+		if !slices.Contains(rules.Names, rule) {
+			return fmt.Errorf("rules must be one of: %v; got: %s", rules.Names, rule)
+		}
+	}
+	if when.CIStatus != "" && !slices.Contains(ci.States, when.CIStatus) {
+		return fmt.Errorf("ci_status must be one of: %v; got: %s", ci.States, when.CIStatus)
+	}
+
+	then := p.Then
+	if then.MaxDecision == "" && then.MaxScore == nil {
+		return errors.New("at least one effect is required under 'then'")
+	}
+	if then.MaxDecision != "" && !slices.Contains(decisionOrder, then.MaxDecision) {
+		return fmt.Errorf("max_decision must be one of: %v; got: %s", decisionOrder, then.MaxDecision)
+	}
+	if then.MaxScore != nil && (*then.MaxScore < 0 || *then.MaxScore > 100) {
+		return fmt.Errorf("max_score must be between 0 and 100; got: %d", *then.MaxScore)
+	}
+	return nil
+}
+
+// match reports whether every condition holds, with a reason for each
+func (c Condition) match(input Input) ([]string, bool) {
+	var reasons []string
+
+	if c.CommitLabel != "" {
+		var commits []string
+		for _, comparison := range input.Comparisons {
+			for _, commit := range comparison.Commits {
+				if commit.QETestingLabel == c.CommitLabel {
+					commits = append(commits, commit.ShortSHA)
+				}
+			}
+		}
+		if len(commits) == 0 {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("commits labeled %s: %s", c.CommitLabel, summarize(commits)))
+	}
+
+	if c.ConcernSeverity != "" {
+		threshold := slices.Index(severityOrder, c.ConcernSeverity)
+		var concerns []string
+		for _, concern := range input.Concerns {
+			if slices.Index(severityOrder, concern.Severity) >= threshold {
+				concerns = append(concerns, fmt.Sprintf("[%s] %s", concern.Severity, concern.Description))
+			}
+		}
+		if len(concerns) == 0 {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("concerns at %s severity or above: %s", c.ConcernSeverity, summarize(concerns)))
+	}
+
+	if len(c.Files) > 0 {
+		var files []string
+		for _, comparison := range input.Comparisons {
+			for _, file := range comparison.Files {
+				if repoconfig.MatchAny(c.Files, file.Filename) {
+					files = append(files, file.Filename)
+				}
+			}
+		}
+		if len(files) == 0 {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("matching files changed: %s", summarize(files)))
+	}
+
+	if len(c.Rules) > 0 {
+		var findings []string
+		if input.Rules != nil {
+			for _, finding := range input.Rules.Findings {
+				if slices.Contains(c.Rules, finding.Rule) {
+					findings = append(findings, fmt.Sprintf("%s: %s", finding.Rule, finding.Description))
+				}
+			}
+		}
+		if len(findings) == 0 {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("rule findings: %s", summarize(findings)))
+	}
+
+	if c.CIStatus != "" {
+		var refs []string
+		for _, result := range input.CI {
+			if result.State == c.CIStatus {
+				refs = append(refs, fmt.Sprintf("%s@%s", result.Repo, result.Ref))
+			}
+		}
+		if len(refs) == 0 {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("CI %s on: %s", c.CIStatus, summarize(refs)))
+	}
+
+	return reasons, true
+}
+
+// summarize joins the first few items, noting how many more there are
+func summarize(items []string) string {
+	if len(items) <= maxReasonItems {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:maxReasonItems], ", "), len(items)-maxReasonItems)
+}
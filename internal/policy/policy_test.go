@@ -0,0 +1,259 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/analysis/ci"
+	"release-confidence-score/internal/analysis/rules"
+	"release-confidence-score/internal/git/types"
+)
+
+const examplePolicies = `policies:
+  - name: untested-commits
+    description: Commits waiting for QE testing need a manual review
+    when:
+      commit_label: rcs/needs-qe-testing
+    then:
+      max_decision: review_required
+      max_score: 60
+  - name: critical-concerns
+    when:
+      concern_severity: critical
+    then:
+      max_decision: not_recommended
+  - name: sql-migrations
+    when:
+      files: ["**/migrations/**", "*.sql"]
+    then:
+      max_decision: review_required
+  - name: migration-rule
+    when:
+      rules: [migrations]
+    then:
+      max_decision: review_required
+  - name: red-ci
+    when:
+      ci_status: failed
+    then:
+      max_decision: review_required
+`
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestParse(t *testing.T) {
+	set, err := Parse([]byte(examplePolicies))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if len(set.Policies) != 5 {
+		t.Fatalf("expected 5 policies, got %d", len(set.Policies))
+	}
+	if set.Policies[0].Then.MaxScore == nil || *set.Policies[0].Then.MaxScore != 60 {
+		t.Errorf("MaxScore = %v, want 60", set.Policies[0].Then.MaxScore)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		expectErr string
+	}{
+		{"unknown key", "policies:\n  - name: a\n    when: {commit_lable: x}\n    then: {max_decision: review_required}\n", "failed to parse"},
+		{"missing name", "policies:\n  - when: {commit_label: x}\n    then: {max_decision: review_required}\n", "name is required"},
+		{"no condition", "policies:\n  - name: a\n    then: {max_decision: review_required}\n", "at least one condition"},
+		{"no effect", "policies:\n  - name: a\n    when: {commit_label: x}\n", "at least one effect"},
+		{"invalid decision", "policies:\n  - name: a\n    when: {commit_label: x}\n    then: {max_decision: blocked}\n", "max_decision must be one of"},
+		{"invalid score", "policies:\n  - name: a\n    when: {commit_label: x}\n    then: {max_score: 120}\n", "max_score must be between 0 and 100"},
+		{"invalid severity", "policies:\n  - name: a\n    when: {concern_severity: severe}\n    then: {max_score: 50}\n", "concern_severity must be one of"},
+		{"invalid pattern", "policies:\n  - name: a\n    when: {files: [\"db/[0-9.sql\"]}\n    then: {max_score: 50}\n", "invalid file pattern"},
+		{"unknown rule", "policies:\n  - name: a\n    when: {rules: [migration]}\n    then: {max_score: 50}\n", "rules must be one of"},
+		{"invalid CI status", "policies:\n  - name: a\n    when: {ci_status: red}\n    then: {max_score: 50}\n", "ci_status must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("Parse() error = %v, want error containing %q", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	set, err := LoadFile("")
+	if err != nil || set != nil {
+		t.Errorf("LoadFile(\"\") = %v, %v, want nil, nil", set, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	if err := os.WriteFile(path, []byte(examplePolicies), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	set, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() unexpected error: %v", err)
+	}
+	if len(set.Policies) != 5 {
+		t.Errorf("expected 5 policies, got %d", len(set.Policies))
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil || !strings.Contains(err.Error(), "failed to read policy file") {
+		t.Errorf("LoadFile() error = %v, want read error", err)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	set, err := Parse([]byte(examplePolicies))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		input            Input
+		expectedPolicies []string
+		expectedReasons  []string
+	}{
+		{
+			name: "nothing fires",
+			input: Input{
+				Comparisons: []*types.Comparison{{Files: []types.FileChange{{Filename: "main.go"}}}},
+				Concerns:    []Concern{{Severity: "high", Description: "Risky refactor"}},
+				CI:          []ci.Result{{Repo: "https://github.com/org/api", Ref: "abc123", State: ci.StatePending}},
+			},
+		},
+		{
+			name: "untested commit",
+			input: Input{
+				Comparisons: []*types.Comparison{{Commits: []types.Commit{{ShortSHA: "abc1234", QETestingLabel: "rcs/needs-qe-testing"}}}},
+			},
+			expectedPolicies: []string{"untested-commits"},
+			expectedReasons:  []string{"commits labeled rcs/needs-qe-testing: abc1234"},
+		},
+		{
+			name: "critical concern",
+			input: Input{
+				Concerns: []Concern{{Severity: "critical", Description: "Data loss on rollback"}},
+			},
+			expectedPolicies: []string{"critical-concerns"},
+			expectedReasons:  []string{"concerns at critical severity or above: [critical] Data loss on rollback"},
+		},
+		{
+			name: "migration files and rule",
+			input: Input{
+				Comparisons: []*types.Comparison{{Files: []types.FileChange{{Filename: "db/migrations/001.sql"}}}},
+				Rules:       &rules.Result{Findings: []rules.Finding{{Rule: rules.RuleMigrations, Description: "1 database migration file changed"}}},
+			},
+			expectedPolicies: []string{"sql-migrations", "migration-rule"},
+			expectedReasons: []string{
+				"matching files changed: db/migrations/001.sql",
+				"rule findings: migrations: 1 database migration file changed",
+			},
+		},
+		{
+			name: "red CI",
+			input: Input{
+				CI: []ci.Result{
+					{Repo: "https://github.com/org/api", Ref: "abc123", State: ci.StateFailed},
+					{Repo: "https://gitlab.com/org/worker", Ref: "v2", State: ci.StateSuccess},
+				},
+			},
+			expectedPolicies: []string{"red-ci"},
+			expectedReasons:  []string{"CI failed on: https://github.com/org/api@abc123"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcomes := set.Evaluate(tt.input)
+
+			if len(outcomes) != len(tt.expectedPolicies) {
+				t.Fatalf("expected policies %v, got %+v", tt.expectedPolicies, outcomes)
+			}
+			for i, outcome := range outcomes {
+				if outcome.Policy != tt.expectedPolicies[i] {
+					t.Errorf("outcomes[%d].Policy = %s, want %s", i, outcome.Policy, tt.expectedPolicies[i])
+				}
+				if outcome.Reason != tt.expectedReasons[i] {
+					t.Errorf("outcomes[%d].Reason = %q, want %q", i, outcome.Reason, tt.expectedReasons[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluate_AllConditionsMustHold(t *testing.T) {
+	set := &Set{Policies: []Policy{{
+		Name: "untested-migrations",
+		When: Condition{CommitLabel: "rcs/needs-qe-testing", Files: []string{"**/migrations/**"}},
+		Then: Effect{MaxDecision: DecisionNotRecommended},
+	}}}
+
+	onlyLabel := Input{Comparisons: []*types.Comparison{{
+		Commits: []types.Commit{{ShortSHA: "abc1234", QETestingLabel: "rcs/needs-qe-testing"}},
+		Files:   []types.FileChange{{Filename: "main.go"}},
+	}}}
+	if outcomes := set.Evaluate(onlyLabel); len(outcomes) != 0 {
+		t.Errorf("expected no outcome when only one condition holds, got %+v", outcomes)
+	}
+
+	both := Input{Comparisons: []*types.Comparison{{
+		Commits: []types.Commit{{ShortSHA: "abc1234", QETestingLabel: "rcs/needs-qe-testing"}},
+		Files:   []types.FileChange{{Filename: "db/migrations/001.sql"}},
+	}}}
+	outcomes := set.Evaluate(both)
+	if len(outcomes) != 1 {
+		t.Fatalf("expected 1 outcome, got %+v", outcomes)
+	}
+	if !strings.Contains(outcomes[0].Reason, "; matching files changed") {
+		t.Errorf("Reason = %q, want the reasons of both conditions", outcomes[0].Reason)
+	}
+}
+
+func TestEvaluate_NilSet(t *testing.T) {
+	var set *Set
+	if outcomes := set.Evaluate(Input{}); outcomes != nil {
+		t.Errorf("Evaluate() on nil set = %+v, want nil", outcomes)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name             string
+		score            int
+		decision         string
+		outcomes         []Outcome
+		expectedScore    int
+		expectedDecision string
+	}{
+		{"no outcomes", 90, DecisionRecommended, nil, 90, DecisionRecommended},
+		{"decision capped", 90, DecisionRecommended, []Outcome{{MaxDecision: DecisionReviewRequired}}, 90, DecisionReviewRequired},
+		{"decision never relaxed", 40, DecisionNotRecommended, []Outcome{{MaxDecision: DecisionReviewRequired}}, 40, DecisionNotRecommended},
+		{"score capped", 90, DecisionRecommended, []Outcome{{MaxScore: intPtr(60)}}, 60, DecisionRecommended},
+		{"score never raised", 30, DecisionNotRecommended, []Outcome{{MaxScore: intPtr(60)}}, 30, DecisionNotRecommended},
+		{
+			"strictest outcome wins",
+			95,
+			DecisionRecommended,
+			[]Outcome{{MaxDecision: DecisionReviewRequired, MaxScore: intPtr(70)}, {MaxDecision: DecisionNotRecommended, MaxScore: intPtr(80)}},
+			70,
+			DecisionNotRecommended,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, decision := Apply(tt.score, tt.decision, tt.outcomes)
+			if score != tt.expectedScore || decision != tt.expectedDecision {
+				t.Errorf("Apply() = (%d, %s), want (%d, %s)", score, decision, tt.expectedScore, tt.expectedDecision)
+			}
+		})
+	}
+}
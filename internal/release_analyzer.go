@@ -4,19 +4,35 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
+	"release-confidence-score/internal/analysis/ci"
+	"release-confidence-score/internal/analysis/contracts"
+	"release-confidence-score/internal/analysis/coverage"
+	"release-confidence-score/internal/analysis/dependencies"
+	"release-confidence-score/internal/analysis/goapi"
+	"release-confidence-score/internal/analysis/infrastructure"
+	"release-confidence-score/internal/analysis/migrations"
+	"release-confidence-score/internal/analysis/rules"
 	"release-confidence-score/internal/app_interface"
 	"release-confidence-score/internal/config"
 	"release-confidence-score/internal/git/github"
 	"release-confidence-score/internal/git/gitlab"
 	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/budget"
 	llmerrors "release-confidence-score/internal/llm/errors"
 	"release-confidence-score/internal/llm/formatting"
+	"release-confidence-score/internal/llm/injection"
+	"release-confidence-score/internal/llm/prompts/system"
 	"release-confidence-score/internal/llm/prompts/user"
 	"release-confidence-score/internal/llm/providers"
+	"release-confidence-score/internal/llm/redaction"
 	"release-confidence-score/internal/llm/truncation"
+	"release-confidence-score/internal/policy"
+	"release-confidence-score/internal/repoconfig"
 	"release-confidence-score/internal/report"
 
 	"golang.org/x/oauth2/google"
@@ -31,19 +47,46 @@ var truncationLevels = []string{
 }
 
 type ReleaseAnalyzer struct {
-	githubProvider types.GitProvider
-	gitlabProvider types.GitProvider
-	llmClient      providers.LLMClient
-	config         *config.Config
+	githubProvider  types.GitProvider
+	gitlabProvider  types.GitProvider
+	llmClient       providers.LLMClient
+	ensembleClients []modelClient     // Additional models queried alongside llmClient in ensemble mode
+	repoConfig      *types.RepoConfig // Operator's global repository config; nil when RCS_REPO_CONFIG_FILE is unset
+	policies        *policy.Set       // Policies applied to the final decision; nil when RCS_POLICY_FILE is unset
+	config          *config.Config
+}
+
+// modelClient pairs an LLM client with the model it targets
+type modelClient struct {
+	provider string
+	modelID  string
+	client   providers.LLMClient
+}
+
+// modelRun is the outcome of analyzing a release with one model
+type modelRun struct {
+	analysis   *report.StructuredAnalysis
+	truncation *truncation.TruncationMetadata // nil when the full diff was analyzed
+	chunking   *report.ChunkingResult         // Set when the release was analyzed hierarchically
 }
 
 func New(cfg *config.Config) (*ReleaseAnalyzer, error) {
-	githubClient, err := github.NewClient(cfg)
+	repoConfig, err := repoconfig.LoadFile(cfg.RepoConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := policy.LoadFile(cfg.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	githubClients, err := github.NewClients(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
 	}
 
-	gitlabClient, err := gitlab.NewClient(cfg)
+	gitlabClients, err := gitlab.NewClients(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitLab client: %w", err)
 	}
@@ -60,22 +103,45 @@ func New(cfg *config.Config) (*ReleaseAnalyzer, error) {
 	cfg.GCPServiceAccountKey = nil
 	ts := creds.TokenSource
 
-	llmClient, err := providers.NewClient(cfg, ts)
+	// Sampling needs a non-zero temperature so repeated calls explore different answers
+	clientCfg := cfg
+	if cfg.Sampling.Count > 1 {
+		clientCfg = cfg.WithTemperature(cfg.Sampling.Temperature)
+	}
+
+	llmClient, err := providers.NewClient(clientCfg, ts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create LLM client: %w", err)
 	}
 
+	var ensembleClients []modelClient
+	for _, model := range cfg.Ensemble.Models {
+		client, err := providers.NewClient(clientCfg.ForModel(model), ts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ensemble LLM client for %s: %w", model.ID, err)
+		}
+		ensembleClients = append(ensembleClients, modelClient{provider: model.Provider, modelID: model.ID, client: client})
+	}
+
 	return &ReleaseAnalyzer{
-		githubProvider: github.NewFetcher(githubClient, cfg),
-		gitlabProvider: gitlab.NewFetcher(gitlabClient, cfg),
-		llmClient:      llmClient,
-		config:         cfg,
+		githubProvider:  github.NewFetcher(githubClients, cfg),
+		gitlabProvider:  gitlab.NewFetcher(gitlabClients, cfg),
+		llmClient:       llmClient,
+		ensembleClients: ensembleClients,
+		repoConfig:      repoConfig,
+		policies:        policies,
+		config:          cfg,
 	}, nil
 }
 
 func (ra *ReleaseAnalyzer) AnalyzeAppInterface(mergeRequestIID int64, postToMR bool) (float64, string, error) {
 	slog.Info("Starting release analysis", "mode", "app-interface", "mr_iid", mergeRequestIID)
 
+	// Merge request comments are rendered as markdown, so don't spend LLM calls on a report that can't be posted
+	if postToMR && ra.config.ReportFormat == report.FormatJSON {
+		return 0, "", fmt.Errorf("--post-to-mr requires RCS_REPORT_FORMAT=%s", report.FormatMarkdown)
+	}
+
 	// Create GitLab client for app-interface API calls
 	gitlabClient, err := gitlab.NewClient(ra.config)
 	if err != nil {
@@ -181,6 +247,9 @@ func (ra *ReleaseAnalyzer) getReleaseData(urls []string) ([]*types.Comparison, [
 				return fmt.Errorf("no comparison data returned for %s", url)
 			}
 
+			// Repository config files take precedence over the operator's global file
+			comparison.RepoConfig = repoconfig.Layer(comparison.RepoConfig, ra.repoConfig)
+
 			mu.Lock()
 			defer mu.Unlock()
 
@@ -214,49 +283,76 @@ func (ra *ReleaseAnalyzer) getReleaseData(urls []string) ([]*types.Comparison, [
 
 // analyze formats data, calls the LLM (with progressive truncation if needed), and generates the report
 func (ra *ReleaseAnalyzer) analyze(comparisons []*types.Comparison, userGuidance []types.UserGuidance, documentation []*types.Documentation, appInterfaceMode bool) (float64, string, error) {
-	// Format data and prepare initial prompt
-	diffContent := formatting.FormatComparisons(comparisons)
-	documentationText := formatting.FormatDocumentations(documentation)
-
-	userPrompt, err := user.RenderUserPrompt(diffContent, documentationText, userGuidance, truncation.TruncationMetadata{})
-	if err != nil {
-		return 0, "", fmt.Errorf("failed to format user prompt: %w", err)
+	var run *modelRun
+	var ensemble *report.EnsembleResult
+	var sampling []*report.SamplingResult
+	var services []report.ServiceResult
+	var err error
+
+	// Secrets never leave the process: everything below, including the report, sees redacted data
+	comparisons, documentation, userGuidance, secrets := redactReleaseData(comparisons, documentation, userGuidance)
+
+	// Untrusted content that tries to instruct the model is disclosed in the report
+	injectionAttempts := injection.Scan(comparisons, documentation, userGuidance)
+
+	// Deterministic baseline shown next to the AI score, and the fallback when the LLM is unavailable
+	ruleResult := rules.Evaluate(comparisons)
+
+	// Multi-repo releases can be scored per service; a single repository is scored as usual
+	if ra.config.PerServiceAnalysis && len(comparisons) > 1 {
+		run, services, err = ra.analyzePerService(comparisons, documentation, userGuidance)
+	} else {
+		run, ensemble, sampling, err = ra.scoreRelease(comparisons, documentation, userGuidance)
 	}
 
-	// Try LLM analysis with full content first
-	slog.Info("Calling LLM", "provider", ra.config.ModelProvider, "model_id", ra.config.ModelID)
-	response, err := ra.llmClient.Analyze(userPrompt)
-	var truncationInfo *truncation.TruncationMetadata
-
+	rulesOnly := false
 	if err != nil {
-		// Check if this is a context window error
-		contextErr, ok := err.(*llmerrors.ContextWindowError)
-		if !ok {
-			return 0, "", fmt.Errorf("failed to analyze: %w", err)
-		}
-
-		// Retry with progressive truncation
-		slog.Warn("Context window exceeded, retrying with progressive truncation",
-			"provider", contextErr.Provider,
-			"status_code", contextErr.StatusCode)
-
-		response, truncationInfo, err = ra.retryWithTruncation(comparisons, documentation, userGuidance)
-		if err != nil {
+		if !ra.config.RulesOnlyFallback {
 			return 0, "", err
 		}
+		slog.Warn("LLM analysis failed, falling back to a rules-only report", "error", err)
+		run = &modelRun{analysis: report.RulesOnlyAnalysis(ruleResult, err.Error())}
+		ensemble, sampling, services = nil, nil, nil
+		rulesOnly = true
+	}
+
+	modelID := ra.config.ModelID
+	if len(ra.ensembleClients) > 0 {
+		modelID = ensembleModelIDs(ra.primaryModel(), ra.ensembleClients)
+	}
+	if rulesOnly {
+		modelID = "rules only"
 	}
 
 	// Generate report
 	reportConfig := &report.ReportConfig{
-		LLMResponse: response,
+		Analysis:       run.analysis,
+		Ensemble:       ensemble,
+		Sampling:       sampling,
+		Chunking:       run.chunking,
+		Services:       services,
+		Rules:          ruleResult,
+		RulesOnly:      rulesOnly,
+		Secrets:        secrets,
+		Injection:      injectionAttempts,
+		Dependencies:   dependencies.Collect(comparisons),
+		Migrations:     migrations.Analyze(comparisons),
+		Infrastructure: infrastructure.Collect(comparisons),
+		APIChanges:     contracts.Collect(comparisons),
+		GoAPIChanges:   goapi.Collect(comparisons),
+		TestCoverage:   coverage.Analyze(comparisons),
+		CI:             ci.Analyze(comparisons),
+		GitHubHosts:    ra.config.GitHubHosts(),
+		Policies:       ra.policies,
+		Format:         ra.config.ReportFormat,
 		Metadata: &report.ReportMetadata{
-			ModelID:        ra.config.ModelID,
+			ModelID:        modelID,
 			GenerationTime: time.Now(),
 		},
 		Comparisons:             comparisons,
 		Documentation:           documentation,
 		UserGuidance:            userGuidance,
-		TruncationInfo:          truncationInfo,
+		TruncationInfo:          run.truncation,
 		AutoDeployThreshold:     ra.config.ScoreThresholds.AutoDeploy,
 		ReviewRequiredThreshold: ra.config.ScoreThresholds.ReviewRequired,
 		AppInterfaceMode:        appInterfaceMode,
@@ -272,29 +368,377 @@ func (ra *ReleaseAnalyzer) analyze(comparisons []*types.Comparison, userGuidance
 	return float64(score), finalReport, nil
 }
 
+// redactReleaseData replaces secrets in patches, documentation and guidance with typed placeholders
+func redactReleaseData(comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) ([]*types.Comparison, []*types.Documentation, []types.UserGuidance, []redaction.Finding) {
+	comparisons, secrets := redaction.Comparisons(comparisons)
+	documentation, docSecrets := redaction.Documentation(documentation)
+	userGuidance, guidanceSecrets := redaction.UserGuidance(userGuidance)
+
+	secrets = append(secrets, docSecrets...)
+	secrets = append(secrets, guidanceSecrets...)
+	return comparisons, documentation, userGuidance, secrets
+}
+
+// scoreRelease analyzes the release with the configured ensemble, sampling or single model
+func (ra *ReleaseAnalyzer) scoreRelease(comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) (*modelRun, *report.EnsembleResult, []*report.SamplingResult, error) {
+	switch {
+	case len(ra.ensembleClients) > 0:
+		return ra.runEnsemble(comparisons, documentation, userGuidance)
+	case ra.config.Sampling.Count > 1:
+		run, samplingResult, err := ra.sampleModel(ra.primaryModel(), comparisons, documentation, userGuidance)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return run, nil, []*report.SamplingResult{samplingResult}, nil
+	default:
+		run, err := ra.runModel(ra.primaryModel(), comparisons, documentation, userGuidance)
+		return run, nil, nil, err
+	}
+}
+
+// primaryModel returns the model configured through RCS_MODEL_PROVIDER
+func (ra *ReleaseAnalyzer) primaryModel() modelClient {
+	return modelClient{
+		provider: ra.config.ModelProvider,
+		modelID:  ra.config.ModelID,
+		client:   ra.llmClient,
+	}
+}
+
+// runModel sends the release data to a single model and returns its schema-validated analysis
+func (ra *ReleaseAnalyzer) runModel(model modelClient, comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) (*modelRun, error) {
+	// In hierarchical mode, releases that don't fit are split into chunks instead of truncated
+	if ra.config.AnalysisMode == "hierarchical" {
+		fits, err := ra.fitsContextWindow(model.modelID, comparisons, documentation, userGuidance)
+		if err != nil {
+			return nil, err
+		}
+		if !fits {
+			return ra.runHierarchical(model, comparisons, documentation, userGuidance)
+		}
+	}
+
+	// Pick the starting truncation level from the estimated prompt size
+	userPrompt, nextLevel, truncationInfo, err := ra.preparePrompt(model.modelID, comparisons, documentation, userGuidance)
+	if err != nil {
+		return nil, err
+	}
+
+	userPrompt, response, truncationInfo, err := ra.callModel(model, userPrompt, nextLevel, truncationInfo, comparisons, documentation, userGuidance)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis, err := ra.parseWithRepair(model, userPrompt, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &modelRun{analysis: analysis, truncation: truncationInfo}, nil
+}
+
+// callModel sends a prepared prompt to a single model, falling back to progressively more aggressive
+// truncation (starting at truncationLevels[nextLevel]) on context window errors
+// Returns: the user prompt that was answered, raw response, truncation metadata, error
+func (ra *ReleaseAnalyzer) callModel(model modelClient, userPrompt string, nextLevel int, truncationInfo *truncation.TruncationMetadata, comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) (string, string, *truncation.TruncationMetadata, error) {
+	slog.Info("Calling LLM", "provider", model.provider, "model_id", model.modelID)
+	response, err := model.client.Analyze(userPrompt)
+	if err == nil {
+		return userPrompt, response, truncationInfo, nil
+	}
+
+	// Check if this is a context window error
+	contextErr, ok := err.(*llmerrors.ContextWindowError)
+	if !ok {
+		return "", "", nil, fmt.Errorf("failed to analyze: %w", err)
+	}
+
+	// Retry with progressive truncation, starting after the pre-selected level
+	slog.Warn("Context window exceeded, retrying with progressive truncation",
+		"provider", contextErr.Provider,
+		"status_code", contextErr.StatusCode)
+
+	return ra.retryWithTruncation(model.client, truncationLevels[nextLevel:], comparisons, documentation, userGuidance, contextErr)
+}
+
+// parseWithRepair validates a response against the analysis schema; if it doesn't conform, the model
+// is re-prompted with the validation problems up to RCS_MODEL_REPAIR_ATTEMPTS times before giving up
+func (ra *ReleaseAnalyzer) parseWithRepair(model modelClient, userPrompt, response string) (*report.StructuredAnalysis, error) {
+	analysis, err := report.ParseAnalysis(response)
+
+	for attempt := 1; err != nil && attempt <= ra.config.ModelRepairAttempts; attempt++ {
+		validationErr, ok := err.(*report.ValidationError)
+		if !ok {
+			return nil, err
+		}
+
+		slog.Warn("LLM response failed validation, requesting repair",
+			"provider", model.provider,
+			"model_id", model.modelID,
+			"attempt", attempt,
+			"problems", validationErr.Problems)
+
+		repairPrompt, renderErr := user.RenderRepairPrompt(userPrompt, response, validationErr.Problems)
+		if renderErr != nil {
+			return nil, renderErr
+		}
+
+		response, err = model.client.Analyze(repairPrompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to repair LLM response: %w", err)
+		}
+
+		analysis, err = report.ParseAnalysis(response)
+		if err == nil {
+			slog.Info("LLM response repaired", "model_id", model.modelID, "attempt", attempt)
+		}
+	}
+
+	if err != nil {
+		if ra.config.ModelRepairAttempts > 0 {
+			return nil, fmt.Errorf("LLM response still invalid after %d repair attempts: %w", ra.config.ModelRepairAttempts, err)
+		}
+		return nil, fmt.Errorf("invalid LLM response: %w", err)
+	}
+
+	return analysis, nil
+}
+
+// runEnsemble sends the release data to the primary and all ensemble models concurrently and merges their analyses
+// Models that fail are listed in the report but don't abort the run as long as one analysis succeeds
+func (ra *ReleaseAnalyzer) runEnsemble(comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) (*modelRun, *report.EnsembleResult, []*report.SamplingResult, error) {
+	models := append([]modelClient{ra.primaryModel()}, ra.ensembleClients...)
+
+	runs := make([]*modelRun, len(models))
+	samplings := make([]*report.SamplingResult, len(models))
+	errs := make([]error, len(models))
+
+	// Goroutines record their own errors so one failing model doesn't cancel the others
+	var g errgroup.Group
+	for i, model := range models {
+		g.Go(func() error {
+			run, samplingResult, err := ra.sampleModel(model, comparisons, documentation, userGuidance)
+			if err != nil {
+				slog.Warn("Ensemble model failed", "provider", model.provider, "model_id", model.modelID, "error", err)
+				errs[i] = err
+				return nil
+			}
+			runs[i] = run
+			samplings[i] = samplingResult
+			return nil
+		})
+	}
+	g.Wait()
+
+	var succeeded []report.ModelAnalysis
+	var succeededRuns []*modelRun
+	var sampling []*report.SamplingResult
+	failed := make(map[string]string)
+	for i, model := range models {
+		if errs[i] != nil {
+			failed[model.modelID] = errs[i].Error()
+			continue
+		}
+		succeeded = append(succeeded, report.ModelAnalysis{Model: model.modelID, Analysis: runs[i].analysis})
+		succeededRuns = append(succeededRuns, runs[i])
+		if samplings[i] != nil {
+			sampling = append(sampling, samplings[i])
+		}
+	}
+
+	if len(succeeded) == 0 {
+		return nil, nil, nil, fmt.Errorf("all ensemble models failed: %w", errs[0])
+	}
+
+	merged, ensemble, err := report.MergeEnsemble(succeeded, ra.config.Ensemble.Aggregation, ra.config.Ensemble.DisagreementThreshold)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to merge ensemble analyses: %w", err)
+	}
+	if len(failed) > 0 {
+		ensemble.FailedModels = failed
+	}
+
+	slog.Info("Ensemble analysis complete",
+		"models", len(succeeded),
+		"failed_models", len(failed),
+		"score", merged.Score,
+		"disagreements", len(ensemble.Disagreements))
+
+	return combineRuns(merged, succeededRuns), ensemble, sampling, nil
+}
+
+// sampleModel asks a model for RCS_SAMPLING_COUNT independent analyses and merges them into one
+// Failed samples are skipped as long as one succeeds; without sampling the single analysis is returned
+// and the sampling result is nil
+func (ra *ReleaseAnalyzer) sampleModel(model modelClient, comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) (*modelRun, *report.SamplingResult, error) {
+	count := max(ra.config.Sampling.Count, 1)
+
+	runs := make([]*modelRun, count)
+	errs := make([]error, count)
+
+	var g errgroup.Group
+	for i := range count {
+		g.Go(func() error {
+			runs[i], errs[i] = ra.runModel(model, comparisons, documentation, userGuidance)
+			return nil
+		})
+	}
+	g.Wait()
+
+	if count == 1 {
+		return runs[0], nil, errs[0]
+	}
+
+	var samples []*report.StructuredAnalysis
+	var succeededRuns []*modelRun
+	for i, run := range runs {
+		if errs[i] != nil {
+			slog.Warn("Sample failed", "provider", model.provider, "model_id", model.modelID, "sample", i+1, "error", errs[i])
+			continue
+		}
+		samples = append(samples, run.analysis)
+		succeededRuns = append(succeededRuns, run)
+	}
+
+	if len(samples) == 0 {
+		return nil, nil, fmt.Errorf("all %d samples failed: %w", count, errs[0])
+	}
+
+	merged, result, err := report.MergeSamples(model.modelID, samples, count,
+		ra.config.Sampling.MinConcernOccurrences, ra.config.Sampling.SpreadThreshold)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to merge samples: %w", err)
+	}
+
+	slog.Info("Sampling complete",
+		"model_id", model.modelID,
+		"samples", len(samples),
+		"median", result.Median,
+		"spread", result.Spread,
+		"low_confidence", result.LowConfidence)
+
+	return combineRuns(merged, succeededRuns), result, nil
+}
+
+// ensembleModelIDs returns a comma-separated list of all model IDs in the ensemble for report metadata
+func ensembleModelIDs(primary modelClient, ensembleClients []modelClient) string {
+	ids := []string{primary.modelID}
+	for _, model := range ensembleClients {
+		ids = append(ids, model.modelID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// combineRuns wraps a merged analysis with the most aggressive truncation of the runs it was merged from
+// and the chunking of the first hierarchical run
+func combineRuns(merged *report.StructuredAnalysis, runs []*modelRun) *modelRun {
+	combined := &modelRun{analysis: merged}
+
+	var truncations []*truncation.TruncationMetadata
+	for _, run := range runs {
+		truncations = append(truncations, run.truncation)
+		if combined.chunking == nil {
+			combined.chunking = run.chunking
+		}
+	}
+	combined.truncation = mostAggressiveTruncation(truncations)
+
+	return combined
+}
+
+// mostAggressiveTruncation returns the metadata with the highest truncation level, or nil if none was truncated
+func mostAggressiveTruncation(truncations []*truncation.TruncationMetadata) *truncation.TruncationMetadata {
+	var result *truncation.TruncationMetadata
+	for _, metadata := range truncations {
+		if metadata == nil {
+			continue
+		}
+		if result == nil || slices.Index(truncationLevels, metadata.Level) > slices.Index(truncationLevels, result.Level) {
+			result = metadata
+		}
+	}
+	return result
+}
+
+// preparePrompt renders the user prompt at the lowest truncation level whose estimated size
+// fits the model's context window, so oversized releases don't waste a rejected LLM call
+// Returns: user prompt, index in truncationLevels of the next level to retry with,
+// truncation metadata (nil when the full prompt fits), error
+func (ra *ReleaseAnalyzer) preparePrompt(modelID string, comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) (string, int, *truncation.TruncationMetadata, error) {
+	tokenBudget := budget.ForModel(modelID, ra.config.ModelContextWindow, ra.config.ModelMaxResponseTokens)
+	systemPrompt := system.GetSystemPrompt(ra.config)
+
+	userPrompt, err := user.RenderUserPrompt(
+		formatting.FormatComparisons(comparisons),
+		formatEvidence(comparisons),
+		formatting.FormatDocumentations(documentation),
+		userGuidance,
+		truncation.TruncationMetadata{},
+	)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to format user prompt: %w", err)
+	}
+
+	if tokenBudget.Fits(systemPrompt, userPrompt) {
+		return userPrompt, 0, nil, nil
+	}
+
+	slog.Info("Estimated prompt size exceeds context window, selecting truncation level",
+		"estimated_tokens", budget.EstimateTokens(systemPrompt)+budget.EstimateTokens(userPrompt),
+		"available_tokens", tokenBudget.Available())
+
+	for i, level := range truncationLevels {
+		userPrompt, metadata, err := renderTruncatedPrompt(level, comparisons, documentation, userGuidance)
+		if err != nil {
+			return "", 0, nil, err
+		}
+
+		// Use the most aggressive level even if it still doesn't fit, since the estimate is conservative
+		if tokenBudget.Fits(systemPrompt, userPrompt) || i == len(truncationLevels)-1 {
+			slog.Info("Pre-selected truncation level", "level", level)
+			return userPrompt, i + 1, &metadata, nil
+		}
+	}
+
+	// Unreachable: the loop always returns on the last level
+	return userPrompt, 0, nil, nil
+}
+
+// renderTruncatedPrompt truncates comparisons and documentation to the given level and renders the user prompt
+func renderTruncatedPrompt(level string, comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) (string, truncation.TruncationMetadata, error) {
+	truncatedComparisons, metadata := truncation.TruncateMultipleComparisons(comparisons, level)
+	truncatedDocs := truncation.TruncateDocumentation(documentation, level)
+
+	userPrompt, err := user.RenderUserPrompt(
+		formatting.FormatComparisons(truncatedComparisons),
+		formatEvidence(comparisons),
+		formatting.FormatDocumentations(truncatedDocs),
+		userGuidance,
+		metadata,
+	)
+	if err != nil {
+		return "", metadata, fmt.Errorf("failed to format user prompt with %s truncation: %w", level, err)
+	}
+
+	return userPrompt, metadata, nil
+}
+
 // retryWithTruncation attempts LLM analysis with progressively more aggressive truncation
-func (ra *ReleaseAnalyzer) retryWithTruncation(comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance) (string, *truncation.TruncationMetadata, error) {
-	var lastErr error
-	for _, level := range truncationLevels {
+// lastErr is the context window error that triggered the retry, reported if no level is left to try
+// Returns: the user prompt that was answered, raw response, truncation metadata, error
+func (ra *ReleaseAnalyzer) retryWithTruncation(client providers.LLMClient, levels []string, comparisons []*types.Comparison, documentation []*types.Documentation, userGuidance []types.UserGuidance, lastErr error) (string, string, *truncation.TruncationMetadata, error) {
+	for _, level := range levels {
 		slog.Info("Attempting analysis with truncation", "level", level)
 
-		truncatedComparisons, metadata := truncation.TruncateMultipleComparisons(comparisons, level)
-		truncatedDocs := truncation.TruncateDocumentation(documentation, level)
-
-		userPrompt, err := user.RenderUserPrompt(
-			formatting.FormatComparisons(truncatedComparisons),
-			formatting.FormatDocumentations(truncatedDocs),
-			userGuidance,
-			metadata,
-		)
+		userPrompt, metadata, err := renderTruncatedPrompt(level, comparisons, documentation, userGuidance)
 		if err != nil {
-			return "", nil, fmt.Errorf("failed to format user prompt with %s truncation: %w", level, err)
+			return "", "", nil, err
 		}
 
-		response, err := ra.llmClient.Analyze(userPrompt)
+		response, err := client.Analyze(userPrompt)
 		if err == nil {
 			slog.Info("Analysis succeeded with truncation", "level", level)
-			return response, &metadata, nil
+			return userPrompt, response, &metadata, nil
 		}
 
 		if _, isContextErr := err.(*llmerrors.ContextWindowError); isContextErr {
@@ -303,8 +747,36 @@ func (ra *ReleaseAnalyzer) retryWithTruncation(comparisons []*types.Comparison,
 			continue
 		}
 
-		return "", nil, fmt.Errorf("failed to analyze with %s truncation: %w", level, err)
+		return "", "", nil, fmt.Errorf("failed to analyze with %s truncation: %w", level, err)
 	}
 
-	return "", nil, fmt.Errorf("failed to analyze even with extreme truncation: %w", lastErr)
+	return "", "", nil, fmt.Errorf("failed to analyze even with extreme truncation: %w", lastErr)
+}
+
+// formatEvidence computes the deterministic signals given to the model alongside the diff
+// Evidence is always computed from the untruncated comparisons
+func formatEvidence(comparisons []*types.Comparison) string {
+	evidence := rules.Evaluate(comparisons).Evidence()
+	if dependencyEvidence := dependencies.Evidence(dependencies.Collect(comparisons)); dependencyEvidence != "" {
+		evidence += "\n" + dependencyEvidence
+	}
+	if infrastructureEvidence := infrastructure.Evidence(infrastructure.Collect(comparisons)); infrastructureEvidence != "" {
+		evidence += "\n" + infrastructureEvidence
+	}
+	if apiEvidence := contracts.Evidence(contracts.Collect(comparisons)); apiEvidence != "" {
+		evidence += "\n" + apiEvidence
+	}
+	if goAPIEvidence := goapi.Evidence(goapi.Collect(comparisons)); goAPIEvidence != "" {
+		evidence += "\n" + goAPIEvidence
+	}
+	if migrationEvidence := migrations.Evidence(migrations.Analyze(comparisons)); migrationEvidence != "" {
+		evidence += "\n" + migrationEvidence
+	}
+	if coverageEvidence := coverage.Evidence(coverage.Analyze(comparisons)); coverageEvidence != "" {
+		evidence += "\n" + coverageEvidence
+	}
+	if ciEvidence := ci.Evidence(ci.Analyze(comparisons)); ciEvidence != "" {
+		evidence += "\n" + ciEvidence
+	}
+	return evidence
 }
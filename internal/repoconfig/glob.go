@@ -0,0 +1,85 @@
+package repoconfig
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+// Glob semantics follow .gitignore conventions:
+//   - "**" as a whole path segment matches zero or more directories
+//   - "*", "?" and character classes match within a single segment
+//   - a pattern without a slash matches the file name at any depth ("*.sql")
+//   - a pattern ending in a slash matches everything beneath that directory ("fixtures/")
+//   - a leading slash is ignored; patterns with a slash are always relative to the repository root
+//
+// Matching is case-insensitive, consistent with the built-in risk patterns.
+
+// ValidatePattern reports whether pattern is a well-formed glob
+func ValidatePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return errors.New("pattern is empty")
+	}
+	for _, segment := range splitPattern(pattern) {
+		if _, err := path.Match(segment, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match reports whether filename matches the glob pattern
+// Malformed patterns never match; they are rejected by Parse before reaching here
+func Match(pattern, filename string) bool {
+	return matchSegments(splitPattern(pattern), strings.Split(strings.ToLower(filename), "/"))
+}
+
+// MatchAny reports whether filename matches any of the glob patterns
+func MatchAny(patterns []string, filename string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, filename) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPattern normalizes a pattern and splits it into path segments
+func splitPattern(pattern string) []string {
+	pattern = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(pattern), "/"))
+
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return strings.Split(pattern, "/")
+}
+
+// matchSegments matches path segments against pattern segments, expanding "**" to any number of segments
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				return true
+			}
+			for i := range len(name) + 1 {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], name[0]); err != nil || !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,78 @@
+package repoconfig
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		filename string
+		expected bool
+	}{
+		{"double star matches nested file", "pkg/billing/**", "pkg/billing/invoice/total.go", true},
+		{"double star matches direct child", "pkg/billing/**", "pkg/billing/total.go", true},
+		{"double star anchored at root", "pkg/billing/**", "internal/pkg/billing/total.go", false},
+		{"double star does not match sibling prefix", "pkg/billing/**", "pkg/billing-v2/total.go", false},
+		{"leading double star matches any depth", "**/fixtures/**", "a/b/fixtures/data.json", true},
+		{"leading double star matches zero directories", "**/fixtures/**", "fixtures/data.json", true},
+		{"double star in the middle", "api/**/schema.json", "api/v1/users/schema.json", true},
+		{"double star in the middle matches zero directories", "api/**/schema.json", "api/schema.json", true},
+		{"single star stays within a segment", "pkg/*.go", "pkg/sub/file.go", false},
+		{"single star matches within a segment", "pkg/*.go", "pkg/file.go", true},
+		{"pattern without slash matches name at any depth", "*.golden", "testdata/out/report.golden", true},
+		{"pattern without slash matches root file", "Makefile", "Makefile", true},
+		{"trailing slash matches directory contents", "fixtures/", "fixtures/a/b.yaml", true},
+		{"leading slash is ignored", "/deploy/*.yaml", "deploy/prod.yaml", true},
+		{"question mark matches one character", "migrations/v?.sql", "migrations/v1.sql", true},
+		{"character class", "db/[0-9]*.sql", "db/001_init.sql", true},
+		{"case-insensitive", "pkg/Billing/**", "PKG/billing/Total.go", true},
+		{"no match", "pkg/billing/**", "cmd/main.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Match(tt.pattern, tt.filename); got != tt.expected {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.filename, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	patterns := []string{"fixtures/**", "*.golden"}
+
+	if !MatchAny(patterns, "testdata/a.golden") {
+		t.Error("MatchAny() = false for a file matching the second pattern, want true")
+	}
+	if MatchAny(patterns, "cmd/main.go") {
+		t.Error("MatchAny() = true for a file matching no pattern, want false")
+	}
+	if MatchAny(nil, "cmd/main.go") {
+		t.Error("MatchAny() = true for no patterns, want false")
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   string
+		expectErr bool
+	}{
+		{"valid double star", "pkg/**/*.go", false},
+		{"valid character class", "db/[0-9]*.sql", false},
+		{"empty", "  ", true},
+		{"unterminated character class", "db/[0-9.sql", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePattern(tt.pattern)
+			if tt.expectErr && err == nil {
+				t.Errorf("ValidatePattern(%q) expected error, got nil", tt.pattern)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("ValidatePattern(%q) unexpected error: %v", tt.pattern, err)
+			}
+		})
+	}
+}
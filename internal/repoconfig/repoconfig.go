@@ -0,0 +1,124 @@
+package repoconfig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"release-confidence-score/internal/git/types"
+)
+
+// Filename is the repository config file read from the base of each compared ref range
+const Filename = ".release-confidence.yaml"
+
+// Parse decodes and validates a repository config
+// Unknown keys are rejected so that typos don't silently disable an override
+func Parse(data []byte) (*types.RepoConfig, error) {
+	var cfg types.RepoConfig
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", Filename, err)
+	}
+
+	if err := validate(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// LoadFile reads the operator's global config from disk
+// Returns nil without error when path is empty
+func LoadFile(path string) (*types.RepoConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository config file %s: %w", path, err)
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Fetch reads the repository's config at ref through the documentation source
+// A missing file yields nil; an invalid one is logged and ignored so it never blocks analysis
+func Fetch(ctx context.Context, source types.DocumentationSource, repoURL, ref string) *types.RepoConfig {
+	content, err := source.FetchFileContent(ctx, Filename, ref)
+	if err != nil {
+		slog.Debug("No repository config file found", "repo", repoURL, "ref", ref, "error", err)
+		return nil
+	}
+
+	cfg, err := Parse([]byte(content))
+	if err != nil {
+		slog.Warn("Ignoring invalid repository config file", "repo", repoURL, "ref", ref, "error", err)
+		return nil
+	}
+
+	slog.Debug("Loaded repository config file", "repo", repoURL, "ref", ref)
+	return cfg
+}
+
+// Layer returns the repository config with the global config beneath it
+// Either may be nil; the repository config wins wherever both have an opinion
+func Layer(repo, global *types.RepoConfig) *types.RepoConfig {
+	if repo == nil {
+		return global
+	}
+	if global == nil {
+		return repo
+	}
+
+	layered := *repo
+	layered.Base = global
+	return &layered
+}
+
+// validate checks that every pattern is a valid glob, every threshold is non-negative and every required check is named
+func validate(cfg *types.RepoConfig) error {
+	patterns := map[string][]string{
+		"critical": cfg.RiskPatterns.Critical,
+		"high":     cfg.RiskPatterns.High,
+		"medium":   cfg.RiskPatterns.Medium,
+		"low":      cfg.RiskPatterns.Low,
+	}
+	for _, class := range []string{"critical", "high", "medium", "low"} {
+		for _, pattern := range patterns[class] {
+			if err := ValidatePattern(pattern); err != nil {
+				return fmt.Errorf("invalid %s risk pattern %q: %w", class, pattern, err)
+			}
+		}
+	}
+
+	thresholds := cfg.SmallFileThresholds
+	for level, value := range map[string]int{
+		"low":      thresholds.Low,
+		"moderate": thresholds.Moderate,
+		"high":     thresholds.High,
+		"extreme":  thresholds.Extreme,
+	} {
+		if value < 0 {
+			return fmt.Errorf("small file threshold for %s must be non-negative; got: %d", level, value)
+		}
+	}
+
+	for i, check := range cfg.RequiredChecks {
+		if strings.TrimSpace(check) == "" {
+			return fmt.Errorf("required check #%d must have a name", i+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package repoconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"release-confidence-score/internal/git/types"
+)
+
+// mockDocumentationSource implements types.DocumentationSource for testing
+type mockDocumentationSource struct {
+	files    map[string]string // path -> content
+	fetchRef string            // ref of the last FetchFileContent call
+}
+
+func (m *mockDocumentationSource) GetDefaultBranch(ctx context.Context) (string, error) {
+	return "main", nil
+}
+
+func (m *mockDocumentationSource) FetchFileContent(ctx context.Context, path, ref string) (string, error) {
+	m.fetchRef = ref
+	if content, ok := m.files[path]; ok {
+		return content, nil
+	}
+	return "", errors.New("file not found")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectErr   string
+		expectCheck func(t *testing.T, cfg *types.RepoConfig)
+	}{
+		{
+			name: "full config",
+			content: `risk_patterns:
+  critical:
+    - pkg/billing/**
+  low:
+    - fixtures/**
+small_file_thresholds:
+  high: 80
+  extreme: 30
+required_checks:
+  - unit-tests
+`,
+			expectCheck: func(t *testing.T, cfg *types.RepoConfig) {
+				if !slices.Equal(cfg.RiskPatterns.Critical, []string{"pkg/billing/**"}) {
+					t.Errorf("Critical = %v, want [pkg/billing/**]", cfg.RiskPatterns.Critical)
+				}
+				if !slices.Equal(cfg.RiskPatterns.Low, []string{"fixtures/**"}) {
+					t.Errorf("Low = %v, want [fixtures/**]", cfg.RiskPatterns.Low)
+				}
+				if cfg.SmallFileThresholds.High != 80 || cfg.SmallFileThresholds.Extreme != 30 {
+					t.Errorf("SmallFileThresholds = %+v, want high 80 and extreme 30", cfg.SmallFileThresholds)
+				}
+				if cfg.SmallFileThresholds.Low != 0 {
+					t.Errorf("SmallFileThresholds.Low = %d, want 0 (unset)", cfg.SmallFileThresholds.Low)
+				}
+				if !slices.Equal(cfg.RequiredChecks, []string{"unit-tests"}) {
+					t.Errorf("RequiredChecks = %v, want [unit-tests]", cfg.RequiredChecks)
+				}
+			},
+		},
+		{
+			name:    "empty file",
+			content: "",
+			expectCheck: func(t *testing.T, cfg *types.RepoConfig) {
+				if len(cfg.RiskPatterns.Critical) != 0 {
+					t.Errorf("Critical = %v, want empty", cfg.RiskPatterns.Critical)
+				}
+			},
+		},
+		{
+			name:      "unknown key",
+			content:   "risk_pattern:\n  critical: [\"a/**\"]\n",
+			expectErr: "failed to parse",
+		},
+		{
+			name:      "invalid yaml",
+			content:   "risk_patterns: [",
+			expectErr: "failed to parse",
+		},
+		{
+			name:      "invalid pattern",
+			content:   "risk_patterns:\n  high: [\"db/[0-9.sql\"]\n",
+			expectErr: "invalid high risk pattern",
+		},
+		{
+			name:      "negative threshold",
+			content:   "small_file_thresholds:\n  low: -1\n",
+			expectErr: "must be non-negative",
+		},
+		{
+			name:      "unnamed required check",
+			content:   "required_checks:\n  - unit-tests\n  - \"\"\n",
+			expectErr: "required check #2 must have a name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.content))
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("Parse() error = %v, want error containing %q", err, tt.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			tt.expectCheck(t, cfg)
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Run("empty path", func(t *testing.T) {
+		cfg, err := LoadFile("")
+		if err != nil || cfg != nil {
+			t.Errorf("LoadFile(\"\") = %v, %v, want nil, nil", cfg, err)
+		}
+	})
+
+	t.Run("valid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "global.yaml")
+		if err := os.WriteFile(path, []byte("risk_patterns:\n  critical: [\"pkg/billing/**\"]\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile() unexpected error: %v", err)
+		}
+		if !slices.Equal(cfg.RiskPatterns.Critical, []string{"pkg/billing/**"}) {
+			t.Errorf("Critical = %v, want [pkg/billing/**]", cfg.RiskPatterns.Critical)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+		if err == nil || !strings.Contains(err.Error(), "failed to read") {
+			t.Errorf("LoadFile() error = %v, want read error", err)
+		}
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "global.yaml")
+		if err := os.WriteFile(path, []byte("unknown: true\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := LoadFile(path)
+		if err == nil || !strings.Contains(err.Error(), "invalid repository config file") {
+			t.Errorf("LoadFile() error = %v, want invalid config error", err)
+		}
+	})
+}
+
+func TestFetch(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		expectNil bool
+	}{
+		{"valid file", map[string]string{Filename: "risk_patterns:\n  low: [\"fixtures/**\"]\n"}, false},
+		{"missing file", map[string]string{}, true},
+		{"invalid file", map[string]string{Filename: "risk_patterns: ["}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &mockDocumentationSource{files: tt.files}
+
+			cfg := Fetch(context.Background(), source, "https://github.com/org/repo", "abc123")
+
+			if source.fetchRef != "abc123" {
+				t.Errorf("fetched ref = %q, want abc123", source.fetchRef)
+			}
+			if tt.expectNil && cfg != nil {
+				t.Errorf("Fetch() = %+v, want nil", cfg)
+			}
+			if !tt.expectNil && cfg == nil {
+				t.Error("Fetch() = nil, want config")
+			}
+		})
+	}
+}
+
+func TestLayer(t *testing.T) {
+	repo := &types.RepoConfig{RiskPatterns: types.RiskPatterns{Low: []string{"fixtures/**"}}}
+	global := &types.RepoConfig{RiskPatterns: types.RiskPatterns{Critical: []string{"pkg/billing/**"}}}
+
+	if got := Layer(nil, nil); got != nil {
+		t.Errorf("Layer(nil, nil) = %+v, want nil", got)
+	}
+	if got := Layer(nil, global); got != global {
+		t.Error("Layer(nil, global) should return the global config")
+	}
+	if got := Layer(repo, nil); got != repo {
+		t.Error("Layer(repo, nil) should return the repository config")
+	}
+
+	layered := Layer(repo, global)
+	if layered.Base != global {
+		t.Error("Layer(repo, global).Base should be the global config")
+	}
+	if !slices.Equal(layered.RiskPatterns.Low, repo.RiskPatterns.Low) {
+		t.Errorf("Layer(repo, global) Low = %v, want %v", layered.RiskPatterns.Low, repo.RiskPatterns.Low)
+	}
+	if repo.Base != nil {
+		t.Error("Layer() modified the repository config")
+	}
+}
@@ -0,0 +1,37 @@
+package report
+
+import "fmt"
+
+// ChunkingResult describes how a release was split for hierarchical analysis
+type ChunkingResult struct {
+	Chunks     []ChunkResult `json:"chunks"`
+	Aggregated bool          `json:"aggregated"` // False when the aggregation call failed and chunk analyses were merged locally
+}
+
+// ChunkResult is the analysis of one chunk of a release
+type ChunkResult struct {
+	Label    string        `json:"label"`
+	Files    []string      `json:"files"`
+	Score    int           `json:"score"`
+	Concerns []RiskConcern `json:"concerns,omitempty"`
+}
+
+// TotalFiles returns the number of files across all chunks
+func (c *ChunkingResult) TotalFiles() int {
+	total := 0
+	for _, chunk := range c.Chunks {
+		total += len(chunk.Files)
+	}
+	return total
+}
+
+// MergeChunks combines chunk analyses without an LLM aggregation call
+// A release is only as safe as its riskiest part, so the lowest chunk score is used;
+// findings are the deduplicated union of all chunks
+func MergeChunks(analyses []*StructuredAnalysis) (*StructuredAnalysis, error) {
+	if len(analyses) == 0 {
+		return nil, fmt.Errorf("no chunk analyses to merge")
+	}
+
+	return mergeLowest(analyses), nil
+}
@@ -0,0 +1,142 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeChunks(t *testing.T) {
+	chunk := func(score int, concerns ...RiskConcern) *StructuredAnalysis {
+		analysis := &StructuredAnalysis{Score: score, Summary: "chunk summary"}
+		analysis.RiskSummary.Concerns = concerns
+		return analysis
+	}
+
+	merged, err := MergeChunks([]*StructuredAnalysis{
+		chunk(85, RiskConcern{Severity: "low", Description: "Docs only"}),
+		chunk(55, RiskConcern{Severity: "critical", Description: "Drops users table in db/migrations/002.sql"}),
+		chunk(70, RiskConcern{Severity: "high", Description: "docs only"}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if merged.Score != 55 {
+		t.Errorf("Score = %d, want the lowest chunk score 55", merged.Score)
+	}
+
+	expected := []RiskConcern{
+		{Severity: "critical", Description: "Drops users table in db/migrations/002.sql"},
+		{Severity: "high", Description: "Docs only"},
+	}
+	if len(merged.RiskSummary.Concerns) != len(expected) {
+		t.Fatalf("concerns = %+v, want %+v", merged.RiskSummary.Concerns, expected)
+	}
+	for i, concern := range expected {
+		if merged.RiskSummary.Concerns[i] != concern {
+			t.Errorf("concern[%d] = %+v, want %+v", i, merged.RiskSummary.Concerns[i], concern)
+		}
+	}
+
+	t.Run("no chunks", func(t *testing.T) {
+		if _, err := MergeChunks(nil); err == nil {
+			t.Error("expected error for empty chunk list")
+		}
+	})
+}
+
+func TestGenerateReport_Chunking(t *testing.T) {
+	chunking := &ChunkingResult{
+		Chunks: []ChunkResult{
+			{
+				Label:    "org/repo: db/ (critical)",
+				Files:    []string{"org/repo/db/migrations/002.sql"},
+				Score:    55,
+				Concerns: []RiskConcern{{Severity: "critical", Description: "Drops users table"}},
+			},
+			{
+				Label: "org/repo: docs/ (low)",
+				Files: []string{"org/repo/docs/a.md", "org/repo/docs/b.md"},
+				Score: 95,
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		aggregated bool
+		want       []string
+		notWant    []string
+	}{
+		{
+			name:       "aggregated",
+			aggregated: true,
+			want: []string{
+				"Hierarchical Analysis",
+				"3 changed files were split into 2 chunks",
+				"| 1. org/repo: db/ (critical) | 1 | 55/100 |",
+				"| 2. org/repo: docs/ (low) | 2 | 95/100 |",
+				"- `org/repo/db/migrations/002.sql`",
+				"- [critical] Drops users table",
+				"final aggregation request",
+			},
+			notWant: []string{"aggregation request failed"},
+		},
+		{
+			name:       "merged locally",
+			aggregated: false,
+			want:       []string{"aggregation request failed", "lowest chunk score"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunking.Aggregated = tt.aggregated
+
+			_, report, err := GenerateReport(&ReportConfig{
+				Analysis:                &StructuredAnalysis{Score: 60, Summary: "Chunked"},
+				Chunking:                chunking,
+				Metadata:                &ReportMetadata{ModelID: "claude-test", GenerationTime: time.Now()},
+				AutoDeployThreshold:     80,
+				ReviewRequiredThreshold: 60,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(report, want) {
+					t.Errorf("report missing %q", want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(report, notWant) {
+					t.Errorf("report unexpectedly contains %q", notWant)
+				}
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		_, output, err := GenerateReport(&ReportConfig{
+			Analysis:                &StructuredAnalysis{Score: 60, Summary: "Chunked"},
+			Chunking:                chunking,
+			Format:                  FormatJSON,
+			AutoDeployThreshold:     80,
+			ReviewRequiredThreshold: 60,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var parsed JSONReport
+		if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+			t.Fatalf("report is not valid JSON: %v", err)
+		}
+		if parsed.Chunking == nil || len(parsed.Chunking.Chunks) != 2 || parsed.Chunking.Chunks[1].Files[1] != "org/repo/docs/b.md" {
+			t.Errorf("Chunking = %+v, want both chunks with their files", parsed.Chunking)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"release-confidence-score/internal/analysis/ci"
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/policy"
+)
+
+func TestGenerateReportCIStatus(t *testing.T) {
+	results := []ci.Result{
+		{Repo: "https://github.com/org/api", Ref: "abc123", URL: "https://github.com/org/api/commit/abc123/checks", State: ci.StateFailed, Checks: []types.CICheck{
+			{Name: "build", State: ci.StateSuccess},
+			{Name: "unit-tests", State: ci.StateFailed, URL: "https://github.com/org/api/runs/2"},
+			{Name: "e2e", State: ci.StateMissing, Required: true},
+		}},
+		{Repo: "https://gitlab.com/org/worker", Ref: "v2", State: ci.StateSuccess, Checks: []types.CICheck{{Name: "build", State: ci.StateSuccess}}},
+	}
+
+	_, report, err := GenerateReport(&ReportConfig{
+		Analysis:                &StructuredAnalysis{Score: 85, Summary: "CI"},
+		CI:                      results,
+		Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"🚦 CI Status",
+		"| https://github.com/org/api | [`abc123`](https://github.com/org/api/commit/abc123/checks) | ❌ failed | 3 | [unit-tests (failed)](https://github.com/org/api/runs/2), e2e (missing, required) |",
+		"| https://gitlab.com/org/worker | `v2` | ✅ success | 1 | - |",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("GenerateReport() report missing %q", want)
+		}
+	}
+
+	_, report, err = GenerateReport(&ReportConfig{
+		Analysis:                &StructuredAnalysis{Score: 85},
+		Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+	if strings.Contains(report, "CI Status") {
+		t.Error("expected no CI section without CI results")
+	}
+
+	_, output, err := GenerateReport(&ReportConfig{
+		Analysis:                &StructuredAnalysis{Score: 85},
+		CI:                      results,
+		Format:                  FormatJSON,
+		Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("GenerateReport() JSON error = %v", err)
+	}
+	var jsonReport JSONReport
+	if err := json.Unmarshal([]byte(output), &jsonReport); err != nil {
+		t.Fatalf("failed to parse JSON report: %v", err)
+	}
+	if len(jsonReport.CI) != 2 || jsonReport.CI[0].State != ci.StateFailed || len(jsonReport.CI[0].Checks) != 3 {
+		t.Errorf("CI = %+v, want the CI results", jsonReport.CI)
+	}
+}
+
+func TestGenerateReportRedCIPolicy(t *testing.T) {
+	set, err := policy.Parse([]byte(`policies:
+  - name: red-ci
+    when:
+      ci_status: failed
+    then:
+      max_decision: review_required
+`))
+	if err != nil {
+		t.Fatalf("failed to parse policies: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		state    string
+		expected string
+	}{
+		{"green CI", ci.StateSuccess, "✅ Recommended for release"},
+		{"red CI", ci.StateFailed, "⚠️ **MANUAL REVIEW REQUIRED**"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, report, err := GenerateReport(&ReportConfig{
+				Analysis:                &StructuredAnalysis{Score: 90, Summary: "Safe"},
+				CI:                      []ci.Result{{Repo: "https://github.com/org/api", Ref: "abc123", State: tt.state}},
+				Policies:                set,
+				Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+				AutoDeployThreshold:     80,
+				ReviewRequiredThreshold: 60,
+			})
+			if err != nil {
+				t.Fatalf("GenerateReport() error = %v", err)
+			}
+			if !strings.Contains(report, tt.expected) {
+				t.Errorf("expected report to contain %q", tt.expected)
+			}
+		})
+	}
+}
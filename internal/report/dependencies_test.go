@@ -0,0 +1,88 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"release-confidence-score/internal/git/types"
+)
+
+func TestGenerateReportDependencyTable(t *testing.T) {
+	records := []types.DependencyChange{
+		{Ecosystem: "npm", Package: "axios", File: "package.json", From: "^0.21.1", To: "^1.6.0", Change: "upgraded", Bump: "major"},
+		{Ecosystem: "go", Package: "golang.org/x/sync", File: "go.sum", To: "v0.8.0", Change: "added", Lockfile: true},
+	}
+
+	tests := []struct {
+		name         string
+		dependencies []types.DependencyChange
+		expected     []string
+		notExpected  []string
+	}{
+		{
+			name:         "parsed records replace the free-text notes",
+			dependencies: records,
+			expected: []string{
+				"### 🔗 Dependency Changes",
+				"| `axios` | npm | `^0.21.1` | `^1.6.0` | ⚠️ major upgraded | `package.json` |",
+				"| `golang.org/x/sync` | go | - | `v0.8.0` | added (lockfile) | `go.sum` |",
+			},
+			notExpected: []string{"- axios bumped to v1"},
+		},
+		{
+			name:        "free-text notes without parsed records",
+			expected:    []string{"### 🔗 Dependency Changes", "- axios bumped to v1"},
+			notExpected: []string{"| Package | Ecosystem |"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := &StructuredAnalysis{Score: 85, Summary: "Dependency bump"}
+			analysis.TechnicalDetails.Dependencies = []string{"axios bumped to v1"}
+
+			_, report, err := GenerateReport(&ReportConfig{
+				Analysis:                analysis,
+				Dependencies:            tt.dependencies,
+				Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+				AutoDeployThreshold:     80,
+				ReviewRequiredThreshold: 60,
+			})
+			if err != nil {
+				t.Fatalf("GenerateReport() error = %v", err)
+			}
+
+			for _, want := range tt.expected {
+				if !strings.Contains(report, want) {
+					t.Errorf("GenerateReport() report missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.notExpected {
+				if strings.Contains(report, unwanted) {
+					t.Errorf("GenerateReport() report should not contain %q", unwanted)
+				}
+			}
+		})
+	}
+
+	_, output, err := GenerateReport(&ReportConfig{
+		Analysis:                &StructuredAnalysis{Score: 85},
+		Dependencies:            records,
+		Format:                  FormatJSON,
+		Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("GenerateReport() JSON error = %v", err)
+	}
+	var jsonReport JSONReport
+	if err := json.Unmarshal([]byte(output), &jsonReport); err != nil {
+		t.Fatalf("failed to parse JSON report: %v", err)
+	}
+	if len(jsonReport.Dependencies) != 2 || jsonReport.Dependencies[0] != records[0] {
+		t.Errorf("Dependencies = %+v, want the parsed records", jsonReport.Dependencies)
+	}
+}
@@ -0,0 +1,289 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Ensemble aggregation methods
+const (
+	AggregationMedian = "median"
+	AggregationMin    = "min"
+)
+
+// severityRank orders concern severities from most to least severe
+var severityRank = map[string]int{
+	"critical": 0,
+	"high":     1,
+	"medium":   2,
+	"low":      3,
+}
+
+// ModelAnalysis is the parsed analysis produced by a single model
+type ModelAnalysis struct {
+	Model    string
+	Analysis *StructuredAnalysis
+}
+
+// EnsembleResult describes how an ensemble of models scored a release
+type EnsembleResult struct {
+	Aggregation           string            `json:"aggregation"`
+	DisagreementThreshold int               `json:"disagreement_threshold"`
+	Members               []EnsembleMember  `json:"members"`
+	Disagreements         []Disagreement    `json:"disagreements,omitempty"`
+	FailedModels          map[string]string `json:"failed_models,omitempty"` // Model -> error message for members that returned no analysis
+}
+
+// EnsembleMember is a single model's contribution to the ensemble
+type EnsembleMember struct {
+	Model          string        `json:"model"`
+	Score          int           `json:"score"`
+	UniqueConcerns []RiskConcern `json:"unique_concerns,omitempty"` // Critical/high concerns no other model raised
+}
+
+// Disagreement records a pair of models whose scores differ by more than the threshold
+type Disagreement struct {
+	ModelA     string `json:"model_a"`
+	ScoreA     int    `json:"score_a"`
+	ModelB     string `json:"model_b"`
+	ScoreB     int    `json:"score_b"`
+	Difference int    `json:"difference"`
+}
+
+// MaxDisagreement returns the largest score difference between any two members
+func (e *EnsembleResult) MaxDisagreement() int {
+	maxDiff := 0
+	for _, d := range e.Disagreements {
+		maxDiff = max(maxDiff, d.Difference)
+	}
+	return maxDiff
+}
+
+// MergeEnsemble combines the analyses of several models into one
+// The score is aggregated with the given method, concerns, positives, action items and
+// technical details are the deduplicated union, and prose fields come from the model
+// whose score is closest to the aggregated score
+func MergeEnsemble(results []ModelAnalysis, aggregation string, disagreementThreshold int) (*StructuredAnalysis, *EnsembleResult, error) {
+	if len(results) == 0 {
+		return nil, nil, fmt.Errorf("no model analyses to merge")
+	}
+
+	scores := make([]int, len(results))
+	analyses := make([]*StructuredAnalysis, len(results))
+	for i, result := range results {
+		scores[i] = result.Analysis.Score
+		analyses[i] = result.Analysis
+	}
+
+	var score int
+	switch aggregation {
+	case AggregationMin:
+		score = slices.Min(scores)
+	default:
+		score = medianScore(scores)
+	}
+
+	merged := mergeAnalyses(analyses, score, 1)
+
+	ensemble := &EnsembleResult{
+		Aggregation:           aggregation,
+		DisagreementThreshold: disagreementThreshold,
+		Members:               make([]EnsembleMember, len(results)),
+	}
+
+	for i, result := range results {
+		ensemble.Members[i] = EnsembleMember{
+			Model:          result.Model,
+			Score:          result.Analysis.Score,
+			UniqueConcerns: uniqueSevereConcerns(i, analyses),
+		}
+
+		for j := i + 1; j < len(results); j++ {
+			diff := abs(result.Analysis.Score - results[j].Analysis.Score)
+			if diff > disagreementThreshold {
+				ensemble.Disagreements = append(ensemble.Disagreements, Disagreement{
+					ModelA:     result.Model,
+					ScoreA:     result.Analysis.Score,
+					ModelB:     results[j].Model,
+					ScoreB:     results[j].Analysis.Score,
+					Difference: diff,
+				})
+			}
+		}
+	}
+
+	return merged, ensemble, nil
+}
+
+// mergeAnalyses merges analyses into one with the given score
+// Concerns must appear in at least minOccurrences analyses to be kept; list fields are deduplicated
+func mergeAnalyses(analyses []*StructuredAnalysis, score, minOccurrences int) *StructuredAnalysis {
+	representative := analyses[closestToScore(analyses, score)]
+
+	merged := &StructuredAnalysis{
+		Score:                        score,
+		Summary:                      representative.Summary,
+		DocumentationQuality:         representative.DocumentationQuality,
+		DocumentationRecommendations: representative.DocumentationRecommendations,
+	}
+
+	var concerns [][]RiskConcern
+	var positives, critical, important, followup, code, infrastructure, dependencies [][]string
+	for _, a := range analyses {
+		concerns = append(concerns, a.RiskSummary.Concerns)
+		positives = append(positives, a.RiskSummary.Positives)
+		critical = append(critical, a.ActionItems.Critical)
+		important = append(important, a.ActionItems.Important)
+		followup = append(followup, a.ActionItems.Followup)
+		code = append(code, a.TechnicalDetails.Code)
+		infrastructure = append(infrastructure, a.TechnicalDetails.Infrastructure)
+		dependencies = append(dependencies, a.TechnicalDetails.Dependencies)
+	}
+
+	merged.RiskSummary.Concerns = mergeConcerns(concerns, minOccurrences)
+	merged.RiskSummary.Positives = unionStrings(positives)
+	merged.ActionItems.Critical = unionStrings(critical)
+	merged.ActionItems.Important = unionStrings(important)
+	merged.ActionItems.Followup = unionStrings(followup)
+	merged.TechnicalDetails.Code = unionStrings(code)
+	merged.TechnicalDetails.Infrastructure = unionStrings(infrastructure)
+	merged.TechnicalDetails.Dependencies = unionStrings(dependencies)
+
+	return merged
+}
+
+// mergeConcerns deduplicates concerns by normalized description, keeping the highest severity
+// Concerns seen in fewer than minOccurrences lists are dropped; the result is ordered by severity
+func mergeConcerns(lists [][]RiskConcern, minOccurrences int) []RiskConcern {
+	type entry struct {
+		concern     RiskConcern
+		occurrences int
+	}
+
+	var order []string
+	entries := make(map[string]*entry)
+
+	for _, list := range lists {
+		seenInList := make(map[string]bool)
+		for _, concern := range list {
+			key := normalizeText(concern.Description)
+			if key == "" {
+				continue
+			}
+
+			existing, ok := entries[key]
+			if !ok {
+				existing = &entry{concern: concern}
+				entries[key] = existing
+				order = append(order, key)
+			} else if severityOrder(concern.Severity) < severityOrder(existing.concern.Severity) {
+				existing.concern.Severity = concern.Severity
+			}
+
+			if !seenInList[key] {
+				existing.occurrences++
+				seenInList[key] = true
+			}
+		}
+	}
+
+	var merged []RiskConcern
+	for _, key := range order {
+		if entries[key].occurrences >= minOccurrences {
+			merged = append(merged, entries[key].concern)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return severityOrder(merged[i].Severity) < severityOrder(merged[j].Severity)
+	})
+
+	return merged
+}
+
+// uniqueSevereConcerns returns the critical and high concerns of analyses[index] that no other analysis raised
+func uniqueSevereConcerns(index int, analyses []*StructuredAnalysis) []RiskConcern {
+	others := make(map[string]bool)
+	for i, a := range analyses {
+		if i == index {
+			continue
+		}
+		for _, concern := range a.RiskSummary.Concerns {
+			others[normalizeText(concern.Description)] = true
+		}
+	}
+
+	var unique []RiskConcern
+	for _, concern := range analyses[index].RiskSummary.Concerns {
+		if severityOrder(concern.Severity) > severityRank["high"] {
+			continue
+		}
+		if !others[normalizeText(concern.Description)] {
+			unique = append(unique, concern)
+		}
+	}
+	return unique
+}
+
+// unionStrings returns the deduplicated union of the lists, preserving first-seen order
+func unionStrings(lists [][]string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, item := range list {
+			key := normalizeText(item)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// normalizeText lowercases text, collapses whitespace and trims trailing punctuation for deduplication
+func normalizeText(text string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	return strings.TrimRight(normalized, ".!;: ")
+}
+
+// severityOrder returns the sort position of a severity; unknown severities sort last
+func severityOrder(severity string) int {
+	if rank, ok := severityRank[strings.ToLower(severity)]; ok {
+		return rank
+	}
+	return len(severityRank)
+}
+
+// medianScore returns the median of the scores, rounding down for even counts
+func medianScore(scores []int) int {
+	sorted := slices.Clone(scores)
+	slices.Sort(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// closestToScore returns the index of the analysis whose score is closest to score
+func closestToScore(analyses []*StructuredAnalysis, score int) int {
+	best := 0
+	for i, a := range analyses {
+		if abs(a.Score-score) < abs(analyses[best].Score-score) {
+			best = i
+		}
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
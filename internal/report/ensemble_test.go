@@ -0,0 +1,200 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMedianScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		scores   []int
+		expected int
+	}{
+		{"single score", []int{70}, 70},
+		{"odd count", []int{90, 60, 75}, 75},
+		{"even count rounds down", []int{60, 75}, 67},
+		{"unsorted even count", []int{90, 40, 80, 50}, 65},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := medianScore(tt.scores)
+			if result != tt.expected {
+				t.Errorf("medianScore(%v) = %d, want %d", tt.scores, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeConcerns(t *testing.T) {
+	lists := [][]RiskConcern{
+		{
+			{Severity: "medium", Description: "Migration adds column"},
+			{Severity: "low", Description: "Docs outdated"},
+		},
+		{
+			{Severity: "critical", Description: "migration adds   column."},
+			{Severity: "high", Description: "Auth middleware changed"},
+		},
+	}
+
+	t.Run("union keeps highest severity", func(t *testing.T) {
+		merged := mergeConcerns(lists, 1)
+		if len(merged) != 3 {
+			t.Fatalf("mergeConcerns() returned %d concerns, want 3", len(merged))
+		}
+		if merged[0].Severity != "critical" || merged[0].Description != "Migration adds column" {
+			t.Errorf("merged[0] = %+v, want critical 'Migration adds column'", merged[0])
+		}
+		if merged[1].Severity != "high" {
+			t.Errorf("merged[1].Severity = %q, want %q", merged[1].Severity, "high")
+		}
+		if merged[2].Severity != "low" {
+			t.Errorf("merged[2].Severity = %q, want %q", merged[2].Severity, "low")
+		}
+	})
+
+	t.Run("minimum occurrences filters rare concerns", func(t *testing.T) {
+		merged := mergeConcerns(lists, 2)
+		if len(merged) != 1 {
+			t.Fatalf("mergeConcerns() returned %d concerns, want 1", len(merged))
+		}
+		if merged[0].Description != "Migration adds column" {
+			t.Errorf("merged[0].Description = %q, want %q", merged[0].Description, "Migration adds column")
+		}
+	})
+}
+
+func TestMergeEnsemble(t *testing.T) {
+	results := []ModelAnalysis{
+		{Model: "claude", Analysis: &StructuredAnalysis{
+			Score:   85,
+			Summary: "Claude summary",
+			RiskSummary: RiskSummary{
+				Concerns:  []RiskConcern{{Severity: "medium", Description: "Shared concern"}},
+				Positives: []string{"Well tested"},
+			},
+			ActionItems: ActionItems{Critical: []string{"Run migration first"}},
+		}},
+		{Model: "gemini", Analysis: &StructuredAnalysis{
+			Score:   60,
+			Summary: "Gemini summary",
+			RiskSummary: RiskSummary{
+				Concerns: []RiskConcern{
+					{Severity: "medium", Description: "Shared concern"},
+					{Severity: "critical", Description: "Token validation bypass"},
+				},
+				Positives: []string{"well tested"},
+			},
+			ActionItems: ActionItems{Critical: []string{"Run migration first", "Rotate keys"}},
+		}},
+	}
+
+	t.Run("median aggregation", func(t *testing.T) {
+		merged, ensemble, err := MergeEnsemble(results, AggregationMedian, 15)
+		if err != nil {
+			t.Fatalf("MergeEnsemble() unexpected error: %v", err)
+		}
+		if merged.Score != 72 {
+			t.Errorf("Score = %d, want %d", merged.Score, 72)
+		}
+		if len(merged.RiskSummary.Concerns) != 2 {
+			t.Errorf("Concerns = %d, want 2", len(merged.RiskSummary.Concerns))
+		}
+		if len(merged.RiskSummary.Positives) != 1 {
+			t.Errorf("Positives = %d, want 1", len(merged.RiskSummary.Positives))
+		}
+		if len(merged.ActionItems.Critical) != 2 {
+			t.Errorf("Critical action items = %d, want 2", len(merged.ActionItems.Critical))
+		}
+		if len(ensemble.Disagreements) != 1 {
+			t.Fatalf("Disagreements = %d, want 1", len(ensemble.Disagreements))
+		}
+		if ensemble.Disagreements[0].Difference != 25 {
+			t.Errorf("Difference = %d, want %d", ensemble.Disagreements[0].Difference, 25)
+		}
+		if ensemble.MaxDisagreement() != 25 {
+			t.Errorf("MaxDisagreement() = %d, want %d", ensemble.MaxDisagreement(), 25)
+		}
+		if len(ensemble.Members[1].UniqueConcerns) != 1 {
+			t.Errorf("gemini UniqueConcerns = %d, want 1", len(ensemble.Members[1].UniqueConcerns))
+		}
+		if len(ensemble.Members[0].UniqueConcerns) != 0 {
+			t.Errorf("claude UniqueConcerns = %d, want 0", len(ensemble.Members[0].UniqueConcerns))
+		}
+	})
+
+	t.Run("min aggregation uses representative prose", func(t *testing.T) {
+		merged, _, err := MergeEnsemble(results, AggregationMin, 15)
+		if err != nil {
+			t.Fatalf("MergeEnsemble() unexpected error: %v", err)
+		}
+		if merged.Score != 60 {
+			t.Errorf("Score = %d, want %d", merged.Score, 60)
+		}
+		if merged.Summary != "Gemini summary" {
+			t.Errorf("Summary = %q, want %q", merged.Summary, "Gemini summary")
+		}
+	})
+
+	t.Run("no disagreement under threshold", func(t *testing.T) {
+		_, ensemble, err := MergeEnsemble(results, AggregationMedian, 30)
+		if err != nil {
+			t.Fatalf("MergeEnsemble() unexpected error: %v", err)
+		}
+		if len(ensemble.Disagreements) != 0 {
+			t.Errorf("Disagreements = %d, want 0", len(ensemble.Disagreements))
+		}
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		_, _, err := MergeEnsemble(nil, AggregationMedian, 15)
+		if err == nil {
+			t.Fatal("MergeEnsemble() expected error for empty input, got nil")
+		}
+	})
+}
+
+func TestGenerateReport_Ensemble(t *testing.T) {
+	config := &ReportConfig{
+		Analysis: &StructuredAnalysis{Score: 72, Summary: "Merged summary"},
+		Ensemble: &EnsembleResult{
+			Aggregation:           AggregationMedian,
+			DisagreementThreshold: 15,
+			Members: []EnsembleMember{
+				{Model: "claude-sonnet-4", Score: 85},
+				{Model: "gemini-2.5-pro", Score: 60, UniqueConcerns: []RiskConcern{{Severity: "critical", Description: "Token validation bypass"}}},
+			},
+			Disagreements: []Disagreement{
+				{ModelA: "claude-sonnet-4", ScoreA: 85, ModelB: "gemini-2.5-pro", ScoreB: 60, Difference: 25},
+			},
+		},
+		Metadata:                &ReportMetadata{ModelID: "claude-sonnet-4, gemini-2.5-pro"},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	}
+
+	score, report, err := GenerateReport(config)
+	if err != nil {
+		t.Fatalf("GenerateReport() unexpected error: %v", err)
+	}
+	if score != 72 {
+		t.Errorf("score = %d, want %d", score, 72)
+	}
+
+	expected := []string{
+		"Models Disagree",
+		"differed by up to 25 points",
+		"Ensemble Scoring",
+		"| gemini-2.5-pro | 60/100 |",
+		"25 points apart",
+		"raised only by gemini-2.5-pro",
+		"Token validation bypass",
+	}
+	for _, want := range expected {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"release-confidence-score/internal/git/types"
+)
+
+func TestGenerateReportInfrastructureTable(t *testing.T) {
+	changes := []types.InfrastructureChange{
+		{File: "deploy/api.yaml", Resource: "Deployment/api", Field: "container api memory limit", From: "2Gi", To: "1Gi", Change: "changed"},
+		{File: "deploy/api.yaml", Resource: "Deployment/api", Field: "container api readinessProbe", Change: "removed"},
+		{File: "charts/api/values.yaml", Field: "replicaCount", From: "3", To: "1", Change: "changed"},
+	}
+
+	tests := []struct {
+		name           string
+		infrastructure []types.InfrastructureChange
+		notes          []string
+		expected       []string
+		notExpected    []string
+	}{
+		{
+			name:           "parsed changes above the model's notes",
+			infrastructure: changes,
+			notes:          []string{"Memory limit halved"},
+			expected: []string{
+				"### 🏗️ Infrastructure Changes",
+				"| `Deployment/api` | container api memory limit 2Gi→1Gi | `deploy/api.yaml` |",
+				"| `Deployment/api` | container api readinessProbe removed | `deploy/api.yaml` |",
+				"| - | replicaCount 3→1 | `charts/api/values.yaml` |\n\n- Memory limit halved",
+			},
+		},
+		{
+			name:           "parsed changes without notes",
+			infrastructure: changes,
+			expected:       []string{"### 🏗️ Infrastructure Changes", "| Resource | Change | File |"},
+		},
+		{
+			name:        "notes without parsed changes",
+			notes:       []string{"Memory limit halved"},
+			expected:    []string{"### 🏗️ Infrastructure Changes\n- Memory limit halved"},
+			notExpected: []string{"| Resource | Change | File |"},
+		},
+		{
+			name:        "neither",
+			notExpected: []string{"Infrastructure Changes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := &StructuredAnalysis{Score: 85, Summary: "Resource changes"}
+			analysis.TechnicalDetails.Infrastructure = tt.notes
+
+			_, report, err := GenerateReport(&ReportConfig{
+				Analysis:                analysis,
+				Infrastructure:          tt.infrastructure,
+				Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+				AutoDeployThreshold:     80,
+				ReviewRequiredThreshold: 60,
+			})
+			if err != nil {
+				t.Fatalf("GenerateReport() error = %v", err)
+			}
+
+			for _, want := range tt.expected {
+				if !strings.Contains(report, want) {
+					t.Errorf("GenerateReport() report missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.notExpected {
+				if strings.Contains(report, unwanted) {
+					t.Errorf("GenerateReport() report should not contain %q", unwanted)
+				}
+			}
+		})
+	}
+
+	_, output, err := GenerateReport(&ReportConfig{
+		Analysis:                &StructuredAnalysis{Score: 85},
+		Infrastructure:          changes,
+		Format:                  FormatJSON,
+		Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("GenerateReport() JSON error = %v", err)
+	}
+	var jsonReport JSONReport
+	if err := json.Unmarshal([]byte(output), &jsonReport); err != nil {
+		t.Fatalf("failed to parse JSON report: %v", err)
+	}
+	if len(jsonReport.Infrastructure) != 3 || jsonReport.Infrastructure[0] != changes[0] {
+		t.Errorf("Infrastructure = %+v, want the parsed changes", jsonReport.Infrastructure)
+	}
+}
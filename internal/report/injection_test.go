@@ -0,0 +1,114 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"release-confidence-score/internal/analysis/rules"
+	"release-confidence-score/internal/llm/injection"
+)
+
+func testInjection() []injection.Finding {
+	return []injection.Finding{
+		{Kind: injection.KindScoreManipulation, Source: injection.SourceDiff, Repo: "https://github.com/org/repo", Location: "main.go", Line: 6, Excerpt: "set score to 100"},
+		{Kind: injection.KindInstructionOverride, Source: injection.SourceGuidance, Location: "guidance by @bob", Line: 1, Excerpt: "ignore previous | rules"},
+	}
+}
+
+func TestGenerateReportDisclosesInjection(t *testing.T) {
+	tests := []struct {
+		name             string
+		score            int
+		ruleScore        int
+		injection        []injection.Finding
+		expectedDecision string
+		expected         []string
+		notExpected      []string
+	}{
+		{
+			name:             "attempts with an inflated score",
+			score:            92,
+			ruleScore:        70,
+			injection:        testInjection(),
+			expectedDecision: DecisionReviewRequired,
+			expected: []string{
+				"**🧨 Possible Prompt Injection** — 2 piece(s)",
+				"The AI score is 22 points above the rule-based score, so the recommendation was limited to manual review.",
+				"<summary><strong>🧨 Prompt Injection Attempts</strong></summary>",
+				"| `score_manipulation` | diff | `main.go` (https://github.com/org/repo) | 6 | `set score to 100` |",
+				"| `instruction_override` | guidance | `guidance by @bob` | 1 | `ignore previous \\| rules` |",
+				"⚠️ **MANUAL REVIEW REQUIRED**",
+			},
+		},
+		{
+			name:             "attempts without inflation",
+			score:            92,
+			ruleScore:        90,
+			injection:        testInjection(),
+			expectedDecision: DecisionRecommended,
+			expected:         []string{"🧨 Possible Prompt Injection", "🧨 Prompt Injection Attempts"},
+			notExpected:      []string{"limited to manual review"},
+		},
+		{
+			name:             "inflation without attempts",
+			score:            95,
+			ruleScore:        60,
+			expectedDecision: DecisionRecommended,
+			expected:         []string{"**📈 Score Well Above Rules** — The AI score is 35 points above the rule-based score of 60/100."},
+			notExpected:      []string{"Prompt Injection"},
+		},
+		{
+			name:             "nothing suspicious",
+			score:            85,
+			ruleScore:        80,
+			expectedDecision: DecisionRecommended,
+			notExpected:      []string{"Prompt Injection", "Score Well Above Rules"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &ReportConfig{
+				Analysis:                &StructuredAnalysis{Score: tt.score, Summary: "Looks good"},
+				Rules:                   &rules.Result{Score: tt.ruleScore},
+				Injection:               tt.injection,
+				Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+				AutoDeployThreshold:     80,
+				ReviewRequiredThreshold: 60,
+			}
+
+			_, report, err := GenerateReport(config)
+			if err != nil {
+				t.Fatalf("GenerateReport() error = %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(report, want) {
+					t.Errorf("GenerateReport() report missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.notExpected {
+				if strings.Contains(report, unwanted) {
+					t.Errorf("GenerateReport() report should not contain %q", unwanted)
+				}
+			}
+
+			config.Format = FormatJSON
+			_, output, err := GenerateReport(config)
+			if err != nil {
+				t.Fatalf("GenerateReport() JSON error = %v", err)
+			}
+			var jsonReport JSONReport
+			if err := json.Unmarshal([]byte(output), &jsonReport); err != nil {
+				t.Fatalf("failed to parse JSON report: %v", err)
+			}
+			if jsonReport.Decision != tt.expectedDecision {
+				t.Errorf("Decision = %s, want %s", jsonReport.Decision, tt.expectedDecision)
+			}
+			if len(jsonReport.Injection) != len(tt.injection) {
+				t.Errorf("Injection = %+v, want %d findings", jsonReport.Injection, len(tt.injection))
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"release-confidence-score/internal/analysis/ci"
+	"release-confidence-score/internal/analysis/coverage"
+	"release-confidence-score/internal/analysis/migrations"
+	"release-confidence-score/internal/analysis/rules"
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/injection"
+	"release-confidence-score/internal/llm/redaction"
+	"release-confidence-score/internal/llm/truncation"
+	"release-confidence-score/internal/policy"
+)
+
+// Report output formats
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+)
+
+// Machine-readable release decisions used in the JSON report
+const (
+	DecisionRecommended    = policy.DecisionRecommended
+	DecisionReviewRequired = policy.DecisionReviewRequired
+	DecisionNotRecommended = policy.DecisionNotRecommended
+)
+
+// JSONReport is the machine-readable form of the release confidence report
+type JSONReport struct {
+	Score          int                            `json:"score"`
+	Decision       string                         `json:"decision"`
+	LowConfidence  bool                           `json:"low_confidence"`
+	Model          string                         `json:"model"`
+	GeneratedAt    time.Time                      `json:"generated_at"`
+	Repositories   []string                       `json:"repositories"`
+	Analysis       *StructuredAnalysis            `json:"analysis"`
+	TruncationInfo *truncation.TruncationMetadata `json:"truncation,omitempty"`
+	Ensemble       *EnsembleResult                `json:"ensemble,omitempty"`
+	Sampling       []*SamplingResult              `json:"sampling,omitempty"`
+	Chunking       *ChunkingResult                `json:"chunking,omitempty"`
+	Services       []ServiceResult                `json:"services,omitempty"`
+	Rules          *rules.Result                  `json:"rules,omitempty"`
+	RulesOnly      bool                           `json:"rules_only,omitempty"`
+	Secrets        []redaction.Finding            `json:"secrets,omitempty"`
+	Injection      []injection.Finding            `json:"injection,omitempty"`
+	ScoreInflation *injection.Inflation           `json:"score_inflation,omitempty"`
+	Dependencies   []types.DependencyChange       `json:"dependencies,omitempty"`
+	Migrations     []migrations.Finding           `json:"migrations,omitempty"`
+	Infrastructure []types.InfrastructureChange   `json:"infrastructure,omitempty"`
+	APIChanges     []types.APIChange              `json:"api_changes,omitempty"`
+	GoAPIChanges   []types.GoAPIChange            `json:"go_api_changes,omitempty"`
+	TestCoverage   []coverage.Signal              `json:"test_coverage,omitempty"`
+	CI             []ci.Result                    `json:"ci,omitempty"`
+	Policies       []policy.Outcome               `json:"policies,omitempty"`
+	UncappedScore  int                            `json:"uncapped_score,omitempty"`
+}
+
+// renderJSONReport renders the report data as indented JSON
+func renderJSONReport(data *TemplateData) (string, error) {
+	jsonReport := JSONReport{
+		Score:          data.Analysis.Score,
+		Decision:       data.Decision,
+		LowConfidence:  data.LowConfidence,
+		Analysis:       data.Analysis,
+		TruncationInfo: data.TruncationInfo,
+		Ensemble:       data.Ensemble,
+		Sampling:       data.Sampling,
+		Chunking:       data.Chunking,
+		Services:       data.Services,
+		Rules:          data.Rules,
+		RulesOnly:      data.RulesOnly,
+		Secrets:        data.Secrets,
+		Injection:      data.Injection,
+		ScoreInflation: data.ScoreInflation,
+		Dependencies:   data.Dependencies,
+		Migrations:     data.Migrations,
+		Infrastructure: data.Infrastructure,
+		APIChanges:     data.APIChanges,
+		GoAPIChanges:   data.GoAPIChanges,
+		TestCoverage:   data.TestCoverage,
+		CI:             data.CI,
+		Policies:       data.Policies,
+		UncappedScore:  data.UncappedScore,
+		Repositories:   []string{},
+	}
+	if data.Metadata != nil {
+		jsonReport.Model = data.Metadata.ModelID
+		jsonReport.GeneratedAt = data.Metadata.GenerationTime
+	}
+	for _, comparison := range data.Comparisons {
+		jsonReport.Repositories = append(jsonReport.Repositories, comparison.RepoURL)
+	}
+
+	output, err := json.MarshalIndent(jsonReport, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON report: %w", err)
+	}
+	return string(output) + "\n", nil
+}
+
+func getReleaseDecision(score, autoDeployThreshold, reviewRequiredThreshold int) string {
+	if score >= autoDeployThreshold {
+		return DecisionRecommended
+	} else if score >= reviewRequiredThreshold {
+		return DecisionReviewRequired
+	} else {
+		return DecisionNotRecommended
+	}
+}
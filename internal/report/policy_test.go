@@ -0,0 +1,169 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/policy"
+)
+
+func testPolicies(t *testing.T) *policy.Set {
+	t.Helper()
+
+	set, err := policy.Parse([]byte(`policies:
+  - name: untested-commits
+    description: Commits waiting for QE testing need a manual review
+    when:
+      commit_label: rcs/needs-qe-testing
+    then:
+      max_decision: review_required
+      max_score: 60
+  - name: critical-concerns
+    when:
+      concern_severity: critical
+    then:
+      max_decision: not_recommended
+  - name: schema-changes
+    when:
+      files: ["db/migrations/**"]
+    then:
+      max_score: 50
+`))
+	if err != nil {
+		t.Fatalf("failed to parse policies: %v", err)
+	}
+	return set
+}
+
+func TestGenerateReportAppliesPolicies(t *testing.T) {
+	untested := []*types.Comparison{{
+		RepoURL: "https://github.com/org/repo",
+		Commits: []types.Commit{{SHA: "abc1234567", ShortSHA: "abc1234", QETestingLabel: "rcs/needs-qe-testing"}},
+	}}
+
+	tests := []struct {
+		name          string
+		analysis      *StructuredAnalysis
+		comparisons   []*types.Comparison
+		expectedScore int
+		expected      []string
+		notExpected   []string
+	}{
+		{
+			name:          "no policy fires",
+			analysis:      &StructuredAnalysis{Score: 90, Summary: "Safe"},
+			expectedScore: 90,
+			expected:      []string{"✅ Recommended for release"},
+			notExpected:   []string{"Policies Applied", "capped by policy"},
+		},
+		{
+			name:          "score and decision capped",
+			analysis:      &StructuredAnalysis{Score: 90, Summary: "Safe"},
+			comparisons:   untested,
+			expectedScore: 60,
+			expected: []string{
+				"**Confidence score:** 60/100 (capped by policy from 90/100)",
+				"⚠️ **MANUAL REVIEW REQUIRED**",
+				"**🛡️ Policies Applied:**",
+				"- **untested-commits** (Commits waiting for QE testing need a manual review): commits labeled rcs/needs-qe-testing: abc1234 → decision at most `review_required` → score at most 60",
+			},
+		},
+		{
+			name:     "score capped below the review threshold",
+			analysis: &StructuredAnalysis{Score: 90, Summary: "Safe"},
+			comparisons: []*types.Comparison{{
+				RepoURL: "https://github.com/org/repo",
+				Files:   []types.FileChange{{Filename: "db/migrations/001_add_index.sql"}},
+			}},
+			expectedScore: 50,
+			expected: []string{
+				"**Confidence score:** 50/100 (capped by policy from 90/100)",
+				"🚫 **RELEASE NOT RECOMMENDED**",
+				"- **schema-changes**: matching files changed: db/migrations/001_add_index.sql → score at most 50",
+			},
+			notExpected: []string{"✅ Recommended for release"},
+		},
+		{
+			name: "decision forced without changing the score",
+			analysis: &StructuredAnalysis{Score: 85, Summary: "Mostly safe", RiskSummary: RiskSummary{
+				Concerns: []RiskConcern{{Severity: "critical", Description: "Irreversible data migration"}},
+			}},
+			expectedScore: 85,
+			expected: []string{
+				"**Confidence score:** 85/100\n",
+				"🚫 **RELEASE NOT RECOMMENDED**",
+				"- **critical-concerns**: concerns at critical severity or above: [critical] Irreversible data migration → decision at most `not_recommended`",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalScore := tt.analysis.Score
+
+			score, report, err := GenerateReport(&ReportConfig{
+				Analysis:                tt.analysis,
+				Comparisons:             tt.comparisons,
+				Policies:                testPolicies(t),
+				Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+				AutoDeployThreshold:     80,
+				ReviewRequiredThreshold: 60,
+			})
+			if err != nil {
+				t.Fatalf("GenerateReport() error = %v", err)
+			}
+
+			if score != tt.expectedScore {
+				t.Errorf("GenerateReport() score = %d, want %d", score, tt.expectedScore)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(report, want) {
+					t.Errorf("GenerateReport() report missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.notExpected {
+				if strings.Contains(report, unwanted) {
+					t.Errorf("GenerateReport() report should not contain %q", unwanted)
+				}
+			}
+			if tt.analysis.Score != originalScore {
+				t.Error("GenerateReport() modified the caller's analysis")
+			}
+		})
+	}
+}
+
+func TestGenerateReportJSONIncludesPolicies(t *testing.T) {
+	_, output, err := GenerateReport(&ReportConfig{
+		Analysis: &StructuredAnalysis{Score: 90, Summary: "Safe"},
+		Comparisons: []*types.Comparison{{
+			RepoURL: "https://github.com/org/repo",
+			Commits: []types.Commit{{ShortSHA: "abc1234", QETestingLabel: "rcs/needs-qe-testing"}},
+		}},
+		Policies:                testPolicies(t),
+		Format:                  FormatJSON,
+		Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+
+	var jsonReport JSONReport
+	if err := json.Unmarshal([]byte(output), &jsonReport); err != nil {
+		t.Fatalf("failed to parse JSON report: %v", err)
+	}
+	if jsonReport.Score != 60 || jsonReport.UncappedScore != 90 {
+		t.Errorf("Score = %d, UncappedScore = %d, want 60 and 90", jsonReport.Score, jsonReport.UncappedScore)
+	}
+	if jsonReport.Decision != DecisionReviewRequired {
+		t.Errorf("Decision = %s, want %s", jsonReport.Decision, DecisionReviewRequired)
+	}
+	if len(jsonReport.Policies) != 1 || jsonReport.Policies[0].Policy != "untested-commits" {
+		t.Errorf("Policies = %+v, want the untested-commits outcome", jsonReport.Policies)
+	}
+}
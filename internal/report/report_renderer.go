@@ -5,14 +5,27 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
+	"release-confidence-score/internal/analysis/ci"
+	"release-confidence-score/internal/analysis/coverage"
+	"release-confidence-score/internal/analysis/dependencies"
+	"release-confidence-score/internal/analysis/goapi"
+	"release-confidence-score/internal/analysis/infrastructure"
+	"release-confidence-score/internal/analysis/migrations"
+	"release-confidence-score/internal/analysis/rules"
 	"release-confidence-score/internal/git/shared"
 	"release-confidence-score/internal/git/types"
+	"release-confidence-score/internal/llm/injection"
+	"release-confidence-score/internal/llm/redaction"
 	"release-confidence-score/internal/llm/truncation"
+	"release-confidence-score/internal/policy"
 )
 
 //go:embed report_template.md
@@ -29,17 +42,25 @@ func init() {
 // templateFuncs returns all custom template functions
 func templateFuncs() template.FuncMap {
 	return template.FuncMap{
-		"hasPrefix":           strings.HasPrefix,
-		"contains":            strings.Contains,
-		"escapePipes":         escapePipes,
-		"qeStatus":            qeStatus,
-		"authorizationStatus": authorizationStatus,
-		"prLink":              prLink,
-		"formatAuthor":        formatAuthor,
-		"docURL":              docURL,
-		"commitLink":          commitLink,
-		"formatDate":          formatDate,
-		"docFileInfo":         docFileInfo,
+		"hasPrefix":            strings.HasPrefix,
+		"contains":             strings.Contains,
+		"join":                 strings.Join,
+		"escapePipes":          escapePipes,
+		"qeStatus":             qeStatus,
+		"authorizationStatus":  authorizationStatus,
+		"prLink":               prLink,
+		"formatAuthor":         formatAuthor,
+		"docURL":               docURL,
+		"commitLink":           commitLink,
+		"formatDate":           formatDate,
+		"docFileInfo":          docFileInfo,
+		"joinScores":           joinScores,
+		"add":                  add,
+		"dependencyChange":     dependencyChange,
+		"infrastructureChange": infrastructure.Describe,
+		"goSignature":          goapi.Signature,
+		"coverageRatio":        coverage.FormatRatio,
+		"ciProblems":           ciProblems,
 	}
 }
 
@@ -67,25 +88,36 @@ func authorizationStatus(isAuthorized bool) string {
 	return "❌ Unauthorized"
 }
 
-func prLink(prNumber int64, repoURL string) string {
+func prLink(prNumber int64, repoURL string, githubHosts []string) string {
 	if prNumber <= 0 {
 		return "N/A"
 	}
 
 	// GitLab uses /-/merge_requests/, GitHub uses /pull/
-	if strings.Contains(repoURL, "github.com") {
+	if isGitHubURL(repoURL, githubHosts) {
 		return fmt.Sprintf("[#%d](%s/pull/%d)", prNumber, repoURL, prNumber)
 	}
 	return fmt.Sprintf("[!%d](%s/-/merge_requests/%d)", prNumber, repoURL, prNumber)
 }
 
-func formatAuthor(author, commentURL string) string {
-	if strings.Contains(commentURL, "github.com") {
-		return fmt.Sprintf("[@%s](https://github.com/%s)", author, author)
+func formatAuthor(author, commentURL string, githubHosts []string) string {
+	if isGitHubURL(commentURL, githubHosts) {
+		parsed, _ := url.Parse(commentURL)
+		return fmt.Sprintf("[@%s](%s://%s/%s)", author, parsed.Scheme, parsed.Host, author)
 	}
 	return "@" + author
 }
 
+// isGitHubURL reports whether rawURL is on github.com or one of the GitHub Enterprise Server hosts
+func isGitHubURL(rawURL string, githubHosts []string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	return host == "github.com" || slices.Contains(githubHosts, host)
+}
+
 func docURL(filename, repoURL, branch string) string {
 	if strings.HasPrefix(filename, "http") {
 		return filename
@@ -106,6 +138,49 @@ func docFileInfo(filename, repoURL, branch, content string) string {
 	return fmt.Sprintf("- %s - %d chars", url, len(content))
 }
 
+func joinScores(scores []int) string {
+	parts := make([]string, len(scores))
+	for i, score := range scores {
+		parts[i] = strconv.Itoa(score)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func add(a, b int) int {
+	return a + b
+}
+
+// dependencyChange describes a dependency change for the report table, highlighting major version bumps
+func dependencyChange(change types.DependencyChange) string {
+	description := change.Change
+	if change.Bump != "" {
+		description = change.Bump + " " + change.Change
+	}
+	if change.Bump == dependencies.BumpMajor {
+		description = "⚠️ " + description
+	}
+	if change.Lockfile {
+		description += " (lockfile)"
+	}
+	return description
+}
+
+// ciProblems lists the failed, skipped and missing checks of a head ref, linking each to its run
+func ciProblems(result ci.Result) string {
+	var problems []string
+	for _, check := range ci.Problems(result) {
+		description := escapePipes(ci.Describe(check))
+		if check.URL != "" {
+			description = fmt.Sprintf("[%s](%s)", description, check.URL)
+		}
+		problems = append(problems, description)
+	}
+	if len(problems) == 0 {
+		return "-"
+	}
+	return strings.Join(problems, ", ")
+}
+
 // stripMarkdownCodeBlocks removes markdown code block markers from LLM responses
 // Handles both ```json and ``` style code blocks
 func stripMarkdownCodeBlocks(content string) string {
@@ -128,11 +203,17 @@ func stripMarkdownCodeBlocks(content string) string {
 }
 
 func getReleaseRecommendation(score, autoDeployThreshold, reviewRequiredThreshold int) string {
-	if score >= autoDeployThreshold {
+	return recommendationForDecision(getReleaseDecision(score, autoDeployThreshold, reviewRequiredThreshold))
+}
+
+// recommendationForDecision returns the markdown recommendation for a release decision
+func recommendationForDecision(decision string) string {
+	switch decision {
+	case DecisionRecommended:
 		return "✅ Recommended for release"
-	} else if score >= reviewRequiredThreshold {
+	case DecisionReviewRequired:
 		return "⚠️ **MANUAL REVIEW REQUIRED**"
-	} else {
+	default:
 		return "🚫 **RELEASE NOT RECOMMENDED**"
 	}
 }
@@ -183,6 +264,25 @@ type ReportMetadata struct {
 // ReportConfig holds all configuration and data needed for report generation
 type ReportConfig struct {
 	LLMResponse             string
+	Analysis                *StructuredAnalysis          // Pre-parsed analysis; takes precedence over LLMResponse when set
+	Ensemble                *EnsembleResult              // Optional ensemble scoring details
+	Sampling                []*SamplingResult            // Optional self-consistency sampling details, one per model
+	Chunking                *ChunkingResult              // Optional hierarchical analysis details
+	Services                []ServiceResult              // Optional per-service analyses; Analysis is their merged result
+	Rules                   *rules.Result                // Optional deterministic rule evaluation shown next to the AI score
+	Policies                *policy.Set                  // Optional policies that constrain the final score and decision
+	RulesOnly               bool                         // Analysis was derived from Rules because the LLM analysis failed
+	Secrets                 []redaction.Finding          // Secrets redacted from the release data before analysis
+	Injection               []injection.Finding          // Instruction-like content found in the release data
+	Dependencies            []types.DependencyChange     // Parsed dependency changes; replace the model's free-text dependency notes
+	Migrations              []migrations.Finding         // Dangerous statements found in database migrations
+	Infrastructure          []types.InfrastructureChange // Semantic Kubernetes and Helm changes, shown above the model's infrastructure notes
+	APIChanges              []types.APIChange            // Contract changes computed from OpenAPI, Swagger, protobuf and GraphQL files
+	GoAPIChanges            []types.GoAPIChange          // Exported Go identifiers added, removed or changed
+	TestCoverage            []coverage.Signal            // Source changes paired with test changes, per comparison
+	CI                      []ci.Result                  // CI status of each head ref; also evaluated by policies
+	GitHubHosts             []string                     // GitHub Enterprise Server hosts, linked like github.com
+	Format                  string                       // "markdown" (default) or "json"
 	Metadata                *ReportMetadata
 	Comparisons             []*types.Comparison
 	Documentation           []*types.Documentation
@@ -201,21 +301,62 @@ type TemplateData struct {
 	Comparisons           []*types.Comparison
 	Documentation         []*types.Documentation
 	ReleaseRecommendation string
+	Decision              string                         // Machine-readable form of ReleaseRecommendation
+	Policies              []policy.Outcome               // Policies that fired, in policy file order
+	UncappedScore         int                            // Score before policies capped it; 0 when no policy lowered the score
 	AllUserGuidance       []types.UserGuidance           // All user guidance for comprehensive reporting
 	TruncationInfo        *truncation.TruncationMetadata // Optional truncation information
+	Ensemble              *EnsembleResult                // Optional ensemble scoring details
+	Sampling              []*SamplingResult              // Optional self-consistency sampling details
+	Chunking              *ChunkingResult                // Optional hierarchical analysis details
+	Services              []ServiceResult                // Optional per-service analyses
+	Rules                 *rules.Result                  // Optional deterministic rule evaluation
+	RulesOnly             bool                           // No LLM analysis; the report is built from Rules alone
+	Secrets               []redaction.Finding            // Secrets redacted before analysis, with their locations
+	Injection             []injection.Finding            // Suspected prompt injection attempts
+	ScoreInflation        *injection.Inflation           // Set when the AI score is suspiciously far above the rule score
+	Dependencies          []types.DependencyChange       // Parsed dependency changes shown as a table
+	Migrations            []migrations.Finding           // Static migration check findings
+	Infrastructure        []types.InfrastructureChange   // Semantic Kubernetes and Helm changes shown as a table
+	APIChanges            []types.APIChange              // API contract changes, listed under their own heading
+	GoAPIChanges          []types.GoAPIChange            // Go exported API changes, listed under their own heading
+	TestCoverage          []coverage.Signal              // Test coverage signals shown as a table
+	CI                    []ci.Result                    // CI status of each head ref shown as a table
+	GitHubHosts           []string                       // GitHub Enterprise Server hosts, linked like github.com
+	LowConfidence         bool                           // Sampled scores spread beyond the configured threshold
 	AppInterfaceMode      bool
 	FeedbackURL           string
 }
 
-// GenerateReport parses LLM response and generates the final report
-func GenerateReport(config *ReportConfig) (score int, report string, err error) {
-	// Strip markdown code blocks if present (LLMs sometimes wrap JSON in ```json ... ```)
-	jsonContent := stripMarkdownCodeBlocks(config.LLMResponse)
+// ParseAnalysis validates an LLM response against the analysis schema and parses it into a StructuredAnalysis
+// Returns a *ValidationError when the response is not valid JSON or violates the schema
+func ParseAnalysis(llmResponse string) (*StructuredAnalysis, error) {
+	// Strip code fences and surrounding prose (LLMs sometimes wrap JSON in ```json ... ``` or explain it)
+	jsonContent := extractJSON(llmResponse)
+
+	var document any
+	if err := json.Unmarshal([]byte(jsonContent), &document); err != nil {
+		return nil, &ValidationError{Problems: []string{fmt.Sprintf("response is not valid JSON: %v", err)}}
+	}
+	if problems := validateSchema(document, analysisSchema, ""); len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
 
-	// Parse the structured JSON response
 	var analysis StructuredAnalysis
 	if err := json.Unmarshal([]byte(jsonContent), &analysis); err != nil {
-		return 0, "", fmt.Errorf("failed to parse JSON response: %w", err)
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	return &analysis, nil
+}
+
+// GenerateReport parses LLM response and generates the final report
+func GenerateReport(config *ReportConfig) (score int, report string, err error) {
+	analysis := config.Analysis
+	if analysis == nil {
+		analysis, err = ParseAnalysis(config.LLMResponse)
+		if err != nil {
+			return 0, "", err
+		}
 	}
 
 	// Sort user guidance by date (ascending)
@@ -223,22 +364,79 @@ func GenerateReport(config *ReportConfig) (score int, report string, err error)
 		return config.UserGuidance[i].Date.Before(config.UserGuidance[j].Date)
 	})
 
-	// Determine release recommendation based on score
-	recommendation := getReleaseRecommendation(analysis.Score, config.AutoDeployThreshold, config.ReviewRequiredThreshold)
+	// Determine release decision based on score, then let policies constrain it
+	decision := getReleaseDecision(analysis.Score, config.AutoDeployThreshold, config.ReviewRequiredThreshold)
+
+	// An AI score far above the rule-based signals may have been talked up by injected instructions
+	var inflation *injection.Inflation
+	if config.Rules != nil && !config.RulesOnly {
+		inflation = injection.CheckInflation(analysis.Score, config.Rules.Score, config.Injection)
+	}
+	if inflation != nil && len(config.Injection) > 0 {
+		decision = policy.Stricter(decision, DecisionReviewRequired)
+	}
+
+	outcomes := config.Policies.Evaluate(policyInput(config, analysis))
+	score, decision = policy.Apply(analysis.Score, decision, outcomes)
+
+	// A capped score gets at least the decision its thresholds call for, so a cap alone can't read as recommended
+	decision = policy.Stricter(decision, getReleaseDecision(score, config.AutoDeployThreshold, config.ReviewRequiredThreshold))
+
+	// No model reviewed the code in a rules-only run, so a clean rule score alone can't recommend the release
+	if config.RulesOnly {
+		decision = policy.Stricter(decision, DecisionReviewRequired)
+	}
+
+	uncappedScore := 0
+	if score < analysis.Score {
+		uncappedScore = analysis.Score
+		capped := *analysis
+		capped.Score = score
+		analysis = &capped
+	}
 
 	// Create template data
 	templateData := &TemplateData{
-		Analysis:              &analysis,
+		Analysis:              analysis,
 		Metadata:              config.Metadata,
 		Comparisons:           config.Comparisons,
 		Documentation:         config.Documentation,
-		ReleaseRecommendation: recommendation,
+		ReleaseRecommendation: recommendationForDecision(decision),
+		Decision:              decision,
+		Policies:              outcomes,
+		UncappedScore:         uncappedScore,
 		AllUserGuidance:       config.UserGuidance,
 		TruncationInfo:        config.TruncationInfo,
+		Ensemble:              config.Ensemble,
+		Sampling:              config.Sampling,
+		Chunking:              config.Chunking,
+		Services:              config.Services,
+		Rules:                 config.Rules,
+		RulesOnly:             config.RulesOnly,
+		Secrets:               config.Secrets,
+		Injection:             config.Injection,
+		ScoreInflation:        inflation,
+		Dependencies:          config.Dependencies,
+		Migrations:            config.Migrations,
+		Infrastructure:        config.Infrastructure,
+		APIChanges:            config.APIChanges,
+		GoAPIChanges:          config.GoAPIChanges,
+		TestCoverage:          config.TestCoverage,
+		CI:                    config.CI,
+		GitHubHosts:           config.GitHubHosts,
+		LowConfidence:         lowConfidence(config.Sampling) || servicesLowConfidence(config.Services),
 		AppInterfaceMode:      config.AppInterfaceMode,
 		FeedbackURL:           config.FeedbackURL,
 	}
 
+	if config.Format == FormatJSON {
+		report, err := renderJSONReport(templateData)
+		if err != nil {
+			return 0, "", err
+		}
+		return analysis.Score, report, nil
+	}
+
 	// Execute pre-compiled template
 	var buf bytes.Buffer
 	if err := reportTemplate.Execute(&buf, templateData); err != nil {
@@ -247,3 +445,16 @@ func GenerateReport(config *ReportConfig) (score int, report string, err error)
 
 	return analysis.Score, buf.String(), nil
 }
+
+// policyInput collects the release data policies are evaluated against
+func policyInput(config *ReportConfig, analysis *StructuredAnalysis) policy.Input {
+	input := policy.Input{
+		Comparisons: config.Comparisons,
+		Rules:       config.Rules,
+		CI:          config.CI,
+	}
+	for _, concern := range analysis.RiskSummary.Concerns {
+		input.Concerns = append(input.Concerns, policy.Concern{Severity: concern.Severity, Description: concern.Description})
+	}
+	return input
+}
@@ -1,10 +1,13 @@
 package report
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
 
+	"release-confidence-score/internal/analysis/coverage"
+	"release-confidence-score/internal/analysis/migrations"
 	"release-confidence-score/internal/git/types"
 	"release-confidence-score/internal/llm/truncation"
 )
@@ -129,11 +132,15 @@ func TestPRLink(t *testing.T) {
 		{"valid PR", 123, "https://github.com/user/repo", "[#123](https://github.com/user/repo/pull/123)"},
 		{"zero PR", 0, "https://github.com/user/repo", "N/A"},
 		{"negative PR", -1, "https://github.com/user/repo", "N/A"},
+		{"GitHub Enterprise Server PR", 7, "https://GitHub.Example.com/user/repo", "[#7](https://GitHub.Example.com/user/repo/pull/7)"},
+		{"GitHub Enterprise Server PR with explicit port", 10, "https://github.example.com:443/user/repo", "[#10](https://github.example.com:443/user/repo/pull/10)"},
+		{"GitLab MR", 8, "https://gitlab.example.com/group/repo", "[!8](https://gitlab.example.com/group/repo/-/merge_requests/8)"},
+		{"github.com in the path", 9, "https://gitlab.example.com/github.com/repo", "[!9](https://gitlab.example.com/github.com/repo/-/merge_requests/9)"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := prLink(tt.prNumber, tt.repoURL)
+			result := prLink(tt.prNumber, tt.repoURL, []string{"github.example.com"})
 			if result != tt.expected {
 				t.Errorf("prLink(%d, %q) = %q, want %q", tt.prNumber, tt.repoURL, result, tt.expected)
 			}
@@ -151,11 +158,12 @@ func TestFormatAuthor(t *testing.T) {
 		{"github user", "johndoe", "https://github.com/owner/repo/pull/1#comment", "[@johndoe](https://github.com/johndoe)"},
 		{"gitlab user", "janedoe", "https://gitlab.com/owner/repo/-/merge_requests/1#note", "@janedoe"},
 		{"other platform", "user", "https://example.com/comment/1", "@user"},
+		{"GitHub Enterprise Server user", "alice", "https://github.example.com/owner/repo/pull/1#comment", "[@alice](https://github.example.com/alice)"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatAuthor(tt.author, tt.commentURL)
+			result := formatAuthor(tt.author, tt.commentURL, []string{"github.example.com"})
 			if result != tt.expected {
 				t.Errorf("formatAuthor(%q, %q) = %q, want %q", tt.author, tt.commentURL, result, tt.expected)
 			}
@@ -453,6 +461,145 @@ func TestGenerateReport(t *testing.T) {
 	}
 }
 
+func TestGenerateReportAnalysisSections(t *testing.T) {
+	migrationFindings := []migrations.Finding{
+		{Check: migrations.CheckDropColumn, Severity: "high", Repo: "https://github.com/org/app", File: "db/migrations/001.up.sql", Line: 4, Statement: "ALTER TABLE users DROP COLUMN legacy_id", Description: "Dropping a column destroys its data"},
+		{Check: migrations.CheckMissingDownMigration, Severity: "medium", File: "db/migrations/001.up.sql", Description: "No down migration"},
+	}
+	apiChanges := []types.APIChange{
+		{File: "api/openapi.yaml", Kind: "endpoint_removed", Location: "DELETE /users/{id}", Description: "Endpoint removed", Breaking: true},
+		{File: "api/openapi.yaml", Kind: "type_changed", Location: "GET /users response 200 field [].age", Description: "Type changed from integer to string|null", Breaking: true},
+		{File: "api/openapi.yaml", Kind: "endpoint_added", Location: "GET /health", Description: "Endpoint added"},
+		{File: "proto/users.proto", Kind: "field_number_reused", Location: "users.v1.User field 3", Description: "Number 3 reused: field email string replaced by phone string", Breaking: true},
+	}
+	goAPIChanges := []types.GoAPIChange{
+		{Package: "pkg/client", File: "pkg/client/client.go", Identifier: "Get", Kind: "func", Change: "changed", From: "func Get(string) error", To: "func Get(context.Context, string) error", Breaking: true},
+		{Package: "pkg/client", File: "pkg/client/options.go", Identifier: "Options.Proxy", Kind: "field", Change: "added", To: "string"},
+		{Package: "pkg/store", File: "pkg/store/store.go", Identifier: "Number", Kind: "type", Change: "removed", From: "type Number interface{int | float64}", Breaking: true},
+	}
+	coverageSignals := []coverage.Signal{
+		{Repo: "https://github.com/org/api", SourceFiles: 4, TestFiles: 1, SourceLines: 200, TestLines: 50, Ratio: 0.25, Untested: []coverage.UntestedFile{
+			{File: "pkg/auth/token.go", Risk: "critical", Lines: 6},
+			{File: "pkg/billing/invoice.go", Risk: "medium", Lines: 70},
+		}},
+		{Repo: "https://github.com/org/e2e", TestFiles: 2, TestLines: 30},
+	}
+
+	tests := []struct {
+		name       string
+		section    string
+		config     ReportConfig // Only the section's data; analysis, metadata and thresholds are filled in
+		expected   []string
+		unexpected []string
+		checkJSON  func(t *testing.T, jsonReport JSONReport)
+	}{
+		{
+			name:    "migration checks",
+			section: "🗄️ Migration Checks",
+			config:  ReportConfig{Migrations: migrationFindings},
+			expected: []string{
+				"| `drop_column` | high | `db/migrations/001.up.sql:4` (https://github.com/org/app) | `ALTER TABLE users DROP COLUMN legacy_id` | Dropping a column destroys its data |",
+				"| `missing_down_migration` | medium | `db/migrations/001.up.sql` | - | No down migration |",
+			},
+			checkJSON: func(t *testing.T, jsonReport JSONReport) {
+				if len(jsonReport.Migrations) != 2 || jsonReport.Migrations[0] != migrationFindings[0] {
+					t.Errorf("Migrations = %+v, want the findings", jsonReport.Migrations)
+				}
+			},
+		},
+		{
+			name:    "API contract changes",
+			section: "📜 API Contract Changes",
+			config:  ReportConfig{APIChanges: apiChanges},
+			expected: []string{
+				"| `endpoint_removed` | Endpoint removed | `DELETE /users/{id}` | ⚠️ yes | `api/openapi.yaml` |",
+				"| `type_changed` | Type changed from integer to string\\|null | `GET /users response 200 field [].age` | ⚠️ yes | `api/openapi.yaml` |",
+				"| `endpoint_added` | Endpoint added | `GET /health` | no | `api/openapi.yaml` |",
+				"| `field_number_reused` | Number 3 reused: field email string replaced by phone string | `users.v1.User field 3` | ⚠️ yes | `proto/users.proto` |",
+			},
+			checkJSON: func(t *testing.T, jsonReport JSONReport) {
+				if len(jsonReport.APIChanges) != 4 || jsonReport.APIChanges[0] != apiChanges[0] {
+					t.Errorf("APIChanges = %+v, want the computed changes", jsonReport.APIChanges)
+				}
+			},
+		},
+		{
+			name:    "Go API changes",
+			section: "🧩 Go API Changes",
+			config:  ReportConfig{GoAPIChanges: goAPIChanges},
+			expected: []string{
+				"| `pkg/client` | `Get` (func) | changed | `func Get(string) error → func Get(context.Context, string) error` | ⚠️ yes |",
+				"| `pkg/client` | `Options.Proxy` (field) | added | `string` | no |",
+				"| `pkg/store` | `Number` (type) | removed | `type Number interface{int \\| float64}` | ⚠️ yes |",
+			},
+			checkJSON: func(t *testing.T, jsonReport JSONReport) {
+				if len(jsonReport.GoAPIChanges) != 3 || jsonReport.GoAPIChanges[0] != goAPIChanges[0] {
+					t.Errorf("GoAPIChanges = %+v, want the computed changes", jsonReport.GoAPIChanges)
+				}
+			},
+		},
+		{
+			name:    "test coverage signals",
+			section: "🧪 Test Coverage Signals",
+			config:  ReportConfig{TestCoverage: coverageSignals},
+			expected: []string{
+				"| https://github.com/org/api | 4 | 1 | 200 | 50 | 0.25 |",
+				"| https://github.com/org/e2e | 0 | 2 | 0 | 30 | - |",
+				"**Risky source files without a test change** in https://github.com/org/api:",
+				"| `pkg/auth/token.go` | critical | 6 |",
+				"| `pkg/billing/invoice.go` | medium | 70 |",
+			},
+			unexpected: []string{"without a test change** in https://github.com/org/e2e"},
+			checkJSON: func(t *testing.T, jsonReport JSONReport) {
+				if len(jsonReport.TestCoverage) != 2 || len(jsonReport.TestCoverage[0].Untested) != 2 {
+					t.Errorf("TestCoverage = %+v, want the computed signals", jsonReport.TestCoverage)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generate := func(config ReportConfig) string {
+				config.Analysis = &StructuredAnalysis{Score: 85, Summary: "Release"}
+				config.Metadata = &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()}
+				config.AutoDeployThreshold = 80
+				config.ReviewRequiredThreshold = 60
+
+				_, report, err := GenerateReport(&config)
+				if err != nil {
+					t.Fatalf("GenerateReport() error = %v", err)
+				}
+				return report
+			}
+
+			report := generate(tt.config)
+			for _, want := range append([]string{tt.section}, tt.expected...) {
+				if !strings.Contains(report, want) {
+					t.Errorf("GenerateReport() report missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.unexpected {
+				if strings.Contains(report, unwanted) {
+					t.Errorf("GenerateReport() report unexpectedly contains %q", unwanted)
+				}
+			}
+
+			if report := generate(ReportConfig{}); strings.Contains(report, tt.section) {
+				t.Errorf("expected no %q section without data", tt.section)
+			}
+
+			jsonConfig := tt.config
+			jsonConfig.Format = FormatJSON
+			var jsonReport JSONReport
+			if err := json.Unmarshal([]byte(generate(jsonConfig)), &jsonReport); err != nil {
+				t.Fatalf("failed to parse JSON report: %v", err)
+			}
+			tt.checkJSON(t, jsonReport)
+		})
+	}
+}
+
 func TestGenerateReportInvalidJSON(t *testing.T) {
 	config := &ReportConfig{
 		LLMResponse:             "not valid json",
@@ -646,6 +793,35 @@ func TestGenerateReportWithComparisons(t *testing.T) {
 	}
 }
 
+func TestGenerateReportGitHubEnterpriseLinks(t *testing.T) {
+	_, report, err := GenerateReport(&ReportConfig{
+		Analysis: &StructuredAnalysis{Score: 85, Summary: "GHES"},
+		Comparisons: []*types.Comparison{{
+			RepoURL: "https://github.example.com/org/api",
+			Commits: []types.Commit{{SHA: "abc123def456", ShortSHA: "abc123", Message: "Fix bug", Author: "Alice", PRNumber: 7}},
+		}},
+		UserGuidance: []types.UserGuidance{
+			{Content: "Safe to ship", Author: "bob", Date: time.Now(), CommentURL: "https://github.example.com/org/api/pull/7#issuecomment-1", IsAuthorized: true},
+		},
+		GitHubHosts:             []string{"github.example.com"},
+		Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"[#7](https://github.example.com/org/api/pull/7)",
+		"[@bob](https://github.example.com/bob)",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("GenerateReport() report missing %q", want)
+		}
+	}
+}
+
 func TestGenerateReportWithDocumentation(t *testing.T) {
 	jsonResponse := `{
 		"score": 90,
@@ -864,3 +1040,40 @@ func TestGenerateReportWithAggressiveTruncation(t *testing.T) {
 		t.Error("GenerateReport() report missing aggressive truncation details")
 	}
 }
+
+func TestGenerateReportListsOmittedContent(t *testing.T) {
+	truncationInfo := &truncation.TruncationMetadata{
+		Truncated:             true,
+		Level:                 "high",
+		TotalFiles:            3,
+		FilesPreserved:        1,
+		FilesTruncated:        2,
+		DescriptionsTruncated: 3,
+		Omitted: []truncation.OmittedContent{
+			{File: "internal/handler.go", Hunks: []string{"@@ -10,4 +10,6 @@", "@@ -80,2 +82,2 @@"}, Lines: 42},
+			{File: "docs/guide.md", Lines: 120},
+		},
+	}
+
+	_, report, err := GenerateReport(&ReportConfig{
+		Analysis:                &StructuredAnalysis{Score: 70, Summary: "Truncated"},
+		Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+		TruncationInfo:          truncationInfo,
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"Omitted content by file",
+		"- `internal/handler.go`: 42 lines in 2 hunks (@@ -10,4 +10,6 @@, @@ -80,2 +82,2 @@)",
+		"- `docs/guide.md`: 120 lines from the middle of the patch",
+		"- Tails of 3 long PR/MR descriptions and commit message bodies",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("GenerateReport() report missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package report
+
+import (
+	"fmt"
+
+	"release-confidence-score/internal/analysis/rules"
+)
+
+// RulesOnlyAnalysis builds an analysis from the rule evaluation alone, for runs where the LLM analysis failed
+// Every rule finding becomes a concern, and the release is flagged for manual review
+func RulesOnlyAnalysis(result *rules.Result, reason string) *StructuredAnalysis {
+	analysis := &StructuredAnalysis{
+		Score:   result.Score,
+		Summary: fmt.Sprintf("The LLM analysis could not be completed (%s), so this score was computed from deterministic rules only. It reflects release size and risk signals, not a review of the code changes.", reason),
+		RiskSummary: RiskSummary{
+			Concerns:  []RiskConcern{},
+			Positives: []string{},
+		},
+		ActionItems: ActionItems{
+			Critical:  []string{},
+			Important: []string{"Review the code changes manually; no AI analysis was performed for this release"},
+			Followup:  []string{},
+		},
+		TechnicalDetails: TechnicalDetails{
+			Code:           []string{},
+			Infrastructure: []string{},
+			Dependencies:   []string{},
+		},
+		DocumentationQuality:         "Not assessed: the LLM analysis was unavailable.",
+		DocumentationRecommendations: "Not assessed: the LLM analysis was unavailable.",
+	}
+
+	for _, finding := range result.Findings {
+		analysis.RiskSummary.Concerns = append(analysis.RiskSummary.Concerns, RiskConcern{
+			Severity:    finding.Severity,
+			Description: finding.Description,
+		})
+	}
+	return analysis
+}
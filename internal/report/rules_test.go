@@ -0,0 +1,155 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"release-confidence-score/internal/analysis/rules"
+)
+
+func testRuleResult() *rules.Result {
+	return &rules.Result{
+		Score: 75,
+		Findings: []rules.Finding{
+			{Rule: "migrations", Severity: rules.SeverityHigh, Description: "1 database migration file changed", Penalty: 15, References: []string{"db/migrations/001.sql"}},
+			{Rule: "deleted_files", Severity: rules.SeverityLow, Description: "5 files deleted", Penalty: 10},
+		},
+	}
+}
+
+func TestRulesOnlyAnalysis(t *testing.T) {
+	analysis := RulesOnlyAnalysis(testRuleResult(), "service unavailable")
+
+	if analysis.Score != 75 {
+		t.Errorf("Score = %d, want 75", analysis.Score)
+	}
+	if !strings.Contains(analysis.Summary, "service unavailable") {
+		t.Errorf("Summary = %q, want it to mention the LLM failure", analysis.Summary)
+	}
+	if len(analysis.RiskSummary.Concerns) != 2 {
+		t.Fatalf("expected one concern per finding, got %+v", analysis.RiskSummary.Concerns)
+	}
+	if analysis.RiskSummary.Concerns[0] != (RiskConcern{Severity: "high", Description: "1 database migration file changed"}) {
+		t.Errorf("Concerns[0] = %+v, want the migration finding", analysis.RiskSummary.Concerns[0])
+	}
+	if len(analysis.ActionItems.Important) != 1 {
+		t.Errorf("expected a manual review action item, got %+v", analysis.ActionItems.Important)
+	}
+}
+
+func TestGenerateReportShowsRuleSignals(t *testing.T) {
+	tests := []struct {
+		name        string
+		rulesOnly   bool
+		expected    []string
+		notExpected []string
+	}{
+		{
+			name:      "alongside the AI analysis",
+			rulesOnly: false,
+			expected: []string{
+				"**Rule-based score:** 75/100",
+				"📏 Rule-Based Signals",
+				"The findings were given to the model as evidence.",
+				"| `migrations` | high | -15 | 1 database migration file changed: db/migrations/001.sql |",
+				"| `deleted_files` | low | -10 | 5 files deleted |",
+			},
+			notExpected: []string{"Rules-Only Report"},
+		},
+		{
+			name:      "rules-only report",
+			rulesOnly: true,
+			expected:  []string{"🧮 Rules-Only Report", "📏 Rule-Based Signals", "**Rule score:** 75/100"},
+			notExpected: []string{
+				"**Rule-based score:**",
+				"given to the model as evidence",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := &StructuredAnalysis{Score: 90, Summary: "Looks good"}
+			if tt.rulesOnly {
+				analysis = RulesOnlyAnalysis(testRuleResult(), "timeout")
+			}
+
+			_, report, err := GenerateReport(&ReportConfig{
+				Analysis:                analysis,
+				Rules:                   testRuleResult(),
+				RulesOnly:               tt.rulesOnly,
+				Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+				AutoDeployThreshold:     80,
+				ReviewRequiredThreshold: 60,
+			})
+			if err != nil {
+				t.Fatalf("GenerateReport() error = %v", err)
+			}
+
+			for _, want := range tt.expected {
+				if !strings.Contains(report, want) {
+					t.Errorf("GenerateReport() report missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.notExpected {
+				if strings.Contains(report, unwanted) {
+					t.Errorf("GenerateReport() report should not contain %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateReportRulesOnlyRequiresReview(t *testing.T) {
+	result := &rules.Result{Score: 95}
+
+	_, report, err := GenerateReport(&ReportConfig{
+		Analysis:                RulesOnlyAnalysis(result, "timeout"),
+		Rules:                   result,
+		RulesOnly:               true,
+		Metadata:                &ReportMetadata{ModelID: "rules only", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+
+	if !strings.Contains(report, "MANUAL REVIEW REQUIRED") {
+		t.Error("expected a rules-only report to require manual review")
+	}
+	if strings.Contains(report, "Recommended for release") {
+		t.Error("expected a rules-only report never to recommend the release")
+	}
+}
+
+func TestGenerateReportJSONIncludesRules(t *testing.T) {
+	_, output, err := GenerateReport(&ReportConfig{
+		Analysis:                RulesOnlyAnalysis(testRuleResult(), "timeout"),
+		Rules:                   testRuleResult(),
+		RulesOnly:               true,
+		Format:                  FormatJSON,
+		Metadata:                &ReportMetadata{ModelID: "rules only", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+
+	var jsonReport JSONReport
+	if err := json.Unmarshal([]byte(output), &jsonReport); err != nil {
+		t.Fatalf("failed to parse JSON report: %v", err)
+	}
+	if !jsonReport.RulesOnly {
+		t.Error("RulesOnly = false, want true")
+	}
+	if jsonReport.Rules == nil || jsonReport.Rules.Score != 75 || len(jsonReport.Rules.Findings) != 2 {
+		t.Errorf("Rules = %+v, want the rule evaluation", jsonReport.Rules)
+	}
+	if jsonReport.Decision != DecisionReviewRequired {
+		t.Errorf("Decision = %s, want %s", jsonReport.Decision, DecisionReviewRequired)
+	}
+}
@@ -0,0 +1,64 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+)
+
+// SamplingResult describes how repeated samples of a single model scored a release
+type SamplingResult struct {
+	Model                 string `json:"model"`
+	Requested             int    `json:"requested_samples"`
+	Scores                []int  `json:"scores"`
+	Median                int    `json:"median"`
+	Spread                int    `json:"spread"` // Difference between the highest and lowest sample score
+	SpreadThreshold       int    `json:"spread_threshold"`
+	MinConcernOccurrences int    `json:"min_concern_occurrences"`
+	LowConfidence         bool   `json:"low_confidence"` // Spread exceeded the threshold
+}
+
+// MergeSamples combines repeated samples of one model into a single analysis
+// The score is the median of the samples and only concerns raised in at least minConcernOccurrences
+// samples are kept; 0 means a majority of the successful samples
+func MergeSamples(model string, samples []*StructuredAnalysis, requested, minConcernOccurrences, spreadThreshold int) (*StructuredAnalysis, *SamplingResult, error) {
+	if len(samples) == 0 {
+		return nil, nil, fmt.Errorf("no samples to merge")
+	}
+
+	scores := make([]int, len(samples))
+	for i, sample := range samples {
+		scores[i] = sample.Score
+	}
+
+	// Requiring more occurrences than there are samples would drop every concern when some samples failed
+	if minConcernOccurrences == 0 {
+		minConcernOccurrences = len(samples)/2 + 1
+	}
+	minConcernOccurrences = min(minConcernOccurrences, len(samples))
+
+	median := medianScore(scores)
+	spread := slices.Max(scores) - slices.Min(scores)
+
+	result := &SamplingResult{
+		Model:                 model,
+		Requested:             requested,
+		Scores:                scores,
+		Median:                median,
+		Spread:                spread,
+		SpreadThreshold:       spreadThreshold,
+		MinConcernOccurrences: minConcernOccurrences,
+		LowConfidence:         spread > spreadThreshold,
+	}
+
+	return mergeAnalyses(samples, median, minConcernOccurrences), result, nil
+}
+
+// lowConfidence reports whether any sampled model's scores spread beyond the threshold
+func lowConfidence(sampling []*SamplingResult) bool {
+	for _, s := range sampling {
+		if s.LowConfidence {
+			return true
+		}
+	}
+	return false
+}
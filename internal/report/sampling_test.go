@@ -0,0 +1,206 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"release-confidence-score/internal/git/types"
+)
+
+func TestMergeSamples(t *testing.T) {
+	sample := func(score int, concerns ...string) *StructuredAnalysis {
+		analysis := &StructuredAnalysis{Score: score, Summary: "summary"}
+		for _, c := range concerns {
+			analysis.RiskSummary.Concerns = append(analysis.RiskSummary.Concerns, RiskConcern{Severity: "high", Description: c})
+		}
+		return analysis
+	}
+
+	tests := []struct {
+		name                  string
+		samples               []*StructuredAnalysis
+		requested             int
+		minOccurrences        int
+		expectedMedian        int
+		expectedSpread        int
+		expectedLowConfidence bool
+		expectedConcerns      []string
+		expectedMinOccurrence int
+	}{
+		{
+			name:                  "stable samples keep majority concerns",
+			samples:               []*StructuredAnalysis{sample(80, "Schema change", "Flaky test"), sample(82, "Schema change"), sample(78, "schema change.")},
+			requested:             3,
+			expectedMedian:        80,
+			expectedSpread:        4,
+			expectedConcerns:      []string{"Schema change"},
+			expectedMinOccurrence: 2,
+		},
+		{
+			name:                  "high spread is low confidence",
+			samples:               []*StructuredAnalysis{sample(90), sample(60), sample(75)},
+			requested:             3,
+			expectedMedian:        75,
+			expectedSpread:        30,
+			expectedLowConfidence: true,
+			expectedMinOccurrence: 2,
+		},
+		{
+			name:                  "explicit occurrences capped at successful samples",
+			samples:               []*StructuredAnalysis{sample(70, "Auth change"), sample(72, "Auth change")},
+			requested:             4,
+			minOccurrences:        3,
+			expectedMedian:        71,
+			expectedSpread:        2,
+			expectedConcerns:      []string{"Auth change"},
+			expectedMinOccurrence: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, result, err := MergeSamples("model", tt.samples, tt.requested, tt.minOccurrences, 10)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if merged.Score != tt.expectedMedian || result.Median != tt.expectedMedian {
+				t.Errorf("score = %d, median = %d, want %d", merged.Score, result.Median, tt.expectedMedian)
+			}
+			if result.Spread != tt.expectedSpread {
+				t.Errorf("Spread = %d, want %d", result.Spread, tt.expectedSpread)
+			}
+			if result.LowConfidence != tt.expectedLowConfidence {
+				t.Errorf("LowConfidence = %v, want %v", result.LowConfidence, tt.expectedLowConfidence)
+			}
+			if result.MinConcernOccurrences != tt.expectedMinOccurrence {
+				t.Errorf("MinConcernOccurrences = %d, want %d", result.MinConcernOccurrences, tt.expectedMinOccurrence)
+			}
+			if result.Requested != tt.requested || len(result.Scores) != len(tt.samples) {
+				t.Errorf("Requested = %d, Scores = %v", result.Requested, result.Scores)
+			}
+			if len(merged.RiskSummary.Concerns) != len(tt.expectedConcerns) {
+				t.Fatalf("concerns = %+v, want %v", merged.RiskSummary.Concerns, tt.expectedConcerns)
+			}
+			for i, description := range tt.expectedConcerns {
+				if merged.RiskSummary.Concerns[i].Description != description {
+					t.Errorf("concern[%d] = %q, want %q", i, merged.RiskSummary.Concerns[i].Description, description)
+				}
+			}
+		})
+	}
+
+	t.Run("no samples", func(t *testing.T) {
+		if _, _, err := MergeSamples("model", nil, 3, 0, 10); err == nil {
+			t.Error("expected error for empty samples")
+		}
+	})
+}
+
+func TestGenerateReport_Sampling(t *testing.T) {
+	sampling := []*SamplingResult{{
+		Model:                 "claude-test",
+		Requested:             3,
+		Scores:                []int{90, 60, 75},
+		Median:                75,
+		Spread:                30,
+		SpreadThreshold:       10,
+		MinConcernOccurrences: 2,
+		LowConfidence:         true,
+	}}
+
+	_, report, err := GenerateReport(&ReportConfig{
+		Analysis:                &StructuredAnalysis{Score: 75, Summary: "Sampled"},
+		Sampling:                sampling,
+		Metadata:                &ReportMetadata{ModelID: "claude-test", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"Low-Confidence Assessment",
+		"Score Stability",
+		"| claude-test | 3/3 | 90, 60, 75 | 75/100 | 30 ⚠️ |",
+		"at least 2 samples",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+}
+
+func TestGenerateReport_JSON(t *testing.T) {
+	generated := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name                  string
+		sampling              []*SamplingResult
+		expectedLowConfidence bool
+	}{
+		{"without sampling", nil, false},
+		{"low-confidence sampling", []*SamplingResult{{Model: "claude-test", Scores: []int{55, 80}, Median: 67, Spread: 25, SpreadThreshold: 10, LowConfidence: true}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, output, err := GenerateReport(&ReportConfig{
+				LLMResponse:             `{"score": 67, "summary": "JSON summary"}`,
+				Sampling:                tt.sampling,
+				Format:                  FormatJSON,
+				Metadata:                &ReportMetadata{ModelID: "claude-test", GenerationTime: generated},
+				Comparisons:             []*types.Comparison{{RepoURL: "https://github.com/org/repo"}},
+				AutoDeployThreshold:     80,
+				ReviewRequiredThreshold: 60,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if score != 67 {
+				t.Errorf("score = %d, want 67", score)
+			}
+
+			var parsed JSONReport
+			if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+				t.Fatalf("report is not valid JSON: %v\n%s", err, output)
+			}
+			if parsed.Score != 67 || parsed.Decision != DecisionReviewRequired {
+				t.Errorf("score/decision = %d/%s, want 67/%s", parsed.Score, parsed.Decision, DecisionReviewRequired)
+			}
+			if parsed.LowConfidence != tt.expectedLowConfidence {
+				t.Errorf("LowConfidence = %v, want %v", parsed.LowConfidence, tt.expectedLowConfidence)
+			}
+			if parsed.Model != "claude-test" || !parsed.GeneratedAt.Equal(generated) {
+				t.Errorf("metadata = %s/%v", parsed.Model, parsed.GeneratedAt)
+			}
+			if len(parsed.Repositories) != 1 || parsed.Analysis.Summary != "JSON summary" {
+				t.Errorf("unexpected report content: %+v", parsed)
+			}
+			if len(parsed.Sampling) != len(tt.sampling) {
+				t.Errorf("Sampling = %+v, want %d entries", parsed.Sampling, len(tt.sampling))
+			}
+		})
+	}
+}
+
+func TestGetReleaseDecision(t *testing.T) {
+	tests := []struct {
+		score    int
+		expected string
+	}{
+		{90, DecisionRecommended},
+		{80, DecisionRecommended},
+		{79, DecisionReviewRequired},
+		{60, DecisionReviewRequired},
+		{59, DecisionNotRecommended},
+	}
+
+	for _, tt := range tests {
+		if result := getReleaseDecision(tt.score, 80, 60); result != tt.expected {
+			t.Errorf("getReleaseDecision(%d) = %s, want %s", tt.score, result, tt.expected)
+		}
+	}
+}
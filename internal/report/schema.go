@@ -0,0 +1,192 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"release-confidence-score/internal/llm/schema"
+)
+
+// analysisSchema is the full analysis schema that LLM responses are validated against
+var analysisSchema = schema.Analysis()
+
+// ValidationError lists every way an LLM response violates the analysis schema
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("LLM response failed schema validation: %s", strings.Join(e.Problems, "; "))
+}
+
+// extractJSON returns the first JSON object in content
+// Code fences and any prose before or after the object are dropped; an unterminated
+// object is returned as-is so the parse error reports the truncation
+func extractJSON(content string) string {
+	trimmed := stripMarkdownCodeBlocks(content)
+
+	start := strings.Index(trimmed, "{")
+	if start == -1 {
+		return trimmed
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(trimmed); i++ {
+		c := trimmed[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return trimmed[start : i+1]
+			}
+		}
+	}
+
+	return trimmed[start:]
+}
+
+// validateSchema validates a decoded JSON value against a schema node
+// Supports the subset of JSON Schema used by the analysis schema: type, required,
+// properties, items, enum, minimum, maximum and minLength
+func validateSchema(value any, schema map[string]any, path string) []string {
+	var problems []string
+
+	if schemaType, ok := schema["type"].(string); ok && !matchesType(value, schemaType) {
+		return []string{fmt.Sprintf("%s must be of type %s, got %s", displayPath(path), schemaType, jsonTypeName(value))}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		problems = append(problems, fmt.Sprintf("%s must be one of %v, got %v", displayPath(path), enum, formatValue(value)))
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if minimum, ok := schema["minimum"].(float64); ok && v < minimum {
+			problems = append(problems, fmt.Sprintf("%s must be at least %g, got %g", displayPath(path), minimum, v))
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && v > maximum {
+			problems = append(problems, fmt.Sprintf("%s must be at most %g, got %g", displayPath(path), maximum, v))
+		}
+
+	case string:
+		if minLength, ok := schema["minLength"].(float64); ok && len(strings.TrimSpace(v)) < int(minLength) {
+			problems = append(problems, fmt.Sprintf("%s must not be empty", displayPath(path)))
+		}
+
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, present := v[name.(string)]; !present {
+					problems = append(problems, fmt.Sprintf("%s is required", joinPath(path, name.(string))))
+				}
+			}
+		}
+		if properties, ok := schema["properties"].(map[string]any); ok {
+			// Sorted so the problem list is stable between runs
+			names := make([]string, 0, len(properties))
+			for name := range properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				if propertyValue, present := v[name]; present {
+					problems = append(problems, validateSchema(propertyValue, properties[name].(map[string]any), joinPath(path, name))...)
+				}
+			}
+		}
+
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				problems = append(problems, validateSchema(item, items, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+
+	return problems
+}
+
+// matchesType reports whether a decoded JSON value has the given JSON Schema type
+func matchesType(value any, schemaType string) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	default:
+		return true
+	}
+}
+
+// jsonTypeName returns the JSON type name of a decoded value for error messages
+func jsonTypeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func formatValue(value any) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "response"
+	}
+	return path
+}
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain object", `{"score": 80}`, `{"score": 80}`},
+		{"code fence", "```json\n{\"score\": 80}\n```", `{"score": 80}`},
+		{"leading and trailing prose", "Here you go:\n{\"score\": 80}\nHope this helps!", `{"score": 80}`},
+		{"braces inside strings", `{"summary": "uses {templates} and \"quotes}\""} trailing`, `{"summary": "uses {templates} and \"quotes}\""}`},
+		{"nested objects", `{"a": {"b": {}}} extra`, `{"a": {"b": {}}}`},
+		{"truncated object", `{"score": 80, "summary": "cut`, `{"score": 80, "summary": "cut`},
+		{"no object", "no json here", "no json here"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := extractJSON(tt.input)
+			if result != tt.expected {
+				t.Errorf("extractJSON() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseAnalysis_Validation(t *testing.T) {
+	tests := []struct {
+		name             string
+		response         string
+		expectedProblems []string
+	}{
+		{
+			name:     "valid response",
+			response: `{"score": 72, "summary": "OK", "risk_summary": {"concerns": [{"severity": "high", "description": "Risk"}], "positives": []}}`,
+		},
+		{
+			name:     "unknown fields are ignored",
+			response: `{"score": 72, "summary": "OK", "confidence": "high"}`,
+		},
+		{
+			name:             "truncated JSON",
+			response:         `{"score": 72, "summary": "O`,
+			expectedProblems: []string{"response is not valid JSON"},
+		},
+		{
+			name:             "score out of range",
+			response:         `{"score": 120, "summary": "OK"}`,
+			expectedProblems: []string{"score must be at most 100, got 120"},
+		},
+		{
+			name:             "fractional score",
+			response:         `{"score": 72.5, "summary": "OK"}`,
+			expectedProblems: []string{"score must be of type integer, got number"},
+		},
+		{
+			name:             "missing required fields",
+			response:         `{"risk_summary": {}}`,
+			expectedProblems: []string{"score is required", "summary is required"},
+		},
+		{
+			name:     "unknown severity and empty description",
+			response: `{"score": 50, "summary": "OK", "risk_summary": {"concerns": [{"severity": "blocker", "description": " "}]}}`,
+			expectedProblems: []string{
+				`risk_summary.concerns[0].description must not be empty`,
+				`risk_summary.concerns[0].severity must be one of [critical high medium low], got "blocker"`,
+			},
+		},
+		{
+			name:             "wrong list type",
+			response:         `{"score": 50, "summary": "OK", "action_items": {"critical": "Run migration"}}`,
+			expectedProblems: []string{"action_items.critical must be of type array, got string"},
+		},
+		{
+			name:             "not an object",
+			response:         `[1, 2, 3]`,
+			expectedProblems: []string{"response must be of type object, got array"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis, err := ParseAnalysis(tt.response)
+
+			if len(tt.expectedProblems) == 0 {
+				if err != nil {
+					t.Fatalf("ParseAnalysis() unexpected error: %v", err)
+				}
+				if analysis == nil {
+					t.Fatal("ParseAnalysis() returned nil analysis")
+				}
+				return
+			}
+
+			validationErr, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("ParseAnalysis() error = %v, want *ValidationError", err)
+			}
+			if len(validationErr.Problems) != len(tt.expectedProblems) {
+				t.Fatalf("Problems = %v, want %v", validationErr.Problems, tt.expectedProblems)
+			}
+			for i, want := range tt.expectedProblems {
+				if !strings.Contains(validationErr.Problems[i], want) {
+					t.Errorf("Problems[%d] = %q, want it to contain %q", i, validationErr.Problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalysisSchema_DescribesStructuredAnalysis(t *testing.T) {
+	// Every StructuredAnalysis field must be described by the schema
+	encoded, _ := json.Marshal(StructuredAnalysis{})
+	var fields map[string]any
+	json.Unmarshal(encoded, &fields)
+
+	properties := analysisSchema["properties"].(map[string]any)
+	for field := range fields {
+		if _, ok := properties[field]; !ok {
+			t.Errorf("schema is missing property %q", field)
+		}
+	}
+}
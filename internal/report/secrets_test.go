@@ -0,0 +1,91 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"release-confidence-score/internal/llm/redaction"
+)
+
+func testSecrets() []redaction.Finding {
+	return []redaction.Finding{
+		{Type: redaction.TypeAWSAccessKey, Repo: "https://github.com/org/repo", Location: "config/prod.env", Line: 12},
+		{Type: redaction.TypeGitHubToken, Repo: "https://github.com/org/repo", Location: "scripts/deploy.sh", Line: 4, Removed: true},
+		{Type: redaction.TypeHighEntropy, Location: "guidance by @alice", Line: 1},
+	}
+}
+
+func TestGenerateReportShowsSecrets(t *testing.T) {
+	tests := []struct {
+		name        string
+		secrets     []redaction.Finding
+		expected    []string
+		notExpected []string
+	}{
+		{
+			name:    "secrets found",
+			secrets: testSecrets(),
+			expected: []string{
+				"**🔐 Secrets Detected in Diff** — 3 likely secret(s)",
+				"<summary><strong>🔐 Secrets Detected in Diff</strong></summary>",
+				"| `aws_access_key` | `config/prod.env` (https://github.com/org/repo) | 12 |",
+				"| `github_token` | `scripts/deploy.sh` (https://github.com/org/repo) | 4 (removed) |",
+				"| `high_entropy_secret` | `guidance by @alice` | 1 |",
+			},
+		},
+		{
+			name:        "no secrets",
+			notExpected: []string{"Secrets Detected"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, report, err := GenerateReport(&ReportConfig{
+				Analysis:                &StructuredAnalysis{Score: 90, Summary: "Looks good"},
+				Secrets:                 tt.secrets,
+				Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+				AutoDeployThreshold:     80,
+				ReviewRequiredThreshold: 60,
+			})
+			if err != nil {
+				t.Fatalf("GenerateReport() error = %v", err)
+			}
+
+			for _, want := range tt.expected {
+				if !strings.Contains(report, want) {
+					t.Errorf("GenerateReport() report missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.notExpected {
+				if strings.Contains(report, unwanted) {
+					t.Errorf("GenerateReport() report should not contain %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateReportJSONIncludesSecrets(t *testing.T) {
+	_, output, err := GenerateReport(&ReportConfig{
+		Analysis:                &StructuredAnalysis{Score: 90, Summary: "Looks good"},
+		Secrets:                 testSecrets(),
+		Format:                  FormatJSON,
+		Metadata:                &ReportMetadata{ModelID: "test-model", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+
+	var jsonReport JSONReport
+	if err := json.Unmarshal([]byte(output), &jsonReport); err != nil {
+		t.Fatalf("failed to parse JSON report: %v", err)
+	}
+	if len(jsonReport.Secrets) != 3 || jsonReport.Secrets[1] != testSecrets()[1] {
+		t.Errorf("Secrets = %+v, want the redaction findings", jsonReport.Secrets)
+	}
+}
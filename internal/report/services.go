@@ -0,0 +1,75 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+
+	"release-confidence-score/internal/llm/truncation"
+)
+
+// ServiceResult is the analysis of one repository in a per-service release analysis
+type ServiceResult struct {
+	Service    string                         `json:"service"` // Repository path, e.g. "org/repo"
+	RepoURL    string                         `json:"repo_url"`
+	Files      int                            `json:"files"`
+	Analysis   *StructuredAnalysis            `json:"analysis"`
+	Truncation *truncation.TruncationMetadata `json:"truncation,omitempty"`
+	Chunking   *ChunkingResult                `json:"chunking,omitempty"`
+	Ensemble   *EnsembleResult                `json:"ensemble,omitempty"`
+	Sampling   []*SamplingResult              `json:"sampling,omitempty"`
+}
+
+// LowConfidence reports whether any sampled model's scores for the service spread beyond the threshold
+func (s ServiceResult) LowConfidence() bool {
+	return lowConfidence(s.Sampling)
+}
+
+// ModelsDisagree reports whether ensemble members disagreed on the service's score
+func (s ServiceResult) ModelsDisagree() bool {
+	return s.Ensemble != nil && len(s.Ensemble.Disagreements) > 0
+}
+
+// TopConcern returns the most severe concern raised for the service, or nil when there are none
+func (s ServiceResult) TopConcern() *RiskConcern {
+	var top *RiskConcern
+	for i, concern := range s.Analysis.RiskSummary.Concerns {
+		if top == nil || severityOrder(concern.Severity) < severityOrder(top.Severity) {
+			top = &s.Analysis.RiskSummary.Concerns[i]
+		}
+	}
+	return top
+}
+
+// MergeServices combines per-service analyses into one release analysis
+// The release is only as safe as its riskiest service, so the lowest service score is used;
+// findings are the deduplicated union of all services
+func MergeServices(services []ServiceResult) (*StructuredAnalysis, error) {
+	if len(services) == 0 {
+		return nil, fmt.Errorf("no service analyses to merge")
+	}
+
+	analyses := make([]*StructuredAnalysis, len(services))
+	for i, service := range services {
+		analyses[i] = service.Analysis
+	}
+	return mergeLowest(analyses), nil
+}
+
+// servicesLowConfidence reports whether any service's assessment is low-confidence
+func servicesLowConfidence(services []ServiceResult) bool {
+	for _, service := range services {
+		if service.LowConfidence() {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeLowest merges analyses using the lowest score
+func mergeLowest(analyses []*StructuredAnalysis) *StructuredAnalysis {
+	scores := make([]int, len(analyses))
+	for i, analysis := range analyses {
+		scores[i] = analysis.Score
+	}
+	return mergeAnalyses(analyses, slices.Min(scores), 1)
+}
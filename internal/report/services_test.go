@@ -0,0 +1,141 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeServices(t *testing.T) {
+	services := []ServiceResult{
+		{Service: "org/api", Analysis: &StructuredAnalysis{Score: 90, Summary: "API is safe", RiskSummary: RiskSummary{
+			Concerns: []RiskConcern{{Severity: "low", Description: "New log line"}},
+		}}},
+		{Service: "org/worker", Analysis: &StructuredAnalysis{Score: 45, Summary: "Worker is risky", RiskSummary: RiskSummary{
+			Concerns: []RiskConcern{{Severity: "critical", Description: "Job drops queue"}},
+		}}},
+	}
+
+	merged, err := MergeServices(services)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if merged.Score != 45 {
+		t.Errorf("Score = %d, want the lowest service score 45", merged.Score)
+	}
+	if merged.Summary != "Worker is risky" {
+		t.Errorf("Summary = %q, want the riskiest service's summary", merged.Summary)
+	}
+	if len(merged.RiskSummary.Concerns) != 2 || merged.RiskSummary.Concerns[0].Description != "Job drops queue" {
+		t.Errorf("concerns = %+v, want both services' concerns ordered by severity", merged.RiskSummary.Concerns)
+	}
+
+	t.Run("no services", func(t *testing.T) {
+		if _, err := MergeServices(nil); err == nil {
+			t.Error("expected error for empty service list")
+		}
+	})
+}
+
+func TestServiceResultTopConcern(t *testing.T) {
+	tests := []struct {
+		name     string
+		concerns []RiskConcern
+		expected string
+	}{
+		{name: "no concerns", expected: ""},
+		{
+			name:     "most severe concern",
+			concerns: []RiskConcern{{Severity: "medium", Description: "A"}, {Severity: "high", Description: "B"}, {Severity: "high", Description: "C"}},
+			expected: "B",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := ServiceResult{Analysis: &StructuredAnalysis{RiskSummary: RiskSummary{Concerns: tt.concerns}}}
+
+			top := service.TopConcern()
+			if tt.expected == "" {
+				if top != nil {
+					t.Errorf("TopConcern() = %+v, want nil", top)
+				}
+				return
+			}
+			if top == nil || top.Description != tt.expected {
+				t.Errorf("TopConcern() = %+v, want %s", top, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateReport_Services(t *testing.T) {
+	services := []ServiceResult{
+		{
+			Service:  "org/worker",
+			RepoURL:  "https://github.com/org/worker",
+			Files:    3,
+			Analysis: &StructuredAnalysis{Score: 45, Summary: "Worker is risky", RiskSummary: RiskSummary{Concerns: []RiskConcern{{Severity: "critical", Description: "Drops | queue"}}}},
+			Sampling: []*SamplingResult{{Model: "claude-test", Scores: []int{60, 30}, Spread: 30, SpreadThreshold: 10, LowConfidence: true}},
+		},
+		{
+			Service:  "org/api",
+			RepoURL:  "https://github.com/org/api",
+			Files:    1,
+			Analysis: &StructuredAnalysis{Score: 90, Summary: "API is safe"},
+			Chunking: &ChunkingResult{Chunks: []ChunkResult{{Label: "a"}, {Label: "b"}}, Aggregated: true},
+		},
+	}
+
+	_, report, err := GenerateReport(&ReportConfig{
+		Analysis:                &StructuredAnalysis{Score: 45, Summary: "Merged"},
+		Services:                services,
+		Metadata:                &ReportMetadata{ModelID: "claude-test", GenerationTime: time.Now()},
+		AutoDeployThreshold:     80,
+		ReviewRequiredThreshold: 60,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"Score by Service",
+		"| [org/worker](https://github.com/org/worker) | 45/100 🎲 | 3 | [critical] Drops \\| queue |",
+		"| [org/api](https://github.com/org/api) | 90/100 | 1 | None |",
+		"Low-Confidence Assessment",
+		"See *Service Analyses* below",
+		"### org/worker — 45/100",
+		"*Samples from claude-test:* 60, 30 (spread 30 ⚠️ above 10)",
+		"*Analyzed hierarchically in 2 chunks.*",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+
+	t.Run("json", func(t *testing.T) {
+		_, output, err := GenerateReport(&ReportConfig{
+			Analysis:                &StructuredAnalysis{Score: 45, Summary: "Merged"},
+			Services:                services,
+			Format:                  FormatJSON,
+			AutoDeployThreshold:     80,
+			ReviewRequiredThreshold: 60,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var parsed JSONReport
+		if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+			t.Fatalf("report is not valid JSON: %v", err)
+		}
+		if !parsed.LowConfidence {
+			t.Error("LowConfidence should be set when a service is low-confidence")
+		}
+		if len(parsed.Services) != 2 || parsed.Services[0].Service != "org/worker" || parsed.Services[0].Analysis.Score != 45 {
+			t.Errorf("Services = %+v, want both services", parsed.Services)
+		}
+	})
+}